@@ -4,6 +4,8 @@ import (
 	"log"
 
 	"github.com/CESARBR/knot-babeltower/internal/config"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
 	"github.com/streadway/amqp"
 )
 
@@ -17,6 +19,7 @@ type SimpleClient interface {
 
 type simpleClient struct {
 	channel   *amqp.Channel
+	memory    *network.Memory
 	queueName string
 }
 
@@ -26,6 +29,11 @@ func NewSimpleSender() SimpleClient {
 }
 
 func (s *simpleClient) Connect(config config.RabbitMQ) error {
+	if network.IsMemoryURL(config.URL) {
+		s.memory = network.GetMemory(config.URL, logging.NewLogrus("info").Get("Memory"))
+		return nil
+	}
+
 	conn, err := amqp.Dial(config.URL)
 	if err != nil {
 		log.Printf("Unable to connect: %s", err)
@@ -41,6 +49,9 @@ func (s *simpleClient) Connect(config config.RabbitMQ) error {
 }
 
 func (s *simpleClient) Send(exchange, key string, body []byte, headers map[string]interface{}) error {
+	if s.memory != nil {
+		return s.memory.Publish(exchange, key, body, headers)
+	}
 
 	return s.channel.Publish(
 		exchange, // exchange
@@ -59,6 +70,10 @@ func (s *simpleClient) GetQueueName() string {
 }
 
 func (s *simpleClient) Subscribe(exchange, key string, headers map[string]interface{}) (chan []byte, error) {
+	if s.memory != nil {
+		return s.subscribeMemory(exchange, key)
+	}
+
 	queue, err := s.channel.QueueDeclare("", false, false, true, true, headers)
 	if err != nil {
 		return nil, err
@@ -82,3 +97,24 @@ func (s *simpleClient) Subscribe(exchange, key string, headers map[string]interf
 	}(chanDelivery, chanRet)
 	return chanRet, nil
 }
+
+func (s *simpleClient) subscribeMemory(exchange, key string) (chan []byte, error) {
+	s.queueName = s.memory.DeclareQueue("")
+	err := s.memory.BindQueue(s.queueName, exchange, key)
+	if err != nil {
+		return nil, err
+	}
+
+	msgChan := make(chan network.InMsg)
+	err = s.memory.Consume(s.queueName, msgChan)
+	if err != nil {
+		return nil, err
+	}
+	chanRet := make(chan []byte)
+	go func(msgs <-chan network.InMsg, outChan chan []byte) {
+		for msg := range msgs {
+			outChan <- msg.Body
+		}
+	}(msgChan, chanRet)
+	return chanRet, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/CESARBR/knot-babeltower/internal/config"
+	dataEntities "github.com/CESARBR/knot-babeltower/pkg/data/entities"
 	"github.com/CESARBR/knot-babeltower/pkg/network"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 )
@@ -14,6 +15,8 @@ import (
 type RPCService interface {
 	Auth(string, string) (string, error)
 	List() ([]*entities.Thing, error)
+	LastValues(string, []int) ([]dataEntities.SensorValue, error)
+	History(string, []int, string) ([]dataEntities.SensorHistory, error)
 }
 
 type simpleService struct {
@@ -89,3 +92,71 @@ func (s *simpleService) List() ([]*entities.Thing, error) {
 		return nil, errors.New("timeout waiting response")
 	}
 }
+
+func (s *simpleService) LastValues(id string, sensorIds []int) ([]dataEntities.SensorValue, error) {
+	channel, err := s.client.Subscribe("device", "reply", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req := network.LastValuesRequest{ID: id, SensorIds: sensorIds}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.client.Send("device", "data.last", body, map[string]interface{}{"Authorization": s.authToken, "correlation_id": "1", "reply_to": "reply"})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-channel:
+		msg := network.LastValuesResponse{}
+		if err := json.Unmarshal(resp, &msg); err != nil {
+			return nil, err
+		}
+
+		if msg.Error != nil {
+			return nil, errors.New(*msg.Error)
+		}
+
+		return msg.Values, nil
+	case <-time.After(time.Second):
+		return nil, errors.New("timeout waiting response")
+	}
+}
+
+func (s *simpleService) History(id string, sensorIds []int, interval string) ([]dataEntities.SensorHistory, error) {
+	channel, err := s.client.Subscribe("device", "reply", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req := network.HistoryRequest{ID: id, SensorIds: sensorIds, Interval: interval}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.client.Send("device", "data.history", body, map[string]interface{}{"Authorization": s.authToken, "correlation_id": "1", "reply_to": "reply"})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-channel:
+		msg := network.HistoryResponse{}
+		if err := json.Unmarshal(resp, &msg); err != nil {
+			return nil, err
+		}
+
+		if msg.Error != nil {
+			return nil, errors.New(*msg.Error)
+		}
+
+		return msg.Sensors, nil
+	case <-time.After(time.Second):
+		return nil, errors.New("timeout waiting response")
+	}
+}
@@ -1,16 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	cli "github.com/CESARBR/knot-babeltower/cmd/client"
 	"github.com/CESARBR/knot-babeltower/internal/config"
+	"github.com/CESARBR/knot-babeltower/internal/mainflux"
 	"github.com/CESARBR/knot-babeltower/pkg/mocks"
 	"github.com/CESARBR/knot-babeltower/pkg/network"
 	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
@@ -20,21 +27,54 @@ import (
 )
 
 var (
-	quitMain chan bool
-	sender   cli.SimpleClient
-	rpc      cli.RPCService
-	token    string
+	mainfluxServer *httptest.Server
+	historyDir     string
+	quitMain       chan bool
+	sender         cli.SimpleClient
+	rpc            cli.RPCService
+	token          string
 )
 
-// GetTestConfig local configuration default
-func GetTestConfig() config.Config {
+// GetTestConfig local configuration default, using the in-process broker and
+// the fake Mainflux services listening on mainfluxURL, and storing the
+// history on historyDir
+func GetTestConfig(mainfluxURL, historyDir string) (config.Config, error) {
+	u, err := url.Parse(mainfluxURL)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	if err != nil {
+		return config.Config{}, err
+	}
+
 	return config.Config{
-		Server:   config.Server{Port: 8080},
+		Server:   config.Server{Port: 8080, ShutdownTimeout: 10 * time.Second},
 		Logger:   config.Logger{Level: "debug"},
-		Users:    config.Users{Hostname: "users", Port: 8180},
-		RabbitMQ: config.RabbitMQ{URL: "amqp://rabbitmq"},
-		Things:   config.Things{Hostname: "things", Port: 8180},
-	}
+		Users:    config.Users{Hostname: u.Hostname(), Port: uint16(port)},
+		RabbitMQ: config.RabbitMQ{URL: "memory://", ConfirmTimeout: 5 * time.Second, Prefetch: 16},
+		Things: config.Things{
+			Hostname: u.Hostname(),
+			Port:     uint16(port),
+			Cache:    config.ThingsCache{TTL: 30 * time.Second, MaxSize: 1000},
+			Presence: config.Presence{Timeout: 5 * time.Minute},
+			Types:    config.ThingsTypes{Path: "../internal/config/types.yaml"},
+			Schema:   config.ThingsSchema{BreakingChanges: "reject", Storage: "memory"},
+			Batch:    config.ThingsBatch{Concurrency: 4},
+		},
+		MsgHandler: config.MsgHandler{Workers: 8},
+		Data: config.Data{
+			Normalize:    true,
+			MaxClockSkew: 5 * time.Minute,
+			LastValues:   config.LastValues{Storage: "memory"},
+			History:      config.History{Path: historyDir, SegmentDuration: time.Hour, MaxAge: time.Hour},
+		},
+		Rules:     config.Rules{Storage: "memory"},
+		Alarms:    config.Alarms{Storage: "memory", MaxCleared: 100},
+		Commands:  config.Commands{Storage: "memory", Timeout: 30 * time.Second, MaxFinished: 100, QueueMaxAge: time.Hour},
+		Schedules: config.Schedules{Storage: "memory", MaxRuns: 10},
+	}, nil
 }
 
 func getToken(config config.Users) (string, error) {
@@ -46,9 +86,20 @@ func getToken(config config.Users) (string, error) {
 }
 
 func SetupSuite() error {
+	mainfluxServer = httptest.NewServer(mainflux.NewServer(&mocks.FakeLogger{}).Handler())
+	dir, err := ioutil.TempDir("", "babeltower-history")
+	if err != nil {
+		return err
+	}
+	historyDir = dir
+
+	config, err := GetTestConfig(mainfluxServer.URL, historyDir)
+	if err != nil {
+		return err
+	}
+
 	quitMain = make(chan bool, 1)
 	started := make(chan bool, 1)
-	config := GetTestConfig()
 	go Main(config, quitMain, started)
 	select {
 	case <-started:
@@ -57,7 +108,6 @@ func SetupSuite() error {
 		return errors.New("timeout waiting broker startup")
 	}
 
-	var err error
 	token, err = getToken(config.Users)
 	if err != nil {
 		return err
@@ -69,7 +119,8 @@ func SetupSuite() error {
 
 func TearDownSuite() error {
 	quitMain <- true
-	return nil
+	mainfluxServer.Close()
+	return os.RemoveAll(historyDir)
 }
 
 // Two clients send and each one receives the response
@@ -268,6 +319,12 @@ func TestDataEvents(t *testing.T) {
 			assert.FailNow(t, err.Error())
 		}
 	}()
+	err = updateSchema("123", []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "testSensor"},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
 	tests := []struct {
 		name         string
 		exchange     string
@@ -398,6 +455,11 @@ func registerThing(ID, name string) (string, error) {
 	return tmp["token"].(string), nil
 }
 
+func updateSchema(ID string, schema []thingEntities.Schema) error {
+	var resp interface{} = network.SchemaUpdatedResponse{}
+	return subcribeAndSend(network.SchemaUpdateRequest{ID: ID, Schema: schema}, "device", "device.schema.sent", token, &resp, "device", "device.schema.updated")
+}
+
 func unregisterThing(ID string) error {
 	var resp interface{} = &network.DeviceRegisteredResponse{}
 	return subcribeAndSend(network.DeviceUnregisterRequest{ID: ID}, "device", "device.unregister", token, &resp, "device", "device.unregistered")
@@ -433,7 +495,7 @@ func TestHappyPathRPCAuth(t *testing.T) {
 	}
 }
 func TestHappyPathRPCList(t *testing.T) {
-	_, err := registerThing("123", "testThing")
+	thingToken, err := registerThing("123", "testThing")
 	if err != nil {
 		assert.FailNow(t, err.Error())
 	}
@@ -459,12 +521,560 @@ func TestHappyPathRPCList(t *testing.T) {
 			assert.Equal(t, 1, len(things))
 			assert.Equal(t, "123", things[0].ID)
 			assert.Equal(t, "testThing", things[0].Name)
-			assert.Empty(t, things[0].Token)
+			assert.Equal(t, thingToken, things[0].Token)
 			assert.Nil(t, things[0].Schema)
+			assert.False(t, things[0].Online)
+			assert.Nil(t, things[0].LastSeen)
+
+			_, err = rpc.Auth("123", thingToken)
+			assert.Nil(t, err)
+
+			things, err = rpc.List()
+			assert.Nil(t, err)
+			if assert.Equal(t, 1, len(things)) && assert.NotNil(t, things[0].Presence) {
+				assert.True(t, things[0].Online)
+				assert.NotNil(t, things[0].LastSeen)
+			}
 		})
 	}
 }
 
+func TestHappyPathRPCLastValues(t *testing.T) {
+	_, err := registerThing("123", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("123")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	schema := []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"},
+		{SensorID: 2, ValueType: 3, Unit: 0, TypeID: 65521, Name: "switch"},
+	}
+	err = updateSchema("123", schema)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	var resp interface{} = network.DataSent{}
+	sent := network.DataSent{ID: "123", Data: []thingEntities.Data{{SensorID: 1, Value: 12.5}, {SensorID: 2, Value: true}}}
+	err = subcribeAndSend(sent, "data.sent", "", token, &resp, "data.published", "")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	tests := []struct {
+		name           string
+		sensorIds      []int
+		expectedValues []thingEntities.Data
+	}{
+		{"without sensors all the sensors last values should be returned", nil, sent.Data},
+		{"with sensors only their last values should be returned", []int{2}, sent.Data[1:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := rpc.LastValues("123", tt.sensorIds)
+
+			assert.Nil(t, err)
+			if assert.Equal(t, len(tt.expectedValues), len(values)) {
+				for i, value := range values {
+					assert.Equal(t, tt.expectedValues[i].SensorID, value.SensorID)
+					assert.Equal(t, tt.expectedValues[i].Value, value.Value)
+					assert.Equal(t, schema[value.SensorID-1], *value.Schema)
+					assert.False(t, value.Timestamp.IsZero())
+				}
+			}
+		})
+	}
+}
+
+func TestHappyPathRPCHistory(t *testing.T) {
+	_, err := registerThing("456", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("456")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	schema := []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"},
+		{SensorID: 2, ValueType: 3, Unit: 0, TypeID: 65521, Name: "switch"},
+	}
+	err = updateSchema("456", schema)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	var resp interface{} = network.DataSent{}
+	sent := network.DataSent{ID: "456", Data: []thingEntities.Data{{SensorID: 1, Value: 10.5}, {SensorID: 1, Value: 20.5}}}
+	err = subcribeAndSend(sent, "data.sent", "", token, &resp, "data.published", "")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	t.Run("without interval the sensors values should be returned", func(t *testing.T) {
+		sensors, err := rpc.History("456", nil, "")
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 2, len(sensors)) {
+			assert.Equal(t, 1, sensors[0].SensorID)
+			if assert.Equal(t, 2, len(sensors[0].Values)) {
+				assert.Equal(t, 10.5, sensors[0].Values[0].Value)
+				assert.Equal(t, 20.5, sensors[0].Values[1].Value)
+			}
+			assert.Equal(t, 2, sensors[1].SensorID)
+			assert.Empty(t, sensors[1].Values)
+		}
+	})
+
+	t.Run("with interval the sensors values should be downsampled", func(t *testing.T) {
+		sensors, err := rpc.History("456", []int{1}, "48h")
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(sensors)) && assert.Equal(t, 1, len(sensors[0].Buckets)) {
+			bucket := sensors[0].Buckets[0]
+			assert.Equal(t, 10.5, bucket.Min)
+			assert.Equal(t, 20.5, bucket.Max)
+			assert.Equal(t, 15.5, bucket.Avg)
+			assert.Equal(t, 2, bucket.Count)
+		}
+	})
+
+	t.Run("with invalid interval an error should be returned", func(t *testing.T) {
+		_, err := rpc.History("456", nil, "5 minutes")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestThingsHTTP(t *testing.T) {
+	schema := []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "testSensor"},
+	}
+	invalidSchema := []thingEntities.Schema{
+		{SensorID: 1, ValueType: 3, Unit: 1, TypeID: 13, Name: "testSensor"},
+	}
+	energySchema := []thingEntities.Schema{
+		{SensorID: 2, ValueType: 1, Unit: 4, TypeID: 0x15, Name: "energy"},
+	}
+	breakingSchema := []thingEntities.Schema{
+		{SensorID: 2, ValueType: 2, Unit: 1, TypeID: 13, Name: "volume"},
+	}
+
+	// the steps run in order, each one depending on the previous ones
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"register without token", "POST", "/things", "", map[string]string{"id": "abc", "name": "testThing"}, nethttp.StatusUnauthorized},
+		{"register", "POST", "/things", token, map[string]string{"id": "abc", "name": "testThing"}, nethttp.StatusCreated},
+		{"register again", "POST", "/things", token, map[string]string{"id": "abc", "name": "testThing"}, nethttp.StatusConflict},
+		{"register invalid ID", "POST", "/things", token, map[string]string{"id": "invalid ID", "name": "testThing"}, nethttp.StatusUnprocessableEntity},
+		{"get", "GET", "/things/abc", token, nil, nethttp.StatusOK},
+		{"list", "GET", "/things", token, nil, nethttp.StatusOK},
+		{"list with invalid token", "GET", "/things", "invalid-token", nil, nethttp.StatusForbidden},
+		{"update schema", "PUT", "/things/abc/schema", token, map[string]interface{}{"schema": schema}, nethttp.StatusOK},
+		{"update invalid schema", "PUT", "/things/abc/schema", token, map[string]interface{}{"schema": invalidSchema}, nethttp.StatusUnprocessableEntity},
+		{"update energy schema", "PUT", "/things/abc/schema", token, map[string]interface{}{"schema": energySchema}, nethttp.StatusOK},
+		{"update schema changing a value type", "PUT", "/things/abc/schema", token, map[string]interface{}{"schema": breakingSchema}, nethttp.StatusConflict},
+		{"list schema versions", "GET", "/things/abc/schema/versions", token, nil, nethttp.StatusOK},
+		{"list schema versions without token", "GET", "/things/abc/schema/versions", "", nil, nethttp.StatusUnauthorized},
+		{"list schema types", "GET", "/schema/types", token, nil, nethttp.StatusOK},
+		{"list schema types without token", "GET", "/schema/types", "", nil, nethttp.StatusUnauthorized},
+		{"unregister", "DELETE", "/things/abc", token, nil, nethttp.StatusNoContent},
+		{"get unregistered", "GET", "/things/abc", token, nil, nethttp.StatusNotFound},
+		{"unregister again", "DELETE", "/things/abc", token, nil, nethttp.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				assert.FailNow(t, err.Error())
+			}
+
+			req, err := nethttp.NewRequest(tt.method, "http://localhost:8080"+tt.path, bytes.NewBuffer(body))
+			if err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			req.Header.Set("Authorization", tt.token)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := nethttp.DefaultClient.Do(req)
+			if err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if resp.StatusCode == nethttp.StatusCreated {
+				thing := thingEntities.Thing{}
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&thing))
+				assert.Equal(t, "abc", thing.ID)
+				assert.Equal(t, "testThing", thing.Name)
+				assert.NotEmpty(t, thing.Token)
+			}
+			if tt.path == "/things/abc/schema/versions" && resp.StatusCode == nethttp.StatusOK {
+				versions := []thingEntities.SchemaVersion{}
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&versions))
+				if assert.Len(t, versions, 2) {
+					assert.Equal(t, 2, versions[1].Version)
+					assert.Equal(t, energySchema, versions[1].Diff.Added)
+					assert.Equal(t, schema, versions[1].Diff.Removed)
+				}
+			}
+		})
+	}
+}
+
+func TestRulesHTTP(t *testing.T) {
+	_, err := registerThing("789", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("789")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	err = updateSchema("789", []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "testSensor"},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	rule := map[string]interface{}{
+		"name":       "testRule",
+		"thingId":    "789",
+		"conditions": []map[string]interface{}{{"sensorId": 1, "operator": "gt", "value": 30}},
+		"actions":    []map[string]interface{}{{"type": "event"}},
+	}
+	body, err := json.Marshal(rule)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req, err := nethttp.NewRequest("POST", "http://localhost:8080/rules", bytes.NewBuffer(body))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, nethttp.StatusCreated, resp.StatusCode)
+
+	t.Run("data satisfying the rule should trigger it", func(t *testing.T) {
+		var triggered interface{} = network.RuleTriggered{}
+		data := network.DataSent{ID: "789", Data: []thingEntities.Data{{SensorID: 1, Value: 20.5}, {SensorID: 1, Value: 30.5}}}
+		err := subcribeAndSend(data, "data.sent", "", token, &triggered, "rule.triggered", "")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+
+		event, ok := triggered.(map[string]interface{})
+		if !assert.True(t, ok) {
+			return
+		}
+		assert.Equal(t, "testRule", event["name"])
+		assert.Equal(t, "789", event["thingId"])
+		assert.Equal(t, 30.5, event["data"].([]interface{})[0].(map[string]interface{})["value"])
+	})
+}
+
+func TestAlarmsHTTP(t *testing.T) {
+	_, err := registerThing("78a", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("78a")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	err = updateSchema("78a", []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "testSensor"},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	definition := map[string]interface{}{
+		"name":     "testAlarm",
+		"thingId":  "78a",
+		"sensorId": 1,
+		"raise":    map[string]interface{}{"operator": "gt", "value": 30},
+		"clear":    map[string]interface{}{"operator": "lt", "value": 25},
+	}
+	body, err := json.Marshal(definition)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req, err := nethttp.NewRequest("POST", "http://localhost:8080/alarm-definitions", bytes.NewBuffer(body))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, nethttp.StatusCreated, resp.StatusCode)
+
+	t.Run("data satisfying the raise condition should raise an alarm", func(t *testing.T) {
+		var raised interface{}
+		data := network.DataSent{ID: "78a", Data: []thingEntities.Data{{SensorID: 1, Value: 20.5}, {SensorID: 1, Value: 30.5}}}
+		err := subcribeAndSend(data, "data.sent", "", token, &raised, "alarm", "alarm.raised")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+
+		alarm, ok := raised.(map[string]interface{})
+		if !assert.True(t, ok) {
+			return
+		}
+		assert.Equal(t, "testAlarm", alarm["name"])
+		assert.Equal(t, "active", alarm["state"])
+		assert.Equal(t, 30.5, alarm["raisedValue"])
+
+		req, err := nethttp.NewRequest("GET", "http://localhost:8080/alarms?state=active&thingId=78a", nil)
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		defer resp.Body.Close()
+
+		alarms := []map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&alarms))
+		if assert.Len(t, alarms, 1) {
+			assert.Equal(t, alarm["id"], alarms[0]["id"])
+		}
+	})
+}
+
+func TestCommandsHTTP(t *testing.T) {
+	_, err := registerThing("cde", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("cde")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	err = updateSchema("cde", []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "testSensor"},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	var sent interface{}
+	update := network.DataUpdate{ID: "cde", CommandID: "endpoint-command", Data: []thingEntities.Data{{SensorID: 1, Value: 20.7}}}
+	err = subcribeAndSend(update, "device", "data.update", token, &sent, "device", "device.cde.data.update")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, "endpoint-command", sent.(map[string]interface{})["commandId"])
+
+	t.Run("command acknowledged by the thing should be completed", func(t *testing.T) {
+		var completed interface{}
+		ack := network.DataUpdateAck{ID: "cde", CommandID: "endpoint-command"}
+		err := subcribeAndSend(ack, "device", "data.update.ack", token, &completed, "command", "command.completed")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, "endpoint-command", completed.(map[string]interface{})["id"])
+
+		req, err := nethttp.NewRequest("GET", "http://localhost:8080/commands/endpoint-command", nil)
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		req.Header.Set("Authorization", token)
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		defer resp.Body.Close()
+
+		command := map[string]interface{}{}
+		assert.Equal(t, nethttp.StatusOK, resp.StatusCode)
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&command))
+		assert.Equal(t, "completed", command["status"])
+		assert.Equal(t, "cde", command["thingId"])
+	})
+}
+
+func TestSchedulesHTTP(t *testing.T) {
+	_, err := registerThing("cdf", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("cdf")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	err = updateSchema("cdf", []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "testSensor"},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	updates, err := sender.Subscribe("device", "device.cdf.data.update", map[string]interface{}{})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	schedule := map[string]interface{}{
+		"name":    "testSchedule",
+		"thingId": "cdf",
+		"at":      time.Now().Add(200 * time.Millisecond),
+		"action":  map[string]interface{}{"type": "updateData", "data": []map[string]interface{}{{"sensorId": 1, "value": 20.7}}},
+	}
+	body, err := json.Marshal(schedule)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req, err := nethttp.NewRequest("POST", "http://localhost:8080/schedules", bytes.NewBuffer(body))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, nethttp.StatusCreated, resp.StatusCode)
+
+	t.Run("schedule should send the update at its time", func(t *testing.T) {
+		select {
+		case msg := <-updates:
+			var update map[string]interface{}
+			assert.NoError(t, json.Unmarshal(msg, &update))
+			assert.Equal(t, "cdf", update["id"])
+			assert.NotEmpty(t, update["commandId"])
+		case <-time.After(2 * time.Second):
+			assert.Fail(t, "timeout waiting scheduled update")
+		}
+	})
+}
+
+func TestPreferencesHTTP(t *testing.T) {
+	_, err := registerThing("cda", "testThing")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		err = unregisterThing("cda")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+	}()
+	err = updateSchema("cda", []thingEntities.Schema{
+		{SensorID: 1, ValueType: 1, Unit: 1, TypeID: 0x05, Name: "temperature"},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"units": []map[string]int{{"typeId": 0x05, "unit": 2}}})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req, err := nethttp.NewRequest("PUT", "http://localhost:8080/preferences/units", bytes.NewBuffer(body))
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	t.Run("published data should be normalized to the preferred unit", func(t *testing.T) {
+		var published interface{} = &network.DataSent{}
+		err := subcribeAndSend(network.DataSent{ID: "cda", Data: []thingEntities.Data{{SensorID: 1, Value: 20}}}, "data.sent", "", token, &published, "data.published", "")
+		if err != nil {
+			assert.FailNow(t, err.Error())
+		}
+
+		data := published.(*network.DataSent).Data
+		if assert.Len(t, data, 1) && assert.NotNil(t, data[0].Normalized) {
+			assert.Equal(t, float64(20), data[0].Value)
+			assert.InDelta(t, 68, data[0].Normalized.Value, 1e-9)
+			assert.Equal(t, "°F", data[0].Normalized.Symbol)
+		}
+	})
+}
+
+func TestBatchRegistration(t *testing.T) {
+	var registered interface{} = &network.DeviceRegisteredBatchResponse{}
+	err := subcribeAndSend(network.DeviceRegisterBatchRequest{Things: []network.DeviceRegisterRequest{
+		{ID: "ba1", Name: "lamp"},
+		{ID: "ba2", Name: "fan"},
+		{ID: "ba1", Name: "door"},
+	}}, "device", "device.register.batch", token, &registered, "device", "device.registered.batch")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	things := registered.(*network.DeviceRegisteredBatchResponse).Things
+	if assert.Len(t, things, 3) {
+		assert.Nil(t, things[0].Error)
+		assert.NotEmpty(t, things[0].Token)
+		assert.Nil(t, things[1].Error)
+		if assert.NotNil(t, things[2].Error) {
+			assert.Equal(t, "thing's id repeated in the batch", *things[2].Error)
+		}
+	}
+
+	var unregistered interface{} = &network.DeviceUnregisteredBatchResponse{}
+	err = subcribeAndSend(network.DeviceUnregisterBatchRequest{Things: []network.DeviceUnregisterRequest{
+		{ID: "ba1"},
+		{ID: "ba2"},
+	}}, "device", "device.unregister.batch", token, &unregistered, "device", "device.unregistered.batch")
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+
+	removed := unregistered.(*network.DeviceUnregisteredBatchResponse).Things
+	if assert.Len(t, removed, 2) {
+		assert.Nil(t, removed[0].Error)
+		assert.Nil(t, removed[1].Error)
+	}
+}
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	if testing.Short() {
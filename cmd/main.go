@@ -1,17 +1,46 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/CESARBR/knot-babeltower/internal/config"
+	"github.com/CESARBR/knot-babeltower/internal/mainflux"
+	alarmControllers "github.com/CESARBR/knot-babeltower/pkg/alarm/controllers"
+	alarmDeliveryAMQP "github.com/CESARBR/knot-babeltower/pkg/alarm/delivery/amqp"
+	alarmInteractors "github.com/CESARBR/knot-babeltower/pkg/alarm/interactors"
+	alarmStorage "github.com/CESARBR/knot-babeltower/pkg/alarm/storage"
+	commandControllers "github.com/CESARBR/knot-babeltower/pkg/command/controllers"
+	commandDeliveryAMQP "github.com/CESARBR/knot-babeltower/pkg/command/delivery/amqp"
+	commandInteractors "github.com/CESARBR/knot-babeltower/pkg/command/interactors"
+	commandStorage "github.com/CESARBR/knot-babeltower/pkg/command/storage"
+	dataControllers "github.com/CESARBR/knot-babeltower/pkg/data/controllers"
+	dataDeliveryAMQP "github.com/CESARBR/knot-babeltower/pkg/data/delivery/amqp"
+	dataInteractors "github.com/CESARBR/knot-babeltower/pkg/data/interactors"
+	"github.com/CESARBR/knot-babeltower/pkg/data/storage"
 	"github.com/CESARBR/knot-babeltower/pkg/network"
+	preferenceControllers "github.com/CESARBR/knot-babeltower/pkg/preference/controllers"
+	preferenceInteractors "github.com/CESARBR/knot-babeltower/pkg/preference/interactors"
+	preferenceStorage "github.com/CESARBR/knot-babeltower/pkg/preference/storage"
+	ruleControllers "github.com/CESARBR/knot-babeltower/pkg/rule/controllers"
+	ruleDeliveryAMQP "github.com/CESARBR/knot-babeltower/pkg/rule/delivery/amqp"
+	ruleInteractors "github.com/CESARBR/knot-babeltower/pkg/rule/interactors"
+	ruleStorage "github.com/CESARBR/knot-babeltower/pkg/rule/storage"
+	scheduleControllers "github.com/CESARBR/knot-babeltower/pkg/schedule/controllers"
+	scheduleDeliveryAMQP "github.com/CESARBR/knot-babeltower/pkg/schedule/delivery/amqp"
+	scheduleInteractors "github.com/CESARBR/knot-babeltower/pkg/schedule/interactors"
+	scheduleStorage "github.com/CESARBR/knot-babeltower/pkg/schedule/storage"
 	"github.com/CESARBR/knot-babeltower/pkg/server"
 	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
 	thingDeliveryAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
 	thingDeliveryHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
 	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	thingStorage "github.com/CESARBR/knot-babeltower/pkg/thing/storage"
 	userControllers "github.com/CESARBR/knot-babeltower/pkg/user/controllers"
 	userDeliveryHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
 	userInteractors "github.com/CESARBR/knot-babeltower/pkg/user/interactors"
@@ -25,6 +54,87 @@ func monitorSignals(sigs chan os.Signal, quit chan bool, logger logging.Logger)
 	quit <- true
 }
 
+// newQueueService selects the in-process broker when configured with the
+// memory:// URL and RabbitMQ otherwise
+func newQueueService(config config.RabbitMQ, logrus *logging.Logrus) network.QueueService {
+	if network.IsMemoryURL(config.URL) {
+		return network.GetMemory(config.URL, logrus.Get("Memory"))
+	}
+
+	return network.NewAmqp(config.URL, config.ConfirmTimeout, config.Prefetch, logrus.Get("Amqp"))
+}
+
+// The stores below are kept in memory, so they're lost when the service
+// restarts, unless configured with the file storage.
+
+// newLastValueStore creates the store of the sensors' last values, whose
+// file is written periodically
+func newLastValueStore(config config.LastValues) (storage.LastValueStore, error) {
+	if config.Storage == "file" {
+		return storage.NewFileLastValueStore(config.Path, config.FlushInterval)
+	}
+
+	return storage.NewMemoryLastValueStore(), nil
+}
+
+// newRuleStore creates the store of the users' rules
+func newRuleStore(config config.Rules) (ruleStorage.RuleStore, error) {
+	if config.Storage == "file" {
+		return ruleStorage.NewFileRuleStore(config.Path)
+	}
+
+	return ruleStorage.NewMemoryRuleStore(), nil
+}
+
+// newAlarmStore creates the store of the alarm definitions and alarms,
+// keeping up to the configured number of cleared alarms
+func newAlarmStore(config config.Alarms) (alarmStorage.AlarmStore, error) {
+	if config.Storage == "file" {
+		return alarmStorage.NewFileAlarmStore(config.Path, config.MaxCleared)
+	}
+
+	return alarmStorage.NewMemoryAlarmStore(config.MaxCleared), nil
+}
+
+// newCommandStore creates the store of the commands sent to the things,
+// keeping up to the configured number of finished commands, whose file is
+// written periodically
+func newCommandStore(config config.Commands) (commandStorage.CommandStore, error) {
+	if config.Storage == "file" {
+		return commandStorage.NewFileCommandStore(config.Path, config.MaxFinished, config.FlushInterval)
+	}
+
+	return commandStorage.NewMemoryCommandStore(config.MaxFinished), nil
+}
+
+// newScheduleStore creates the store of the users' schedules
+func newScheduleStore(config config.Schedules) (scheduleStorage.ScheduleStore, error) {
+	if config.Storage == "file" {
+		return scheduleStorage.NewFileScheduleStore(config.Path)
+	}
+
+	return scheduleStorage.NewMemoryScheduleStore(), nil
+}
+
+// newPreferenceStore creates the store of the users' unit preferences
+func newPreferenceStore(config config.Preferences) (preferenceStorage.PreferenceStore, error) {
+	if config.Storage == "file" {
+		return preferenceStorage.NewFilePreferenceStore(config.Path)
+	}
+
+	return preferenceStorage.NewMemoryPreferenceStore(), nil
+}
+
+// newSchemaStore creates the store of the things' schema versions, keeping
+// up to the configured number of versions of each thing
+func newSchemaStore(config config.ThingsSchema) (thingStorage.SchemaStore, error) {
+	if config.Storage == "file" {
+		return thingStorage.NewFileSchemaStore(config.Path, config.MaxVersions)
+	}
+
+	return thingStorage.NewMemorySchemaStore(config.MaxVersions), nil
+}
+
 // Main will be used for unit tests
 func Main(config config.Config, quit chan bool, startedChan chan bool) {
 	logrus := logging.NewLogrus(config.Logger.Level)
@@ -40,38 +150,133 @@ func Main(config config.Config, quit chan bool, startedChan chan bool) {
 
 	// AMQP
 	amqpStartedChan := make(chan bool, 1)
-	amqp := network.NewAmqp(config.RabbitMQ.URL, logrus.Get("Amqp"))
+	amqp := newQueueService(config.RabbitMQ, logrus)
 
 	// AMQP Publishers
 	clientPublisher := thingDeliveryAMQP.NewMsgClientPublisher(logrus.Get("ClientPublisher"), amqp.GetSender())
 	commandSender := thingDeliveryAMQP.NewCommandSender(logrus.Get("Command Sender"), amqp.GetSender())
+	dataCommandSender := dataDeliveryAMQP.NewCommandSender(logrus.Get("Data Command Sender"), amqp.GetSender())
+	rulePublisher := ruleDeliveryAMQP.NewMsgPublisher(logrus.Get("RulePublisher"), amqp.GetSender())
+	alarmPublisher := alarmDeliveryAMQP.NewMsgPublisher(logrus.Get("AlarmPublisher"), amqp.GetSender())
+	commandPublisher := commandDeliveryAMQP.NewMsgPublisher(logrus.Get("CommandPublisher"), amqp.GetSender())
+	scheduleCommandSender := scheduleDeliveryAMQP.NewCommandSender(logrus.Get("Schedule Command Sender"), amqp.GetSender())
 
 	// Services
 	userProxy := userDeliveryHTTP.NewUserProxy(logrus.Get("UserProxy"), config.Users.Hostname, config.Users.Port)
 	thingProxy := thingDeliveryHTTP.NewThingProxy(logrus.Get("ThingProxy"), config.Things.Hostname, config.Things.Port)
+	thingCache := thingDeliveryHTTP.NewCachedThingProxy(logrus.Get("ThingCache"), thingProxy, config.Things.Cache.TTL, config.Things.Cache.MaxSize)
+	types, err := thingStorage.LoadTypeRegistry(config.Things.Types.Path)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	lastValues, err := newLastValueStore(config.Data.LastValues)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	history, err := storage.NewSegmentHistoryStore(config.Data.History.Path, storage.HistoryRetention{
+		SegmentSize:     config.Data.History.SegmentSize,
+		SegmentDuration: config.Data.History.SegmentDuration,
+		MaxAge:          config.Data.History.MaxAge,
+		MaxSize:         config.Data.History.MaxSize,
+	})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	rules, err := newRuleStore(config.Rules)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	alarms, err := newAlarmStore(config.Alarms)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	commands, err := newCommandStore(config.Commands)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	schedules, err := newScheduleStore(config.Schedules)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	preferences, err := newPreferenceStore(config.Preferences)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	schemas, err := newSchemaStore(config.Things.Schema)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	breakingChanges := thingInteractors.BreakingChangePolicy(config.Things.Schema.BreakingChanges)
+	if breakingChanges != thingInteractors.BreakingChangesFlag && breakingChanges != thingInteractors.BreakingChangesReject {
+		logger.Fatalf("invalid schema breaking changes policy: %s", breakingChanges)
+	}
 
 	// Interactors
 	createUser := userInteractors.NewCreateUser(logrus.Get("CreateUser"), userProxy)
 	createToken := userInteractors.NewCreateToken(logrus.Get("CreateToken"), userProxy)
-	thingInteractor := thingInteractors.NewThingInteractor(logrus.Get("ThingInteractor"), clientPublisher, thingProxy)
+	dataInteractor := dataInteractors.NewDataInteractor(logrus.Get("DataInteractor"), thingCache, lastValues, history)
+	ruleInteractor := ruleInteractors.NewRuleInteractor(logrus.Get("RuleInteractor"), userProxy, thingCache, clientPublisher, rulePublisher, rules)
+	alarmInteractor := alarmInteractors.NewAlarmInteractor(logrus.Get("AlarmInteractor"), userProxy, thingCache, alarmPublisher, alarms)
+	commandInteractor := commandInteractors.NewCommandInteractor(logrus.Get("CommandInteractor"), thingCache, commandPublisher, commands, config.Commands.Timeout, config.Commands.QueueMaxAge)
+	preferenceInteractor := preferenceInteractors.NewPreferenceInteractor(logrus.Get("PreferenceInteractor"), userProxy, types, preferences)
+	var unitPreferences thingInteractors.UnitPreferences
+	if config.Data.Normalize {
+		unitPreferences = preferenceInteractor
+	}
+	presence := thingInteractors.NewTimeoutPresenceTracker(logrus.Get("PresenceTracker"), clientPublisher, config.Things.Presence.Timeout, config.Things.Presence.Timeouts)
+	thingInteractor := thingInteractors.NewThingInteractor(logrus.Get("ThingInteractor"), clientPublisher, thingCache, thingInteractors.ThingOptions{
+		Presence:         presence,
+		Commands:         commandInteractor,
+		Types:            types,
+		Preferences:      unitPreferences,
+		Schemas:          schemas,
+		Breaking:         breakingChanges,
+		BatchConcurrency: config.Things.Batch.Concurrency,
+		MaxClockSkew:     config.Data.MaxClockSkew,
+		DataListeners:    []thingInteractors.DataListener{dataInteractor, ruleInteractor, alarmInteractor, commandInteractor},
+	})
+	scheduleInteractor := scheduleInteractors.NewScheduleInteractor(logrus.Get("ScheduleInteractor"), userProxy, thingCache, thingInteractor, schedules, config.Schedules.MaxRuns)
 
 	// Controllers
 	thingController := thingControllers.NewThingController(logrus.Get("ThingController"), thingInteractor, commandSender)
 	userController := userControllers.NewUserController(logrus.Get("UserController"), createUser, createToken)
+	thingHTTPController := thingControllers.NewThingHTTPController(logrus.Get("ThingHTTPController"), thingInteractor)
+	dataController := dataControllers.NewDataController(logrus.Get("DataController"), dataInteractor, dataCommandSender)
+	dataHTTPController := dataControllers.NewDataHTTPController(logrus.Get("DataHTTPController"), dataInteractor)
+	ruleHTTPController := ruleControllers.NewRuleHTTPController(logrus.Get("RuleHTTPController"), ruleInteractor)
+	alarmHTTPController := alarmControllers.NewAlarmHTTPController(logrus.Get("AlarmHTTPController"), alarmInteractor)
+	commandController := commandControllers.NewCommandController(logrus.Get("CommandController"), commandInteractor)
+	commandHTTPController := commandControllers.NewCommandHTTPController(logrus.Get("CommandHTTPController"), commandInteractor)
+	scheduleController := scheduleControllers.NewScheduleController(logrus.Get("ScheduleController"), scheduleInteractor, scheduleCommandSender)
+	scheduleHTTPController := scheduleControllers.NewScheduleHTTPController(logrus.Get("ScheduleHTTPController"), scheduleInteractor)
+	preferenceHTTPController := preferenceControllers.NewPreferenceHTTPController(logrus.Get("PreferenceHTTPController"), preferenceInteractor)
 
 	// Server
 	serverStartedChan := make(chan bool, 1)
-	http := server.NewServer(config.Server.Port, logrus.Get("Server"), userController)
+	dataStream := server.NewDataStream(logrus.Get("DataStream"), amqp.GetReceiver(), thingInteractor, config.Server.AllowedOrigins)
+	http := server.NewServer(config.Server.Port, logrus.Get("Server"), server.Dependencies{
+		UserController:       userController,
+		ThingController:      thingHTTPController,
+		DataController:       dataHTTPController,
+		RuleController:       ruleHTTPController,
+		AlarmController:      alarmHTTPController,
+		CommandController:    commandHTTPController,
+		ScheduleController:   scheduleHTTPController,
+		PreferenceController: preferenceHTTPController,
+		ThingCache:           thingCache,
+		DataStream:           dataStream,
+	})
 
 	// AMQP Handler
 	msgStartedChan := make(chan bool, 1)
-	msgHandler := server.NewMsgHandler(logrus.Get("MsgHandler"), amqp.GetReceiver(), thingController)
+	msgHandler := server.NewMsgHandler(logrus.Get("MsgHandler"), amqp.GetReceiver(), thingController, dataController, commandController, scheduleController, config.MsgHandler.Workers, config.MsgHandler.MaxRetries, config.MsgHandler.RetryDelay)
 
 	// Start goroutines
 	go amqp.Start(amqpStartedChan)
 	go http.Start(serverStartedChan)
 
 	// Main loop
+	msgHandlerStarted := false
 	for {
 		select {
 		case started := <-serverStartedChan:
@@ -79,9 +284,25 @@ func Main(config config.Config, quit chan bool, startedChan chan bool) {
 				logger.Info("server started")
 			}
 		case started := <-amqpStartedChan:
-			if started {
+			if started && msgHandlerStarted {
+				// the subscriptions are restored by the AMQP handler itself
+				logger.Info("AMQP connection restored")
+			} else if started {
 				logger.Info("AMQP connection started")
+				msgHandlerStarted = true
 				go msgHandler.Start(msgStartedChan)
+				err := dataStream.Start()
+				if err != nil {
+					logger.Error(err)
+				}
+				err = commandInteractor.Start()
+				if err != nil {
+					logger.Error(err)
+				}
+				err = scheduleInteractor.Start()
+				if err != nil {
+					logger.Error(err)
+				}
 			}
 		case started := <-msgStartedChan:
 			if started {
@@ -91,14 +312,66 @@ func Main(config config.Config, quit chan bool, startedChan chan bool) {
 				quit <- true
 			}
 		case <-quit:
-			msgHandler.Stop()
-			amqp.Stop()
-			http.Stop()
-			os.Exit(0)
+			// the messages, the requests and the publishes they make are
+			// each waited for up to a share of the shutdown timeout
+			stageTimeout := config.Server.ShutdownTimeout / 3
+			stopWithin(stageTimeout, func(ctx context.Context) {
+				err := msgHandler.Stop(ctx)
+				if err != nil {
+					logger.Error(err)
+				}
+			})
+			stopWithin(stageTimeout, http.Stop)
+			presence.Stop()
+			commandInteractor.Stop()
+			scheduleInteractor.Stop()
+			stopWithin(stageTimeout, amqp.Stop)
+			err := history.Close()
+			if err != nil {
+				logger.Error(err)
+			}
+			err = lastValues.Close()
+			if err != nil {
+				logger.Error(err)
+			}
+			err = commands.Close()
+			if err != nil {
+				logger.Error(err)
+			}
+			return
 		}
 	}
 }
 
+// stopWithin calls the stop function with a context expiring after the
+// timeout
+func stopWithin(timeout time.Duration, stop func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stop(ctx)
+}
+
+// fakeMainflux runs the stand-in for the Mainflux users and things services,
+// which are both served on the same port
+func fakeMainflux(args []string) {
+	flags := flag.NewFlagSet("fake-mainflux", flag.ExitOnError)
+	port := flags.Int("port", 8180, "port serving the users and things services")
+	level := flags.String("level", "info", "logging level")
+	_ = flags.Parse(args)
+
+	logger := logging.NewLogrus(*level).Get("FakeMainflux")
+	err := mainflux.NewServer(logger).ListenAndServe(fmt.Sprintf(":%d", *port))
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fake-mainflux" {
+		fakeMainflux(os.Args[2:])
+		return
+	}
+
 	Main(config.Load(), make(chan bool, 1), make(chan bool, 1))
 }
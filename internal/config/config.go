@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
 
@@ -11,7 +12,9 @@ import (
 
 // Server represents the server configuration properties
 type Server struct {
-	Port int
+	Port            int
+	ShutdownTimeout time.Duration
+	AllowedOrigins  []string
 }
 
 // Logger represents the logger configuration properties
@@ -27,13 +30,122 @@ type Users struct {
 
 // RabbitMQ represents the rabbitmq configuration properties
 type RabbitMQ struct {
-	URL string
+	URL            string
+	ConfirmTimeout time.Duration
+	Prefetch       int
+}
+
+// MsgHandler represents the AMQP message handler configuration properties
+type MsgHandler struct {
+	Workers    int
+	MaxRetries int
+	RetryDelay time.Duration
 }
 
 // Things represents the things service to proxy request
 type Things struct {
 	Hostname string
 	Port     uint16
+	Cache    ThingsCache
+	Presence Presence
+	Types    ThingsTypes
+	Schema   ThingsSchema
+	Batch    ThingsBatch
+}
+
+// ThingsCache represents the things cache configuration properties
+type ThingsCache struct {
+	TTL     time.Duration
+	MaxSize int
+}
+
+// ThingsTypes represents the sensor types registry configuration properties
+type ThingsTypes struct {
+	Path string
+}
+
+// ThingsSchema represents the things' schema versioning configuration
+// properties. BreakingChanges is the policy applied to the schema updates
+// with breaking changes: flag or reject.
+type ThingsSchema struct {
+	BreakingChanges string
+	Storage         string
+	Path            string
+	MaxVersions     int
+}
+
+// ThingsBatch represents the things' batch registration configuration
+// properties
+type ThingsBatch struct {
+	Concurrency int
+}
+
+// Presence represents the things presence tracking configuration properties.
+// Timeouts overrides the timeout of specific things, by their ID.
+type Presence struct {
+	Timeout  time.Duration
+	Timeouts map[string]time.Duration
+}
+
+// Data represents the things' data configuration properties
+type Data struct {
+	MaxClockSkew time.Duration
+	Normalize    bool
+	LastValues   LastValues
+	History      History
+}
+
+// LastValues represents the last values store configuration properties
+type LastValues struct {
+	Storage       string
+	Path          string
+	FlushInterval time.Duration
+}
+
+// History represents the data history store configuration properties
+type History struct {
+	Path            string
+	SegmentSize     int64
+	SegmentDuration time.Duration
+	MaxAge          time.Duration
+	MaxSize         int64
+}
+
+// Rules represents the rules store configuration properties
+type Rules struct {
+	Storage string
+	Path    string
+}
+
+// Alarms represents the alarms store configuration properties
+type Alarms struct {
+	Storage    string
+	Path       string
+	MaxCleared int
+}
+
+// Commands represents the commands tracking configuration properties
+type Commands struct {
+	Storage       string
+	Path          string
+	FlushInterval time.Duration
+	Timeout       time.Duration
+	MaxFinished   int
+	QueueMaxAge   time.Duration
+}
+
+// Schedules represents the schedules store configuration properties
+type Schedules struct {
+	Storage string
+	Path    string
+	MaxRuns int
+}
+
+// Preferences represents the users' preferences store configuration
+// properties
+type Preferences struct {
+	Storage string
+	Path    string
 }
 
 // Config represents the service configuration
@@ -43,6 +155,13 @@ type Config struct {
 	Users
 	RabbitMQ
 	Things
+	MsgHandler
+	Data
+	Rules
+	Alarms
+	Commands
+	Schedules
+	Preferences
 }
 
 func readFile(name string) {
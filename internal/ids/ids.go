@@ -0,0 +1,18 @@
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// New generates a random ID, formed by 16 bytes in hexadecimal, to identify
+// the entities created by babeltower
+func New() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,141 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/internal/atomicfile"
+)
+
+// File keeps the content of a store kept in memory in a JSON file, so it's
+// restored when the service restarts. The changes are made through the file,
+// which serializes them with the writes, and the whole content is rewritten
+// atomically after them.
+type File struct {
+	path    string
+	name    string
+	content func() interface{}
+
+	// mutex serializes the changes and the writes, and protects the write
+	// state below
+	mutex    sync.Mutex
+	dirty    bool
+	writeErr error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a new File instance writing the content returned by the
+// function received as parameter, which is called while no change is being
+// made. The name identifies what's stored on the errors. When the flush
+// interval is zero, the file is written on every change. Otherwise, the
+// changes are written periodically and when the file is closed.
+func New(path, name string, flushInterval time.Duration, content func() interface{}) *File {
+	f := &File{path: path, name: name, content: content}
+	if flushInterval > 0 {
+		f.stop = make(chan struct{})
+		f.done = make(chan struct{})
+		go f.flushPeriodically(flushInterval)
+	}
+
+	return f
+}
+
+// Load parses the content previously written to the file into the value,
+// which is kept unchanged when the file doesn't exist yet
+func (f *File) Load(v interface{}) error {
+	raw, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s file: %w", f.name, err)
+	}
+
+	err = json.Unmarshal(raw, v)
+	if err != nil {
+		return fmt.Errorf("error parsing %s file: %w", f.name, err)
+	}
+
+	return nil
+}
+
+// Update makes the change and writes the file, unless the change fails or
+// the writes are periodic. The error of a periodic write which failed is
+// returned once, by the next update.
+func (f *File) Update(change func() error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	err := change()
+	if err != nil {
+		return err
+	}
+
+	f.dirty = true
+	if f.stop == nil {
+		return f.write()
+	}
+
+	err = f.writeErr
+	f.writeErr = nil
+	return err
+}
+
+// Close stops the periodic writes and writes the changes not written yet
+func (f *File) Close() error {
+	if f.stop != nil {
+		close(f.stop)
+		<-f.done
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.dirty {
+		return nil
+	}
+	return f.write()
+}
+
+func (f *File) flushPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(f.done)
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			f.mutex.Lock()
+			if f.dirty {
+				f.writeErr = f.write()
+			}
+			f.mutex.Unlock()
+		}
+	}
+}
+
+// write replaces the file atomically, so a failure while writing doesn't
+// corrupt the content previously written. It must be called while holding
+// the mutex.
+func (f *File) write() error {
+	raw, err := json.Marshal(f.content())
+	if err != nil {
+		return fmt.Errorf("error serializing %s: %w", f.name, err)
+	}
+
+	err = atomicfile.WriteFile(f.path, raw)
+	if err != nil {
+		return fmt.Errorf("error writing %s file: %w", f.name, err)
+	}
+
+	f.dirty = false
+	return nil
+}
@@ -0,0 +1,423 @@
+package mainflux
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+	contentType  = "application/json"
+)
+
+var emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)*$`)
+
+// Server is a stand-in for the Mainflux users and things services, keeping
+// the users, tokens and things in memory. Both services are served by the
+// same handler, so they can share the users' tokens.
+type Server struct {
+	logger logging.Logger
+	mutex  sync.Mutex
+	users  map[string]string // email -> password
+	tokens map[string]string // token -> email
+	things map[string]*thing // id -> thing
+	order  []string
+}
+
+type user struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type tokenRes struct {
+	Token string `json:"token"`
+}
+
+type userInfoRes struct {
+	Email string `json:"email"`
+}
+
+type thing struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name,omitempty"`
+	Key      string      `json:"key"`
+	Metadata interface{} `json:"metadata,omitempty"`
+	owner    string
+}
+
+type thingsPageRes struct {
+	Total  int      `json:"total"`
+	Offset int      `json:"offset"`
+	Limit  int      `json:"limit"`
+	Things []*thing `json:"things"`
+}
+
+// NewServer creates a new fake Mainflux server without users and things
+func NewServer(logger logging.Logger) *Server {
+	return &Server{
+		logger: logger,
+		users:  map[string]string{},
+		tokens: map[string]string{},
+		things: map[string]*thing{},
+	}
+}
+
+// Handler returns the handler serving the users and things services' API
+func (s *Server) Handler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/users", s.createUser).Methods("POST")
+	r.HandleFunc("/users", s.viewUser).Methods("GET")
+	r.HandleFunc("/tokens", s.createToken).Methods("POST")
+	r.HandleFunc("/things", s.createThing).Methods("POST")
+	r.HandleFunc("/things", s.listThings).Methods("GET")
+	r.HandleFunc("/things/{id}", s.getThing).Methods("GET")
+	r.HandleFunc("/things/{id}", s.updateThing).Methods("PUT")
+	r.HandleFunc("/things/{id}", s.removeThing).Methods("DELETE")
+	return r
+}
+
+// ListenAndServe serves the users and things services' API on the address
+func (s *Server) ListenAndServe(addr string) error {
+	s.logger.Infof("listening on %s", addr)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var u user
+	if !s.decodeBody(w, r, &u) {
+		return
+	}
+
+	if !u.valid() {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.users[u.Email]; ok {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	s.users[u.Email] = u.Password
+	s.logger.Debugf("user %s created", u.Email)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) createToken(w http.ResponseWriter, r *http.Request) {
+	var u user
+	if !s.decodeBody(w, r, &u) {
+		return
+	}
+
+	if !u.valid() {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	password, ok := s.users[u.Email]
+	if !ok || password != u.Password {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	token := newID()
+	s.tokens[token] = u.Email
+	s.writeJSON(w, http.StatusCreated, tokenRes{token})
+}
+
+func (s *Server) viewUser(w http.ResponseWriter, r *http.Request) {
+	email, ok := s.identify(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, userInfoRes{email})
+}
+
+func (s *Server) createThing(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.identify(w, r)
+	if !ok {
+		return
+	}
+
+	var t thing
+	if !s.decodeBody(w, r, &t) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t.Key == "" {
+		t.Key = newID()
+	}
+
+	for _, other := range s.things {
+		if other.Key == t.Key {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	}
+
+	t.ID = newID()
+	t.owner = owner
+	s.things[t.ID] = &t
+	s.order = append(s.order, t.ID)
+
+	s.logger.Debugf("thing %s created", t.ID)
+	w.Header().Set("Location", "/things/"+t.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) listThings(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.identify(w, r)
+	if !ok {
+		return
+	}
+
+	offset, err := readUintQuery(r, "offset", 0)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	limit, err := readUintQuery(r, "limit", defaultLimit)
+	if err != nil || limit == 0 || limit > maxLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var metadata interface{}
+	if value := r.URL.Query().Get("metadata"); value != "" {
+		err = json.Unmarshal([]byte(value), &metadata)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	owned := []*thing{}
+	for _, id := range s.order {
+		t := s.things[id]
+		if t.owner == owner && (metadata == nil || contains(t.Metadata, metadata)) {
+			owned = append(owned, t)
+		}
+	}
+
+	page := thingsPageRes{Total: len(owned), Offset: offset, Limit: limit, Things: []*thing{}}
+	if offset < len(owned) {
+		end := offset + limit
+		if end > len(owned) {
+			end = len(owned)
+		}
+		page.Things = owned[offset:end]
+	}
+
+	s.writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) getThing(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.identify(w, r)
+	if !ok {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, ok := s.things[mux.Vars(r)["id"]]
+	if !ok || t.owner != owner {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) updateThing(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.identify(w, r)
+	if !ok {
+		return
+	}
+
+	var update thing
+	if !s.decodeBody(w, r, &update) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, ok := s.things[mux.Vars(r)["id"]]
+	if !ok || t.owner != owner {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	t.Name = update.Name
+	t.Metadata = update.Metadata
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) removeThing(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.identify(w, r)
+	if !ok {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := mux.Vars(r)["id"]
+	if t, ok := s.things[id]; ok && t.owner == owner {
+		delete(s.things, id)
+		for i := range s.order {
+			if s.order[i] == id {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+		s.logger.Debugf("thing %s removed", id)
+	}
+
+	// as Mainflux does, removing a thing which doesn't exist succeeds
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// identify returns the email of the user owning the request's token. When the
+// token is invalid, the request is answered with 403 Forbidden.
+func (s *Server) identify(w http.ResponseWriter, r *http.Request) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	email, ok := s.tokens[r.Header.Get("Authorization")]
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return "", false
+	}
+
+	return email, true
+}
+
+// decodeBody decodes the JSON request's body. When it isn't possible, the
+// request is answered with the corresponding error status.
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		s.logger.Errorf("error sending response, %s", err)
+	}
+}
+
+// valid reports whether the user has a valid e-mail and a password, as
+// required to create the user and its tokens
+func (u user) valid() bool {
+	return emailRegexp.MatchString(u.Email) && u.Password != ""
+}
+
+// contains reports whether the JSON value a contains b, as the PostgreSQL's
+// @> operator used by Mainflux to filter the things by metadata
+func contains(a, b interface{}) bool {
+	switch b := b.(type) {
+	case map[string]interface{}:
+		obj, ok := a.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for key, value := range b {
+			if !contains(obj[key], value) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		arr, ok := a.([]interface{})
+		if !ok {
+			return false
+		}
+
+		for _, value := range b {
+			found := false
+			for _, elem := range arr {
+				if contains(elem, value) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
+
+func readUintQuery(r *http.Request, key string, def int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return int(n), nil
+}
+
+// newID generates a random UUID, as the ones used by Mainflux
+func newID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
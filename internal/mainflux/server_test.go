@@ -0,0 +1,171 @@
+package mainflux
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	thingDeliveryHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	userDeliveryHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func startServer(t *testing.T) (*httptest.Server, string, uint16) {
+	ts := httptest.NewServer(NewServer(&mocks.FakeLogger{}).Handler())
+	u, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	assert.NoError(t, err)
+	return ts, u.Hostname(), uint16(port)
+}
+
+func createToken(t *testing.T, hostname string, port uint16, email string) string {
+	proxy := userDeliveryHTTP.NewUserProxy(&mocks.FakeLogger{}, hostname, port)
+	user := userEntities.User{Email: email, Password: "12345678"}
+	assert.NoError(t, proxy.Create(user))
+	token, err := proxy.CreateToken(user)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestUsers(t *testing.T) {
+	ts, hostname, port := startServer(t)
+	defer ts.Close()
+	proxy := userDeliveryHTTP.NewUserProxy(&mocks.FakeLogger{}, hostname, port)
+	assert.NoError(t, proxy.Create(userEntities.User{Email: "registered@test.com", Password: "12345678"}))
+
+	testCases := []struct {
+		name             string
+		user             userEntities.User
+		expectedCreate   error
+		expectedTokenErr error
+	}{
+		{
+			"new user is created and receives a token",
+			userEntities.User{Email: "new@test.com", Password: "12345678"},
+			nil,
+			nil,
+		},
+		{
+			"registered user conflicts and receives a token",
+			userEntities.User{Email: "registered@test.com", Password: "12345678"},
+			userEntities.ErrUserExists,
+			nil,
+		},
+		{
+			"registered user with wrong password doesn't receive a token",
+			userEntities.User{Email: "registered@test.com", Password: "wrong-password"},
+			userEntities.ErrUserExists,
+			userEntities.ErrUserForbidden,
+		},
+		{
+			"invalid e-mail isn't created",
+			userEntities.User{Email: "invalid e-mail", Password: "12345678"},
+			userEntities.ErrUserBadRequest,
+			userEntities.ErrUserBadRequest,
+		},
+		{
+			"empty password isn't created",
+			userEntities.User{Email: "empty@test.com"},
+			userEntities.ErrUserBadRequest,
+			userEntities.ErrUserBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := proxy.Create(tc.user)
+			assert.Equal(t, tc.expectedCreate, err)
+
+			token, err := proxy.CreateToken(tc.user)
+			assert.Equal(t, tc.expectedTokenErr, err)
+			if err == nil {
+				assert.NotEmpty(t, token)
+
+				email, err := proxy.Identify(token)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.user.Email, email)
+			}
+		})
+	}
+
+	_, err := proxy.Identify("invalid-token")
+	assert.Equal(t, userEntities.ErrUserForbidden, err)
+}
+
+func TestThings(t *testing.T) {
+	ts, hostname, port := startServer(t)
+	defer ts.Close()
+	token := createToken(t, hostname, port, "owner@test.com")
+	otherToken := createToken(t, hostname, port, "other@test.com")
+	proxy := thingDeliveryHTTP.NewThingProxy(&mocks.FakeLogger{}, hostname, port)
+	schema := []thingEntities.Schema{{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"}}
+
+	id, err := proxy.Create("0123456789abcdef", "thing", token)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	assert.NoError(t, proxy.UpdateSchema(token, "0123456789abcdef", schema))
+	thing, err := proxy.Get(token, "0123456789abcdef")
+	assert.NoError(t, err)
+	assert.Equal(t, &thingEntities.Thing{ID: "0123456789abcdef", Token: id, Name: "thing", Schema: schema}, thing)
+
+	_, err = proxy.Get(otherToken, "0123456789abcdef")
+	assert.Equal(t, thingEntities.ErrThingNotFound, err)
+
+	_, err = proxy.List("invalid-token")
+	assert.Equal(t, thingEntities.ErrThingForbidden, err)
+
+	assert.NoError(t, proxy.Remove(token, "0123456789abcdef"))
+	things, err := proxy.List(token)
+	assert.NoError(t, err)
+	assert.Empty(t, things)
+}
+
+func TestListThingsPagination(t *testing.T) {
+	ts, hostname, port := startServer(t)
+	defer ts.Close()
+	token := createToken(t, hostname, port, "owner@test.com")
+	proxy := thingDeliveryHTTP.NewThingProxy(&mocks.FakeLogger{}, hostname, port)
+	for i := 0; i < 150; i++ {
+		_, err := proxy.Create(fmt.Sprintf("%016x", i), "thing", token)
+		assert.NoError(t, err)
+	}
+
+	things, err := proxy.List(token)
+	assert.NoError(t, err)
+	if assert.Len(t, things, 150) {
+		assert.Equal(t, fmt.Sprintf("%016x", 149), things[149].ID)
+	}
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{"default page", "", http.StatusOK},
+		{"last page", "?limit=100&offset=100", http.StatusOK},
+		{"limit above maximum", "?limit=101", http.StatusBadRequest},
+		{"zero limit", "?limit=0", http.StatusBadRequest},
+		{"negative offset", "?offset=-1", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+"/things"+tc.query, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
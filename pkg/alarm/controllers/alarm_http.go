@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/interactors"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/gorilla/mux"
+)
+
+// AlarmHTTPController handles the HTTP requests to manage the users' alarm
+// definitions and alarms
+type AlarmHTTPController struct {
+	logger          logging.Logger
+	alarmInteractor interactors.Interactor
+}
+
+// DefinitionRequest represents the request to create or update an alarm
+// definition
+type DefinitionRequest struct {
+	Name     string              `json:"name"`
+	ThingID  string              `json:"thingId"`
+	SensorID int                 `json:"sensorId"`
+	Severity string              `json:"severity"`
+	Raise    entities.Condition  `json:"raise"`
+	Clear    *entities.Condition `json:"clear"`
+}
+
+// NewAlarmHTTPController constructs the controller
+func NewAlarmHTTPController(logger logging.Logger, alarmInteractor interactors.Interactor) *AlarmHTTPController {
+	return &AlarmHTTPController{logger, alarmInteractor}
+}
+
+// CreateDefinition godoc
+// @Summary Creates a new alarm definition
+// @Description The definition raises an alarm when its sensor's value satisfies the raise condition, which stays active until the value satisfies the clear condition or, when there's no clear condition, stops satisfying the raise condition.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param definition body DefinitionRequest true "Definition's sensor, severity and conditions"
+// @Success 201 {object} entities.Definition "Created alarm definition with its id"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or alarm definition"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarm-definitions [post]
+// CreateDefinition handles the server request and calls the create alarm definition use case
+func (ac *AlarmHTTPController) CreateDefinition(w http.ResponseWriter, r *http.Request) {
+	definition, ok := ac.parseDefinition(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := ac.alarmInteractor.CreateDefinition(r.Header.Get("Authorization"), definition)
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.logger.Infof("alarm definition %s created", created.ID)
+	w.Header().Set("Location", "/alarm-definitions/"+created.ID)
+	ac.writeResponse(w, http.StatusCreated, created)
+}
+
+// ListDefinitions godoc
+// @Summary Lists the user's alarm definitions
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Success 200 {array} entities.Definition "User's alarm definitions"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarm-definitions [get]
+// ListDefinitions handles the server request and calls the list alarm definitions use case
+func (ac *AlarmHTTPController) ListDefinitions(w http.ResponseWriter, r *http.Request) {
+	definitions, err := ac.alarmInteractor.ListDefinitions(r.Header.Get("Authorization"))
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.writeResponse(w, http.StatusOK, definitions)
+}
+
+// GetDefinition godoc
+// @Summary Gets an alarm definition
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Alarm definition's id"
+// @Success 200 {object} entities.Definition "Alarm definition"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Alarm definition not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarm-definitions/{id} [get]
+// GetDefinition handles the server request and calls the get alarm definition use case
+func (ac *AlarmHTTPController) GetDefinition(w http.ResponseWriter, r *http.Request) {
+	definition, err := ac.alarmInteractor.GetDefinition(r.Header.Get("Authorization"), mux.Vars(r)["id"])
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.writeResponse(w, http.StatusOK, definition)
+}
+
+// UpdateDefinition godoc
+// @Summary Updates an alarm definition
+// @Description The active alarm raised by the definition is cleared by the updated conditions, or right away when the definition is changed to another sensor.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Alarm definition's id"
+// @Param definition body DefinitionRequest true "Definition's sensor, severity and conditions"
+// @Success 200 {object} entities.Definition "Updated alarm definition"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Alarm definition not found"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or alarm definition"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarm-definitions/{id} [put]
+// UpdateDefinition handles the server request and calls the update alarm definition use case
+func (ac *AlarmHTTPController) UpdateDefinition(w http.ResponseWriter, r *http.Request) {
+	definition, ok := ac.parseDefinition(w, r)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	updated, err := ac.alarmInteractor.UpdateDefinition(r.Header.Get("Authorization"), id, definition)
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.logger.Infof("alarm definition %s updated", id)
+	ac.writeResponse(w, http.StatusOK, updated)
+}
+
+// DeleteDefinition godoc
+// @Summary Deletes an alarm definition
+// @Description The definition's active alarm is cleared, while the alarms it raised are kept.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Alarm definition's id"
+// @Success 204 "Alarm definition deleted"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Alarm definition not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarm-definitions/{id} [delete]
+// DeleteDefinition handles the server request and calls the delete alarm definition use case
+func (ac *AlarmHTTPController) DeleteDefinition(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	err := ac.alarmInteractor.DeleteDefinition(r.Header.Get("Authorization"), id)
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.logger.Infof("alarm definition %s deleted", id)
+	ac.writeResponse(w, http.StatusNoContent, nil)
+}
+
+// List godoc
+// @Summary Lists the user's alarms
+// @Description The alarms are sorted from the most recently raised. Only the most recently cleared alarms are kept, according to the alarms.maxCleared configuration.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param state query string false "Alarms' state: active or cleared"
+// @Param thingId query string false "Thing's id"
+// @Success 200 {array} entities.Alarm "User's alarms"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid state"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarms [get]
+// List handles the server request and calls the list alarms use case
+func (ac *AlarmHTTPController) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	alarms, err := ac.alarmInteractor.List(r.Header.Get("Authorization"), query.Get("state"), query.Get("thingId"))
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.writeResponse(w, http.StatusOK, alarms)
+}
+
+// Get godoc
+// @Summary Gets an alarm
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Alarm's id"
+// @Success 200 {object} entities.Alarm "Alarm"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Alarm not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarms/{id} [get]
+// Get handles the server request and calls the get alarm use case
+func (ac *AlarmHTTPController) Get(w http.ResponseWriter, r *http.Request) {
+	alarm, err := ac.alarmInteractor.Get(r.Header.Get("Authorization"), mux.Vars(r)["id"])
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.writeResponse(w, http.StatusOK, alarm)
+}
+
+// Acknowledge godoc
+// @Summary Acknowledges an alarm
+// @Description Both active and cleared alarms can be acknowledged, once.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Alarm's id"
+// @Success 200 {object} entities.Alarm "Acknowledged alarm"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Alarm not found"
+// @Failure 409 {object} controllers.ErrorResponse "Alarm already acknowledged"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /alarms/{id}/ack [post]
+// Acknowledge handles the server request and calls the acknowledge alarm use case
+func (ac *AlarmHTTPController) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	alarm, err := ac.alarmInteractor.Acknowledge(r.Header.Get("Authorization"), id)
+	if err != nil {
+		ac.writeError(w, err)
+		return
+	}
+
+	ac.logger.Infof("alarm %s acknowledged", id)
+	ac.writeResponse(w, http.StatusOK, alarm)
+}
+
+// parseDefinition decodes the alarm definition from the request's body. When
+// it isn't possible, the request is answered with 422 Unprocessable Entity.
+func (ac *AlarmHTTPController) parseDefinition(w http.ResponseWriter, r *http.Request) (entities.Definition, bool) {
+	var req DefinitionRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ac.logger.Error("failed to parse request body")
+		ac.writeResponse(w, http.StatusUnprocessableEntity, &thingControllers.ErrorResponse{Message: err.Error()})
+		return entities.Definition{}, false
+	}
+
+	definition := entities.Definition{
+		Name:     req.Name,
+		ThingID:  req.ThingID,
+		SensorID: req.SensorID,
+		Severity: req.Severity,
+		Raise:    req.Raise,
+		Clear:    req.Clear,
+	}
+	return definition, true
+}
+
+func (ac *AlarmHTTPController) writeError(w http.ResponseWriter, err error) {
+	ac.logger.Error(err)
+	ac.writeResponse(w, mapAlarmErrorToStatusCode(err), &thingControllers.ErrorResponse{Message: err.Error()})
+}
+
+func (ac *AlarmHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	if msg == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	js, err := json.Marshal(msg)
+	if err != nil {
+		ac.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		ac.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func mapAlarmErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, thingInteractors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, userEntities.ErrUserForbidden),
+		errors.Is(err, thingEntities.ErrThingForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrDefinitionNotFound),
+		errors.Is(err, entities.ErrAlarmNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, interactors.ErrAlarmAcknowledged):
+		return http.StatusConflict
+	case errors.Is(err, interactors.ErrDefinitionInvalid),
+		errors.Is(err, interactors.ErrStateInvalid):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,61 @@
+package amqp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+)
+
+const (
+	exchangeAlarm          = "alarm"
+	exchangeAlarmType      = "direct"
+	routingKeyRaised       = "alarm.raised"
+	routingKeyCleared      = "alarm.cleared"
+	routingKeyAcknowledged = "alarm.acknowledged"
+)
+
+// Publisher provides methods to send the alarms events to the clients
+type Publisher interface {
+	PublishAlarmRaised(alarm entities.Alarm) error
+	PublishAlarmCleared(alarm entities.Alarm) error
+	PublishAlarmAcknowledged(alarm entities.Alarm) error
+}
+
+// msgPublisher publishes the alarms events to the broker
+type msgPublisher struct {
+	logger logging.Logger
+	amqp   network.AmqpSender
+}
+
+// NewMsgPublisher creates a new msgPublisher instance
+func NewMsgPublisher(logger logging.Logger, amqp network.AmqpSender) Publisher {
+	return &msgPublisher{logger, amqp}
+}
+
+// PublishAlarmRaised sends the alarm raised event
+func (mp *msgPublisher) PublishAlarmRaised(alarm entities.Alarm) error {
+	return mp.publish(routingKeyRaised, alarm)
+}
+
+// PublishAlarmCleared sends the alarm cleared event
+func (mp *msgPublisher) PublishAlarmCleared(alarm entities.Alarm) error {
+	return mp.publish(routingKeyCleared, alarm)
+}
+
+// PublishAlarmAcknowledged sends the alarm acknowledged event
+func (mp *msgPublisher) PublishAlarmAcknowledged(alarm entities.Alarm) error {
+	return mp.publish(routingKeyAcknowledged, alarm)
+}
+
+func (mp *msgPublisher) publish(routingKey string, alarm entities.Alarm) error {
+	msg, err := json.Marshal(alarm)
+	if err != nil {
+		return fmt.Errorf("message parsing error: %w", err)
+	}
+
+	return thingAMQP.MapPublishError(mp.amqp.PublishPersistentMessage(exchangeAlarm, exchangeAlarmType, routingKey, msg, nil))
+}
@@ -0,0 +1,70 @@
+package entities
+
+import "time"
+
+// Severities of the alarms, from the most to the least severe
+const (
+	SeverityCritical = "critical"
+	SeverityMajor    = "major"
+	SeverityMinor    = "minor"
+	SeverityWarning  = "warning"
+)
+
+// States of the alarm's lifecycle
+const (
+	StateActive  = "active"
+	StateCleared = "cleared"
+)
+
+// Definition represents the conditions raising and clearing an alarm on a
+// sensor of the user's thing. The alarm is raised when the sensor's value
+// satisfies the raise condition and stays active until the value satisfies
+// the clear condition or, when there's no clear condition, stops satisfying
+// the raise condition. Only one alarm of each definition is active at a time.
+type Definition struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	ThingID  string     `json:"thingId"`
+	SensorID int        `json:"sensorId"`
+	Severity string     `json:"severity"`
+	Raise    Condition  `json:"raise"`
+	Clear    *Condition `json:"clear,omitempty"`
+
+	// Owner is the e-mail of the user who created the definition
+	Owner string `json:"-"`
+	// ThingToken is the thing's ID on the things service, which is unique
+	// among all the users
+	ThingToken string `json:"-"`
+}
+
+// Condition represents a comparison of the sensor's value, with the same
+// operators as the rules' conditions except the rate ones
+type Condition struct {
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// Alarm represents an alarm raised by a definition, which keeps the
+// definition's name and severity at the time it was raised. The times are
+// the ones the values were read, as informed by the thing, or received.
+type Alarm struct {
+	ID             string      `json:"id"`
+	DefinitionID   string      `json:"definitionId"`
+	Name           string      `json:"name"`
+	ThingID        string      `json:"thingId"`
+	SensorID       int         `json:"sensorId"`
+	Severity       string      `json:"severity"`
+	State          string      `json:"state"`
+	RaisedAt       time.Time   `json:"raisedAt"`
+	RaisedValue    interface{} `json:"raisedValue"`
+	ClearedAt      *time.Time  `json:"clearedAt,omitempty"`
+	ClearedValue   interface{} `json:"clearedValue,omitempty"`
+	Acknowledged   bool        `json:"acknowledged"`
+	AcknowledgedAt *time.Time  `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy string      `json:"acknowledgedBy,omitempty"`
+
+	// Owner is the e-mail of the user who owns the alarm's definition
+	Owner string `json:"-"`
+	// ThingToken is the thing's ID on the things service
+	ThingToken string `json:"-"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "errors"
+
+var (
+	// ErrDefinitionNotFound is returned when the alarm definition isn't found
+	// among the user's definitions
+	ErrDefinitionNotFound = errors.New("alarm definition not found")
+
+	// ErrAlarmNotFound is returned when the alarm isn't found among the user's
+	// alarms
+	ErrAlarmNotFound = errors.New("alarm not found")
+)
@@ -0,0 +1,16 @@
+package interactors
+
+import "errors"
+
+var (
+	// ErrDefinitionInvalid is returned when the alarm definition has an invalid
+	// format or refers to a thing or sensor which doesn't exist
+	ErrDefinitionInvalid = errors.New("invalid alarm definition")
+
+	// ErrStateInvalid is returned when listing the alarms in an unknown state
+	ErrStateInvalid = errors.New("invalid alarm state")
+
+	// ErrAlarmAcknowledged is returned when acknowledging an alarm which was
+	// already acknowledged
+	ErrAlarmAcknowledged = errors.New("alarm already acknowledged")
+)
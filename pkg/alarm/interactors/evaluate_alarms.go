@@ -0,0 +1,193 @@
+package interactors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/internal/ids"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	ruleEntities "github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+const (
+	eventRaised       = "raised"
+	eventCleared      = "cleared"
+	eventAcknowledged = "acknowledged"
+)
+
+// alarmEvent represents a change of the alarm's lifecycle to be sent to the
+// clients
+type alarmEvent struct {
+	kind  string
+	alarm entities.Alarm
+}
+
+// OnDataPublished evaluates the alarm definitions of the thing on the data,
+// in the order it was published, raising and clearing their alarms
+func (i *AlarmInteractor) OnDataPublished(thing *thingEntities.Thing, data []thingEntities.Data) {
+	definitions, err := i.store.ListDefinitionsByThing(thing.Token)
+	if err != nil {
+		i.logger.Errorf("error getting thing %s alarm definitions: %s", thing.ID, err)
+		return
+	}
+
+	events := []alarmEvent{}
+	i.alarmsMutex.Lock()
+	for _, d := range data {
+		for _, definition := range definitions {
+			if definition.SensorID != d.SensorID {
+				continue
+			}
+
+			event, err := i.evaluate(definition, d)
+			if err != nil {
+				i.logger.Errorf("error evaluating alarm definition %s: %s", definition.ID, err)
+				continue
+			}
+			if event != nil {
+				events = append(events, *event)
+			}
+		}
+	}
+	i.alarmsMutex.Unlock()
+
+	for _, event := range events {
+		i.publish(event)
+	}
+}
+
+// evaluate raises the definition's alarm when there's no active alarm and
+// the value satisfies the raise condition, or clears the active alarm when
+// the value satisfies the clear condition
+func (i *AlarmInteractor) evaluate(definition entities.Definition, d thingEntities.Data) (*alarmEvent, error) {
+	active, err := i.store.ActiveAlarm(definition.ID)
+	if err != nil && !errors.Is(err, entities.ErrAlarmNotFound) {
+		return nil, err
+	}
+
+	at := d.ReadAt(i.now())
+	if active == nil {
+		if !matches(definition.Raise, d.Value) {
+			return nil, nil
+		}
+
+		id, err := ids.New()
+		if err != nil {
+			return nil, fmt.Errorf("error generating alarm's id: %w", err)
+		}
+
+		alarm := entities.Alarm{
+			ID:           id,
+			DefinitionID: definition.ID,
+			Name:         definition.Name,
+			ThingID:      definition.ThingID,
+			SensorID:     definition.SensorID,
+			Severity:     definition.Severity,
+			State:        entities.StateActive,
+			RaisedAt:     at,
+			RaisedValue:  d.Value,
+			Owner:        definition.Owner,
+			ThingToken:   definition.ThingToken,
+		}
+		err = i.store.SaveAlarm(alarm)
+		if err != nil {
+			return nil, fmt.Errorf("error storing alarm: %w", err)
+		}
+
+		return &alarmEvent{eventRaised, alarm}, nil
+	}
+
+	clears := !matches(definition.Raise, d.Value)
+	if definition.Clear != nil {
+		clears = matches(*definition.Clear, d.Value)
+	}
+	if !clears {
+		return nil, nil
+	}
+
+	active.State = entities.StateCleared
+	active.ClearedAt = &at
+	active.ClearedValue = d.Value
+	err = i.store.SaveAlarm(*active)
+	if err != nil {
+		return nil, fmt.Errorf("error storing alarm: %w", err)
+	}
+
+	return &alarmEvent{eventCleared, *active}, nil
+}
+
+// clearActiveAlarm clears the definition's active alarm, without a value,
+// when its definition is removed or changed to another sensor
+func (i *AlarmInteractor) clearActiveAlarm(definitionID string) {
+	i.alarmsMutex.Lock()
+	active, err := i.store.ActiveAlarm(definitionID)
+	if err == nil {
+		now := i.now()
+		active.State = entities.StateCleared
+		active.ClearedAt = &now
+		err = i.store.SaveAlarm(*active)
+	}
+	i.alarmsMutex.Unlock()
+
+	if errors.Is(err, entities.ErrAlarmNotFound) {
+		return
+	}
+	if err != nil {
+		i.logger.Errorf("error clearing alarm definition %s active alarm: %s", definitionID, err)
+		return
+	}
+
+	i.publish(alarmEvent{eventCleared, *active})
+}
+
+func (i *AlarmInteractor) publish(event alarmEvent) {
+	i.logger.Infof("alarm %s %s", event.alarm.ID, event.kind)
+
+	var err error
+	switch event.kind {
+	case eventRaised:
+		err = i.publisher.PublishAlarmRaised(event.alarm)
+	case eventCleared:
+		err = i.publisher.PublishAlarmCleared(event.alarm)
+	case eventAcknowledged:
+		err = i.publisher.PublishAlarmAcknowledged(event.alarm)
+	}
+
+	if errors.Is(err, thingAMQP.ErrUndeliverable) {
+		i.logger.Warn(err)
+	} else if err != nil {
+		i.logger.Errorf("error sending alarm %s %s event: %s", event.alarm.ID, event.kind, err)
+	}
+}
+
+// matches compares the value received in JSON, which is a number, boolean or
+// string, to the condition's value
+func matches(c entities.Condition, value interface{}) bool {
+	switch c.Operator {
+	case ruleEntities.OperatorEqual:
+		return thingEntities.EqualValues(value, c.Value)
+	case ruleEntities.OperatorNotEqual:
+		return !thingEntities.EqualValues(value, c.Value)
+	}
+
+	v, ok := value.(float64)
+	threshold, _ := c.Value.(float64)
+	if !ok {
+		return false
+	}
+
+	switch c.Operator {
+	case ruleEntities.OperatorGreater:
+		return v > threshold
+	case ruleEntities.OperatorGreaterEqual:
+		return v >= threshold
+	case ruleEntities.OperatorLess:
+		return v < threshold
+	case ruleEntities.OperatorLessEqual:
+		return v <= threshold
+	default:
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+package interactors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/storage"
+	ruleEntities "github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+var receivedAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func reading(sensorID int, value interface{}, seconds int) thingEntities.Data {
+	timestamp := receivedAt.Add(time.Duration(seconds) * time.Second)
+	return thingEntities.Data{SensorID: sensorID, Value: value, ReceivedAt: &timestamp}
+}
+
+func TestEvaluateAlarms(t *testing.T) {
+	type change struct {
+		event string
+		value interface{}
+	}
+
+	testCases := []struct {
+		name            string
+		change          func(definition *entities.Definition)
+		published       []thingEntities.Data
+		expectedChanges []change
+	}{
+		{
+			"raised once while the raise condition holds",
+			func(definition *entities.Definition) {},
+			[]thingEntities.Data{reading(1, 29.5, 0), reading(1, 30.5, 1), reading(1, 31.5, 2)},
+			[]change{{"PublishAlarmRaised", 30.5}},
+		},
+		{
+			"cleared only when the clear condition holds",
+			func(definition *entities.Definition) {},
+			[]thingEntities.Data{reading(1, 30.5, 0), reading(1, 27.5, 1), reading(1, 24.5, 2), reading(1, 31.5, 3)},
+			[]change{{"PublishAlarmRaised", 30.5}, {"PublishAlarmCleared", 24.5}, {"PublishAlarmRaised", 31.5}},
+		},
+		{
+			"cleared when the raise condition stops holding without a clear condition",
+			func(definition *entities.Definition) { definition.Clear = nil },
+			[]thingEntities.Data{reading(1, 30.5, 0), reading(1, 27.5, 1)},
+			[]change{{"PublishAlarmRaised", 30.5}, {"PublishAlarmCleared", 27.5}},
+		},
+		{
+			"boolean sensor",
+			func(definition *entities.Definition) {
+				definition.SensorID = 2
+				definition.Raise = entities.Condition{Operator: ruleEntities.OperatorEqual, Value: true}
+				definition.Clear = nil
+			},
+			[]thingEntities.Data{reading(2, false, 0), reading(1, 30.5, 1), reading(2, true, 2), reading(2, false, 3)},
+			[]change{{"PublishAlarmRaised", true}, {"PublishAlarmCleared", false}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := storage.NewMemoryAlarmStore(0)
+			interactor, publisher := newTestInteractor(store)
+			definition := temperatureDefinition()
+			tc.change(&definition)
+			created, err := interactor.CreateDefinition("user-token", definition)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			interactor.OnDataPublished(sensorThing, tc.published)
+
+			changes := []change{}
+			for _, call := range publisher.Calls {
+				alarm := call.Arguments.Get(0).(entities.Alarm)
+				value := alarm.RaisedValue
+				if call.Method == "PublishAlarmCleared" {
+					value = alarm.ClearedValue
+				}
+				changes = append(changes, change{call.Method, value})
+				assert.Equal(t, created.ID, alarm.DefinitionID)
+			}
+			assert.Equal(t, tc.expectedChanges, changes)
+		})
+	}
+}
+
+func TestAlarmLifecycle(t *testing.T) {
+	store := storage.NewMemoryAlarmStore(0)
+	interactor, publisher := newTestInteractor(store)
+	definition, err := interactor.CreateDefinition("user-token", temperatureDefinition())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	interactor.OnDataPublished(&thingEntities.Thing{ID: "sensor-thing", Token: "other-mainflux-id"}, []thingEntities.Data{reading(1, 30.5, 0)})
+	interactor.OnDataPublished(sensorThing, []thingEntities.Data{reading(1, 30.5, 10)})
+	alarms, err := interactor.List("user-token", entities.StateActive, "")
+	assert.NoError(t, err)
+	if !assert.Len(t, alarms, 1) {
+		return
+	}
+
+	raised := alarms[0]
+	assert.Equal(t, entities.Alarm{
+		ID:           raised.ID,
+		DefinitionID: definition.ID,
+		Name:         "high temperature",
+		ThingID:      "sensor-thing",
+		SensorID:     1,
+		Severity:     entities.SeverityCritical,
+		State:        entities.StateActive,
+		RaisedAt:     receivedAt.Add(10 * time.Second),
+		RaisedValue:  30.5,
+		Owner:        "user@test.com",
+		ThingToken:   "sensor-mainflux-id",
+	}, raised)
+
+	_, err = interactor.Get("other-user-token", raised.ID)
+	assert.Equal(t, entities.ErrAlarmNotFound, err)
+	_, err = interactor.Acknowledge("other-user-token", raised.ID)
+	assert.Equal(t, entities.ErrAlarmNotFound, err)
+
+	acknowledged, err := interactor.Acknowledge("user-token", raised.ID)
+	assert.NoError(t, err)
+	assert.True(t, acknowledged.Acknowledged)
+	assert.Equal(t, receivedAt, *acknowledged.AcknowledgedAt)
+	assert.Equal(t, "user@test.com", acknowledged.AcknowledgedBy)
+	_, err = interactor.Acknowledge("user-token", raised.ID)
+	assert.Equal(t, ErrAlarmAcknowledged, err)
+
+	// the acknowledged alarm is still active until it's cleared
+	assert.NoError(t, interactor.DeleteDefinition("user-token", definition.ID))
+	cleared, err := interactor.Get("user-token", raised.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.StateCleared, cleared.State)
+	assert.Equal(t, receivedAt, *cleared.ClearedAt)
+	assert.True(t, cleared.Acknowledged)
+
+	alarms, err = interactor.List("user-token", entities.StateCleared, "sensor-thing")
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Alarm{*cleared}, alarms)
+	alarms, err = interactor.List("user-token", entities.StateActive, "")
+	assert.NoError(t, err)
+	assert.Empty(t, alarms)
+	_, err = interactor.List("user-token", "acknowledged", "")
+	assert.Error(t, err)
+
+	publisher.AssertNumberOfCalls(t, "PublishAlarmRaised", 1)
+	publisher.AssertNumberOfCalls(t, "PublishAlarmAcknowledged", 1)
+	publisher.AssertNumberOfCalls(t, "PublishAlarmCleared", 1)
+}
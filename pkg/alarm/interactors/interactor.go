@@ -0,0 +1,59 @@
+package interactors
+
+import (
+	"sync"
+	"time"
+
+	alarmAMQP "github.com/CESARBR/knot-babeltower/pkg/alarm/delivery/amqp"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	thingHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
+	userHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
+)
+
+// Interactor is an interface that defines the alarm's use cases operations
+type Interactor interface {
+	CreateDefinition(authorization string, definition entities.Definition) (*entities.Definition, error)
+	ListDefinitions(authorization string) ([]entities.Definition, error)
+	GetDefinition(authorization, id string) (*entities.Definition, error)
+	UpdateDefinition(authorization, id string, definition entities.Definition) (*entities.Definition, error)
+	DeleteDefinition(authorization, id string) error
+	List(authorization, state, thingID string) ([]entities.Alarm, error)
+	Get(authorization, id string) (*entities.Alarm, error)
+	Acknowledge(authorization, id string) (*entities.Alarm, error)
+}
+
+// AlarmInteractor represents the alarm interactor capabilities, it's composed
+// by the necessary dependencies. It's notified about the data published by
+// the things to raise and clear the alarms.
+type AlarmInteractor struct {
+	logger     logging.Logger
+	userProxy  userHTTP.UserProxy
+	thingProxy thingHTTP.ThingProxy
+	publisher  alarmAMQP.Publisher
+	store      storage.AlarmStore
+	now        func() time.Time
+
+	// alarmsMutex serializes the alarms' state changes, so an alarm isn't
+	// raised twice or acknowledged while being cleared
+	alarmsMutex sync.Mutex
+}
+
+// NewAlarmInteractor creates a new AlarmInteractor instance
+func NewAlarmInteractor(
+	logger logging.Logger,
+	userProxy userHTTP.UserProxy,
+	thingProxy thingHTTP.ThingProxy,
+	publisher alarmAMQP.Publisher,
+	store storage.AlarmStore,
+) *AlarmInteractor {
+	return &AlarmInteractor{
+		logger:     logger,
+		userProxy:  userProxy,
+		thingProxy: thingProxy,
+		publisher:  publisher,
+		store:      store,
+		now:        time.Now,
+	}
+}
@@ -0,0 +1,93 @@
+package interactors
+
+import (
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+)
+
+// List returns the alarms of the token's user from the most recently raised.
+// The alarms can be restricted to a state, active or cleared, and to a thing.
+func (i *AlarmInteractor) List(authorization, state, thingID string) ([]entities.Alarm, error) {
+	switch state {
+	case "", entities.StateActive, entities.StateCleared:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrStateInvalid, state)
+	}
+
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	alarms, err := i.store.ListAlarms(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []entities.Alarm{}
+	for _, alarm := range alarms {
+		if (state == "" || alarm.State == state) && (thingID == "" || alarm.ThingID == thingID) {
+			filtered = append(filtered, alarm)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Get returns the alarm when it belongs to the token's user
+func (i *AlarmInteractor) Get(authorization, id string) (*entities.Alarm, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.ownedAlarm(owner, id)
+}
+
+// Acknowledge marks the alarm as acknowledged by the token's user, whether
+// it's active or cleared, and sends the alarm acknowledged event
+func (i *AlarmInteractor) Acknowledge(authorization, id string) (*entities.Alarm, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	i.alarmsMutex.Lock()
+	alarm, err := i.ownedAlarm(owner, id)
+	if err == nil && alarm.Acknowledged {
+		err = ErrAlarmAcknowledged
+	}
+	if err != nil {
+		i.alarmsMutex.Unlock()
+		return nil, err
+	}
+
+	now := i.now()
+	alarm.Acknowledged = true
+	alarm.AcknowledgedAt = &now
+	alarm.AcknowledgedBy = owner
+	err = i.store.SaveAlarm(*alarm)
+	i.alarmsMutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("error storing alarm: %w", err)
+	}
+
+	i.publish(alarmEvent{eventAcknowledged, *alarm})
+	return alarm, nil
+}
+
+// ownedAlarm returns the alarm when it belongs to the user. The alarms of
+// other users aren't found, so their IDs aren't disclosed.
+func (i *AlarmInteractor) ownedAlarm(owner, id string) (*entities.Alarm, error) {
+	alarm, err := i.store.GetAlarm(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if alarm.Owner != owner {
+		return nil, entities.ErrAlarmNotFound
+	}
+
+	return alarm, nil
+}
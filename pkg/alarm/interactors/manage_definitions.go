@@ -0,0 +1,212 @@
+package interactors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/internal/ids"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	ruleEntities "github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// CreateDefinition validates and stores a new alarm definition of the token's
+// user. The severity is major when not informed.
+func (i *AlarmInteractor) CreateDefinition(authorization string, definition entities.Definition) (*entities.Definition, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	thingToken, err := i.validate(authorization, &definition)
+	if err != nil {
+		return nil, err
+	}
+
+	definition.ID, err = ids.New()
+	if err != nil {
+		return nil, fmt.Errorf("error generating alarm definition's id: %w", err)
+	}
+	definition.Owner = owner
+	definition.ThingToken = thingToken
+
+	err = i.store.SaveDefinition(definition)
+	if err != nil {
+		return nil, fmt.Errorf("error storing alarm definition: %w", err)
+	}
+
+	return &definition, nil
+}
+
+// ListDefinitions returns the alarm definitions of the token's user
+func (i *AlarmInteractor) ListDefinitions(authorization string) ([]entities.Definition, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.store.ListDefinitions(owner)
+}
+
+// GetDefinition returns the alarm definition when it belongs to the token's
+// user
+func (i *AlarmInteractor) GetDefinition(authorization, id string) (*entities.Definition, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.ownedDefinition(owner, id)
+}
+
+// UpdateDefinition validates and replaces the alarm definition when it
+// belongs to the token's user. The active alarm raised by the definition is
+// cleared when the updated definition refers to another sensor, otherwise
+// it's cleared by the updated conditions.
+func (i *AlarmInteractor) UpdateDefinition(authorization, id string, definition entities.Definition) (*entities.Definition, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := i.ownedDefinition(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	thingToken, err := i.validate(authorization, &definition)
+	if err != nil {
+		return nil, err
+	}
+
+	definition.ID = id
+	definition.Owner = owner
+	definition.ThingToken = thingToken
+	err = i.store.SaveDefinition(definition)
+	if err != nil {
+		return nil, fmt.Errorf("error storing alarm definition: %w", err)
+	}
+
+	if previous.ThingToken != thingToken || previous.SensorID != definition.SensorID {
+		i.clearActiveAlarm(id)
+	}
+
+	return &definition, nil
+}
+
+// DeleteDefinition removes the alarm definition when it belongs to the
+// token's user, clearing its active alarm. The alarms it raised are kept.
+func (i *AlarmInteractor) DeleteDefinition(authorization, id string) error {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.ownedDefinition(owner, id)
+	if err != nil {
+		return err
+	}
+
+	err = i.store.RemoveDefinition(id)
+	if err != nil {
+		return err
+	}
+
+	i.clearActiveAlarm(id)
+	return nil
+}
+
+func (i *AlarmInteractor) identify(authorization string) (string, error) {
+	if authorization == "" {
+		return "", thingInteractors.ErrAuthNotProvided
+	}
+
+	owner, err := i.userProxy.Identify(authorization)
+	if err != nil {
+		return "", fmt.Errorf("error identifying user: %w", err)
+	}
+
+	return owner, nil
+}
+
+// ownedDefinition returns the alarm definition when it belongs to the user.
+// The definitions of other users aren't found, so their IDs aren't disclosed.
+func (i *AlarmInteractor) ownedDefinition(owner, id string) (*entities.Definition, error) {
+	definition, err := i.store.GetDefinition(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if definition.Owner != owner {
+		return nil, entities.ErrDefinitionNotFound
+	}
+
+	return definition, nil
+}
+
+// validate verifies the definition refers to a sensor of the user's thing,
+// returning the thing's ID on the things service
+func (i *AlarmInteractor) validate(authorization string, definition *entities.Definition) (string, error) {
+	if definition.Name == "" {
+		return "", fmt.Errorf("%w: name not provided", ErrDefinitionInvalid)
+	}
+	if definition.ThingID == "" {
+		return "", fmt.Errorf("%w: thing's id not provided", ErrDefinitionInvalid)
+	}
+
+	switch definition.Severity {
+	case "":
+		definition.Severity = entities.SeverityMajor
+	case entities.SeverityCritical, entities.SeverityMajor, entities.SeverityMinor, entities.SeverityWarning:
+	default:
+		return "", fmt.Errorf("%w: unknown severity %q", ErrDefinitionInvalid, definition.Severity)
+	}
+
+	err := validateCondition("raise", definition.Raise)
+	if err != nil {
+		return "", err
+	}
+	if definition.Clear != nil {
+		err = validateCondition("clear", *definition.Clear)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	thing, err := i.thingProxy.Get(authorization, definition.ThingID)
+	if errors.Is(err, thingEntities.ErrThingNotFound) {
+		return "", fmt.Errorf("%w: thing %s not found", ErrDefinitionInvalid, definition.ThingID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting thing metadata: %w", err)
+	}
+
+	for _, s := range thing.Schema {
+		if s.SensorID == definition.SensorID {
+			return thing.Token, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: sensor %d not in thing's schema", ErrDefinitionInvalid, definition.SensorID)
+}
+
+func validateCondition(name string, c entities.Condition) error {
+	switch c.Operator {
+	case ruleEntities.OperatorEqual, ruleEntities.OperatorNotEqual:
+		switch c.Value.(type) {
+		case float64, bool, string:
+		default:
+			return fmt.Errorf("%w: the %s operator requires a number, boolean or string value", ErrDefinitionInvalid, c.Operator)
+		}
+	case ruleEntities.OperatorGreater, ruleEntities.OperatorGreaterEqual,
+		ruleEntities.OperatorLess, ruleEntities.OperatorLessEqual:
+		if _, ok := c.Value.(float64); !ok {
+			return fmt.Errorf("%w: the %s operator requires a numeric value", ErrDefinitionInvalid, c.Operator)
+		}
+	default:
+		return fmt.Errorf("%w: unknown %s operator %q", ErrDefinitionInvalid, name, c.Operator)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	ruleEntities "github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var sensorThing = &thingEntities.Thing{ID: "sensor-thing", Token: "sensor-mainflux-id", Schema: []thingEntities.Schema{
+	{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"},
+	{SensorID: 2, ValueType: 3, Unit: 0, TypeID: 65521, Name: "door"},
+}}
+
+func temperatureDefinition() entities.Definition {
+	return entities.Definition{
+		Name:     "high temperature",
+		ThingID:  "sensor-thing",
+		SensorID: 1,
+		Severity: entities.SeverityCritical,
+		Raise:    entities.Condition{Operator: ruleEntities.OperatorGreater, Value: float64(30)},
+		Clear:    &entities.Condition{Operator: ruleEntities.OperatorLess, Value: float64(25)},
+	}
+}
+
+func newTestInteractor(store storage.AlarmStore) (*AlarmInteractor, *mocks.FakeAlarmPublisher) {
+	userProxy := &mocks.FakeUserProxy{}
+	userProxy.On("Identify", "user-token").Return("user@test.com", nil)
+	userProxy.On("Identify", "other-user-token").Return("other@test.com", nil)
+	userProxy.On("Identify", "invalid-token").Return("", userEntities.ErrUserForbidden)
+	thingProxy := &mocks.FakeThingProxy{}
+	thingProxy.On("Get", "user-token", "sensor-thing").Return(sensorThing, nil)
+	thingProxy.On("Get", "user-token", "unknown-thing").Return((*thingEntities.Thing)(nil), thingEntities.ErrThingNotFound)
+	publisher := &mocks.FakeAlarmPublisher{}
+	publisher.On("PublishAlarmRaised", mock.Anything).Return(nil)
+	publisher.On("PublishAlarmCleared", mock.Anything).Return(nil)
+	publisher.On("PublishAlarmAcknowledged", mock.Anything).Return(nil)
+
+	interactor := NewAlarmInteractor(&mocks.FakeLogger{}, userProxy, thingProxy, publisher, store)
+	interactor.now = func() time.Time { return receivedAt }
+	return interactor, publisher
+}
+
+func TestCreateDefinition(t *testing.T) {
+	testCases := []struct {
+		name             string
+		authorization    string
+		change           func(definition *entities.Definition)
+		expectedSeverity string
+		expectedErr      error
+	}{
+		{
+			"authorization token not provided",
+			"",
+			func(definition *entities.Definition) {},
+			"",
+			thingInteractors.ErrAuthNotProvided,
+		},
+		{
+			"invalid authorization token",
+			"invalid-token",
+			func(definition *entities.Definition) {},
+			"",
+			userEntities.ErrUserForbidden,
+		},
+		{
+			"name not provided",
+			"user-token",
+			func(definition *entities.Definition) { definition.Name = "" },
+			"",
+			ErrDefinitionInvalid,
+		},
+		{
+			"unknown severity",
+			"user-token",
+			func(definition *entities.Definition) { definition.Severity = "fatal" },
+			"",
+			ErrDefinitionInvalid,
+		},
+		{
+			"unknown raise operator",
+			"user-token",
+			func(definition *entities.Definition) { definition.Raise.Operator = ruleEntities.OperatorRateGreater },
+			"",
+			ErrDefinitionInvalid,
+		},
+		{
+			"threshold without numeric value",
+			"user-token",
+			func(definition *entities.Definition) { definition.Clear.Value = "25" },
+			"",
+			ErrDefinitionInvalid,
+		},
+		{
+			"thing not found",
+			"user-token",
+			func(definition *entities.Definition) { definition.ThingID = "unknown-thing" },
+			"",
+			ErrDefinitionInvalid,
+		},
+		{
+			"sensor not in the thing's schema",
+			"user-token",
+			func(definition *entities.Definition) { definition.SensorID = 3 },
+			"",
+			ErrDefinitionInvalid,
+		},
+		{
+			"valid definition",
+			"user-token",
+			func(definition *entities.Definition) {},
+			entities.SeverityCritical,
+			nil,
+		},
+		{
+			"valid definition without severity and clear condition",
+			"user-token",
+			func(definition *entities.Definition) {
+				definition.Severity = ""
+				definition.Clear = nil
+			},
+			entities.SeverityMajor,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := storage.NewMemoryAlarmStore(0)
+			interactor, _ := newTestInteractor(store)
+			definition := temperatureDefinition()
+			tc.change(&definition)
+
+			created, err := interactor.CreateDefinition(tc.authorization, definition)
+
+			assert.True(t, errors.Is(err, tc.expectedErr), "unexpected error %v", err)
+			definitions, _ := store.ListDefinitions("user@test.com")
+			if tc.expectedErr != nil {
+				assert.Nil(t, created)
+				assert.Empty(t, definitions)
+				return
+			}
+
+			assert.NotEmpty(t, created.ID)
+			assert.Equal(t, tc.expectedSeverity, created.Severity)
+			assert.Equal(t, "user@test.com", created.Owner)
+			assert.Equal(t, "sensor-mainflux-id", created.ThingToken)
+			assert.Equal(t, []entities.Definition{*created}, definitions)
+		})
+	}
+}
+
+func TestManageDefinitionsOwnership(t *testing.T) {
+	store := storage.NewMemoryAlarmStore(0)
+	interactor, _ := newTestInteractor(store)
+	created, err := interactor.CreateDefinition("user-token", temperatureDefinition())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = interactor.GetDefinition("other-user-token", created.ID)
+	assert.Equal(t, entities.ErrDefinitionNotFound, err)
+	_, err = interactor.UpdateDefinition("other-user-token", created.ID, temperatureDefinition())
+	assert.Equal(t, entities.ErrDefinitionNotFound, err)
+	assert.Equal(t, entities.ErrDefinitionNotFound, interactor.DeleteDefinition("other-user-token", created.ID))
+	definitions, err := interactor.ListDefinitions("other-user-token")
+	assert.NoError(t, err)
+	assert.Empty(t, definitions)
+
+	changed := temperatureDefinition()
+	changed.Severity = entities.SeverityMinor
+	updated, err := interactor.UpdateDefinition("user-token", created.ID, changed)
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	definition, err := interactor.GetDefinition("user-token", created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.SeverityMinor, definition.Severity)
+
+	assert.NoError(t, interactor.DeleteDefinition("user-token", created.ID))
+	_, err = interactor.GetDefinition("user-token", created.ID)
+	assert.Equal(t, entities.ErrDefinitionNotFound, err)
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+)
+
+// FileAlarmStore keeps the alarm definitions and alarms in memory and writes
+// them to a JSON file on every change, so they're restored when the service
+// restarts
+type FileAlarmStore struct {
+	file   *jsonfile.File
+	memory *MemoryAlarmStore
+}
+
+// content represents the file's content, along with the fields which aren't
+// exposed to the users
+type content struct {
+	Definitions []definitionRecord `json:"definitions"`
+	Alarms      []alarmRecord      `json:"alarms"`
+}
+
+type definitionRecord struct {
+	Owner      string              `json:"owner"`
+	ThingToken string              `json:"thingToken"`
+	Definition entities.Definition `json:"definition"`
+}
+
+type alarmRecord struct {
+	Owner      string         `json:"owner"`
+	ThingToken string         `json:"thingToken"`
+	Alarm      entities.Alarm `json:"alarm"`
+}
+
+// NewFileAlarmStore creates a new FileAlarmStore instance keeping up to
+// maxCleared cleared alarms and loading the ones previously written to the
+// file, which is created when it doesn't exist yet
+func NewFileAlarmStore(path string, maxCleared int) (*FileAlarmStore, error) {
+	s := &FileAlarmStore{memory: NewMemoryAlarmStore(maxCleared)}
+	s.file = jsonfile.New(path, "alarms", 0, s.content)
+
+	var c content
+	err := s.file.Load(&c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range c.Definitions {
+		r.Definition.Owner = r.Owner
+		r.Definition.ThingToken = r.ThingToken
+		s.memory.definitions[r.Definition.ID] = r.Definition
+	}
+	for _, r := range c.Alarms {
+		r.Alarm.Owner = r.Owner
+		r.Alarm.ThingToken = r.ThingToken
+		s.memory.alarms[r.Alarm.ID] = r.Alarm
+	}
+
+	return s, nil
+}
+
+// SaveDefinition stores the definition, replacing the previous one with the
+// same ID, and writes the file
+func (s *FileAlarmStore) SaveDefinition(definition entities.Definition) error {
+	return s.file.Update(func() error { return s.memory.SaveDefinition(definition) })
+}
+
+// GetDefinition returns the definition with the ID
+func (s *FileAlarmStore) GetDefinition(id string) (*entities.Definition, error) {
+	return s.memory.GetDefinition(id)
+}
+
+// ListDefinitions returns the user's definitions sorted by their name
+func (s *FileAlarmStore) ListDefinitions(owner string) ([]entities.Definition, error) {
+	return s.memory.ListDefinitions(owner)
+}
+
+// ListDefinitionsByThing returns the definitions evaluated on the thing's
+// data sorted by their name
+func (s *FileAlarmStore) ListDefinitionsByThing(thingToken string) ([]entities.Definition, error) {
+	return s.memory.ListDefinitionsByThing(thingToken)
+}
+
+// RemoveDefinition removes the definition with the ID and writes the file
+func (s *FileAlarmStore) RemoveDefinition(id string) error {
+	return s.file.Update(func() error { return s.memory.RemoveDefinition(id) })
+}
+
+// SaveAlarm stores the alarm, replacing the previous one with the same ID,
+// and writes the file
+func (s *FileAlarmStore) SaveAlarm(alarm entities.Alarm) error {
+	return s.file.Update(func() error { return s.memory.SaveAlarm(alarm) })
+}
+
+// GetAlarm returns the alarm with the ID
+func (s *FileAlarmStore) GetAlarm(id string) (*entities.Alarm, error) {
+	return s.memory.GetAlarm(id)
+}
+
+// ListAlarms returns the user's alarms from the most recently raised
+func (s *FileAlarmStore) ListAlarms(owner string) ([]entities.Alarm, error) {
+	return s.memory.ListAlarms(owner)
+}
+
+// ActiveAlarm returns the definition's active alarm
+func (s *FileAlarmStore) ActiveAlarm(definitionID string) (*entities.Alarm, error) {
+	return s.memory.ActiveAlarm(definitionID)
+}
+
+// content returns the file's content. It's called by the file while no
+// change is being made.
+func (s *FileAlarmStore) content() interface{} {
+	definitions := s.memory.filterDefinitions(func(entities.Definition) bool { return true })
+	alarms := s.memory.filterAlarms(func(entities.Alarm) bool { return true })
+	c := content{
+		Definitions: make([]definitionRecord, 0, len(definitions)),
+		Alarms:      make([]alarmRecord, 0, len(alarms)),
+	}
+	for _, d := range definitions {
+		c.Definitions = append(c.Definitions, definitionRecord{d.Owner, d.ThingToken, d})
+	}
+	for _, a := range alarms {
+		c.Alarms = append(c.Alarms, alarmRecord{a.Owner, a.ThingToken, a})
+	}
+
+	return c
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+)
+
+// MemoryAlarmStore keeps the alarm definitions and alarms in memory, so
+// they're lost when the service is restarted. Only the most recently cleared
+// alarms are kept, up to the maximum, while the active ones are always kept.
+type MemoryAlarmStore struct {
+	maxCleared  int
+	mutex       sync.RWMutex
+	definitions map[string]entities.Definition
+	alarms      map[string]entities.Alarm
+}
+
+// NewMemoryAlarmStore creates a new MemoryAlarmStore instance keeping up to
+// maxCleared cleared alarms. Zero disables the limit.
+func NewMemoryAlarmStore(maxCleared int) *MemoryAlarmStore {
+	return &MemoryAlarmStore{
+		maxCleared:  maxCleared,
+		definitions: map[string]entities.Definition{},
+		alarms:      map[string]entities.Alarm{},
+	}
+}
+
+// SaveDefinition stores the definition, replacing the previous one with the
+// same ID
+func (s *MemoryAlarmStore) SaveDefinition(definition entities.Definition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.definitions[definition.ID] = definition
+	return nil
+}
+
+// GetDefinition returns the definition with the ID
+func (s *MemoryAlarmStore) GetDefinition(id string) (*entities.Definition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	definition, ok := s.definitions[id]
+	if !ok {
+		return nil, entities.ErrDefinitionNotFound
+	}
+
+	return &definition, nil
+}
+
+// ListDefinitions returns the user's definitions sorted by their name
+func (s *MemoryAlarmStore) ListDefinitions(owner string) ([]entities.Definition, error) {
+	return s.filterDefinitions(func(d entities.Definition) bool { return d.Owner == owner }), nil
+}
+
+// ListDefinitionsByThing returns the definitions evaluated on the thing's
+// data sorted by their name
+func (s *MemoryAlarmStore) ListDefinitionsByThing(thingToken string) ([]entities.Definition, error) {
+	return s.filterDefinitions(func(d entities.Definition) bool { return d.ThingToken == thingToken }), nil
+}
+
+// RemoveDefinition removes the definition with the ID. The alarms it raised
+// are kept.
+func (s *MemoryAlarmStore) RemoveDefinition(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.definitions[id]; !ok {
+		return entities.ErrDefinitionNotFound
+	}
+
+	delete(s.definitions, id)
+	return nil
+}
+
+// SaveAlarm stores the alarm, replacing the previous one with the same ID,
+// and removes the oldest cleared alarms exceeding the maximum
+func (s *MemoryAlarmStore) SaveAlarm(alarm entities.Alarm) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.alarms[alarm.ID] = alarm
+	if alarm.State == entities.StateCleared {
+		s.enforceMaxCleared()
+	}
+
+	return nil
+}
+
+// GetAlarm returns the alarm with the ID
+func (s *MemoryAlarmStore) GetAlarm(id string) (*entities.Alarm, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	alarm, ok := s.alarms[id]
+	if !ok {
+		return nil, entities.ErrAlarmNotFound
+	}
+
+	return &alarm, nil
+}
+
+// ListAlarms returns the user's alarms from the most recently raised
+func (s *MemoryAlarmStore) ListAlarms(owner string) ([]entities.Alarm, error) {
+	return s.filterAlarms(func(a entities.Alarm) bool { return a.Owner == owner }), nil
+}
+
+// ActiveAlarm returns the definition's active alarm
+func (s *MemoryAlarmStore) ActiveAlarm(definitionID string) (*entities.Alarm, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, alarm := range s.alarms {
+		if alarm.DefinitionID == definitionID && alarm.State == entities.StateActive {
+			return &alarm, nil
+		}
+	}
+
+	return nil, entities.ErrAlarmNotFound
+}
+
+func (s *MemoryAlarmStore) enforceMaxCleared() {
+	if s.maxCleared <= 0 {
+		return
+	}
+
+	cleared := []entities.Alarm{}
+	for _, alarm := range s.alarms {
+		if alarm.State == entities.StateCleared {
+			cleared = append(cleared, alarm)
+		}
+	}
+	if len(cleared) <= s.maxCleared {
+		return
+	}
+
+	sort.Slice(cleared, func(i, j int) bool {
+		return cleared[i].ClearedAt.Before(*cleared[j].ClearedAt)
+	})
+	for _, alarm := range cleared[:len(cleared)-s.maxCleared] {
+		delete(s.alarms, alarm.ID)
+	}
+}
+
+func (s *MemoryAlarmStore) filterDefinitions(match func(d entities.Definition) bool) []entities.Definition {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	definitions := []entities.Definition{}
+	for _, d := range s.definitions {
+		if match(d) {
+			definitions = append(definitions, d)
+		}
+	}
+
+	sortDefinitions(definitions)
+	return definitions
+}
+
+func (s *MemoryAlarmStore) filterAlarms(match func(a entities.Alarm) bool) []entities.Alarm {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	alarms := []entities.Alarm{}
+	for _, a := range s.alarms {
+		if match(a) {
+			alarms = append(alarms, a)
+		}
+	}
+
+	sortAlarms(alarms)
+	return alarms
+}
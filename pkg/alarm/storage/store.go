@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+)
+
+// AlarmStore represents the storage of the users' alarm definitions and the
+// alarms raised by them
+type AlarmStore interface {
+	SaveDefinition(definition entities.Definition) error
+	GetDefinition(id string) (*entities.Definition, error)
+	ListDefinitions(owner string) ([]entities.Definition, error)
+	ListDefinitionsByThing(thingToken string) ([]entities.Definition, error)
+	RemoveDefinition(id string) error
+	SaveAlarm(alarm entities.Alarm) error
+	GetAlarm(id string) (*entities.Alarm, error)
+	ListAlarms(owner string) ([]entities.Alarm, error)
+	ActiveAlarm(definitionID string) (*entities.Alarm, error)
+}
+
+// sortDefinitions sorts the definitions by their name, and ID for the same
+// name
+func sortDefinitions(definitions []entities.Definition) {
+	sort.Slice(definitions, func(i, j int) bool {
+		if definitions[i].Name != definitions[j].Name {
+			return definitions[i].Name < definitions[j].Name
+		}
+		return definitions[i].ID < definitions[j].ID
+	})
+}
+
+// sortAlarms sorts the alarms from the most recently raised, by their ID for
+// the same time
+func sortAlarms(alarms []entities.Alarm) {
+	sort.Slice(alarms, func(i, j int) bool {
+		if !alarms[i].RaisedAt.Equal(alarms[j].RaisedAt) {
+			return alarms[i].RaisedAt.After(alarms[j].RaisedAt)
+		}
+		return alarms[i].ID < alarms[j].ID
+	})
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	ruleEntities "github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+var raisedAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "alarms")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func newDefinition(id, name, owner, thingToken string) entities.Definition {
+	return entities.Definition{
+		ID:         id,
+		Name:       name,
+		ThingID:    "fbe64efa6c7f717e",
+		SensorID:   1,
+		Severity:   entities.SeverityMajor,
+		Raise:      entities.Condition{Operator: ruleEntities.OperatorGreater, Value: float64(30)},
+		Owner:      owner,
+		ThingToken: thingToken,
+	}
+}
+
+func newAlarm(id, definitionID, owner string, raisedMinutes int, cleared bool) entities.Alarm {
+	alarm := entities.Alarm{
+		ID:           id,
+		DefinitionID: definitionID,
+		Name:         "temperature",
+		ThingID:      "fbe64efa6c7f717e",
+		SensorID:     1,
+		Severity:     entities.SeverityMajor,
+		State:        entities.StateActive,
+		RaisedAt:     raisedAt.Add(time.Duration(raisedMinutes) * time.Minute),
+		RaisedValue:  30.5,
+		Owner:        owner,
+		ThingToken:   "mainflux-id",
+	}
+	if cleared {
+		clearedAt := alarm.RaisedAt.Add(time.Minute)
+		alarm.State = entities.StateCleared
+		alarm.ClearedAt = &clearedAt
+		alarm.ClearedValue = 29.5
+	}
+
+	return alarm
+}
+
+func TestAlarmStores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T, maxCleared int) AlarmStore
+	}{
+		{
+			"memory",
+			func(t *testing.T, maxCleared int) AlarmStore {
+				return NewMemoryAlarmStore(maxCleared)
+			},
+		},
+		{
+			"file",
+			func(t *testing.T, maxCleared int) AlarmStore {
+				store, err := NewFileAlarmStore(filepath.Join(tempDir(t), "data", "alarms.json"), maxCleared)
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+" definitions", func(t *testing.T) {
+			store := tc.newStore(t, 0)
+			temperature := newDefinition("1", "temperature", "user@test.com", "mainflux-id")
+			humidity := newDefinition("2", "humidity", "user@test.com", "other-mainflux-id")
+			other := newDefinition("3", "other", "other@test.com", "mainflux-id")
+			for _, d := range []entities.Definition{temperature, humidity, other} {
+				assert.NoError(t, store.SaveDefinition(d))
+			}
+
+			temperature.Severity = entities.SeverityCritical
+			assert.NoError(t, store.SaveDefinition(temperature))
+			definition, err := store.GetDefinition("1")
+			assert.NoError(t, err)
+			assert.Equal(t, &temperature, definition)
+
+			definitions, err := store.ListDefinitions("user@test.com")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Definition{humidity, temperature}, definitions)
+
+			definitions, err = store.ListDefinitionsByThing("mainflux-id")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Definition{other, temperature}, definitions)
+
+			assert.NoError(t, store.RemoveDefinition("1"))
+			_, err = store.GetDefinition("1")
+			assert.Equal(t, entities.ErrDefinitionNotFound, err)
+			assert.Equal(t, entities.ErrDefinitionNotFound, store.RemoveDefinition("1"))
+		})
+
+		t.Run(tc.name+" alarms", func(t *testing.T) {
+			store := tc.newStore(t, 2)
+			alarms := []entities.Alarm{
+				newAlarm("a", "1", "user@test.com", 0, true),
+				newAlarm("b", "1", "user@test.com", 10, true),
+				newAlarm("c", "1", "user@test.com", 20, false),
+				newAlarm("d", "2", "other@test.com", 30, false),
+			}
+			for _, a := range alarms {
+				assert.NoError(t, store.SaveAlarm(a))
+			}
+
+			alarm, err := store.GetAlarm("b")
+			assert.NoError(t, err)
+			assert.Equal(t, &alarms[1], alarm)
+			_, err = store.GetAlarm("e")
+			assert.Equal(t, entities.ErrAlarmNotFound, err)
+
+			active, err := store.ActiveAlarm("1")
+			assert.NoError(t, err)
+			assert.Equal(t, &alarms[2], active)
+			_, err = store.ActiveAlarm("3")
+			assert.Equal(t, entities.ErrAlarmNotFound, err)
+
+			// the oldest cleared alarm is removed when the third one is cleared
+			cleared := newAlarm("c", "1", "user@test.com", 20, true)
+			assert.NoError(t, store.SaveAlarm(cleared))
+			list, err := store.ListAlarms("user@test.com")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Alarm{cleared, alarms[1]}, list)
+			_, err = store.ActiveAlarm("1")
+			assert.Equal(t, entities.ErrAlarmNotFound, err)
+		})
+	}
+}
+
+func TestFileAlarmStoreRestoresAlarms(t *testing.T) {
+	path := filepath.Join(tempDir(t), "alarms.json")
+	definition := newDefinition("1", "temperature", "user@test.com", "mainflux-id")
+	definition.Clear = &entities.Condition{Operator: ruleEntities.OperatorLess, Value: float64(25)}
+	alarm := newAlarm("a", "1", "user@test.com", 0, false)
+	store, err := NewFileAlarmStore(path, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, store.SaveDefinition(definition))
+	assert.NoError(t, store.SaveAlarm(alarm))
+
+	restored, err := NewFileAlarmStore(path, 0)
+	assert.NoError(t, err)
+
+	storedDefinition, err := restored.GetDefinition("1")
+	assert.NoError(t, err)
+	assert.Equal(t, &definition, storedDefinition)
+	active, err := restored.ActiveAlarm("1")
+	assert.NoError(t, err)
+	assert.Equal(t, &alarm, active)
+}
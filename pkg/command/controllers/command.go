@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/interactors"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+)
+
+// CommandController handles the commands acknowledgements received from the
+// queue
+type CommandController interface {
+	Acknowledge(body []byte, authorization string) error
+}
+
+type commandController struct {
+	logger            logging.Logger
+	commandInteractor interactors.Interactor
+}
+
+// NewCommandController constructs the CommandController
+func NewCommandController(logger logging.Logger, commandInteractor interactors.Interactor) CommandController {
+	return &commandController{logger, commandInteractor}
+}
+
+// Acknowledge handles the command acknowledgement sent by the thing and
+// execute its use case
+func (cc *commandController) Acknowledge(body []byte, authorization string) error {
+	var msg network.DataUpdateAck
+	err := json.Unmarshal(body, &msg)
+	if err != nil {
+		return fmt.Errorf("message body parsing error: %w", err)
+	}
+
+	cc.logger.Info("command acknowledgement received")
+	reason := ""
+	if msg.Error != nil {
+		reason = *msg.Error
+	}
+
+	return cc.commandInteractor.Acknowledge(authorization, msg.ID, msg.CommandID, reason)
+}
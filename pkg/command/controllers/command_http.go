@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/command/interactors"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/gorilla/mux"
+)
+
+// CommandHTTPController handles the HTTP requests to query the commands sent
+// to the users' things
+type CommandHTTPController struct {
+	logger            logging.Logger
+	commandInteractor interactors.Interactor
+}
+
+// NewCommandHTTPController constructs the controller
+func NewCommandHTTPController(logger logging.Logger, commandInteractor interactors.Interactor) *CommandHTTPController {
+	return &CommandHTTPController{logger, commandInteractor}
+}
+
+// Get godoc
+// @Summary Gets the status of a command sent to a thing
+// @Description The data update and request commands are pending until the thing acknowledges them or publishes the data they refer to, and fail when the thing reports an error or doesn't answer before the timeout.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Command's id"
+// @Success 200 {object} entities.Command "Command with its status"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Command not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /commands/{id} [get]
+// Get handles the server request and calls the get command use case
+func (cc *CommandHTTPController) Get(w http.ResponseWriter, r *http.Request) {
+	command, err := cc.commandInteractor.Get(r.Header.Get("Authorization"), mux.Vars(r)["id"])
+	if err != nil {
+		cc.writeError(w, err)
+		return
+	}
+
+	cc.writeResponse(w, http.StatusOK, command)
+}
+
+func (cc *CommandHTTPController) writeError(w http.ResponseWriter, err error) {
+	cc.logger.Error(err)
+	cc.writeResponse(w, mapCommandErrorToStatusCode(err), &thingControllers.ErrorResponse{Message: err.Error()})
+}
+
+func (cc *CommandHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	js, err := json.Marshal(msg)
+	if err != nil {
+		cc.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		cc.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func mapCommandErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, thingInteractors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, thingEntities.ErrThingForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrCommandNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,54 @@
+package amqp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+)
+
+const (
+	exchangeCommand     = "command"
+	exchangeCommandType = "direct"
+	routingKeyCompleted = "command.completed"
+	routingKeyFailed    = "command.failed"
+)
+
+// Publisher provides methods to send the commands events to the clients
+type Publisher interface {
+	PublishCommandCompleted(command entities.Command) error
+	PublishCommandFailed(command entities.Command) error
+}
+
+// msgPublisher publishes the commands events to the broker
+type msgPublisher struct {
+	logger logging.Logger
+	amqp   network.AmqpSender
+}
+
+// NewMsgPublisher creates a new msgPublisher instance
+func NewMsgPublisher(logger logging.Logger, amqp network.AmqpSender) Publisher {
+	return &msgPublisher{logger, amqp}
+}
+
+// PublishCommandCompleted sends the command completed event
+func (mp *msgPublisher) PublishCommandCompleted(command entities.Command) error {
+	return mp.publish(routingKeyCompleted, command)
+}
+
+// PublishCommandFailed sends the command failed event
+func (mp *msgPublisher) PublishCommandFailed(command entities.Command) error {
+	return mp.publish(routingKeyFailed, command)
+}
+
+func (mp *msgPublisher) publish(routingKey string, command entities.Command) error {
+	msg, err := json.Marshal(command)
+	if err != nil {
+		return fmt.Errorf("message parsing error: %w", err)
+	}
+
+	return thingAMQP.MapPublishError(mp.amqp.PublishPersistentMessage(exchangeCommand, exchangeCommandType, routingKey, msg, nil))
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// Types of the commands sent to the things
+const (
+	TypeUpdateData  = "updateData"
+	TypeRequestData = "requestData"
+)
+
+// Statuses of the command's delivery
+const (
+	StatusQueued    = "queued"
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Command represents a data update or request sent to a thing, which is
+// pending until the thing acknowledges it or sends the data it refers to.
+// The command fails when the thing reports an error or doesn't answer before
+// the timeout. The commands sent while the thing is offline are queued until
+// it reconnects.
+type Command struct {
+	ID         string               `json:"id"`
+	Type       string               `json:"type"`
+	ThingID    string               `json:"thingId"`
+	Data       []thingEntities.Data `json:"data,omitempty"`
+	SensorIDs  []int                `json:"sensorIds,omitempty"`
+	Status     string               `json:"status"`
+	Error      string               `json:"error,omitempty"`
+	CreatedAt  time.Time            `json:"createdAt"`
+	SentAt     *time.Time           `json:"sentAt,omitempty"`
+	FinishedAt *time.Time           `json:"finishedAt,omitempty"`
+
+	// ThingToken is the thing's ID on the things service, which is unique
+	// among all the users
+	ThingToken string `json:"-"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "errors"
+
+var (
+	// ErrCommandNotFound is returned when the command isn't found among the
+	// commands sent to the user's things
+	ErrCommandNotFound = errors.New("command not found")
+
+	// ErrCommandExists is returned when a command is sent with the ID of a
+	// command already sent
+	ErrCommandExists = errors.New("command already exists")
+)
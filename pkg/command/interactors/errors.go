@@ -0,0 +1,21 @@
+package interactors
+
+import "errors"
+
+var (
+	// ErrCommandIDNotProvided is returned when the command acknowledged by the
+	// thing isn't informed
+	ErrCommandIDNotProvided = errors.New("command's id not provided")
+
+	// ErrCommandExpired is the reason of the commands failed for not being
+	// completed before the timeout
+	ErrCommandExpired = errors.New("command not completed before the timeout")
+
+	// ErrCommandDropped is the reason of the queued commands failed for the
+	// thing not reconnecting before the maximum queue age
+	ErrCommandDropped = errors.New("thing not reconnected before the command's maximum queue age")
+
+	// ErrCommandSuperseded is the reason of the queued data updates failed
+	// for having every value superseded by a later data update
+	ErrCommandSuperseded = errors.New("command superseded by a later data update")
+)
@@ -0,0 +1,142 @@
+package interactors
+
+import (
+	"sync"
+	"time"
+
+	commandAMQP "github.com/CESARBR/knot-babeltower/pkg/command/delivery/amqp"
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/command/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	thingHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
+)
+
+// Interactor is an interface that defines the command's use cases operations
+type Interactor interface {
+	Get(authorization, id string) (*entities.Command, error)
+	Acknowledge(authorization, thingID, commandID, reason string) error
+}
+
+// CommandInteractor represents the command interactor capabilities, it's
+// composed by the necessary dependencies. It tracks the commands sent to the
+// things and is notified about the data published by them to complete the
+// commands. The commands to the offline things are queued until they
+// reconnect.
+type CommandInteractor struct {
+	logger      logging.Logger
+	thingProxy  thingHTTP.ThingProxy
+	publisher   commandAMQP.Publisher
+	store       storage.CommandStore
+	timeout     time.Duration
+	maxQueueAge time.Duration
+	now         func() time.Time
+
+	// mutex serializes the commands' status changes, so a command isn't
+	// completed while failing
+	mutex   sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+// NewCommandInteractor creates a new CommandInteractor instance. The commands
+// fail when they aren't completed before the timeout after being sent, and
+// the queued commands when the thing doesn't reconnect before the maximum
+// queue age, unless they're zero.
+func NewCommandInteractor(
+	logger logging.Logger,
+	thingProxy thingHTTP.ThingProxy,
+	publisher commandAMQP.Publisher,
+	store storage.CommandStore,
+	timeout time.Duration,
+	maxQueueAge time.Duration,
+) *CommandInteractor {
+	return &CommandInteractor{
+		logger:      logger,
+		thingProxy:  thingProxy,
+		publisher:   publisher,
+		store:       store,
+		timeout:     timeout,
+		maxQueueAge: maxQueueAge,
+		now:         time.Now,
+		timers:      map[string]*time.Timer{},
+	}
+}
+
+// Start restarts the timeouts of the pending and queued commands restored by
+// the store, failing the ones which have already expired
+func (i *CommandInteractor) Start() error {
+	pending, err := i.store.ListPending()
+	if err != nil {
+		return err
+	}
+
+	queued, err := i.store.ListQueued()
+	if err != nil {
+		return err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, command := range append(pending, queued...) {
+		i.schedule(command)
+	}
+
+	return nil
+}
+
+// Stop stops the timeouts, so the pending commands are kept pending
+func (i *CommandInteractor) Stop() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.stopped = true
+	for id, timer := range i.timers {
+		timer.Stop()
+		delete(i.timers, id)
+	}
+}
+
+// schedule fails the command when its timeout expires: the maximum queue age
+// since it was created for the queued commands and the timeout since it was
+// sent for the pending ones. The previous timeout is stopped. It must be
+// called holding the mutex.
+func (i *CommandInteractor) schedule(command entities.Command) {
+	id := command.ID
+	if timer, ok := i.timers[id]; ok {
+		timer.Stop()
+		delete(i.timers, id)
+	}
+
+	if i.stopped {
+		return
+	}
+
+	if command.Status == entities.StatusQueued {
+		if i.maxQueueAge <= 0 {
+			return
+		}
+
+		remaining := command.CreatedAt.Add(i.maxQueueAge).Sub(i.now())
+		i.timers[id] = time.AfterFunc(remaining, func() {
+			i.expire(id, entities.StatusQueued, ErrCommandDropped)
+		})
+		return
+	}
+
+	if i.timeout <= 0 {
+		return
+	}
+
+	// the commands stored before the time they're sent was recorded were
+	// sent when created
+	sentAt := command.CreatedAt
+	if command.SentAt != nil {
+		sentAt = *command.SentAt
+	}
+
+	remaining := sentAt.Add(i.timeout).Sub(i.now())
+	i.timers[id] = time.AfterFunc(remaining, func() {
+		i.expire(id, entities.StatusPending, ErrCommandExpired)
+	})
+}
@@ -0,0 +1,85 @@
+package interactors
+
+import (
+	"errors"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// Get returns the command sent to one of the user's things
+func (i *CommandInteractor) Get(authorization, id string) (*entities.Command, error) {
+	if authorization == "" {
+		return nil, thingInteractors.ErrAuthNotProvided
+	}
+
+	command, err := i.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.verifyThing(authorization, command.ThingID, *command)
+	if err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// Acknowledge finishes the command as reported by the thing, which fails
+// when a reason is informed. The commands already finished, such as the
+// expired ones, are kept as they are.
+func (i *CommandInteractor) Acknowledge(authorization, thingID, commandID, reason string) error {
+	if authorization == "" {
+		return thingInteractors.ErrAuthNotProvided
+	}
+	if thingID == "" {
+		return thingInteractors.ErrIDNotProvided
+	}
+	if commandID == "" {
+		return ErrCommandIDNotProvided
+	}
+
+	command, err := i.store.Get(commandID)
+	if err != nil {
+		return err
+	}
+
+	err = i.verifyThing(authorization, thingID, *command)
+	if err != nil {
+		return err
+	}
+
+	if command.Status != entities.StatusPending {
+		i.logger.Infof("command %s acknowledged after being %s", commandID, command.Status)
+		return nil
+	}
+
+	if reason == "" {
+		i.finish(commandID, entities.StatusPending, entities.StatusCompleted, "")
+	} else {
+		i.finish(commandID, entities.StatusPending, entities.StatusFailed, reason)
+	}
+
+	return nil
+}
+
+// verifyThing verifies the command was sent to the thing seen by the token.
+// Since the things' IDs are only unique for each user, the command isn't
+// found when the thing has the same ID but is another user's thing.
+func (i *CommandInteractor) verifyThing(authorization, thingID string, command entities.Command) error {
+	thing, err := i.thingProxy.Get(authorization, thingID)
+	if errors.Is(err, thingEntities.ErrThingNotFound) {
+		return entities.ErrCommandNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if thing.Token != command.ThingToken || thingID != command.ThingID {
+		return entities.ErrCommandNotFound
+	}
+
+	return nil
+}
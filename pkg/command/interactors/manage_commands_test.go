@@ -0,0 +1,156 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/command/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var sentAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+var fanThing = &thingEntities.Thing{ID: "fan-thing", Token: "fan-mainflux-id"}
+
+func newTestInteractor(timeout time.Duration) (*CommandInteractor, *mocks.FakeCommandPublisher) {
+	thingProxy := &mocks.FakeThingProxy{}
+	thingProxy.On("Get", "user-token", "fan-thing").Return(fanThing, nil)
+	thingProxy.On("Get", "other-user-token", "fan-thing").Return(&thingEntities.Thing{ID: "fan-thing", Token: "other-mainflux-id"}, nil)
+	thingProxy.On("Get", "user-token", "unknown-thing").Return((*thingEntities.Thing)(nil), thingEntities.ErrThingNotFound)
+	publisher := &mocks.FakeCommandPublisher{}
+	publisher.On("PublishCommandCompleted", mock.Anything).Return(nil)
+	publisher.On("PublishCommandFailed", mock.Anything).Return(nil)
+
+	interactor := NewCommandInteractor(&mocks.FakeLogger{}, thingProxy, publisher, storage.NewMemoryCommandStore(0), timeout, 0)
+	interactor.now = func() time.Time { return sentAt }
+	return interactor, publisher
+}
+
+func fanCommand() *entities.Command {
+	return &entities.Command{
+		Type:       entities.TypeUpdateData,
+		ThingID:    "fan-thing",
+		Data:       []thingEntities.Data{{SensorID: 1, Value: true}},
+		ThingToken: "fan-mainflux-id",
+	}
+}
+
+func TestGetCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authorization string
+		id            string
+		expectedErr   error
+	}{
+		{"command returned to the thing's owner", "user-token", "fan-command", nil},
+		{"authorization token not provided", "", "fan-command", thingInteractors.ErrAuthNotProvided},
+		{"command not found", "user-token", "unknown-command", entities.ErrCommandNotFound},
+		{"command sent to another user's thing", "other-user-token", "fan-command", entities.ErrCommandNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interactor, _ := newTestInteractor(0)
+			command := fanCommand()
+			command.ID = "fan-command"
+			assert.NoError(t, interactor.Track(command))
+
+			got, err := interactor.Get(tc.authorization, tc.id)
+
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			if tc.expectedErr == nil {
+				assert.Equal(t, command, got)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeCommand(t *testing.T) {
+	testCases := []struct {
+		name            string
+		authorization   string
+		thingID         string
+		commandID       string
+		reason          string
+		expectedErr     error
+		expectedStatus  string
+		expectedPublish string
+	}{
+		{
+			"command completed by the thing",
+			"user-token", "fan-thing", "fan-command", "",
+			nil, entities.StatusCompleted, "PublishCommandCompleted",
+		},
+		{
+			"command failed by the thing",
+			"user-token", "fan-thing", "fan-command", "sensor is read-only",
+			nil, entities.StatusFailed, "PublishCommandFailed",
+		},
+		{
+			"command's id not provided",
+			"user-token", "fan-thing", "", "",
+			ErrCommandIDNotProvided, entities.StatusPending, "",
+		},
+		{
+			"thing's id not provided",
+			"user-token", "", "fan-command", "",
+			thingInteractors.ErrIDNotProvided, entities.StatusPending, "",
+		},
+		{
+			"command acknowledged by another thing",
+			"user-token", "unknown-thing", "fan-command", "",
+			entities.ErrCommandNotFound, entities.StatusPending, "",
+		},
+		{
+			"command acknowledged by another user's thing",
+			"other-user-token", "fan-thing", "fan-command", "",
+			entities.ErrCommandNotFound, entities.StatusPending, "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interactor, publisher := newTestInteractor(0)
+			command := fanCommand()
+			command.ID = "fan-command"
+			assert.NoError(t, interactor.Track(command))
+
+			err := interactor.Acknowledge(tc.authorization, tc.thingID, tc.commandID, tc.reason)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+
+			stored, err := interactor.Get("user-token", "fan-command")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, stored.Status)
+			assert.Equal(t, tc.reason, stored.Error)
+			if tc.expectedPublish == "" {
+				assert.Empty(t, publisher.Calls)
+			} else {
+				publisher.AssertCalled(t, tc.expectedPublish, *stored)
+				assert.Equal(t, sentAt, *stored.FinishedAt)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeFinishedCommand(t *testing.T) {
+	interactor, publisher := newTestInteractor(0)
+	command := fanCommand()
+	assert.NoError(t, interactor.Track(command))
+	interactor.Fail(command.ID, errors.New("broker unavailable"))
+
+	err := interactor.Acknowledge("user-token", "fan-thing", command.ID, "")
+
+	assert.NoError(t, err)
+	stored, err := interactor.Get("user-token", command.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.StatusFailed, stored.Status)
+	assert.Equal(t, "broker unavailable", stored.Error)
+	publisher.AssertNumberOfCalls(t, "PublishCommandFailed", 1)
+	publisher.AssertNotCalled(t, "PublishCommandCompleted", mock.Anything)
+}
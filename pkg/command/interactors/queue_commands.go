@@ -0,0 +1,103 @@
+package interactors
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// Queue stores the command as queued until the thing reconnects, failing it
+// when the maximum queue age is reached. The values updated by a data update
+// supersede the ones of the thing's previously queued data updates, which
+// fail when they're left without values. The command's ID is generated when
+// it isn't informed by the client.
+func (i *CommandInteractor) Queue(command *entities.Command) error {
+	i.mutex.Lock()
+	err := i.add(command, entities.StatusQueued)
+	var superseded []*entities.Command
+	if err == nil && command.Type == entities.TypeUpdateData {
+		superseded, err = i.coalesce(*command)
+	}
+	i.mutex.Unlock()
+
+	for _, s := range superseded {
+		i.report(s)
+	}
+
+	return err
+}
+
+// Dequeue changes the thing's queued commands to pending, as sent at the
+// current time, and returns them from the oldest to be sent to the thing
+func (i *CommandInteractor) Dequeue(thingToken string) ([]entities.Command, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	queued, err := i.store.ListQueued()
+	if err != nil {
+		return nil, err
+	}
+
+	dequeued := []entities.Command{}
+	for _, command := range queued {
+		if command.ThingToken != thingToken {
+			continue
+		}
+
+		now := i.now()
+		command.Status = entities.StatusPending
+		command.SentAt = &now
+		err = i.store.Save(command)
+		if err != nil {
+			return dequeued, err
+		}
+
+		i.schedule(command)
+		dequeued = append(dequeued, command)
+	}
+
+	return dequeued, nil
+}
+
+// coalesce removes the values updated by the command from the thing's
+// previously queued data updates, failing the ones left without values, which
+// are returned to be reported. It must be called holding the mutex.
+func (i *CommandInteractor) coalesce(command entities.Command) ([]*entities.Command, error) {
+	queued, err := i.store.ListQueued()
+	if err != nil {
+		return nil, err
+	}
+
+	superseded := []*entities.Command{}
+	for _, q := range queued {
+		if q.ID == command.ID || q.ThingToken != command.ThingToken || q.Type != entities.TypeUpdateData {
+			continue
+		}
+
+		data := []thingEntities.Data{}
+		for _, d := range q.Data {
+			if !containsSensor(command.Data, d.SensorID) {
+				data = append(data, d)
+			}
+		}
+		if len(data) == len(q.Data) {
+			continue
+		}
+
+		if len(data) > 0 {
+			q.Data = data
+			err = i.store.Save(q)
+			if err != nil {
+				return superseded, err
+			}
+			continue
+		}
+
+		failed, err := i.updateStatus(q.ID, entities.StatusQueued, entities.StatusFailed, ErrCommandSuperseded.Error())
+		if err != nil {
+			return superseded, err
+		}
+		superseded = append(superseded, failed)
+	}
+
+	return superseded, nil
+}
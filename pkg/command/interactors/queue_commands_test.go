@@ -0,0 +1,161 @@
+package interactors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ticking makes the interactor's clock advance a second on every reading, so
+// the commands are queued in order
+func ticking(interactor *CommandInteractor) {
+	now := sentAt
+	interactor.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func queueCommand(t *testing.T, interactor *CommandInteractor, command *entities.Command) *entities.Command {
+	assert.NoError(t, interactor.Queue(command))
+	return command
+}
+
+func TestQueueCommand(t *testing.T) {
+	interactor, publisher := newTestInteractor(20 * time.Millisecond)
+	defer interactor.Stop()
+
+	command := queueCommand(t, interactor, fanCommand())
+	assert.Len(t, command.ID, 32)
+	assert.Equal(t, entities.StatusQueued, command.Status)
+	assert.Nil(t, command.SentAt)
+
+	// the timeout only starts when the command is sent
+	time.Sleep(50 * time.Millisecond)
+	stored, err := interactor.Get("user-token", command.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.StatusQueued, stored.Status)
+	assert.Empty(t, publisher.Calls)
+}
+
+func TestCoalesceQueuedUpdates(t *testing.T) {
+	interactor, publisher := newTestInteractor(0)
+	ticking(interactor)
+
+	first := fanCommand()
+	first.Data = []thingEntities.Data{{SensorID: 1, Value: true}, {SensorID: 2, Value: float64(20)}}
+	queueCommand(t, interactor, first)
+	request := fanCommand()
+	request.Type = entities.TypeRequestData
+	request.Data = nil
+	request.SensorIDs = []int{1, 2}
+	queueCommand(t, interactor, request)
+	otherThing := fanCommand()
+	otherThing.ThingToken = "other-mainflux-id"
+	queueCommand(t, interactor, otherThing)
+
+	second := fanCommand()
+	second.Data = []thingEntities.Data{{SensorID: 1, Value: false}}
+	queueCommand(t, interactor, second)
+
+	stored, err := interactor.store.Get(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.StatusQueued, stored.Status)
+	assert.Equal(t, []thingEntities.Data{{SensorID: 2, Value: float64(20)}}, stored.Data)
+	assert.Empty(t, publisher.Calls)
+
+	third := fanCommand()
+	third.Data = []thingEntities.Data{{SensorID: 2, Value: float64(18)}}
+	queueCommand(t, interactor, third)
+
+	stored, err = interactor.store.Get(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.StatusFailed, stored.Status)
+	assert.Equal(t, ErrCommandSuperseded.Error(), stored.Error)
+	publisher.AssertCalled(t, "PublishCommandFailed", *stored)
+
+	queued, err := interactor.store.ListQueued()
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, q := range queued {
+		ids = append(ids, q.ID)
+	}
+	assert.Equal(t, []string{request.ID, otherThing.ID, second.ID, third.ID}, ids)
+}
+
+func TestDequeueCommands(t *testing.T) {
+	interactor, _ := newTestInteractor(0)
+	ticking(interactor)
+
+	first := queueCommand(t, interactor, fanCommand())
+	otherThing := fanCommand()
+	otherThing.ThingToken = "other-mainflux-id"
+	queueCommand(t, interactor, otherThing)
+	second := fanCommand()
+	second.Type = entities.TypeRequestData
+	second.Data = nil
+	second.SensorIDs = []int{1}
+	queueCommand(t, interactor, second)
+
+	dequeued, err := interactor.Dequeue("fan-mainflux-id")
+	assert.NoError(t, err)
+	if !assert.Len(t, dequeued, 2) {
+		return
+	}
+	assert.Equal(t, first.ID, dequeued[0].ID)
+	assert.Equal(t, second.ID, dequeued[1].ID)
+	for _, command := range dequeued {
+		assert.Equal(t, entities.StatusPending, command.Status)
+		assert.True(t, command.SentAt.After(command.CreatedAt))
+	}
+
+	pending, err := interactor.store.ListPending()
+	assert.NoError(t, err)
+	assert.Equal(t, dequeued, pending)
+
+	dequeued, err = interactor.Dequeue("fan-mainflux-id")
+	assert.NoError(t, err)
+	assert.Empty(t, dequeued)
+}
+
+func TestDropQueuedCommand(t *testing.T) {
+	interactor, publisher := newTestInteractor(0)
+	interactor.maxQueueAge = 20 * time.Millisecond
+	failed := make(chan entities.Command, 1)
+	publisher.ExpectedCalls = nil
+	publisher.On("PublishCommandFailed", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		failed <- args.Get(0).(entities.Command)
+	})
+	defer interactor.Stop()
+
+	command := queueCommand(t, interactor, fanCommand())
+
+	select {
+	case dropped := <-failed:
+		assert.Equal(t, command.ID, dropped.ID)
+		assert.Equal(t, entities.StatusFailed, dropped.Status)
+		assert.Equal(t, ErrCommandDropped.Error(), dropped.Error)
+	case <-time.After(time.Second):
+		t.Error("queued command not dropped")
+	}
+}
+
+func TestDequeuedCommandNotDropped(t *testing.T) {
+	interactor, publisher := newTestInteractor(0)
+	interactor.maxQueueAge = 20 * time.Millisecond
+	defer interactor.Stop()
+
+	command := queueCommand(t, interactor, fanCommand())
+	_, err := interactor.Dequeue("fan-mainflux-id")
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	stored, err := interactor.store.Get(command.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.StatusPending, stored.Status)
+	assert.Empty(t, publisher.Calls)
+}
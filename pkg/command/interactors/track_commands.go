@@ -0,0 +1,200 @@
+package interactors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/internal/ids"
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// Track stores the command as pending and starts its timeout. The command's
+// ID is generated when it isn't informed by the client.
+func (i *CommandInteractor) Track(command *entities.Command) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.add(command, entities.StatusPending)
+}
+
+// Fail finishes the pending command as failed, e.g. because it couldn't be
+// sent to the thing
+func (i *CommandInteractor) Fail(commandID string, reason error) {
+	i.finish(commandID, entities.StatusPending, entities.StatusFailed, reason.Error())
+}
+
+// OnDataPublished completes the thing's pending commands whose data was
+// published: the data updates when every sensor has the updated value and
+// the data requests when every requested sensor is sent
+func (i *CommandInteractor) OnDataPublished(thing *thingEntities.Thing, data []thingEntities.Data) {
+	pending, err := i.store.ListPendingByThing(thing.Token)
+	if err != nil {
+		i.logger.Errorf("error getting the pending commands: %s", err)
+		return
+	}
+
+	for _, command := range pending {
+		if completedBy(command, data) {
+			i.finish(command.ID, entities.StatusPending, entities.StatusCompleted, "")
+		}
+	}
+}
+
+// add stores the new command with the status and starts its timeout. It
+// must be called holding the mutex.
+func (i *CommandInteractor) add(command *entities.Command, status string) error {
+	if command.ID == "" {
+		id, err := ids.New()
+		if err != nil {
+			return fmt.Errorf("error generating command's id: %w", err)
+		}
+		command.ID = id
+	} else {
+		_, err := i.store.Get(command.ID)
+		if err == nil {
+			return entities.ErrCommandExists
+		}
+		if !errors.Is(err, entities.ErrCommandNotFound) {
+			return err
+		}
+	}
+
+	now := i.now()
+	command.Status = status
+	command.Error = ""
+	command.CreatedAt = now
+	command.SentAt = nil
+	command.FinishedAt = nil
+	if status == entities.StatusPending {
+		command.SentAt = &now
+	}
+
+	err := i.store.Save(*command)
+	if err != nil {
+		return err
+	}
+
+	i.schedule(*command)
+	return nil
+}
+
+// expire fails the command when it still has the status its timeout was
+// started with
+func (i *CommandInteractor) expire(id, from string, reason error) {
+	i.mutex.Lock()
+	stopped := i.stopped
+	i.mutex.Unlock()
+
+	if !stopped {
+		i.finish(id, from, entities.StatusFailed, reason.Error())
+	}
+}
+
+// finish changes the command's status, when it still has the from status,
+// and sends the corresponding event. The commands already finished are kept
+// as they are.
+func (i *CommandInteractor) finish(id, from, status, reason string) {
+	i.mutex.Lock()
+	command, err := i.updateStatus(id, from, status, reason)
+	i.mutex.Unlock()
+
+	if err != nil {
+		i.logger.Errorf("error finishing command %s: %s", id, err)
+		return
+	}
+
+	i.report(command)
+}
+
+// report sends the event of the finished command, if any
+func (i *CommandInteractor) report(command *entities.Command) {
+	if command == nil {
+		return
+	}
+
+	var err error
+	if command.Status == entities.StatusCompleted {
+		i.logger.Infof("command %s completed", command.ID)
+		err = i.publisher.PublishCommandCompleted(*command)
+	} else {
+		i.logger.Infof("command %s failed: %s", command.ID, command.Error)
+		err = i.publisher.PublishCommandFailed(*command)
+	}
+
+	if errors.Is(err, thingAMQP.ErrUndeliverable) {
+		i.logger.Warn(err)
+	} else if err != nil {
+		i.logger.Errorf("error sending command %s event: %s", command.ID, err)
+	}
+}
+
+// updateStatus finishes the command with the from status, returning nil when
+// it has another status. It must be called holding the mutex.
+func (i *CommandInteractor) updateStatus(id, from, status, reason string) (*entities.Command, error) {
+	command, err := i.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if command.Status != from {
+		return nil, nil
+	}
+
+	finishedAt := i.now()
+	command.Status = status
+	command.Error = reason
+	command.FinishedAt = &finishedAt
+	err = i.store.Save(*command)
+	if err != nil {
+		return nil, err
+	}
+
+	if timer, ok := i.timers[id]; ok {
+		timer.Stop()
+		delete(i.timers, id)
+	}
+
+	return command, nil
+}
+
+func completedBy(command entities.Command, data []thingEntities.Data) bool {
+	switch command.Type {
+	case entities.TypeUpdateData:
+		for _, updated := range command.Data {
+			if !containsValue(data, updated) {
+				return false
+			}
+		}
+		return true
+	case entities.TypeRequestData:
+		for _, id := range command.SensorIDs {
+			if !containsSensor(data, id) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func containsValue(data []thingEntities.Data, value thingEntities.Data) bool {
+	for _, d := range data {
+		if d.SensorID == value.SensorID && thingEntities.EqualValues(d.Value, value.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsSensor(data []thingEntities.Data, sensorID int) bool {
+	for _, d := range data {
+		if d.SensorID == sensorID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTrackCommand(t *testing.T) {
+	interactor, _ := newTestInteractor(0)
+
+	generated := fanCommand()
+	assert.NoError(t, interactor.Track(generated))
+	assert.Len(t, generated.ID, 32)
+	assert.Equal(t, entities.StatusPending, generated.Status)
+	assert.Equal(t, sentAt, generated.CreatedAt)
+
+	informed := fanCommand()
+	informed.ID = "fan-command"
+	assert.NoError(t, interactor.Track(informed))
+	assert.Equal(t, "fan-command", informed.ID)
+
+	duplicated := fanCommand()
+	duplicated.ID = "fan-command"
+	err := interactor.Track(duplicated)
+	assert.True(t, errors.Is(err, entities.ErrCommandExists))
+}
+
+func TestCompleteCommandsOnDataPublished(t *testing.T) {
+	testCases := []struct {
+		name           string
+		command        entities.Command
+		thing          *thingEntities.Thing
+		published      []thingEntities.Data
+		expectedStatus string
+	}{
+		{
+			"update completed when every sensor has the updated value",
+			entities.Command{Type: entities.TypeUpdateData, Data: []thingEntities.Data{{SensorID: 1, Value: true}, {SensorID: 2, Value: float64(20)}}},
+			fanThing,
+			[]thingEntities.Data{{SensorID: 2, Value: float64(20)}, {SensorID: 1, Value: true}},
+			entities.StatusCompleted,
+		},
+		{
+			"update kept pending when a sensor has another value",
+			entities.Command{Type: entities.TypeUpdateData, Data: []thingEntities.Data{{SensorID: 1, Value: true}, {SensorID: 2, Value: float64(20)}}},
+			fanThing,
+			[]thingEntities.Data{{SensorID: 1, Value: true}, {SensorID: 2, Value: float64(18)}},
+			entities.StatusPending,
+		},
+		{
+			"update kept pending when a sensor sends a value which can't be compared",
+			entities.Command{Type: entities.TypeUpdateData, Data: []thingEntities.Data{{SensorID: 1, Value: []interface{}{true}}}},
+			fanThing,
+			[]thingEntities.Data{{SensorID: 1, Value: []interface{}{true}}},
+			entities.StatusPending,
+		},
+		{
+			"request completed when every sensor is sent",
+			entities.Command{Type: entities.TypeRequestData, SensorIDs: []int{1, 2}},
+			fanThing,
+			[]thingEntities.Data{{SensorID: 1, Value: false}, {SensorID: 2, Value: float64(18)}},
+			entities.StatusCompleted,
+		},
+		{
+			"request kept pending when a sensor isn't sent",
+			entities.Command{Type: entities.TypeRequestData, SensorIDs: []int{1, 2}},
+			fanThing,
+			[]thingEntities.Data{{SensorID: 1, Value: false}},
+			entities.StatusPending,
+		},
+		{
+			"command kept pending when another user's thing publishes",
+			entities.Command{Type: entities.TypeRequestData, SensorIDs: []int{1}},
+			&thingEntities.Thing{ID: "fan-thing", Token: "other-mainflux-id"},
+			[]thingEntities.Data{{SensorID: 1, Value: false}},
+			entities.StatusPending,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interactor, publisher := newTestInteractor(0)
+			command := tc.command
+			command.ThingID = "fan-thing"
+			command.ThingToken = "fan-mainflux-id"
+			assert.NoError(t, interactor.Track(&command))
+
+			interactor.OnDataPublished(tc.thing, tc.published)
+
+			stored, err := interactor.Get("user-token", command.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, stored.Status)
+			if tc.expectedStatus == entities.StatusCompleted {
+				publisher.AssertCalled(t, "PublishCommandCompleted", *stored)
+			} else {
+				assert.Empty(t, publisher.Calls)
+			}
+		})
+	}
+}
+
+func TestExpireCommand(t *testing.T) {
+	interactor, publisher := newTestInteractor(20 * time.Millisecond)
+	failed := make(chan entities.Command, 1)
+	publisher.ExpectedCalls = nil
+	publisher.On("PublishCommandFailed", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		failed <- args.Get(0).(entities.Command)
+	})
+	defer interactor.Stop()
+
+	command := fanCommand()
+	assert.NoError(t, interactor.Track(command))
+
+	select {
+	case expired := <-failed:
+		assert.Equal(t, command.ID, expired.ID)
+		assert.Equal(t, entities.StatusFailed, expired.Status)
+		assert.Equal(t, ErrCommandExpired.Error(), expired.Error)
+	case <-time.After(time.Second):
+		t.Error("command not expired")
+	}
+}
+
+func TestRestorePendingCommands(t *testing.T) {
+	interactor, publisher := newTestInteractor(0)
+	command := fanCommand()
+	assert.NoError(t, interactor.Track(command))
+
+	restored := NewCommandInteractor(interactor.logger, interactor.thingProxy, publisher, interactor.store, time.Minute, 0)
+	restored.now = func() time.Time { return sentAt.Add(time.Hour) }
+	failed := make(chan entities.Command, 1)
+	publisher.ExpectedCalls = nil
+	publisher.On("PublishCommandFailed", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		failed <- args.Get(0).(entities.Command)
+	})
+	defer restored.Stop()
+
+	assert.NoError(t, restored.Start())
+
+	select {
+	case expired := <-failed:
+		assert.Equal(t, command.ID, expired.ID)
+	case <-time.After(time.Second):
+		t.Error("restored command not expired")
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+)
+
+// FileCommandStore keeps the commands in memory and writes them to a JSON
+// file, so the pending and queued commands are still tracked when the service
+// restarts. The changes are written periodically, after the flush interval,
+// and when the store is closed, since the commands change on every data
+// published completing them.
+type FileCommandStore struct {
+	file   *jsonfile.File
+	memory *MemoryCommandStore
+}
+
+// record represents a command in the file, along with the fields which
+// aren't exposed to the users
+type record struct {
+	ThingToken string           `json:"thingToken"`
+	Command    entities.Command `json:"command"`
+}
+
+// NewFileCommandStore creates a new FileCommandStore instance keeping up to
+// maxFinished completed or failed commands and loading the ones previously
+// written to the file, which is created when it doesn't exist yet. When the
+// flush interval is zero, the file is written on every change.
+func NewFileCommandStore(path string, maxFinished int, flushInterval time.Duration) (*FileCommandStore, error) {
+	s := &FileCommandStore{memory: NewMemoryCommandStore(maxFinished)}
+	s.file = jsonfile.New(path, "commands", flushInterval, s.records)
+
+	var records []record
+	err := s.file.Load(&records)
+	if err != nil {
+		s.file.Close()
+		return nil, err
+	}
+
+	for _, r := range records {
+		r.Command.ThingToken = r.ThingToken
+		err = s.memory.Save(r.Command)
+		if err != nil {
+			s.file.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Save stores the command, replacing the previous one with the same ID
+func (s *FileCommandStore) Save(command entities.Command) error {
+	return s.file.Update(func() error { return s.memory.Save(command) })
+}
+
+// Get returns the command with the ID
+func (s *FileCommandStore) Get(id string) (*entities.Command, error) {
+	return s.memory.Get(id)
+}
+
+// ListPending returns the pending commands from the oldest
+func (s *FileCommandStore) ListPending() ([]entities.Command, error) {
+	return s.memory.ListPending()
+}
+
+// ListPendingByThing returns the thing's pending commands from the oldest
+func (s *FileCommandStore) ListPendingByThing(thingToken string) ([]entities.Command, error) {
+	return s.memory.ListPendingByThing(thingToken)
+}
+
+// ListQueued returns the queued commands from the oldest
+func (s *FileCommandStore) ListQueued() ([]entities.Command, error) {
+	return s.memory.ListQueued()
+}
+
+// Close writes the commands not written yet
+func (s *FileCommandStore) Close() error {
+	return s.file.Close()
+}
+
+// records returns the file's content. It's called by the file while no
+// change is being made.
+func (s *FileCommandStore) records() interface{} {
+	commands := s.memory.filter(func(entities.Command) bool { return true })
+	records := make([]record, 0, len(commands))
+	for _, c := range commands {
+		records = append(records, record{c.ThingToken, c})
+	}
+
+	return records
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+)
+
+// MemoryCommandStore keeps the commands in memory, so they're lost when the
+// service is restarted. Only the most recently finished commands are kept,
+// up to the maximum, while the pending and queued ones are always kept.
+type MemoryCommandStore struct {
+	maxFinished int
+	mutex       sync.RWMutex
+	commands    map[string]entities.Command
+
+	// pending indexes the pending commands' IDs by the thing's token, since
+	// they're looked up on every data published by the thing
+	pending map[string]map[string]bool
+}
+
+// NewMemoryCommandStore creates a new MemoryCommandStore instance keeping up
+// to maxFinished completed or failed commands. Zero disables the limit.
+func NewMemoryCommandStore(maxFinished int) *MemoryCommandStore {
+	return &MemoryCommandStore{
+		maxFinished: maxFinished,
+		commands:    map[string]entities.Command{},
+		pending:     map[string]map[string]bool{},
+	}
+}
+
+// Save stores the command, replacing the previous one with the same ID, and
+// removes the oldest finished commands exceeding the maximum
+func (s *MemoryCommandStore) Save(command entities.Command) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if previous, ok := s.commands[command.ID]; ok {
+		s.unindex(previous)
+	}
+	s.commands[command.ID] = command
+	s.index(command)
+	if isFinished(command) {
+		s.enforceMaxFinished()
+	}
+
+	return nil
+}
+
+// Get returns the command with the ID
+func (s *MemoryCommandStore) Get(id string) (*entities.Command, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	command, ok := s.commands[id]
+	if !ok {
+		return nil, entities.ErrCommandNotFound
+	}
+
+	return &command, nil
+}
+
+// ListPending returns the pending commands from the oldest
+func (s *MemoryCommandStore) ListPending() ([]entities.Command, error) {
+	return s.filter(func(c entities.Command) bool { return c.Status == entities.StatusPending }), nil
+}
+
+// ListPendingByThing returns the thing's pending commands from the oldest
+func (s *MemoryCommandStore) ListPendingByThing(thingToken string) ([]entities.Command, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	commands := []entities.Command{}
+	for id := range s.pending[thingToken] {
+		commands = append(commands, s.commands[id])
+	}
+
+	sortCommands(commands)
+	return commands, nil
+}
+
+// ListQueued returns the queued commands from the oldest
+func (s *MemoryCommandStore) ListQueued() ([]entities.Command, error) {
+	return s.filter(func(c entities.Command) bool { return c.Status == entities.StatusQueued }), nil
+}
+
+func (s *MemoryCommandStore) index(command entities.Command) {
+	if command.Status != entities.StatusPending {
+		return
+	}
+
+	ids, ok := s.pending[command.ThingToken]
+	if !ok {
+		ids = map[string]bool{}
+		s.pending[command.ThingToken] = ids
+	}
+	ids[command.ID] = true
+}
+
+func (s *MemoryCommandStore) unindex(command entities.Command) {
+	ids := s.pending[command.ThingToken]
+	delete(ids, command.ID)
+	if len(ids) == 0 {
+		delete(s.pending, command.ThingToken)
+	}
+}
+
+// Close does nothing, since there's nothing to release
+func (s *MemoryCommandStore) Close() error {
+	return nil
+}
+
+func (s *MemoryCommandStore) enforceMaxFinished() {
+	if s.maxFinished <= 0 {
+		return
+	}
+
+	finished := []entities.Command{}
+	for _, command := range s.commands {
+		if isFinished(command) {
+			finished = append(finished, command)
+		}
+	}
+	if len(finished) <= s.maxFinished {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+	for _, command := range finished[:len(finished)-s.maxFinished] {
+		delete(s.commands, command.ID)
+	}
+}
+
+func (s *MemoryCommandStore) filter(match func(c entities.Command) bool) []entities.Command {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	commands := []entities.Command{}
+	for _, c := range s.commands {
+		if match(c) {
+			commands = append(commands, c)
+		}
+	}
+
+	sortCommands(commands)
+	return commands
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+)
+
+// CommandStore represents the storage of the commands sent to the things
+type CommandStore interface {
+	Save(command entities.Command) error
+	Get(id string) (*entities.Command, error)
+	ListPending() ([]entities.Command, error)
+	ListPendingByThing(thingToken string) ([]entities.Command, error)
+	ListQueued() ([]entities.Command, error)
+	Close() error
+}
+
+// isFinished returns whether the command was completed or failed
+func isFinished(command entities.Command) bool {
+	return command.Status != entities.StatusPending && command.Status != entities.StatusQueued
+}
+
+// sortCommands sorts the commands from the oldest, by their ID for the same
+// time
+func sortCommands(commands []entities.Command) {
+	sort.Slice(commands, func(i, j int) bool {
+		if !commands[i].CreatedAt.Equal(commands[j].CreatedAt) {
+			return commands[i].CreatedAt.Before(commands[j].CreatedAt)
+		}
+		return commands[i].ID < commands[j].ID
+	})
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+var createdAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "commands")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func newCommand(id string, createdMinutes int, status string) entities.Command {
+	command := entities.Command{
+		ID:         id,
+		Type:       entities.TypeUpdateData,
+		ThingID:    "fbe64efa6c7f717e",
+		Data:       []thingEntities.Data{{SensorID: 1, Value: true}},
+		Status:     status,
+		CreatedAt:  createdAt.Add(time.Duration(createdMinutes) * time.Minute),
+		ThingToken: "mainflux-id",
+	}
+	if isFinished(command) {
+		finishedAt := command.CreatedAt.Add(time.Minute)
+		command.FinishedAt = &finishedAt
+	}
+
+	return command
+}
+
+func TestCommandStores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T, maxFinished int) CommandStore
+	}{
+		{
+			"memory",
+			func(t *testing.T, maxFinished int) CommandStore {
+				return NewMemoryCommandStore(maxFinished)
+			},
+		},
+		{
+			"file",
+			func(t *testing.T, maxFinished int) CommandStore {
+				store, err := NewFileCommandStore(filepath.Join(tempDir(t), "commands.json"), maxFinished, 0)
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := tc.newStore(t, 2)
+			commands := []entities.Command{
+				newCommand("a", 0, entities.StatusCompleted),
+				newCommand("b", 10, entities.StatusFailed),
+				newCommand("c", 30, entities.StatusPending),
+				newCommand("d", 20, entities.StatusPending),
+				newCommand("e", 5, entities.StatusQueued),
+			}
+			for _, c := range commands {
+				assert.NoError(t, store.Save(c))
+			}
+
+			command, err := store.Get("b")
+			assert.NoError(t, err)
+			assert.Equal(t, &commands[1], command)
+			_, err = store.Get("f")
+			assert.Equal(t, entities.ErrCommandNotFound, err)
+
+			pending, err := store.ListPending()
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Command{commands[3], commands[2]}, pending)
+			pending, err = store.ListPendingByThing("mainflux-id")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Command{commands[3], commands[2]}, pending)
+			pending, err = store.ListPendingByThing("other-mainflux-id")
+			assert.NoError(t, err)
+			assert.Empty(t, pending)
+			queued, err := store.ListQueued()
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Command{commands[4]}, queued)
+
+			// the oldest finished command is removed when the third one finishes
+			completed := newCommand("d", 20, entities.StatusCompleted)
+			assert.NoError(t, store.Save(completed))
+			_, err = store.Get("a")
+			assert.Equal(t, entities.ErrCommandNotFound, err)
+			pending, err = store.ListPending()
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Command{commands[2]}, pending)
+			pending, err = store.ListPendingByThing("mainflux-id")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Command{commands[2]}, pending)
+			queued, err = store.ListQueued()
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Command{commands[4]}, queued)
+		})
+	}
+}
+
+func TestFileCommandStoreRestoresCommands(t *testing.T) {
+	path := filepath.Join(tempDir(t), "commands.json")
+	command := newCommand("a", 0, entities.StatusPending)
+	command.Type = entities.TypeRequestData
+	command.Data = nil
+	command.SensorIDs = []int{1, 2}
+	store, err := NewFileCommandStore(path, 0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(command))
+
+	restored, err := NewFileCommandStore(path, 0, 0)
+	assert.NoError(t, err)
+
+	pending, err := restored.ListPending()
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Command{command}, pending)
+	pending, err = restored.ListPendingByThing(command.ThingToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Command{command}, pending)
+}
+
+func TestFileCommandStoreWritesPendingChangesOnClose(t *testing.T) {
+	path := filepath.Join(tempDir(t), "commands.json")
+	command := newCommand("a", 0, entities.StatusPending)
+	store, err := NewFileCommandStore(path, 0, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(command))
+
+	restored, err := NewFileCommandStore(path, 0, 0)
+	assert.NoError(t, err)
+	_, err = restored.Get("a")
+	assert.Equal(t, entities.ErrCommandNotFound, err)
+
+	assert.NoError(t, store.Close())
+	restored, err = NewFileCommandStore(path, 0, 0)
+	assert.NoError(t, err)
+	stored, err := restored.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, &command, stored)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/delivery/amqp"
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/data/interactors"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// DataController handles the data commands received from the queue
+type DataController interface {
+	GetLastValues(body []byte, authorization, replyTo, corrID string) error
+	GetHistory(body []byte, authorization, replyTo, corrID string) error
+}
+
+type dataController struct {
+	logger         logging.Logger
+	dataInteractor interactors.Interactor
+	sender         amqp.Sender
+}
+
+// NewDataController constructs the DataController
+func NewDataController(logger logging.Logger, dataInteractor interactors.Interactor, sender amqp.Sender) DataController {
+	return &dataController{logger, dataInteractor, sender}
+}
+
+// GetLastValues handles the last values request and execute its use case
+func (dc *dataController) GetLastValues(body []byte, authorization, replyTo, corrID string) error {
+	var req network.LastValuesRequest
+	err := json.Unmarshal(body, &req)
+	if err != nil {
+		return fmt.Errorf("message body parsing error: %w", err)
+	}
+
+	dc.logger.Info("last values command received")
+	if replyTo == "" {
+		return thingInteractors.ErrReplyToNotProvided
+	}
+
+	if corrID == "" {
+		return dc.replyLastValues(req.ID, nil, replyTo, corrID, thingInteractors.ErrCorrelationIDNotProvided)
+	}
+
+	values, err := dc.dataInteractor.GetLastValues(authorization, req.ID, req.SensorIds)
+	return dc.replyLastValues(req.ID, values, replyTo, corrID, err)
+}
+
+// GetHistory handles the history request and execute its use case
+func (dc *dataController) GetHistory(body []byte, authorization, replyTo, corrID string) error {
+	var req network.HistoryRequest
+	err := json.Unmarshal(body, &req)
+	if err != nil {
+		return fmt.Errorf("message body parsing error: %w", err)
+	}
+
+	dc.logger.Info("history command received")
+	if replyTo == "" {
+		return thingInteractors.ErrReplyToNotProvided
+	}
+
+	if corrID == "" {
+		return dc.replyHistory(req.ID, nil, replyTo, corrID, thingInteractors.ErrCorrelationIDNotProvided)
+	}
+
+	query := entities.HistoryQuery{SensorIDs: req.SensorIds, From: req.From, To: req.To}
+	if req.Interval != "" {
+		query.Interval, err = time.ParseDuration(req.Interval)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", interactors.ErrIntervalInvalid, req.Interval)
+			return dc.replyHistory(req.ID, nil, replyTo, corrID, err)
+		}
+	}
+
+	history, err := dc.dataInteractor.GetHistory(authorization, req.ID, query)
+	return dc.replyHistory(req.ID, history, replyTo, corrID, err)
+}
+
+// replyLastValues sends the response, returning the use case error so the
+// message is settled accordingly
+func (dc *dataController) replyLastValues(thingID string, values []entities.SensorValue, replyTo, corrID string, err error) error {
+	sendErr := dc.sender.SendLastValuesResponse(thingID, values, replyTo, corrID, err)
+	if sendErr != nil {
+		return fmt.Errorf("error sending response: %v: %w", err, sendErr)
+	}
+
+	return err
+}
+
+// replyHistory sends the response, returning the use case error so the
+// message is settled accordingly
+func (dc *dataController) replyHistory(thingID string, history []entities.SensorHistory, replyTo, corrID string, err error) error {
+	sendErr := dc.sender.SendHistoryResponse(thingID, history, replyTo, corrID, err)
+	if sendErr != nil {
+		return fmt.Errorf("error sending response: %v: %w", err, sendErr)
+	}
+
+	return err
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/data/interactors"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errInvalidSensorID = errors.New("invalid sensor id")
+	errInvalidTime     = errors.New("invalid time, expected RFC 3339 format")
+)
+
+// DataHTTPController handles the HTTP requests to query the things' data
+type DataHTTPController struct {
+	logger         logging.Logger
+	dataInteractor interactors.Interactor
+}
+
+// NewDataHTTPController constructs the controller
+func NewDataHTTPController(logger logging.Logger, dataInteractor interactors.Interactor) *DataHTTPController {
+	return &DataHTTPController{logger, dataInteractor}
+}
+
+// GetLastValues godoc
+// @Summary Gets the last values received from the thing's sensors
+// @Description The sensors which never sent data are omitted.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Thing's id"
+// @Param sensorId query []int false "Sensors to get, all of them when not provided"
+// @Success 200 {array} entities.SensorValue "Sensors' last values"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Thing not found"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid sensor id"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /things/{id}/data/last [get]
+// GetLastValues handles the server request and calls the get last values use case
+func (dc *DataHTTPController) GetLastValues(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		dc.writeError(w, thingInteractors.ErrAuthNotProvided)
+		return
+	}
+
+	sensorIDs, err := parseSensorIDs(r)
+	if err != nil {
+		dc.writeError(w, err)
+		return
+	}
+
+	values, err := dc.dataInteractor.GetLastValues(authorization, mux.Vars(r)["id"], sensorIDs)
+	if err != nil {
+		dc.writeError(w, err)
+		return
+	}
+
+	dc.writeResponse(w, http.StatusOK, values)
+}
+
+// GetHistory godoc
+// @Summary Gets the values received from the thing's sensors in a time window
+// @Description The values are returned as they were received or, when the interval is provided, downsampled in buckets with the minimum, maximum and average of the numeric values. Boolean values are aggregated as 0 and 1.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Thing's id"
+// @Param sensorId query []int false "Sensors to get, all of them when not provided"
+// @Param from query string false "Time window start in RFC 3339 format, 24 hours before its end by default"
+// @Param to query string false "Time window end in RFC 3339 format, the current time by default"
+// @Param interval query string false "Downsampling interval, such as 5m or 1h"
+// @Success 200 {array} entities.SensorHistory "Sensors' history"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Thing not found"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid sensor id, time window or interval"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /things/{id}/data/history [get]
+// GetHistory handles the server request and calls the get history use case
+func (dc *DataHTTPController) GetHistory(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		dc.writeError(w, thingInteractors.ErrAuthNotProvided)
+		return
+	}
+
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		dc.writeError(w, err)
+		return
+	}
+
+	history, err := dc.dataInteractor.GetHistory(authorization, mux.Vars(r)["id"], query)
+	if err != nil {
+		dc.writeError(w, err)
+		return
+	}
+
+	dc.writeResponse(w, http.StatusOK, history)
+}
+
+func (dc *DataHTTPController) writeError(w http.ResponseWriter, err error) {
+	dc.logger.Error(err)
+	dc.writeResponse(w, mapDataErrorToStatusCode(err), &thingControllers.ErrorResponse{Message: err.Error()})
+}
+
+func (dc *DataHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	js, err := json.Marshal(msg)
+	if err != nil {
+		dc.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		dc.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func parseSensorIDs(r *http.Request) ([]int, error) {
+	sensorIDs := []int{}
+	for _, value := range r.URL.Query()["sensorId"] {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidSensorID, value)
+		}
+		sensorIDs = append(sensorIDs, id)
+	}
+
+	return sensorIDs, nil
+}
+
+func parseHistoryQuery(r *http.Request) (entities.HistoryQuery, error) {
+	var query entities.HistoryQuery
+	var err error
+	params := r.URL.Query()
+
+	query.SensorIDs, err = parseSensorIDs(r)
+	if err != nil {
+		return query, err
+	}
+
+	for param, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := params.Get(param)
+		if value == "" {
+			continue
+		}
+
+		*t, err = time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return query, fmt.Errorf("%w: %s", errInvalidTime, value)
+		}
+	}
+
+	if value := params.Get("interval"); value != "" {
+		query.Interval, err = time.ParseDuration(value)
+		if err != nil {
+			return query, fmt.Errorf("%w: %s", interactors.ErrIntervalInvalid, value)
+		}
+	}
+
+	return query, nil
+}
+
+func mapDataErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, thingInteractors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, thingEntities.ErrThingForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, thingEntities.ErrThingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errInvalidSensorID),
+		errors.Is(err, errInvalidTime),
+		errors.Is(err, thingInteractors.ErrSensorInvalid),
+		errors.Is(err, interactors.ErrTimeRangeInvalid),
+		errors.Is(err, interactors.ErrIntervalInvalid),
+		errors.Is(err, interactors.ErrHistoryTooLarge):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,72 @@
+package amqp
+
+import (
+	"encoding/json"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+)
+
+const (
+	exchangeDevices     = "device"
+	exchangeDevicesType = "direct"
+)
+
+// Sender represents the operations to send the data commands response
+type Sender interface {
+	SendLastValuesResponse(thingID string, values []entities.SensorValue, replyTo, corrID string, err error) error
+	SendHistoryResponse(thingID string, history []entities.SensorHistory, replyTo, corrID string, err error) error
+}
+
+// commandSender handle messages received from a service
+type commandSender struct {
+	logger logging.Logger
+	amqp   network.AmqpSender
+}
+
+// NewCommandSender creates a new commandSender instance
+func NewCommandSender(logger logging.Logger, amqp network.AmqpSender) Sender {
+	return &commandSender{logger, amqp}
+}
+
+// SendLastValuesResponse sends the thing's last values command response
+func (cs *commandSender) SendLastValuesResponse(thingID string, values []entities.SensorValue, replyTo, corrID string, err error) error {
+	if values == nil {
+		values = []entities.SensorValue{}
+	}
+
+	resp := &network.LastValuesResponse{ID: thingID, Values: values, Error: getErrMsg(err)}
+	return cs.reply(resp, replyTo, corrID)
+}
+
+// SendHistoryResponse sends the thing's history command response
+func (cs *commandSender) SendHistoryResponse(thingID string, history []entities.SensorHistory, replyTo, corrID string, err error) error {
+	if history == nil {
+		history = []entities.SensorHistory{}
+	}
+
+	resp := &network.HistoryResponse{ID: thingID, Sensors: history, Error: getErrMsg(err)}
+	return cs.reply(resp, replyTo, corrID)
+}
+
+func (cs *commandSender) reply(resp interface{}, replyTo, corrID string) error {
+	headers := map[string]interface{}{
+		"correlation_id": corrID,
+	}
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return thingAMQP.MapPublishError(cs.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, replyTo, msg, headers))
+}
+
+func getErrMsg(err error) *string {
+	if err != nil {
+		msg := err.Error()
+		return &msg
+	}
+	return nil
+}
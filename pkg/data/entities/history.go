@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// Reading represents a value received from a thing's sensor at a given time
+type Reading struct {
+	SensorID  int         `json:"sensorId"`
+	Value     interface{} `json:"value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// HistoryQuery represents the filters to query the history of a thing's
+// sensors. The values are downsampled in buckets of the interval duration
+// when it's provided.
+type HistoryQuery struct {
+	SensorIDs []int
+	From      time.Time
+	To        time.Time
+	Interval  time.Duration
+}
+
+// SensorHistory represents the values received from a sensor in a time
+// window, either as they were received or downsampled in buckets
+type SensorHistory struct {
+	SensorID int      `json:"sensorId"`
+	Values   []Sample `json:"values,omitempty"`
+	Buckets  []Bucket `json:"buckets,omitempty"`
+}
+
+// Sample represents a value received from a sensor
+type Sample struct {
+	Value     interface{} `json:"value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// Bucket represents the aggregation of the numeric values received from a
+// sensor in an interval starting at the bucket's start. Boolean values are
+// aggregated as 0 and 1 and raw values are ignored.
+type Bucket struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Count int       `json:"count"`
+}
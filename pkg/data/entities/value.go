@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"time"
+
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// SensorValue represents the last value received from a thing's sensor
+type SensorValue struct {
+	SensorID  int                   `json:"sensorId"`
+	Value     interface{}           `json:"value"`
+	Schema    *thingEntities.Schema `json:"schema"`
+	Timestamp time.Time             `json:"timestamp"`
+}
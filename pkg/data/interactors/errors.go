@@ -0,0 +1,16 @@
+package interactors
+
+import "errors"
+
+var (
+	// ErrTimeRangeInvalid is returned when the history's time window starts after it ends
+	ErrTimeRangeInvalid = errors.New("time window start is after its end")
+
+	// ErrIntervalInvalid is returned when the downsampling interval is negative or
+	// results in too many buckets for the time window
+	ErrIntervalInvalid = errors.New("invalid downsampling interval")
+
+	// ErrHistoryTooLarge is returned when the time window has too many values to
+	// be returned without downsampling
+	ErrHistoryTooLarge = errors.New("too many values in the time window, narrow it or provide an interval")
+)
@@ -0,0 +1,119 @@
+package interactors
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/data/storage"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+const (
+	defaultHistoryWindow = 24 * time.Hour
+	maxHistoryBuckets    = 10000
+	maxHistoryValues     = 100000
+)
+
+// GetHistory returns the values received from the thing's sensors in the
+// query's time window, which defaults to the last 24 hours. All the sensors
+// of the thing's schema are returned when no sensor ID is provided. When the
+// query has an interval, the values are downsampled in buckets. Otherwise,
+// the number of values returned is limited.
+func (i *DataInteractor) GetHistory(authorization, thingID string, query entities.HistoryQuery) ([]entities.SensorHistory, error) {
+	if authorization == "" {
+		return nil, thingInteractors.ErrAuthNotProvided
+	}
+	if thingID == "" {
+		return nil, thingInteractors.ErrIDNotProvided
+	}
+
+	if query.To.IsZero() {
+		query.To = i.now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultHistoryWindow)
+	}
+	if query.From.After(query.To) {
+		return nil, ErrTimeRangeInvalid
+	}
+	if query.Interval < 0 || (query.Interval > 0 && query.To.Sub(query.From)/query.Interval >= maxHistoryBuckets) {
+		return nil, ErrIntervalInvalid
+	}
+
+	thing, err := i.thingProxy.Get(authorization, thingID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing metadata: %w", err)
+	}
+
+	sensorIDs := query.SensorIDs
+	for _, id := range sensorIDs {
+		if findSchema(thing.Schema, id) == nil {
+			return nil, thingInteractors.ErrSensorInvalid
+		}
+	}
+	if len(sensorIDs) == 0 {
+		for _, s := range thing.Schema {
+			sensorIDs = append(sensorIDs, s.SensorID)
+		}
+	}
+
+	limit := maxHistoryValues
+	if query.Interval > 0 {
+		limit = 0
+	}
+
+	readings, err := i.history.Query(thing.Token, query.SensorIDs, query.From, query.To, limit)
+	if errors.Is(err, storage.ErrTooManyReadings) {
+		return nil, ErrHistoryTooLarge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying thing's history: %w", err)
+	}
+
+	samples := map[int][]entities.Sample{}
+	for _, r := range readings {
+		samples[r.SensorID] = append(samples[r.SensorID], entities.Sample{Value: r.Value, Timestamp: r.Timestamp})
+	}
+
+	history := make([]entities.SensorHistory, 0, len(sensorIDs))
+	for _, id := range sensorIDs {
+		sensorHistory := entities.SensorHistory{SensorID: id}
+		if query.Interval > 0 {
+			sensorHistory.Buckets = downsample(samples[id], query.From, query.Interval)
+		} else {
+			sensorHistory.Values = samples[id]
+		}
+		history = append(history, sensorHistory)
+	}
+
+	return history, nil
+}
+
+// downsample aggregates the samples, sorted by their timestamp, in buckets
+// of the interval aligned to the start time. The empty buckets are omitted.
+func downsample(samples []entities.Sample, start time.Time, interval time.Duration) []entities.Bucket {
+	buckets := []entities.Bucket{}
+	for _, sample := range samples {
+		value, ok := thingEntities.NumericValue(sample.Value)
+		if !ok {
+			continue
+		}
+
+		bucketStart := start.Add(sample.Timestamp.Sub(start) / interval * interval)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(bucketStart) {
+			buckets = append(buckets, entities.Bucket{Start: bucketStart, Min: value, Max: value})
+		}
+
+		bucket := &buckets[len(buckets)-1]
+		bucket.Min = math.Min(bucket.Min, value)
+		bucket.Max = math.Max(bucket.Max, value)
+		bucket.Count++
+		bucket.Avg += (value - bucket.Avg) / float64(bucket.Count)
+	}
+
+	return buckets
+}
@@ -0,0 +1,214 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/data/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/stretchr/testify/assert"
+)
+
+var historyReadings = []entities.Reading{
+	{SensorID: 1, Value: float64(10), Timestamp: receivedAt.Add(-50 * time.Minute)},
+	{SensorID: 2, Value: true, Timestamp: receivedAt.Add(-45 * time.Minute)},
+	{SensorID: 1, Value: float64(20), Timestamp: receivedAt.Add(-40 * time.Minute)},
+	{SensorID: 2, Value: false, Timestamp: receivedAt.Add(-20 * time.Minute)},
+	{SensorID: 1, Value: float64(12), Timestamp: receivedAt.Add(-10 * time.Minute)},
+}
+
+func TestGetHistory(t *testing.T) {
+	testCases := []struct {
+		name            string
+		authorization   string
+		thingID         string
+		query           entities.HistoryQuery
+		proxyErr        error
+		storedReadings  []entities.Reading
+		expectedFrom    time.Time
+		expectedHistory []entities.SensorHistory
+		expectedErr     error
+	}{
+		{
+			"authorization token not provided",
+			"",
+			"thing-id",
+			entities.HistoryQuery{},
+			nil,
+			nil,
+			time.Time{},
+			nil,
+			thingInteractors.ErrAuthNotProvided,
+		},
+		{
+			"thing's id not provided",
+			"authorization-token",
+			"",
+			entities.HistoryQuery{},
+			nil,
+			nil,
+			time.Time{},
+			nil,
+			thingInteractors.ErrIDNotProvided,
+		},
+		{
+			"time window ending before it starts",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{From: receivedAt, To: receivedAt.Add(-time.Hour)},
+			nil,
+			nil,
+			time.Time{},
+			nil,
+			ErrTimeRangeInvalid,
+		},
+		{
+			"negative interval",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{Interval: -time.Minute},
+			nil,
+			nil,
+			time.Time{},
+			nil,
+			ErrIntervalInvalid,
+		},
+		{
+			"interval too small for the time window",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{Interval: time.Second},
+			nil,
+			nil,
+			time.Time{},
+			nil,
+			ErrIntervalInvalid,
+		},
+		{
+			"failed to get thing from thing's service",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{},
+			thingEntities.ErrThingNotFound,
+			nil,
+			time.Time{},
+			nil,
+			thingEntities.ErrThingNotFound,
+		},
+		{
+			"sensor not in the thing's schema",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{SensorIDs: []int{4}},
+			nil,
+			nil,
+			time.Time{},
+			nil,
+			thingInteractors.ErrSensorInvalid,
+		},
+		{
+			"every sensor of the schema in the last 24 hours",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{},
+			nil,
+			historyReadings,
+			receivedAt.Add(-24 * time.Hour),
+			[]entities.SensorHistory{
+				{SensorID: 1, Values: []entities.Sample{
+					{Value: float64(10), Timestamp: receivedAt.Add(-50 * time.Minute)},
+					{Value: float64(20), Timestamp: receivedAt.Add(-40 * time.Minute)},
+					{Value: float64(12), Timestamp: receivedAt.Add(-10 * time.Minute)},
+				}},
+				{SensorID: 2, Values: []entities.Sample{
+					{Value: true, Timestamp: receivedAt.Add(-45 * time.Minute)},
+					{Value: false, Timestamp: receivedAt.Add(-20 * time.Minute)},
+				}},
+				{SensorID: 3},
+			},
+			nil,
+		},
+		{
+			"requested sensors downsampled in buckets",
+			"authorization-token",
+			"thing-id",
+			entities.HistoryQuery{SensorIDs: []int{1, 2}, From: receivedAt.Add(-time.Hour), To: receivedAt, Interval: 30 * time.Minute},
+			nil,
+			historyReadings,
+			receivedAt.Add(-time.Hour),
+			[]entities.SensorHistory{
+				{SensorID: 1, Buckets: []entities.Bucket{
+					{Start: receivedAt.Add(-time.Hour), Min: 10, Max: 20, Avg: 15, Count: 2},
+					{Start: receivedAt.Add(-30 * time.Minute), Min: 12, Max: 12, Avg: 12, Count: 1},
+				}},
+				{SensorID: 2, Buckets: []entities.Bucket{
+					{Start: receivedAt.Add(-time.Hour), Min: 1, Max: 1, Avg: 1, Count: 1},
+					{Start: receivedAt.Add(-30 * time.Minute), Min: 0, Max: 0, Avg: 0, Count: 1},
+				}},
+			},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy := &mocks.FakeThingProxy{}
+			proxy.On("Get", tc.authorization, tc.thingID).Return(thing, tc.proxyErr).Maybe()
+			limit := maxHistoryValues
+			if tc.query.Interval > 0 {
+				limit = 0
+			}
+			history := &mocks.FakeHistoryStore{}
+			history.On("Query", "mainflux-id", tc.query.SensorIDs, tc.expectedFrom, receivedAt, limit).Return(tc.storedReadings, nil).Maybe()
+			interactor := NewDataInteractor(&mocks.FakeLogger{}, proxy, storage.NewMemoryLastValueStore(), history)
+			interactor.now = func() time.Time { return receivedAt }
+
+			sensors, err := interactor.GetHistory(tc.authorization, tc.thingID, tc.query)
+
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			assert.Equal(t, tc.expectedHistory, sensors)
+			proxy.AssertExpectations(t)
+			history.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetHistoryTooLarge(t *testing.T) {
+	proxy := &mocks.FakeThingProxy{}
+	proxy.On("Get", "authorization-token", "thing-id").Return(thing, nil)
+	history := &mocks.FakeHistoryStore{}
+	history.On("Query", "mainflux-id", []int(nil), receivedAt.Add(-defaultHistoryWindow), receivedAt, maxHistoryValues).Return([]entities.Reading(nil), storage.ErrTooManyReadings)
+	interactor := NewDataInteractor(&mocks.FakeLogger{}, proxy, storage.NewMemoryLastValueStore(), history)
+	interactor.now = func() time.Time { return receivedAt }
+
+	sensors, err := interactor.GetHistory("authorization-token", "thing-id", entities.HistoryQuery{})
+
+	assert.Equal(t, ErrHistoryTooLarge, err)
+	assert.Nil(t, sensors)
+	history.AssertExpectations(t)
+}
+
+func TestDataPublishedIsAppendedToHistory(t *testing.T) {
+	readAt := receivedAt.Add(-time.Minute)
+	publishedAt := receivedAt.Add(-time.Second)
+	history := &mocks.FakeHistoryStore{}
+	history.On("Append", "mainflux-id", []entities.Reading{
+		{SensorID: 1, Value: float64(12.5), Timestamp: readAt},
+		{SensorID: 2, Value: true, Timestamp: publishedAt},
+		{SensorID: 3, Value: float64(1), Timestamp: receivedAt},
+	}).Return(nil)
+	interactor := NewDataInteractor(&mocks.FakeLogger{}, &mocks.FakeThingProxy{}, storage.NewMemoryLastValueStore(), history)
+	interactor.now = func() time.Time { return receivedAt }
+
+	interactor.OnDataPublished(thing, []thingEntities.Data{
+		{SensorID: 1, Value: float64(12.5), Timestamp: &readAt, ReceivedAt: &publishedAt},
+		{SensorID: 2, Value: true, ReceivedAt: &publishedAt},
+		{SensorID: 3, Value: float64(1)},
+	})
+
+	history.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+package interactors
+
+import (
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/data/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
+)
+
+// Interactor is an interface that defines the data's use cases operations
+type Interactor interface {
+	GetLastValues(authorization, thingID string, sensorIDs []int) ([]entities.SensorValue, error)
+	GetHistory(authorization, thingID string, query entities.HistoryQuery) ([]entities.SensorHistory, error)
+}
+
+// DataInteractor represents the data interactor capabilities, it's composed
+// by the necessary dependencies
+type DataInteractor struct {
+	logger     logging.Logger
+	thingProxy http.ThingProxy
+	lastValues storage.LastValueStore
+	history    storage.HistoryStore
+	now        func() time.Time
+}
+
+// NewDataInteractor creates a new DataInteractor instance
+func NewDataInteractor(
+	logger logging.Logger,
+	thingProxy http.ThingProxy,
+	lastValues storage.LastValueStore,
+	history storage.HistoryStore,
+) *DataInteractor {
+	return &DataInteractor{logger, thingProxy, lastValues, history, time.Now}
+}
@@ -0,0 +1,107 @@
+package interactors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// OnDataPublished stores the data published by the thing as its sensors'
+// last values, along with their schema and the time they were received, and
+// appends it to the thing's history with the time it was read. The last
+// values are ordered by the time they were received, since the things may
+// send the values they buffered after newer ones.
+func (i *DataInteractor) OnDataPublished(thing *thingEntities.Thing, data []thingEntities.Data) {
+	values := make([]entities.SensorValue, 0, len(data))
+	readings := make([]entities.Reading, 0, len(data))
+	for _, d := range data {
+		values = append(values, entities.SensorValue{
+			SensorID:  d.SensorID,
+			Value:     d.Value,
+			Schema:    findSchema(thing.Schema, d.SensorID),
+			Timestamp: i.receivedAt(d),
+		})
+		readings = append(readings, entities.Reading{SensorID: d.SensorID, Value: d.Value, Timestamp: d.ReadAt(i.now())})
+	}
+
+	err := i.lastValues.Save(thing.Token, values)
+	if err != nil {
+		i.logger.Errorf("error storing thing %s last values: %s", thing.ID, err)
+	}
+
+	err = i.history.Append(thing.Token, readings)
+	if err != nil {
+		i.logger.Errorf("error storing thing %s history: %s", thing.ID, err)
+	}
+}
+
+// receivedAt returns the time the data was received by the service
+func (i *DataInteractor) receivedAt(data thingEntities.Data) time.Time {
+	if data.ReceivedAt != nil {
+		return *data.ReceivedAt
+	}
+
+	return i.now()
+}
+
+// GetLastValues returns the last values received from the thing's sensors.
+// All the sensors are returned when no sensor ID is provided and the sensors
+// which never sent data are omitted.
+func (i *DataInteractor) GetLastValues(authorization, thingID string, sensorIDs []int) ([]entities.SensorValue, error) {
+	if authorization == "" {
+		return nil, thingInteractors.ErrAuthNotProvided
+	}
+	if thingID == "" {
+		return nil, thingInteractors.ErrIDNotProvided
+	}
+
+	thing, err := i.thingProxy.Get(authorization, thingID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing metadata: %w", err)
+	}
+
+	for _, id := range sensorIDs {
+		if findSchema(thing.Schema, id) == nil {
+			return nil, thingInteractors.ErrSensorInvalid
+		}
+	}
+
+	values, err := i.lastValues.Get(thing.Token)
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing's last values: %w", err)
+	}
+
+	return filterSensors(values, sensorIDs), nil
+}
+
+func findSchema(schemaList []thingEntities.Schema, sensorID int) *thingEntities.Schema {
+	for _, s := range schemaList {
+		if s.SensorID == sensorID {
+			schema := s
+			return &schema
+		}
+	}
+
+	return nil
+}
+
+func filterSensors(values []entities.SensorValue, sensorIDs []int) []entities.SensorValue {
+	if len(sensorIDs) == 0 {
+		return values
+	}
+
+	filtered := []entities.SensorValue{}
+	for _, value := range values {
+		for _, id := range sensorIDs {
+			if value.SensorID == id {
+				filtered = append(filtered, value)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,149 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/data/storage"
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	receivedAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	schema     = []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"},
+		{SensorID: 2, ValueType: 3, Unit: 0, TypeID: 65521, Name: "switch"},
+		{SensorID: 3, ValueType: 1, Unit: 0, TypeID: 65296, Name: "counter"},
+	}
+	thing = &thingEntities.Thing{ID: "thing-id", Token: "mainflux-id", Name: "thing", Schema: schema}
+)
+
+func newDataInteractor(proxy *mocks.FakeThingProxy) *DataInteractor {
+	history := &mocks.FakeHistoryStore{}
+	history.On("Append", mock.Anything, mock.Anything).Return(nil)
+	interactor := NewDataInteractor(&mocks.FakeLogger{}, proxy, storage.NewMemoryLastValueStore(), history)
+	interactor.now = func() time.Time { return receivedAt }
+	return interactor
+}
+
+func TestGetLastValues(t *testing.T) {
+	testCases := []struct {
+		name           string
+		authorization  string
+		thingID        string
+		sensorIDs      []int
+		proxyErr       error
+		expectedValues []entities.SensorValue
+		expectedErr    error
+	}{
+		{
+			"authorization token not provided",
+			"",
+			"thing-id",
+			nil,
+			nil,
+			nil,
+			thingInteractors.ErrAuthNotProvided,
+		},
+		{
+			"thing's id not provided",
+			"authorization-token",
+			"",
+			nil,
+			nil,
+			nil,
+			thingInteractors.ErrIDNotProvided,
+		},
+		{
+			"failed to get thing from thing's service",
+			"authorization-token",
+			"thing-id",
+			nil,
+			thingEntities.ErrThingNotFound,
+			nil,
+			thingEntities.ErrThingNotFound,
+		},
+		{
+			"sensor not in the thing's schema",
+			"authorization-token",
+			"thing-id",
+			[]int{4},
+			nil,
+			nil,
+			thingInteractors.ErrSensorInvalid,
+		},
+		{
+			"all the sensors which sent data are returned",
+			"authorization-token",
+			"thing-id",
+			nil,
+			nil,
+			[]entities.SensorValue{
+				{SensorID: 1, Value: float64(12.5), Schema: &schema[0], Timestamp: receivedAt},
+				{SensorID: 2, Value: true, Schema: &schema[1], Timestamp: receivedAt},
+			},
+			nil,
+		},
+		{
+			"only the requested sensors are returned",
+			"authorization-token",
+			"thing-id",
+			[]int{2, 3},
+			nil,
+			[]entities.SensorValue{
+				{SensorID: 2, Value: true, Schema: &schema[1], Timestamp: receivedAt},
+			},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy := &mocks.FakeThingProxy{}
+			proxy.On("Get", tc.authorization, tc.thingID).Return(thing, tc.proxyErr).Maybe()
+			interactor := newDataInteractor(proxy)
+			interactor.OnDataPublished(thing, []thingEntities.Data{{SensorID: 2, Value: true}, {SensorID: 1, Value: float64(12.5)}})
+
+			values, err := interactor.GetLastValues(tc.authorization, tc.thingID, tc.sensorIDs)
+
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			assert.Equal(t, tc.expectedValues, values)
+			proxy.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLastValuesAreScopedByMainfluxID(t *testing.T) {
+	otherUserThing := &thingEntities.Thing{ID: "thing-id", Token: "other-mainflux-id", Schema: schema}
+	proxy := &mocks.FakeThingProxy{}
+	proxy.On("Get", "other-token", "thing-id").Return(otherUserThing, nil)
+	interactor := newDataInteractor(proxy)
+	interactor.OnDataPublished(thing, []thingEntities.Data{{SensorID: 1, Value: float64(12.5)}})
+
+	values, err := interactor.GetLastValues("other-token", "thing-id", nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestLastValuesOrderedByReceivedTime(t *testing.T) {
+	proxy := &mocks.FakeThingProxy{}
+	proxy.On("Get", "authorization-token", "thing-id").Return(thing, nil)
+	interactor := newDataInteractor(proxy)
+	readAt := receivedAt.Add(-time.Hour)
+	bufferedAt := receivedAt.Add(time.Minute)
+
+	interactor.OnDataPublished(thing, []thingEntities.Data{{SensorID: 1, Value: float64(12.5), ReceivedAt: &receivedAt}})
+	// a value buffered by the thing is received later, although it was read before
+	interactor.OnDataPublished(thing, []thingEntities.Data{{SensorID: 1, Value: float64(10), Timestamp: &readAt, ReceivedAt: &bufferedAt}})
+
+	values, err := interactor.GetLastValues("authorization-token", "thing-id", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.SensorValue{{SensorID: 1, Value: float64(10), Schema: &schema[0], Timestamp: bufferedAt}}, values)
+}
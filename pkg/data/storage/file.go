@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+)
+
+// FileLastValueStore keeps the last values in memory and writes them to a
+// JSON file, so they're restored when the service restarts. The changes are
+// written periodically, after the flush interval, and when the store is
+// closed, so saving the values doesn't wait the whole file being rewritten.
+type FileLastValueStore struct {
+	file   *jsonfile.File
+	memory *MemoryLastValueStore
+}
+
+// NewFileLastValueStore creates a new FileLastValueStore instance loading the
+// values previously written to the file, which is created when it doesn't
+// exist yet. When the flush interval is zero, the file is written on every
+// change.
+func NewFileLastValueStore(path string, flushInterval time.Duration) (*FileLastValueStore, error) {
+	s := &FileLastValueStore{memory: NewMemoryLastValueStore()}
+	s.file = jsonfile.New(path, "last values", flushInterval, func() interface{} { return s.memory.values })
+
+	err := s.file.Load(&s.memory.values)
+	if err != nil {
+		s.file.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save stores the values, replacing the previous ones of the same sensors
+func (s *FileLastValueStore) Save(thingID string, values []entities.SensorValue) error {
+	return s.file.Update(func() error { return s.memory.Save(thingID, values) })
+}
+
+// Get returns the thing's last values sorted by the sensor ID
+func (s *FileLastValueStore) Get(thingID string) ([]entities.SensorValue, error) {
+	return s.memory.Get(thingID)
+}
+
+// Close writes the values not written yet
+func (s *FileLastValueStore) Close() error {
+	return s.file.Close()
+}
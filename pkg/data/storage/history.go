@@ -0,0 +1,358 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+)
+
+const (
+	segmentExt        = ".seg"
+	maxRecordLineSize = 1024 * 1024
+)
+
+var (
+	// ErrRotationUndefined is returned when the segments have neither a size
+	// nor a duration, so they're never rotated and the retention never runs
+	ErrRotationUndefined = errors.New("history segments need a size or a duration")
+
+	// ErrTooManyReadings is returned when the query matches more readings
+	// than its limit
+	ErrTooManyReadings = errors.New("too many readings in the time window")
+)
+
+// HistoryStore represents the storage of the values received from the
+// things' sensors over time. The things are identified by their ID on the
+// things service, as on the LastValueStore.
+type HistoryStore interface {
+	Append(thingID string, readings []entities.Reading) error
+	Query(thingID string, sensorIDs []int, from, to time.Time, limit int) ([]entities.Reading, error)
+}
+
+// HistoryRetention represents the limits of the segment files. A new segment
+// is started when the current one reaches the size or the duration, and the
+// oldest segments are removed when they're older than the maximum age or
+// the segments exceed the maximum size. Zero disables the limit, but the
+// segments need a size or a duration.
+type HistoryRetention struct {
+	SegmentSize     int64
+	SegmentDuration time.Duration
+	MaxAge          time.Duration
+	MaxSize         int64
+}
+
+// SegmentHistoryStore appends the readings to JSON lines segment files in a
+// directory. The time window of each segment is kept in memory, so the
+// queries only read the segments which may have readings in the window.
+type SegmentHistoryStore struct {
+	dir       string
+	retention HistoryRetention
+	now       func() time.Time
+
+	mutex    sync.Mutex
+	segments []*segment
+	current  *os.File
+}
+
+type segment struct {
+	path      string
+	start     time.Time
+	size      int64
+	modified  time.Time
+	firstRead time.Time
+	lastRead  time.Time
+}
+
+type record struct {
+	ThingID   string      `json:"thingId"`
+	SensorID  int         `json:"sensorId"`
+	Value     interface{} `json:"value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// NewSegmentHistoryStore creates a new SegmentHistoryStore instance on the
+// directory, which is created when it doesn't exist yet. The existing
+// segments are read to restore their time windows.
+func NewSegmentHistoryStore(dir string, retention HistoryRetention) (*SegmentHistoryStore, error) {
+	if retention.SegmentSize <= 0 && retention.SegmentDuration <= 0 {
+		return nil, ErrRotationUndefined
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating history directory: %w", err)
+	}
+
+	s := &SegmentHistoryStore{dir: dir, retention: retention, now: time.Now}
+	err = s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	s.enforceRetention()
+	return s, nil
+}
+
+// Append writes the thing's readings to the current segment and removes the
+// segments out of the retention
+func (s *SegmentHistoryStore) Append(thingID string, readings []entities.Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, r := range readings {
+		err := encoder.Encode(record{thingID, r.SensorID, r.Value, r.Timestamp})
+		if err != nil {
+			return fmt.Errorf("error serializing reading: %w", err)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.rotate()
+	if err != nil {
+		return err
+	}
+
+	n, err := s.current.Write(buf.Bytes())
+	seg := s.segments[len(s.segments)-1]
+	seg.size += int64(n)
+	seg.modified = s.now()
+	if err != nil {
+		return fmt.Errorf("error writing history segment: %w", err)
+	}
+
+	for _, r := range readings {
+		seg.include(r.Timestamp)
+	}
+
+	s.enforceRetention()
+	return nil
+}
+
+// Query returns the thing's readings in the time window, including its
+// limits, sorted by their timestamp. All the sensors are returned when no
+// sensor ID is provided. ErrTooManyReadings is returned when there are more
+// readings than the limit, unless it's zero.
+func (s *SegmentHistoryStore) Query(thingID string, sensorIDs []int, from, to time.Time, limit int) ([]entities.Reading, error) {
+	s.mutex.Lock()
+	paths := []string{}
+	for _, seg := range s.segments {
+		if !seg.firstRead.IsZero() && !seg.lastRead.Before(from) && !seg.firstRead.After(to) {
+			paths = append(paths, seg.path)
+		}
+	}
+	s.mutex.Unlock()
+
+	sensors := map[int]bool{}
+	for _, id := range sensorIDs {
+		sensors[id] = true
+	}
+
+	readings := []entities.Reading{}
+	for _, path := range paths {
+		err := readSegment(path, func(r record) bool {
+			if r.ThingID != thingID || r.Timestamp.Before(from) || r.Timestamp.After(to) {
+				return true
+			}
+			if len(sensors) > 0 && !sensors[r.SensorID] {
+				return true
+			}
+
+			readings = append(readings, entities.Reading{SensorID: r.SensorID, Value: r.Value, Timestamp: r.Timestamp})
+			return limit <= 0 || len(readings) <= limit
+		})
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 && len(readings) > limit {
+			return nil, ErrTooManyReadings
+		}
+	}
+
+	sort.SliceStable(readings, func(i, j int) bool {
+		return readings[i].Timestamp.Before(readings[j].Timestamp)
+	})
+
+	return readings, nil
+}
+
+// Close closes the current segment
+func (s *SegmentHistoryStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.current == nil {
+		return nil
+	}
+
+	err := s.current.Close()
+	s.current = nil
+	return err
+}
+
+// load restores the existing segments, sorted by their start time
+func (s *SegmentHistoryStore) load() error {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("error reading history directory: %w", err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) != segmentExt {
+			continue
+		}
+
+		nsec, err := strconv.ParseInt(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		seg := &segment{
+			path:     filepath.Join(s.dir, name),
+			start:    time.Unix(0, nsec),
+			size:     file.Size(),
+			modified: file.ModTime(),
+		}
+		err = readSegment(seg.path, func(r record) bool {
+			seg.include(r.Timestamp)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		s.segments = append(s.segments, seg)
+	}
+
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].start.Before(s.segments[j].start)
+	})
+
+	return nil
+}
+
+// rotate starts a new segment when there's no current segment or it has
+// reached its limits
+func (s *SegmentHistoryStore) rotate() error {
+	now := s.now()
+	if s.current != nil {
+		seg := s.segments[len(s.segments)-1]
+		full := s.retention.SegmentSize > 0 && seg.size >= s.retention.SegmentSize
+		expired := s.retention.SegmentDuration > 0 && now.Sub(seg.start) >= s.retention.SegmentDuration
+		if !full && !expired {
+			return nil
+		}
+
+		err := s.current.Close()
+		s.current = nil
+		if err != nil {
+			return fmt.Errorf("error closing history segment: %w", err)
+		}
+	}
+
+	// the segments are named by their start time, which must be unique
+	start := now
+	if len(s.segments) > 0 && !start.After(s.segments[len(s.segments)-1].start) {
+		start = s.segments[len(s.segments)-1].start.Add(time.Nanosecond)
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", start.UnixNano(), segmentExt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating history segment: %w", err)
+	}
+
+	s.current = file
+	s.segments = append(s.segments, &segment{path: path, start: start, modified: now})
+	return nil
+}
+
+// enforceRetention removes the oldest segments while they're older than the
+// maximum age or exceed the maximum size. The current segment is kept.
+func (s *SegmentHistoryStore) enforceRetention() {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+
+	now := s.now()
+	for len(s.segments) > 0 {
+		seg := s.segments[0]
+		if s.current != nil && len(s.segments) == 1 {
+			break
+		}
+
+		tooOld := s.retention.MaxAge > 0 && now.Sub(seg.modified) > s.retention.MaxAge
+		tooBig := s.retention.MaxSize > 0 && total > s.retention.MaxSize
+		if !tooOld && !tooBig {
+			break
+		}
+
+		// the segment is dropped even when it can't be removed, so the
+		// retention doesn't get stuck on it
+		_ = os.Remove(seg.path)
+		total -= seg.size
+		s.segments = s.segments[1:]
+	}
+}
+
+func (seg *segment) include(timestamp time.Time) {
+	if seg.firstRead.IsZero() || timestamp.Before(seg.firstRead) {
+		seg.firstRead = timestamp
+	}
+	if timestamp.After(seg.lastRead) {
+		seg.lastRead = timestamp
+	}
+}
+
+// readSegment calls fn with every record of the segment, until it returns
+// false. The lines which can't be parsed, such as a line partially written
+// when the service stopped, are skipped. A segment removed by the retention
+// has no records.
+func readSegment(path string, fn func(r record) bool) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening history segment: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordLineSize)
+	for scanner.Scan() {
+		var r record
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			continue
+		}
+
+		if !fn(r) {
+			break
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return fmt.Errorf("error reading history segment: %w", err)
+	}
+
+	return nil
+}
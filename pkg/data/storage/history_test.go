@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+var historyStart = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func newHistoryStore(t *testing.T, dir string, retention HistoryRetention, clock *time.Time) *SegmentHistoryStore {
+	store, err := NewSegmentHistoryStore(dir, retention)
+	assert.NoError(t, err)
+	store.now = func() time.Time { return *clock }
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func readingsAt(minutes ...int) []entities.Reading {
+	readings := []entities.Reading{}
+	for _, m := range minutes {
+		readings = append(readings, entities.Reading{SensorID: m % 2, Value: float64(m), Timestamp: historyStart.Add(time.Duration(m) * time.Minute)})
+	}
+	return readings
+}
+
+func segmentFiles(t *testing.T, dir string) int {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	assert.NoError(t, err)
+	return len(files)
+}
+
+func TestHistoryQuery(t *testing.T) {
+	clock := historyStart
+	store := newHistoryStore(t, tempDir(t), HistoryRetention{SegmentDuration: time.Hour}, &clock)
+	assert.NoError(t, store.Append("thing", readingsAt(0, 1, 2, 3)))
+	assert.NoError(t, store.Append("other-thing", readingsAt(1, 2)))
+	assert.NoError(t, store.Append("thing", readingsAt(4, 5)))
+
+	testCases := []struct {
+		name             string
+		sensorIDs        []int
+		from             time.Time
+		to               time.Time
+		expectedReadings []entities.Reading
+	}{
+		{
+			"every sensor in the window, including its limits",
+			nil,
+			historyStart.Add(time.Minute),
+			historyStart.Add(4 * time.Minute),
+			readingsAt(1, 2, 3, 4),
+		},
+		{
+			"only the requested sensors",
+			[]int{1},
+			historyStart,
+			historyStart.Add(time.Hour),
+			readingsAt(1, 3, 5),
+		},
+		{
+			"window without readings",
+			nil,
+			historyStart.Add(time.Hour),
+			historyStart.Add(2 * time.Hour),
+			[]entities.Reading{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			readings, err := store.Query("thing", tc.sensorIDs, tc.from, tc.to, 0)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedReadings, readings)
+		})
+	}
+}
+
+func TestHistoryQueryLimit(t *testing.T) {
+	clock := historyStart
+	store := newHistoryStore(t, tempDir(t), HistoryRetention{SegmentSize: 1}, &clock)
+	assert.NoError(t, store.Append("thing", readingsAt(0, 1)))
+	assert.NoError(t, store.Append("thing", readingsAt(2)))
+
+	readings, err := store.Query("thing", nil, historyStart, historyStart.Add(time.Hour), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, readingsAt(0, 1, 2), readings)
+
+	_, err = store.Query("thing", nil, historyStart, historyStart.Add(time.Hour), 2)
+	assert.Equal(t, ErrTooManyReadings, err)
+}
+
+func TestHistoryRotationUndefined(t *testing.T) {
+	_, err := NewSegmentHistoryStore(tempDir(t), HistoryRetention{MaxAge: time.Hour, MaxSize: 1024})
+
+	assert.Equal(t, ErrRotationUndefined, err)
+}
+
+func TestHistoryRetentionEnforcedOnAppend(t *testing.T) {
+	dir := tempDir(t)
+	clock := historyStart
+	store := newHistoryStore(t, dir, HistoryRetention{SegmentDuration: 10 * time.Minute, MaxAge: 2 * time.Minute}, &clock)
+	for _, m := range []int{0, 9, 10} {
+		clock = historyStart.Add(time.Duration(m) * time.Minute)
+		assert.NoError(t, store.Append("thing", readingsAt(m)))
+	}
+	assert.Equal(t, 2, segmentFiles(t, dir))
+
+	// the first segment gets too old while the current one isn't rotated
+	clock = historyStart.Add(12 * time.Minute)
+	assert.NoError(t, store.Append("thing", readingsAt(12)))
+
+	readings, err := store.Query("thing", nil, historyStart, historyStart.Add(time.Hour), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, readingsAt(10, 12), readings)
+	assert.Equal(t, 1, segmentFiles(t, dir))
+}
+
+func TestHistoryRotationAndRetention(t *testing.T) {
+	testCases := []struct {
+		name             string
+		retention        HistoryRetention
+		elapsed          time.Duration
+		expectedSegments int
+		expectedReadings []entities.Reading
+	}{
+		{
+			"segment reaching its size is rotated",
+			HistoryRetention{SegmentSize: 1},
+			0,
+			3,
+			readingsAt(0, 1, 2),
+		},
+		{
+			"segment reaching its duration is rotated",
+			HistoryRetention{SegmentDuration: time.Minute},
+			time.Minute,
+			3,
+			readingsAt(0, 1, 2),
+		},
+		{
+			"segments older than the maximum age are removed",
+			HistoryRetention{SegmentDuration: time.Minute, MaxAge: 90 * time.Second},
+			time.Minute,
+			2,
+			readingsAt(1, 2),
+		},
+		{
+			"oldest segments are removed when exceeding the maximum size",
+			HistoryRetention{SegmentSize: 1, MaxSize: 1},
+			0,
+			1,
+			readingsAt(2),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			clock := historyStart
+			store := newHistoryStore(t, dir, tc.retention, &clock)
+			for _, m := range []int{0, 1, 2} {
+				assert.NoError(t, store.Append("thing", readingsAt(m)))
+				clock = clock.Add(tc.elapsed)
+			}
+
+			readings, err := store.Query("thing", nil, historyStart, historyStart.Add(time.Hour), 0)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedReadings, readings)
+			assert.Equal(t, tc.expectedSegments, segmentFiles(t, dir))
+		})
+	}
+}
+
+func TestHistoryRestoresSegments(t *testing.T) {
+	dir := tempDir(t)
+	clock := historyStart
+	store := newHistoryStore(t, dir, HistoryRetention{SegmentDuration: time.Hour}, &clock)
+	assert.NoError(t, store.Append("thing", readingsAt(0, 1)))
+	assert.NoError(t, store.Close())
+
+	// simulates a line partially written when the service stopped
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	assert.NoError(t, err)
+	file, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"thingId":"thing","sens`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	clock = clock.Add(time.Minute)
+	restored := newHistoryStore(t, dir, HistoryRetention{SegmentDuration: time.Hour}, &clock)
+	assert.NoError(t, restored.Append("thing", readingsAt(2)))
+
+	readings, err := restored.Query("thing", nil, historyStart, historyStart.Add(time.Hour), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, readingsAt(0, 1, 2), readings)
+	assert.Equal(t, 2, segmentFiles(t, dir))
+
+	content, err := ioutil.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.NotEmpty(t, content)
+}
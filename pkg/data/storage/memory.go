@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+)
+
+// MemoryLastValueStore keeps the last values in memory, so they're lost when
+// the service is restarted
+type MemoryLastValueStore struct {
+	mutex  sync.RWMutex
+	values map[string][]entities.SensorValue
+}
+
+// NewMemoryLastValueStore creates a new MemoryLastValueStore instance
+func NewMemoryLastValueStore() *MemoryLastValueStore {
+	return &MemoryLastValueStore{values: map[string][]entities.SensorValue{}}
+}
+
+// Save stores the values, replacing the previous ones of the same sensors
+func (s *MemoryLastValueStore) Save(thingID string, values []entities.SensorValue) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[thingID] = merge(s.values[thingID], values)
+	return nil
+}
+
+// Close does nothing, since there's nothing to release
+func (s *MemoryLastValueStore) Close() error {
+	return nil
+}
+
+// Get returns the thing's last values sorted by the sensor ID
+func (s *MemoryLastValueStore) Get(thingID string) ([]entities.SensorValue, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make([]entities.SensorValue, len(s.values[thingID]))
+	copy(values, s.values[thingID])
+	return values, nil
+}
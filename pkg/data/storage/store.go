@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+)
+
+// LastValueStore represents the storage of the last value received from each
+// thing's sensor. The things are identified by their ID on the things
+// service, since the KNoT IDs are only unique for each user.
+type LastValueStore interface {
+	Save(thingID string, values []entities.SensorValue) error
+	Get(thingID string) ([]entities.SensorValue, error)
+	Close() error
+}
+
+// merge replaces the stored values by the received ones of the same sensor,
+// unless they're older, keeping the values sorted by the sensor ID
+func merge(stored, received []entities.SensorValue) []entities.SensorValue {
+	merged := make([]entities.SensorValue, 0, len(stored)+len(received))
+	merged = append(merged, stored...)
+	for _, value := range received {
+		idx := sort.Search(len(merged), func(i int) bool { return merged[i].SensorID >= value.SensorID })
+		if idx < len(merged) && merged[idx].SensorID == value.SensorID {
+			if !value.Timestamp.Before(merged[idx].Timestamp) {
+				merged[idx] = value
+			}
+			continue
+		}
+
+		merged = append(merged, entities.SensorValue{})
+		copy(merged[idx+1:], merged[idx:])
+		merged[idx] = value
+	}
+
+	return merged
+}
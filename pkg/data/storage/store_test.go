@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "last-values")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestLastValueStores(t *testing.T) {
+	first := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Minute)
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T) LastValueStore
+	}{
+		{
+			"memory",
+			func(t *testing.T) LastValueStore {
+				return NewMemoryLastValueStore()
+			},
+		},
+		{
+			"file",
+			func(t *testing.T) LastValueStore {
+				store, err := NewFileLastValueStore(filepath.Join(tempDir(t), "data", "last-values.json"), time.Millisecond)
+				assert.NoError(t, err)
+				t.Cleanup(func() { store.Close() })
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := tc.newStore(t)
+
+			assert.NoError(t, store.Save("thing", []entities.SensorValue{
+				{SensorID: 3, Value: float64(1), Timestamp: first},
+				{SensorID: 1, Value: true, Timestamp: first},
+			}))
+			assert.NoError(t, store.Save("thing", []entities.SensorValue{
+				{SensorID: 2, Value: "raw", Timestamp: second},
+				{SensorID: 3, Value: float64(2), Timestamp: second},
+			}))
+			assert.NoError(t, store.Save("other-thing", []entities.SensorValue{
+				{SensorID: 1, Value: false, Timestamp: second},
+			}))
+			// an older value doesn't replace a newer one
+			assert.NoError(t, store.Save("thing", []entities.SensorValue{
+				{SensorID: 2, Value: "old", Timestamp: first},
+			}))
+
+			values, err := store.Get("thing")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.SensorValue{
+				{SensorID: 1, Value: true, Timestamp: first},
+				{SensorID: 2, Value: "raw", Timestamp: second},
+				{SensorID: 3, Value: float64(2), Timestamp: second},
+			}, values)
+
+			values, err = store.Get("unknown-thing")
+			assert.NoError(t, err)
+			assert.Empty(t, values)
+		})
+	}
+}
+
+func TestFileLastValueStoreRestoresValues(t *testing.T) {
+	path := filepath.Join(tempDir(t), "last-values.json")
+	values := []entities.SensorValue{{SensorID: 1, Value: float64(12.5), Timestamp: time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)}}
+	store, err := NewFileLastValueStore(path, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save("thing", values))
+	assert.NoError(t, store.Close())
+
+	restored, err := NewFileLastValueStore(path, 0)
+	assert.NoError(t, err)
+
+	stored, err := restored.Get("thing")
+	assert.NoError(t, err)
+	assert.Equal(t, values, stored)
+}
+
+func TestFileLastValueStoreFlushesPeriodically(t *testing.T) {
+	path := filepath.Join(tempDir(t), "last-values.json")
+	values := []entities.SensorValue{{SensorID: 1, Value: float64(12.5), Timestamp: time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)}}
+	store, err := NewFileLastValueStore(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer store.Close()
+	assert.NoError(t, store.Save("thing", values))
+
+	var stored []entities.SensorValue
+	for i := 0; i < 100 && len(stored) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		restored, err := NewFileLastValueStore(path, 0)
+		assert.NoError(t, err)
+		stored, err = restored.Get("thing")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, values, stored)
+}
@@ -5,6 +5,7 @@ type Logger interface {
 	Info(...interface{})
 	Infof(string, ...interface{})
 	Debug(...interface{})
+	Debugf(string, ...interface{})
 	Warn(...interface{})
 	Error(...interface{})
 	Errorf(string, ...interface{})
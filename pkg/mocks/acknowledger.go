@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeAcknowledger represents a mocking type for the message acknowledger
+type FakeAcknowledger struct {
+	mock.Mock
+}
+
+// Ack provides a mock function to acknowledge a message
+func (fa *FakeAcknowledger) Ack() error {
+	ret := fa.Called()
+	return ret.Error(0)
+}
+
+// Requeue provides a mock function to requeue a message
+func (fa *FakeAcknowledger) Requeue() error {
+	ret := fa.Called()
+	return ret.Error(0)
+}
+
+// Retry provides a mock function to retry a message after the delay
+func (fa *FakeAcknowledger) Retry(delay time.Duration) error {
+	ret := fa.Called(delay)
+	return ret.Error(0)
+}
+
+// DeadLetter provides a mock function to dead-letter a message
+func (fa *FakeAcknowledger) DeadLetter(reason string) error {
+	ret := fa.Called(reason)
+	return ret.Error(0)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/alarm/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeAlarmPublisher represents a mocking type for the alarms events publisher
+type FakeAlarmPublisher struct {
+	mock.Mock
+}
+
+// PublishAlarmRaised provides a mock function to send an alarm raised event
+func (fap *FakeAlarmPublisher) PublishAlarmRaised(alarm entities.Alarm) error {
+	ret := fap.Called(alarm)
+	return ret.Error(0)
+}
+
+// PublishAlarmCleared provides a mock function to send an alarm cleared event
+func (fap *FakeAlarmPublisher) PublishAlarmCleared(alarm entities.Alarm) error {
+	ret := fap.Called(alarm)
+	return ret.Error(0)
+}
+
+// PublishAlarmAcknowledged provides a mock function to send an alarm
+// acknowledged event
+func (fap *FakeAlarmPublisher) PublishAlarmAcknowledged(alarm entities.Alarm) error {
+	ret := fap.Called(alarm)
+	return ret.Error(0)
+}
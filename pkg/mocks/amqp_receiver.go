@@ -15,3 +15,15 @@ func (f *FakeAmqpReceiver) OnMessage(msgChan chan network.InMsg, queueName strin
 	args := f.Called(msgChan, queueName, exchangeName, exchangeType, key)
 	return args.Error(0)
 }
+
+// OnTransientMessage provides a mock function to receive the messages while connected
+func (f *FakeAmqpReceiver) OnTransientMessage(msgChan chan network.InMsg, exchangeName string, exchangeType string, key string) error {
+	args := f.Called(msgChan, exchangeName, exchangeType, key)
+	return args.Error(0)
+}
+
+// StopConsuming provides a mock function to stop receiving messages
+func (f *FakeAmqpReceiver) StopConsuming() error {
+	args := f.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,65 @@
+package mocks
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeCommandTracker represents a mocking type for the commands tracker
+type FakeCommandTracker struct {
+	mock.Mock
+}
+
+// Track provides a mock function to track the command
+func (fct *FakeCommandTracker) Track(command *entities.Command) error {
+	ret := fct.Called(command)
+	return ret.Error(0)
+}
+
+// Queue provides a mock function to queue the command
+func (fct *FakeCommandTracker) Queue(command *entities.Command) error {
+	ret := fct.Called(command)
+	return ret.Error(0)
+}
+
+// Dequeue provides a mock function to dequeue the thing's commands
+func (fct *FakeCommandTracker) Dequeue(thingToken string) ([]entities.Command, error) {
+	ret := fct.Called(thingToken)
+	return ret.Get(0).([]entities.Command), ret.Error(1)
+}
+
+// Fail provides a mock function to fail the command
+func (fct *FakeCommandTracker) Fail(commandID string, reason error) {
+	fct.Called(commandID, reason)
+}
+
+// FakeCommandController represents a mocking type for the commands controller
+type FakeCommandController struct {
+	mock.Mock
+}
+
+// Acknowledge provides a mock function to handle the command acknowledgement
+func (fcc *FakeCommandController) Acknowledge(body []byte, authorization string) error {
+	ret := fcc.Called(body, authorization)
+	return ret.Error(0)
+}
+
+// FakeCommandPublisher represents a mocking type for the commands events
+// publisher
+type FakeCommandPublisher struct {
+	mock.Mock
+}
+
+// PublishCommandCompleted provides a mock function to send the command
+// completed event
+func (fcp *FakeCommandPublisher) PublishCommandCompleted(command entities.Command) error {
+	ret := fcp.Called(command)
+	return ret.Error(0)
+}
+
+// PublishCommandFailed provides a mock function to send the command failed
+// event
+func (fcp *FakeCommandPublisher) PublishCommandFailed(command entities.Command) error {
+	ret := fcp.Called(command)
+	return ret.Error(0)
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"time"
+
+	dataEntities "github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeDataListener represents a mocking type for the published data listeners
+type FakeDataListener struct {
+	mock.Mock
+}
+
+// OnDataPublished provides a mock function to receive the published data
+func (fdl *FakeDataListener) OnDataPublished(thing *entities.Thing, data []entities.Data) {
+	fdl.Called(thing, data)
+}
+
+// FakeDataController represents a mocking type for the data commands controller
+type FakeDataController struct {
+	mock.Mock
+}
+
+// GetLastValues provides a mock function to handle the last values command
+func (fdc *FakeDataController) GetLastValues(body []byte, authorization, replyTo, corrID string) error {
+	ret := fdc.Called(body, authorization, replyTo, corrID)
+	return ret.Error(0)
+}
+
+// GetHistory provides a mock function to handle the history command
+func (fdc *FakeDataController) GetHistory(body []byte, authorization, replyTo, corrID string) error {
+	ret := fdc.Called(body, authorization, replyTo, corrID)
+	return ret.Error(0)
+}
+
+// FakeHistoryStore represents a mocking type for the history storage
+type FakeHistoryStore struct {
+	mock.Mock
+}
+
+// Append provides a mock function to store the readings
+func (fhs *FakeHistoryStore) Append(thingID string, readings []dataEntities.Reading) error {
+	ret := fhs.Called(thingID, readings)
+	return ret.Error(0)
+}
+
+// Query provides a mock function to query the stored readings
+func (fhs *FakeHistoryStore) Query(thingID string, sensorIDs []int, from, to time.Time, limit int) ([]dataEntities.Reading, error) {
+	ret := fhs.Called(thingID, sensorIDs, from, to, limit)
+	return ret.Get(0).([]dataEntities.Reading), ret.Error(1)
+}
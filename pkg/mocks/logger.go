@@ -16,6 +16,9 @@ func (fl *FakeLogger) Infof(string, ...interface{}) {}
 // Debug provides a mock function for the debugging Info capability
 func (fl *FakeLogger) Debug(...interface{}) {}
 
+// Debugf provides a mock function for the debugging Info capability
+func (fl *FakeLogger) Debugf(string, ...interface{}) {}
+
 // Warn provides a mock function for the debugging Info capability
 func (fl *FakeLogger) Warn(...interface{}) {}
 
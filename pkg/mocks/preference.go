@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeUnitPreferences represents a mocking type for the users' unit
+// preferences
+type FakeUnitPreferences struct {
+	mock.Mock
+}
+
+// PreferredUnits provides a mock function to get the user's preferred units
+func (f *FakeUnitPreferences) PreferredUnits(authorization string) (map[int]int, error) {
+	ret := f.Called(authorization)
+	return ret.Get(0).(map[int]int), ret.Error(1)
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// FakePresenceTracker represents a mocking type for the things presence tracker
+type FakePresenceTracker struct {
+	mock.Mock
+}
+
+// Seen provides a mock function to record the thing's activity
+func (fpt *FakePresenceTracker) Seen(thingID, mainfluxID string) bool {
+	ret := fpt.Called(thingID, mainfluxID)
+	return ret.Bool(0)
+}
+
+// Remove provides a mock function to forget the thing
+func (fpt *FakePresenceTracker) Remove(mainfluxID string) {
+	fpt.Called(mainfluxID)
+}
+
+// Presence provides a mock function to return the thing's presence
+func (fpt *FakePresenceTracker) Presence(mainfluxID string) entities.Presence {
+	ret := fpt.Called(mainfluxID)
+	return ret.Get(0).(entities.Presence)
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 	"github.com/stretchr/testify/mock"
 )
@@ -26,21 +28,33 @@ func (fp *FakePublisher) PublishUnregisteredDevice(thingID string, err error) er
 	return ret.Error(0)
 }
 
+// PublishRegisteredBatch provides a mock function to send a register devices batch response
+func (fp *FakePublisher) PublishRegisteredBatch(results []entities.BatchResult, err error) error {
+	ret := fp.Called(results, err)
+	return ret.Error(0)
+}
+
+// PublishUnregisteredBatch provides a mock function to send an unregister devices batch response
+func (fp *FakePublisher) PublishUnregisteredBatch(results []entities.BatchResult, err error) error {
+	ret := fp.Called(results, err)
+	return ret.Error(0)
+}
+
 // PublishUpdatedSchema provides a mock function to send an update schema response
-func (fp *FakePublisher) PublishUpdatedSchema(thingID string, schema []entities.Schema, err error) error {
-	ret := fp.Called(thingID, schema, err)
+func (fp *FakePublisher) PublishUpdatedSchema(thingID string, schema []entities.Schema, version *entities.SchemaVersion, err error) error {
+	ret := fp.Called(thingID, schema, version, err)
 	return ret.Error(0)
 }
 
 // PublishUpdateData provides a mock function to send an update data command
-func (fp *FakePublisher) PublishUpdateData(thingID string, data []entities.Data) error {
-	args := fp.Called(thingID, data)
+func (fp *FakePublisher) PublishUpdateData(thingID, commandID string, data []entities.Data) error {
+	args := fp.Called(thingID, commandID, data)
 	return args.Error(0)
 }
 
 // PublishRequestData provides a mock function to send a request data command
-func (fp *FakePublisher) PublishRequestData(thingID string, sensorIds []int) error {
-	args := fp.Called(thingID, sensorIds)
+func (fp *FakePublisher) PublishRequestData(thingID, commandID string, sensorIds []int) error {
+	args := fp.Called(thingID, commandID, sensorIds)
 	return args.Error(0)
 }
 
@@ -49,3 +63,15 @@ func (fp *FakePublisher) PublishPublishedData(thingID, token string, data []enti
 	args := fp.Called(thingID, data)
 	return args.Error(0)
 }
+
+// PublishDeviceOnline provides a mock function to send a device online event
+func (fp *FakePublisher) PublishDeviceOnline(thingID string, lastSeen time.Time) error {
+	args := fp.Called(thingID, lastSeen)
+	return args.Error(0)
+}
+
+// PublishDeviceOffline provides a mock function to send a device offline event
+func (fp *FakePublisher) PublishDeviceOffline(thingID string, lastSeen time.Time) error {
+	args := fp.Called(thingID, lastSeen)
+	return args.Error(0)
+}
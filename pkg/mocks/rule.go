@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"time"
+
+	ruleEntities "github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeRulePublisher represents a mocking type for the rules events publisher
+type FakeRulePublisher struct {
+	mock.Mock
+}
+
+// PublishRuleTriggered provides a mock function to send a rule triggered event
+func (frp *FakeRulePublisher) PublishRuleTriggered(rule ruleEntities.Rule, data []entities.Data, triggeredAt time.Time) error {
+	ret := frp.Called(rule, data, triggeredAt)
+	return ret.Error(0)
+}
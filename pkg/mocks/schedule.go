@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeScheduleController represents a mocking type for the schedules
+// controller
+type FakeScheduleController struct {
+	mock.Mock
+}
+
+// Create provides a mock function to handle the create schedule command
+func (fsc *FakeScheduleController) Create(body []byte, authorization, replyTo, corrID string) error {
+	ret := fsc.Called(body, authorization, replyTo, corrID)
+	return ret.Error(0)
+}
+
+// Update provides a mock function to handle the update schedule command
+func (fsc *FakeScheduleController) Update(body []byte, authorization, replyTo, corrID string) error {
+	ret := fsc.Called(body, authorization, replyTo, corrID)
+	return ret.Error(0)
+}
+
+// Delete provides a mock function to handle the delete schedule command
+func (fsc *FakeScheduleController) Delete(body []byte, authorization, replyTo, corrID string) error {
+	ret := fsc.Called(body, authorization, replyTo, corrID)
+	return ret.Error(0)
+}
+
+// List provides a mock function to handle the list schedules command
+func (fsc *FakeScheduleController) List(authorization, replyTo, corrID string) error {
+	ret := fsc.Called(authorization, replyTo, corrID)
+	return ret.Error(0)
+}
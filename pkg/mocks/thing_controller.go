@@ -32,6 +32,24 @@ func (f *FakeController) Unregister(body []byte, authorizationHeader string) err
 	return ret.Error(0)
 }
 
+// RegisterBatch provides a mock function to not return error
+func (f *FakeController) RegisterBatch(body []byte, authorizationHeader string) error {
+	if len(body) == 0 {
+		return errEmptyBody
+	}
+	ret := f.Called()
+	return ret.Error(0)
+}
+
+// UnregisterBatch provides a mock function to not return error
+func (f *FakeController) UnregisterBatch(body []byte, authorizationHeader string) error {
+	if len(body) == 0 {
+		return errEmptyBody
+	}
+	ret := f.Called()
+	return ret.Error(0)
+}
+
 // UpdateSchema provides a mock function to not return error
 func (f *FakeController) UpdateSchema(body []byte, authorizationHeader string) error {
 	if len(body) == 0 {
@@ -53,6 +71,12 @@ func (f *FakeController) ListDevices(authorization string, replyTo, corrID strin
 	return ret.Error(0)
 }
 
+// ListTypes provides a mock function to not return error
+func (f *FakeController) ListTypes(authorization string, replyTo, corrID string) error {
+	ret := f.Called()
+	return ret.Error(0)
+}
+
 // PublishData provides a mock function to not return error
 func (f *FakeController) PublishData(body []byte, authorization string) error {
 	if len(body) == 0 {
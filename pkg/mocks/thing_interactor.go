@@ -0,0 +1,89 @@
+package mocks
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+// FakeThingInteractor represents a mocking type for the thing's use cases
+type FakeThingInteractor struct {
+	mock.Mock
+}
+
+// Register provides a mock function to register a thing
+func (fti *FakeThingInteractor) Register(authorization, id, name string) error {
+	ret := fti.Called(authorization, id, name)
+	return ret.Error(0)
+}
+
+// Unregister provides a mock function to unregister a thing
+func (fti *FakeThingInteractor) Unregister(authorization, id string) error {
+	ret := fti.Called(authorization, id)
+	return ret.Error(0)
+}
+
+// UpdateSchema provides a mock function to update the thing's schema
+func (fti *FakeThingInteractor) UpdateSchema(authorization, id string, schemaList []entities.Schema) error {
+	ret := fti.Called(authorization, id, schemaList)
+	return ret.Error(0)
+}
+
+// List provides a mock function to list the registered things
+func (fti *FakeThingInteractor) List(authorization string) ([]*entities.Thing, error) {
+	ret := fti.Called(authorization)
+	return ret.Get(0).([]*entities.Thing), ret.Error(1)
+}
+
+// Get provides a mock function to get a registered thing
+func (fti *FakeThingInteractor) Get(authorization, id string) (*entities.Thing, error) {
+	ret := fti.Called(authorization, id)
+	return ret.Get(0).(*entities.Thing), ret.Error(1)
+}
+
+// RequestData provides a mock function to request the thing's data
+func (fti *FakeThingInteractor) RequestData(authorization, thingID, commandID string, sensorIds []int) error {
+	ret := fti.Called(authorization, thingID, commandID, sensorIds)
+	return ret.Error(0)
+}
+
+// UpdateData provides a mock function to update the thing's data
+func (fti *FakeThingInteractor) UpdateData(authorization, thingID, commandID string, data []entities.Data) error {
+	ret := fti.Called(authorization, thingID, commandID, data)
+	return ret.Error(0)
+}
+
+// PublishData provides a mock function to publish the thing's data
+func (fti *FakeThingInteractor) PublishData(authorization, thingID string, data []entities.Data) error {
+	ret := fti.Called(authorization, thingID, data)
+	return ret.Error(0)
+}
+
+// Auth provides a mock function to authenticate a thing
+func (fti *FakeThingInteractor) Auth(authorization, id string) error {
+	ret := fti.Called(authorization, id)
+	return ret.Error(0)
+}
+
+// ListTypes provides a mock function to list the sensor types
+func (fti *FakeThingInteractor) ListTypes(authorization string) (*entities.TypeRegistry, error) {
+	ret := fti.Called(authorization)
+	return ret.Get(0).(*entities.TypeRegistry), ret.Error(1)
+}
+
+// ListSchemaVersions provides a mock function to list the thing's schema versions
+func (fti *FakeThingInteractor) ListSchemaVersions(authorization, id string) ([]entities.SchemaVersion, error) {
+	ret := fti.Called(authorization, id)
+	return ret.Get(0).([]entities.SchemaVersion), ret.Error(1)
+}
+
+// RegisterBatch provides a mock function to register several things
+func (fti *FakeThingInteractor) RegisterBatch(authorization string, things []entities.Thing) error {
+	ret := fti.Called(authorization, things)
+	return ret.Error(0)
+}
+
+// UnregisterBatch provides a mock function to unregister several things
+func (fti *FakeThingInteractor) UnregisterBatch(authorization string, ids []string) error {
+	ret := fti.Called(authorization, ids)
+	return ret.Error(0)
+}
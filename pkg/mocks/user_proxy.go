@@ -31,3 +31,9 @@ func (fup *FakeUserProxy) CreateToken(user entities.User) (string, error) {
 	args := fup.Called(user)
 	return args.String(0), args.Error(1)
 }
+
+// Identify provides a mock function to identify the token's user
+func (fup *FakeUserProxy) Identify(token string) (string, error) {
+	args := fup.Called(token)
+	return args.String(0), args.Error(1)
+}
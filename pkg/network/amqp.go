@@ -1,6 +1,13 @@
 package network
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
 	"github.com/cenkalti/backoff/v4"
 
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
@@ -10,7 +17,7 @@ import (
 // QueueService is the interface that handles remote queue service
 type QueueService interface {
 	Start(started chan bool)
-	Stop()
+	Stop(ctx context.Context)
 	GetSender() AmqpSender
 	GetReceiver() AmqpReceiver
 }
@@ -23,28 +30,170 @@ type AmqpSender interface {
 // AmqpReceiver is the interface to receive amqp messages
 type AmqpReceiver interface {
 	OnMessage(msgChan chan InMsg, queueName, exchangeName, exchangeType, key string) error
+	OnTransientMessage(msgChan chan InMsg, exchangeName, exchangeType, key string) error
+	StopConsuming() error
+}
+
+// eventExchanges are the exchanges the events are published to, along with
+// their types. They are declared when connecting, so the clients can bind to
+// them before the first event is published.
+var eventExchanges = map[string]string{
+	"data.published": "fanout",
+	"rule.triggered": "fanout",
+	"alarm":          "direct",
+	"command":        "direct",
 }
 
 // Amqp handles the connection, queues and exchanges declared
 type Amqp struct {
-	url     string
-	logger  logging.Logger
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	queue   *amqp.Queue
+	url            string
+	confirmTimeout time.Duration
+	prefetch       int
+	logger         logging.Logger
+	conn           *amqp.Connection
+	channel        *amqp.Channel
+	queue          *amqp.Queue
+	mutex          sync.Mutex
+	publishSeq     uint64
+	subscriptions  []subscription
+	consuming      map[string]string
+	stopConsuming  bool
+	stopped        bool
+
+	// pending holds the publishes waiting for the broker's confirmation, by
+	// their delivery tag. It has its own lock, so the confirmations are
+	// dispatched while a message is being published.
+	pendingMutex sync.Mutex
+	pending      map[uint64]chan error
+	publishing   sync.WaitGroup
 }
 
-// InMsg represents the message received from the AMQP broker
-type InMsg struct {
+// subscription represents a registration made through OnMessage or
+// OnTransientMessage, which is replayed on every new channel
+type subscription struct {
+	msgChan      chan InMsg
+	queueName    string
+	exchangeName string
+	exchangeType string
+	key          string
+	transient    bool
+}
+
+var (
+	// ErrUnroutable is returned when the broker can't route a published message to any queue
+	ErrUnroutable = errors.New("message unroutable")
+
+	// ErrPublishNacked is returned when the broker refuses to take responsibility for a published message
+	ErrPublishNacked = errors.New("message not confirmed by the broker")
+
+	// ErrConfirmTimeout is returned when the broker doesn't confirm a published message in time
+	ErrConfirmTimeout = errors.New("timeout waiting message confirmation")
+)
+
+// UnroutableError represents a message returned by the broker because it
+// couldn't be routed to any queue
+type UnroutableError struct {
 	Exchange   string
 	RoutingKey string
-	Headers    map[string]interface{}
-	Body       []byte
+	ReplyCode  uint16
+	ReplyText  string
+}
+
+func (e *UnroutableError) Error() string {
+	return fmt.Sprintf("message to exchange %q with routing key %q returned: %d %s",
+		e.Exchange, e.RoutingKey, e.ReplyCode, e.ReplyText)
 }
 
-// NewAmqp constructs the AMQP connection handler
-func NewAmqp(url string, logger logging.Logger) *Amqp {
-	return &Amqp{url, logger, nil, nil, nil}
+// Unwrap allows the error to be compared with ErrUnroutable
+func (e *UnroutableError) Unwrap() error {
+	return ErrUnroutable
+}
+
+// Acknowledger settles a message received from the AMQP broker
+type Acknowledger interface {
+	Ack() error
+	Requeue() error
+	Retry(delay time.Duration) error
+	DeadLetter(reason string) error
+}
+
+// InMsg represents the message received from the AMQP broker
+type InMsg struct {
+	Exchange     string
+	RoutingKey   string
+	Headers      map[string]interface{}
+	Body         []byte
+	Acknowledger Acknowledger
+}
+
+// Ack acknowledges the message was successfully handled
+func (m InMsg) Ack() error {
+	if m.Acknowledger == nil {
+		return nil
+	}
+	return m.Acknowledger.Ack()
+}
+
+// Requeue gives the message back to the broker so it can be delivered again
+func (m InMsg) Requeue() error {
+	if m.Acknowledger == nil {
+		return nil
+	}
+	return m.Acknowledger.Requeue()
+}
+
+// Retry delivers the message again after the delay, counting the retries
+func (m InMsg) Retry(delay time.Duration) error {
+	if m.Acknowledger == nil {
+		return nil
+	}
+	return m.Acknowledger.Retry(delay)
+}
+
+// Retries returns how many times the message was retried
+func (m InMsg) Retries() int {
+	switch retries := m.Headers[headerRetries].(type) {
+	case int:
+		return retries
+	case int32:
+		return int(retries)
+	case int64:
+		return int(retries)
+	default:
+		return 0
+	}
+}
+
+// DeadLetter routes the message to its queue's dead-letter exchange
+func (m InMsg) DeadLetter(reason string) error {
+	if m.Acknowledger == nil {
+		return nil
+	}
+	return m.Acknowledger.DeadLetter(reason)
+}
+
+// delivery settles a message on the channel it was received from. The
+// exchange and routing key are the ones the message was originally published
+// with, even when it was retried.
+type delivery struct {
+	amqp       *Amqp
+	queueName  string
+	exchange   string
+	routingKey string
+	msg        amqp.Delivery
+}
+
+const (
+	headerFailureReason      = "x-failure-reason"
+	headerOriginalExchange   = "x-original-exchange"
+	headerOriginalRoutingKey = "x-original-routing-key"
+	headerRetries            = "x-retries"
+)
+
+// NewAmqp constructs the AMQP connection handler. The prefetch limits how many
+// unacknowledged messages each consumer receives from the broker.
+func NewAmqp(url string, confirmTimeout time.Duration, prefetch int, logger logging.Logger) *Amqp {
+	return &Amqp{url: url, confirmTimeout: confirmTimeout, prefetch: prefetch, logger: logger}
 }
 
 // GetSender returns the sender
@@ -70,31 +219,114 @@ func (a *Amqp) Start(started chan bool) {
 	started <- true
 }
 
-// Stop closes the connection started
-func (a *Amqp) Stop() {
-	if a.conn != nil && !a.conn.IsClosed() {
-		a.conn.Close()
+// Stop waits the pending publishes to be confirmed, up to the context
+// deadline, and then closes the connection started. The messages published
+// afterwards are refused.
+func (a *Amqp) Stop(ctx context.Context) {
+	a.mutex.Lock()
+	a.stopped = true
+	a.mutex.Unlock()
+
+	flushed := make(chan struct{})
+	go func() {
+		a.publishing.Wait()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		a.logger.Debug("pending publishes flushed")
+	case <-ctx.Done():
+		a.logger.Errorf("error flushing pending publishes: %s", ctx.Err())
+	}
+
+	a.mutex.Lock()
+	conn, channel := a.conn, a.channel
+	a.mutex.Unlock()
+
+	if conn != nil && !conn.IsClosed() {
+		conn.Close()
 	}
 
-	if a.channel != nil {
-		a.channel.Close()
+	if channel != nil {
+		channel.Close()
 	}
 
 	a.logger.Debug("AMQP handler stopped")
 }
 
-// PublishPersistentMessage sends a persistent message to RabbitMQ
+// PublishPersistentMessage sends a persistent message to RabbitMQ and waits
+// until the broker confirms it. An UnroutableError is returned when there is
+// no queue bound to receive the message.
 func (a *Amqp) PublishPersistentMessage(exchange, exchangeType, key string, body []byte, headers amqp.Table) error {
-	err := a.declareExchange(exchange, exchangeType)
+	return a.publish(exchangeType, exchange, key, body, headers, "")
+}
+
+// publishDelayed sends a persistent message straight to the queue, through
+// the default exchange, which expires after the delay
+func (a *Amqp) publishDelayed(queueName string, body []byte, headers amqp.Table, delay time.Duration) error {
+	return a.publish("", "", queueName, body, headers, strconv.FormatInt(int64(delay/time.Millisecond), 10))
+}
+
+// publish sends the message and waits until the broker confirms it. The
+// exchange is declared first, unless its type is empty. The lock is only
+// held while sending, so the messages published concurrently are confirmed
+// together.
+func (a *Amqp) publish(exchangeType, exchange, key string, body []byte, headers amqp.Table, expiration string) error {
+	confirmed, err := a.send(exchangeType, exchange, key, body, headers, expiration)
 	if err != nil {
 		a.logger.Error(err)
 		return err
 	}
+	defer a.publishing.Done()
+
+	select {
+	case err = <-confirmed.result:
+		return err
+	case <-time.After(a.confirmTimeout):
+		a.pendingMutex.Lock()
+		delete(confirmed.pending, confirmed.deliveryTag)
+		a.pendingMutex.Unlock()
+		return ErrConfirmTimeout
+	}
+}
 
-	err = a.channel.Publish(
+// pendingPublish represents a message sent which the broker hasn't
+// confirmed yet, registered on the publishes pending on its channel
+type pendingPublish struct {
+	pending     map[uint64]chan error
+	deliveryTag uint64
+	result      chan error
+}
+
+// send declares the exchange, sends the message and registers it to wait
+// for the confirmation. The message ID is the delivery tag, so the
+// confirmation can be matched with the message returned when it's
+// unroutable.
+func (a *Amqp) send(exchangeType, exchange, key string, body []byte, headers amqp.Table, expiration string) (*pendingPublish, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stopped || a.channel == nil {
+		return nil, amqp.ErrClosed
+	}
+
+	if exchangeType != "" {
+		err := a.declareExchange(exchange, exchangeType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pending := &pendingPublish{a.pending, a.publishSeq + 1, make(chan error, 1)}
+	a.pendingMutex.Lock()
+	a.pending[pending.deliveryTag] = pending.result
+	a.pendingMutex.Unlock()
+
+	err := a.channel.Publish(
 		exchange,
 		key,
-		false, // mandatory
+		true,  // mandatory
 		false, // immediate
 		amqp.Publishing{
 			Headers:         headers,
@@ -103,25 +335,65 @@ func (a *Amqp) PublishPersistentMessage(exchange, exchangeType, key string, body
 			Body:            body,
 			DeliveryMode:    amqp.Persistent,
 			Priority:        0,
+			Expiration:      expiration,
+			MessageId:       strconv.FormatUint(pending.deliveryTag, 10),
 		},
 	)
 	if err != nil {
-		a.logger.Error(err)
-		return err
+		a.pendingMutex.Lock()
+		delete(a.pending, pending.deliveryTag)
+		a.pendingMutex.Unlock()
+		return nil, err
 	}
 
-	return nil
+	a.publishSeq++
+	a.publishing.Add(1)
+	return pending, nil
 }
 
-// OnMessage receive messages and put them on channel
+// OnMessage receive messages and put them on channel. The registration is
+// remembered, so the exchanges, queues, bindings and consumers are declared
+// again whenever the connection is restored.
 func (a *Amqp) OnMessage(msgChan chan InMsg, queueName, exchangeName, exchangeType, key string) error {
-	err := a.declareExchange(exchangeName, exchangeType)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	sub := subscription{msgChan, queueName, exchangeName, exchangeType, key, false}
+	return a.addSubscription(sub)
+}
+
+// OnTransientMessage delivers the messages published to the exchange while
+// the service is connected. They are consumed from an exclusive queue, which
+// the broker deletes when the connection closes, and don't need to be
+// acknowledged.
+func (a *Amqp) OnTransientMessage(msgChan chan InMsg, exchangeName, exchangeType, key string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	sub := subscription{msgChan, "", exchangeName, exchangeType, key, true}
+	return a.addSubscription(sub)
+}
+
+func (a *Amqp) addSubscription(sub subscription) error {
+	a.subscriptions = append(a.subscriptions, sub)
+	if a.channel == nil {
+		// not connected yet, the subscription is made when connected
+		return nil
+	}
+
+	return a.subscribe(sub)
+}
+
+// subscribe declares the subscription's exchange, queue and binding on the
+// current channel and starts consuming from the queue if it isn't yet
+func (a *Amqp) subscribe(sub subscription) error {
+	err := a.declareExchange(sub.exchangeName, sub.exchangeType)
 	if err != nil {
 		a.logger.Error(err)
 		return err
 	}
 
-	err = a.declareQueue(queueName)
+	queueName, err := a.declareSubscriptionQueue(sub)
 	if err != nil {
 		a.logger.Error(err)
 		return err
@@ -129,8 +401,8 @@ func (a *Amqp) OnMessage(msgChan chan InMsg, queueName, exchangeName, exchangeTy
 
 	err = a.channel.QueueBind(
 		queueName,
-		key,
-		exchangeName,
+		sub.key,
+		sub.exchangeName,
 		false, // noWait
 		nil,   // arguments
 	)
@@ -139,22 +411,90 @@ func (a *Amqp) OnMessage(msgChan chan InMsg, queueName, exchangeName, exchangeTy
 		return err
 	}
 
+	if a.stopConsuming || a.consuming[queueName] != "" {
+		return nil
+	}
+
+	consumerTag := "babeltower-" + queueName
 	deliveries, err := a.channel.Consume(
 		queueName,
-		"",    // consumerTag
-		true,  // noAck
-		false, // exclusive
-		false, // noLocal
-		false, // noWait
-		nil,   // arguments
+		consumerTag,
+		sub.transient, // noAck
+		false,         // exclusive
+		false,         // noLocal
+		false,         // noWait
+		nil,           // arguments
 	)
 	if err != nil {
 		a.logger.Error(err)
 		return err
 	}
 
-	go convertDeliveryToInMsg(deliveries, msgChan)
+	a.consuming[queueName] = consumerTag
+	go a.convertDeliveryToInMsg(deliveries, queueName, sub.transient, sub.msgChan)
+
+	return nil
+}
+
+// declareSubscriptionQueue declares the subscription's queue and returns its
+// name. Transient subscriptions use an exclusive queue named by the broker.
+func (a *Amqp) declareSubscriptionQueue(sub subscription) (string, error) {
+	if sub.transient {
+		queue, err := a.channel.QueueDeclare(
+			"",    // name generated by the broker
+			false, // durable
+			true,  // delete when unused
+			true,  // exclusive
+			false, // noWait
+			nil,   // arguments
+		)
+		return queue.Name, err
+	}
+
+	err := a.declareDeadLetter(sub.queueName)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.declareRetry(sub.queueName)
+	if err != nil {
+		return "", err
+	}
+
+	return sub.queueName, a.declareQueue(sub.queueName)
+}
+
+// StopConsuming cancels every consumer, so the broker stops delivering
+// messages while the ones already received can still be acknowledged
+func (a *Amqp) StopConsuming() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.stopConsuming = true
+	for queueName, consumerTag := range a.consuming {
+		err := a.channel.Cancel(consumerTag, false)
+		if err != nil {
+			return fmt.Errorf("error canceling consumer of %s: %w", queueName, err)
+		}
+		delete(a.consuming, queueName)
+	}
+
+	a.logger.Debug("AMQP consumers canceled")
+	return nil
+}
+
+// resubscribe replays every subscription on the current channel
+func (a *Amqp) resubscribe() error {
+	for _, sub := range a.subscriptions {
+		err := a.subscribe(sub)
+		if err != nil {
+			return err
+		}
+	}
 
+	if len(a.subscriptions) > 0 {
+		a.logger.Infof("%d subscriptions restored", len(a.subscriptions))
+	}
 	return nil
 }
 
@@ -165,31 +505,83 @@ func (a *Amqp) connect() error {
 		return err
 	}
 
-	a.conn = conn
-
-	channel, err := a.conn.Channel()
+	channel, err := a.openChannel(conn)
 	if err != nil {
 		a.logger.Error(err)
+		conn.Close()
 		return err
 	}
 
-	err = channel.ExchangeDeclare("data.published", "fanout", true, false, false, true, nil)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.conn = conn
+	a.channel = channel
+	a.publishSeq = 0
+	a.consuming = map[string]string{}
+
+	a.pendingMutex.Lock()
+	a.pending = map[uint64]chan error{}
+	a.pendingMutex.Unlock()
+	confirms := channel.NotifyPublish(make(chan amqp.Confirmation, 16))
+	returns := channel.NotifyReturn(make(chan amqp.Return, 16))
+	go a.dispatchConfirmations(a.pending, confirms, returns)
+
+	err = a.resubscribe()
 	if err != nil {
 		a.logger.Error(err)
+		conn.Close()
 		return err
 	}
 
+	go a.closeWhenChannelFails(conn, channel)
 	a.logger.Debug("AMQP handler connected")
-	a.channel = channel
 
 	return nil
 }
 
+// openChannel opens the channel on the connection, declaring the events'
+// exchanges and enabling the publisher confirms
+func (a *Amqp) openChannel(conn *amqp.Connection) (*amqp.Channel, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	for exchange, exchangeType := range eventExchanges {
+		err = channel.ExchangeDeclare(exchange, exchangeType, true, false, false, true, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = channel.Confirm(false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = channel.Qos(a.prefetch, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
 func (a *Amqp) notifyWhenClosed(started chan bool) {
-	errReason := <-a.conn.NotifyClose(make(chan *amqp.Error))
+	a.mutex.Lock()
+	conn := a.conn
+	a.mutex.Unlock()
+
+	errReason := <-conn.NotifyClose(make(chan *amqp.Error))
 	a.logger.Infof("AMQP connection closed: %s", errReason)
 	started <- false
-	if errReason != nil {
+
+	a.mutex.Lock()
+	stopped := a.stopped
+	a.mutex.Unlock()
+
+	if !stopped {
 		err := backoff.Retry(a.connect, backoff.NewExponentialBackOff())
 		if err != nil {
 			a.logger.Error(err)
@@ -202,6 +594,86 @@ func (a *Amqp) notifyWhenClosed(started chan bool) {
 	}
 }
 
+// closeWhenChannelFails closes the connection when the broker closes the
+// channel due to an error, so the connection and subscriptions are restored
+func (a *Amqp) closeWhenChannelFails(conn *amqp.Connection, channel *amqp.Channel) {
+	errReason := <-channel.NotifyClose(make(chan *amqp.Error, 1))
+	if errReason == nil || conn.IsClosed() {
+		return
+	}
+
+	a.logger.Errorf("AMQP channel closed: %s", errReason)
+	conn.Close()
+}
+
+// dispatchConfirmations sends the broker's confirmations of the channel to
+// the publishes waiting for them, until the channel is closed. The broker
+// sends back the unroutable messages before confirming them, so the returned
+// message is already available when the confirmation arrives.
+func (a *Amqp) dispatchConfirmations(pending map[uint64]chan error, confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) {
+	returned := map[string]amqp.Return{}
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+			returned[ret.MessageId] = ret
+		case confirmation, ok := <-confirms:
+			if !ok {
+				a.failPending(pending, amqp.ErrClosed)
+				return
+			}
+
+			collectReturns(returns, returned)
+			messageID := strconv.FormatUint(confirmation.DeliveryTag, 10)
+			var err error
+			if ret, ok := returned[messageID]; ok {
+				err = &UnroutableError{ret.Exchange, ret.RoutingKey, ret.ReplyCode, ret.ReplyText}
+				delete(returned, messageID)
+			}
+			if !confirmation.Ack {
+				err = ErrPublishNacked
+			}
+
+			a.pendingMutex.Lock()
+			result, ok := pending[confirmation.DeliveryTag]
+			delete(pending, confirmation.DeliveryTag)
+			a.pendingMutex.Unlock()
+			if ok {
+				result <- err
+			}
+		}
+	}
+}
+
+// failPending fails the publishes still waiting for the confirmation
+func (a *Amqp) failPending(pending map[uint64]chan error, err error) {
+	a.pendingMutex.Lock()
+	defer a.pendingMutex.Unlock()
+
+	for deliveryTag, result := range pending {
+		result <- err
+		delete(pending, deliveryTag)
+	}
+}
+
+// collectReturns keeps the returned messages already received
+func collectReturns(returns <-chan amqp.Return, returned map[string]amqp.Return) {
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				return
+			}
+			returned[ret.MessageId] = ret
+		default:
+			return
+		}
+	}
+}
+
 func (a *Amqp) declareExchange(name, exchangeType string) error {
 	return a.channel.ExchangeDeclare(
 		name,
@@ -228,8 +700,127 @@ func (a *Amqp) declareQueue(name string) error {
 	return err
 }
 
-func convertDeliveryToInMsg(deliveries <-chan amqp.Delivery, outMsg chan InMsg) {
+// declareDeadLetter declares the exchange and queue which keep the messages
+// rejected from the queue received as parameter
+func (a *Amqp) declareDeadLetter(queueName string) error {
+	name := deadLetterName(queueName)
+	err := a.declareExchange(name, "fanout")
+	if err != nil {
+		return err
+	}
+
+	_, err = a.channel.QueueDeclare(
+		name,
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	return a.channel.QueueBind(name, "", name, false, nil)
+}
+
+// declareRetry declares the queue which holds the messages being retried from
+// the queue received as parameter. The messages expire after their delay and
+// are sent back to the original queue through the default exchange.
+func (a *Amqp) declareRetry(queueName string) error {
+	_, err := a.channel.QueueDeclare(
+		retryName(queueName),
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // noWait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		},
+	)
+	return err
+}
+
+func (a *Amqp) convertDeliveryToInMsg(deliveries <-chan amqp.Delivery, queueName string, autoAck bool, outMsg chan InMsg) {
 	for d := range deliveries {
-		outMsg <- InMsg{d.Exchange, d.RoutingKey, d.Headers, d.Body}
+		msg := InMsg{Exchange: d.Exchange, RoutingKey: d.RoutingKey, Headers: d.Headers, Body: d.Body}
+		if msg.Retries() > 0 {
+			// the retried messages come back through the default exchange
+			msg.Exchange, _ = d.Headers[headerOriginalExchange].(string)
+			msg.RoutingKey, _ = d.Headers[headerOriginalRoutingKey].(string)
+		}
+		if !autoAck {
+			msg.Acknowledger = &delivery{a, queueName, msg.Exchange, msg.RoutingKey, d}
+		}
+		outMsg <- msg
 	}
 }
+
+func deadLetterName(queueName string) string {
+	return queueName + ".dead-letter"
+}
+
+func retryName(queueName string) string {
+	return queueName + ".retry"
+}
+
+// Ack acknowledges the delivery on the broker
+func (d *delivery) Ack() error {
+	return d.msg.Ack(false)
+}
+
+// Requeue rejects the delivery asking the broker to enqueue it again
+func (d *delivery) Requeue() error {
+	return d.msg.Nack(false, true)
+}
+
+// Retry publishes the delivery to the queue's retry queue, from which the
+// broker sends it back to the queue after the delay, and then acknowledges
+// it. If the message can't be republished, it's requeued right away.
+func (d *delivery) Retry(delay time.Duration) error {
+	headers := amqp.Table{}
+	for key, value := range d.msg.Headers {
+		headers[key] = value
+	}
+	headers[headerRetries] = int32(InMsg{Headers: d.msg.Headers}.Retries() + 1)
+	headers[headerOriginalExchange] = d.exchange
+	headers[headerOriginalRoutingKey] = d.routingKey
+
+	err := d.amqp.publishDelayed(retryName(d.queueName), d.msg.Body, headers, delay)
+	if err != nil {
+		nackErr := d.msg.Nack(false, true)
+		if nackErr != nil {
+			return fmt.Errorf("error requeueing message: %v: %w", nackErr, err)
+		}
+		return fmt.Errorf("error publishing to retry queue: %w", err)
+	}
+
+	return d.msg.Ack(false)
+}
+
+// DeadLetter publishes the delivery to the queue's dead-letter exchange with
+// the original headers plus the failure reason and then acknowledges it. If
+// the message can't be republished, it is rejected, which only dead-letters it
+// when a broker policy sets the queue's dead-letter exchange.
+func (d *delivery) DeadLetter(reason string) error {
+	headers := amqp.Table{}
+	for key, value := range d.msg.Headers {
+		headers[key] = value
+	}
+	headers[headerFailureReason] = reason
+	headers[headerOriginalExchange] = d.exchange
+	headers[headerOriginalRoutingKey] = d.routingKey
+
+	name := deadLetterName(d.queueName)
+	err := d.amqp.PublishPersistentMessage(name, "fanout", "", d.msg.Body, headers)
+	if err != nil {
+		nackErr := d.msg.Nack(false, false)
+		if nackErr != nil {
+			return fmt.Errorf("error rejecting message: %v: %w", nackErr, err)
+		}
+		return fmt.Errorf("error publishing to dead-letter exchange: %w", err)
+	}
+
+	return d.msg.Ack(false)
+}
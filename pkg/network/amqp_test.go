@@ -0,0 +1,47 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatchConfirmations(t *testing.T) {
+	a := &Amqp{}
+	pending := map[uint64]chan error{}
+	for tag := uint64(1); tag <= 4; tag++ {
+		pending[tag] = make(chan error, 1)
+	}
+	results := map[uint64]chan error{}
+	for tag, result := range pending {
+		results[tag] = result
+	}
+
+	confirms := make(chan amqp.Confirmation, 4)
+	returns := make(chan amqp.Return, 4)
+	done := make(chan struct{})
+	go func() {
+		a.dispatchConfirmations(pending, confirms, returns)
+		close(done)
+	}()
+
+	// the second message is returned before being confirmed, while the
+	// third one's confirmation is late
+	returns <- amqp.Return{MessageId: "2", Exchange: "device", RoutingKey: "device.online", ReplyCode: 312, ReplyText: "NO_ROUTE"}
+	confirms <- amqp.Confirmation{DeliveryTag: 2, Ack: true}
+	confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+	confirms <- amqp.Confirmation{DeliveryTag: 3, Ack: false}
+
+	assert.NoError(t, <-results[1])
+	err := <-results[2]
+	assert.True(t, errors.Is(err, ErrUnroutable))
+	assert.Equal(t, &UnroutableError{"device", "device.online", 312, "NO_ROUTE"}, err)
+	assert.Equal(t, ErrPublishNacked, <-results[3])
+
+	close(confirms)
+	<-done
+	assert.Equal(t, amqp.ErrClosed, <-results[4])
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,421 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/streadway/amqp"
+)
+
+// MemoryScheme is the URL scheme which selects the in-process broker
+const MemoryScheme = "memory://"
+
+const (
+	exchangeTypeDirect = "direct"
+	exchangeTypeFanout = "fanout"
+)
+
+var (
+	// ErrExchangeNotFound is returned when using an exchange which wasn't declared
+	ErrExchangeNotFound = errors.New("exchange not found")
+
+	// ErrQueueNotFound is returned when using a queue which wasn't declared
+	ErrQueueNotFound = errors.New("queue not found")
+
+	// ErrExchangeTypeMismatch is returned when declaring an exchange already declared with another type
+	ErrExchangeTypeMismatch = errors.New("exchange already declared with another type")
+
+	// ErrExchangeTypeUnsupported is returned when declaring an exchange with a type other than direct or fanout
+	ErrExchangeTypeUnsupported = errors.New("exchange type not supported")
+)
+
+var (
+	memoryBrokers      = map[string]*Memory{}
+	memoryBrokersMutex sync.Mutex
+)
+
+// Memory is an in-process broker with direct and fanout exchanges semantics,
+// which can replace RabbitMQ on tests and local runs
+type Memory struct {
+	logger        logging.Logger
+	mutex         sync.Mutex
+	exchanges     map[string]*memoryExchange
+	queues        map[string]*memoryQueue
+	stopConsuming bool
+	lastQueueID   int
+}
+
+type memoryExchange struct {
+	kind     string
+	bindings []memoryBinding
+}
+
+type memoryBinding struct {
+	queue string
+	key   string
+}
+
+type memoryQueue struct {
+	name      string
+	messages  []InMsg
+	consumers []chan InMsg
+	next      int
+	cond      *sync.Cond
+}
+
+// memoryDelivery settles a message delivered by the in-process broker
+type memoryDelivery struct {
+	memory *Memory
+	queue  *memoryQueue
+	msg    InMsg
+}
+
+// NewMemory creates a new in-process broker
+func NewMemory(logger logging.Logger) *Memory {
+	return &Memory{
+		logger:    logger,
+		exchanges: map[string]*memoryExchange{},
+		queues:    map[string]*memoryQueue{},
+	}
+}
+
+// GetMemory returns the in-process broker identified by the URL, creating it
+// on the first call, so the service and its clients share the same broker
+func GetMemory(url string, logger logging.Logger) *Memory {
+	memoryBrokersMutex.Lock()
+	defer memoryBrokersMutex.Unlock()
+
+	m, ok := memoryBrokers[url]
+	if !ok {
+		m = NewMemory(logger)
+		memoryBrokers[url] = m
+	}
+
+	return m
+}
+
+// IsMemoryURL reports whether the URL selects the in-process broker
+func IsMemoryURL(url string) bool {
+	return strings.HasPrefix(url, MemoryScheme)
+}
+
+// Start starts the broker
+func (m *Memory) Start(started chan bool) {
+	for exchange, exchangeType := range eventExchanges {
+		err := m.DeclareExchange(exchange, exchangeType)
+		if err != nil {
+			m.logger.Error(err)
+			started <- false
+			return
+		}
+	}
+
+	m.mutex.Lock()
+	m.stopConsuming = false
+	m.mutex.Unlock()
+
+	m.logger.Debug("in-memory broker started")
+	started <- true
+}
+
+// Stop stops delivering messages to the consumers
+func (m *Memory) Stop(ctx context.Context) {
+	err := m.StopConsuming()
+	if err != nil {
+		m.logger.Error(err)
+	}
+
+	m.logger.Debug("in-memory broker stopped")
+}
+
+// GetSender returns the sender
+func (m *Memory) GetSender() AmqpSender {
+	return m
+}
+
+// GetReceiver returns the receiver
+func (m *Memory) GetReceiver() AmqpReceiver {
+	return m
+}
+
+// PublishPersistentMessage declares the exchange and routes the message to
+// the bound queues
+func (m *Memory) PublishPersistentMessage(exchange, exchangeType, key string, body []byte, headers amqp.Table) error {
+	err := m.DeclareExchange(exchange, exchangeType)
+	if err != nil {
+		return err
+	}
+
+	return m.Publish(exchange, key, body, headers)
+}
+
+// OnMessage declares the exchange, the queue and its dead-letter exchange,
+// binds them and delivers the queue's messages to the channel
+func (m *Memory) OnMessage(msgChan chan InMsg, queueName, exchangeName, exchangeType, key string) error {
+	err := m.DeclareExchange(exchangeName, exchangeType)
+	if err != nil {
+		return err
+	}
+
+	err = m.declareDeadLetter(queueName)
+	if err != nil {
+		return err
+	}
+
+	m.DeclareQueue(queueName)
+	err = m.BindQueue(queueName, exchangeName, key)
+	if err != nil {
+		return err
+	}
+
+	return m.Consume(queueName, msgChan)
+}
+
+// OnTransientMessage declares the exchange and a queue with a generated name,
+// binds them and delivers the queue's messages to the channel
+func (m *Memory) OnTransientMessage(msgChan chan InMsg, exchangeName, exchangeType, key string) error {
+	err := m.DeclareExchange(exchangeName, exchangeType)
+	if err != nil {
+		return err
+	}
+
+	queueName := m.DeclareQueue("")
+	err = m.BindQueue(queueName, exchangeName, key)
+	if err != nil {
+		return err
+	}
+
+	return m.Consume(queueName, msgChan)
+}
+
+// StopConsuming stops delivering messages to the consumers. The messages not
+// delivered yet are kept on the queues.
+func (m *Memory) StopConsuming() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stopConsuming = true
+	for _, q := range m.queues {
+		q.cond.Broadcast()
+	}
+
+	return nil
+}
+
+// DeclareExchange creates the exchange if it doesn't exist
+func (m *Memory) DeclareExchange(name, kind string) error {
+	if kind != exchangeTypeDirect && kind != exchangeTypeFanout {
+		return fmt.Errorf("%w: %s", ErrExchangeTypeUnsupported, kind)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e, ok := m.exchanges[name]
+	if !ok {
+		m.exchanges[name] = &memoryExchange{kind: kind}
+		return nil
+	}
+
+	if e.kind != kind {
+		return fmt.Errorf("%w: %s is %s", ErrExchangeTypeMismatch, name, e.kind)
+	}
+
+	return nil
+}
+
+// DeclareQueue creates the queue if it doesn't exist. When the name is empty,
+// a unique name is generated. The queue's name is returned.
+func (m *Memory) DeclareQueue(name string) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if name == "" {
+		m.lastQueueID++
+		name = fmt.Sprintf("memory.gen-%d", m.lastQueueID)
+	}
+
+	if _, ok := m.queues[name]; !ok {
+		q := &memoryQueue{name: name}
+		q.cond = sync.NewCond(&m.mutex)
+		m.queues[name] = q
+		go m.deliver(q)
+	}
+
+	return name
+}
+
+// BindQueue routes the messages published to the exchange with the key to the queue
+func (m *Memory) BindQueue(queueName, exchangeName, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e, ok := m.exchanges[exchangeName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrExchangeNotFound, exchangeName)
+	}
+
+	if _, ok := m.queues[queueName]; !ok {
+		return fmt.Errorf("%w: %s", ErrQueueNotFound, queueName)
+	}
+
+	binding := memoryBinding{queueName, key}
+	for _, b := range e.bindings {
+		if b == binding {
+			return nil
+		}
+	}
+
+	e.bindings = append(e.bindings, binding)
+	return nil
+}
+
+// Consume delivers the queue's messages to the channel. When the queue has
+// more than one consumer, the messages are delivered in a round-robin fashion.
+func (m *Memory) Consume(queueName string, msgChan chan InMsg) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	q, ok := m.queues[queueName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrQueueNotFound, queueName)
+	}
+
+	for _, c := range q.consumers {
+		if c == msgChan {
+			return nil
+		}
+	}
+
+	q.consumers = append(q.consumers, msgChan)
+	q.cond.Broadcast()
+	return nil
+}
+
+// Publish routes the message to the queues bound to the exchange. An
+// UnroutableError is returned when no queue matches the routing key.
+func (m *Memory) Publish(exchange, key string, body []byte, headers map[string]interface{}) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e, ok := m.exchanges[exchange]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrExchangeNotFound, exchange)
+	}
+
+	routed := false
+	for _, b := range e.bindings {
+		if e.kind == exchangeTypeDirect && b.key != key {
+			continue
+		}
+
+		q, ok := m.queues[b.queue]
+		if !ok {
+			continue
+		}
+
+		msg := InMsg{Exchange: exchange, RoutingKey: key, Headers: copyHeaders(headers), Body: body}
+		q.messages = append(q.messages, msg)
+		q.cond.Broadcast()
+		routed = true
+	}
+
+	if !routed {
+		return &UnroutableError{exchange, key, amqp.NoRoute, "NO_ROUTE"}
+	}
+
+	return nil
+}
+
+func (m *Memory) declareDeadLetter(queueName string) error {
+	name := deadLetterName(queueName)
+	err := m.DeclareExchange(name, exchangeTypeFanout)
+	if err != nil {
+		return err
+	}
+
+	m.DeclareQueue(name)
+	return m.BindQueue(name, name, "")
+}
+
+// deliver sends the queue's messages to its consumers while consuming isn't stopped
+func (m *Memory) deliver(q *memoryQueue) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for {
+		for m.stopConsuming || len(q.messages) == 0 || len(q.consumers) == 0 {
+			q.cond.Wait()
+		}
+
+		msg := q.messages[0]
+		q.messages = q.messages[1:]
+		consumer := q.consumers[q.next%len(q.consumers)]
+		q.next++
+		msg.Acknowledger = &memoryDelivery{m, q, msg}
+
+		// the consumer may be publishing while handling a previous message
+		m.mutex.Unlock()
+		consumer <- msg
+		m.mutex.Lock()
+	}
+}
+
+// Ack drops the delivery, since it's already removed from the queue
+func (d *memoryDelivery) Ack() error {
+	return nil
+}
+
+// Requeue puts the delivery back on the head of the queue
+func (d *memoryDelivery) Requeue() error {
+	d.memory.mutex.Lock()
+	defer d.memory.mutex.Unlock()
+
+	msg := d.msg
+	msg.Acknowledger = nil
+	d.queue.messages = append([]InMsg{msg}, d.queue.messages...)
+	d.queue.cond.Broadcast()
+	return nil
+}
+
+// Retry puts the delivery back on the tail of the queue after the delay,
+// counting the retries
+func (d *memoryDelivery) Retry(delay time.Duration) error {
+	msg := d.msg
+	msg.Acknowledger = nil
+	msg.Headers = copyHeaders(d.msg.Headers)
+	msg.Headers[headerRetries] = d.msg.Retries() + 1
+
+	time.AfterFunc(delay, func() {
+		d.memory.mutex.Lock()
+		defer d.memory.mutex.Unlock()
+
+		d.queue.messages = append(d.queue.messages, msg)
+		d.queue.cond.Broadcast()
+	})
+	return nil
+}
+
+// DeadLetter publishes the delivery to the queue's dead-letter exchange with
+// the original headers plus the failure reason
+func (d *memoryDelivery) DeadLetter(reason string) error {
+	headers := copyHeaders(d.msg.Headers)
+	headers[headerFailureReason] = reason
+	headers[headerOriginalExchange] = d.msg.Exchange
+	headers[headerOriginalRoutingKey] = d.msg.RoutingKey
+
+	return d.memory.Publish(deadLetterName(d.queue.name), "", d.msg.Body, headers)
+}
+
+func copyHeaders(headers map[string]interface{}) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
+}
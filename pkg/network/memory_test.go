@@ -0,0 +1,150 @@
+package network_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, msgChan chan network.InMsg) network.InMsg {
+	select {
+	case msg := <-msgChan:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting message")
+		return network.InMsg{}
+	}
+}
+
+func assertNoMessage(t *testing.T, msgChan chan network.InMsg) {
+	select {
+	case msg := <-msgChan:
+		t.Errorf("unexpected message received: %s", msg.Body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryDirectExchange(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	registered := make(chan network.InMsg, 1)
+	unregistered := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnMessage(registered, "registered", "device", "direct", "device.registered"))
+	assert.NoError(t, m.OnMessage(unregistered, "unregistered", "device", "direct", "device.unregistered"))
+
+	err := m.PublishPersistentMessage("device", "direct", "device.registered", []byte("body"), map[string]interface{}{"Authorization": "token"})
+	assert.NoError(t, err)
+
+	msg := receive(t, registered)
+	assert.Equal(t, "device", msg.Exchange)
+	assert.Equal(t, "device.registered", msg.RoutingKey)
+	assert.Equal(t, "token", msg.Headers["Authorization"])
+	assert.Equal(t, []byte("body"), msg.Body)
+	assertNoMessage(t, unregistered)
+}
+
+func TestMemoryFanoutExchange(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	first := make(chan network.InMsg, 1)
+	second := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnMessage(first, "first", "data.published", "fanout", ""))
+	assert.NoError(t, m.OnMessage(second, "second", "data.published", "fanout", ""))
+
+	err := m.PublishPersistentMessage("data.published", "fanout", "any.key", []byte("body"), nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte("body"), receive(t, first).Body)
+	assert.Equal(t, []byte("body"), receive(t, second).Body)
+}
+
+func TestMemoryTransientSubscriptions(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	first := make(chan network.InMsg, 1)
+	second := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnTransientMessage(first, "data.published", "fanout", ""))
+	assert.NoError(t, m.OnTransientMessage(second, "data.published", "fanout", ""))
+
+	err := m.PublishPersistentMessage("data.published", "fanout", "", []byte("body"), nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte("body"), receive(t, first).Body)
+	assert.Equal(t, []byte("body"), receive(t, second).Body)
+}
+
+func TestMemoryUnroutableMessage(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+
+	err := m.PublishPersistentMessage("device", "direct", "device.registered", []byte("body"), nil)
+
+	var unroutable *network.UnroutableError
+	assert.True(t, errors.As(err, &unroutable))
+	assert.True(t, errors.Is(err, network.ErrUnroutable))
+	assert.Equal(t, "device.registered", unroutable.RoutingKey)
+}
+
+func TestMemoryExchangeTypeMismatch(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	assert.NoError(t, m.DeclareExchange("device", "direct"))
+
+	err := m.DeclareExchange("device", "fanout")
+
+	assert.True(t, errors.Is(err, network.ErrExchangeTypeMismatch))
+}
+
+func TestMemoryRequeue(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	msgChan := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnMessage(msgChan, "commands", "device", "direct", "device.register"))
+	assert.NoError(t, m.PublishPersistentMessage("device", "direct", "device.register", []byte("body"), nil))
+
+	assert.NoError(t, receive(t, msgChan).Requeue())
+
+	assert.Equal(t, []byte("body"), receive(t, msgChan).Body)
+}
+
+func TestMemoryRetry(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	msgChan := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnMessage(msgChan, "commands", "device", "direct", "device.register"))
+	assert.NoError(t, m.PublishPersistentMessage("device", "direct", "device.register", []byte("body"), nil))
+
+	msg := receive(t, msgChan)
+	assert.Equal(t, 0, msg.Retries())
+	assert.NoError(t, msg.Retry(10*time.Millisecond))
+
+	msg = receive(t, msgChan)
+	assert.Equal(t, []byte("body"), msg.Body)
+	assert.Equal(t, "device.register", msg.RoutingKey)
+	assert.Equal(t, 1, msg.Retries())
+}
+
+func TestMemoryDeadLetter(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	msgChan := make(chan network.InMsg, 1)
+	deadLetters := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnMessage(msgChan, "commands", "device", "direct", "device.register"))
+	assert.NoError(t, m.Consume("commands.dead-letter", deadLetters))
+	assert.NoError(t, m.PublishPersistentMessage("device", "direct", "device.register", []byte("body"), map[string]interface{}{"Authorization": "token"}))
+
+	assert.NoError(t, receive(t, msgChan).DeadLetter("invalid id"))
+
+	msg := receive(t, deadLetters)
+	assert.Equal(t, []byte("body"), msg.Body)
+	assert.Equal(t, "token", msg.Headers["Authorization"])
+	assert.Equal(t, "invalid id", msg.Headers["x-failure-reason"])
+	assert.Equal(t, "device.register", msg.Headers["x-original-routing-key"])
+}
+
+func TestMemoryStopConsuming(t *testing.T) {
+	m := network.NewMemory(&mocks.FakeLogger{})
+	msgChan := make(chan network.InMsg, 1)
+	assert.NoError(t, m.OnMessage(msgChan, "commands", "device", "direct", "device.register"))
+	assert.NoError(t, m.StopConsuming())
+
+	assert.NoError(t, m.PublishPersistentMessage("device", "direct", "device.register", []byte("body"), nil))
+
+	assertNoMessage(t, msgChan)
+}
@@ -1,6 +1,12 @@
 package network
 
-import "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+import (
+	"time"
+
+	dataEntities "github.com/CESARBR/knot-babeltower/pkg/data/entities"
+	scheduleEntities "github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
 
 // DeviceRegisterRequest represents the incoming register device request message
 type DeviceRegisterRequest struct {
@@ -27,17 +33,48 @@ type DeviceUnregisteredResponse struct {
 	Error *string `json:"error"`
 }
 
+// DeviceRegisterBatchRequest represents the incoming register devices batch
+// request message
+type DeviceRegisterBatchRequest struct {
+	Things []DeviceRegisterRequest `json:"things"`
+}
+
+// DeviceRegisteredBatchResponse represents the outgoing register devices
+// batch response message, with the result of each device in the order they
+// were requested
+type DeviceRegisteredBatchResponse struct {
+	Things []DeviceRegisteredResponse `json:"things"`
+	Error  *string                    `json:"error"`
+}
+
+// DeviceUnregisterBatchRequest represents the incoming unregister devices
+// batch request message
+type DeviceUnregisterBatchRequest struct {
+	Things []DeviceUnregisterRequest `json:"things"`
+}
+
+// DeviceUnregisteredBatchResponse represents the outgoing unregister devices
+// batch response message, with the result of each device in the order they
+// were requested
+type DeviceUnregisteredBatchResponse struct {
+	Things []DeviceUnregisteredResponse `json:"things"`
+	Error  *string                      `json:"error"`
+}
+
 // SchemaUpdateRequest represents the incoming update schema request message
 type SchemaUpdateRequest struct {
 	ID     string            `json:"id"`
 	Schema []entities.Schema `json:"schema,omitempty"`
 }
 
-// SchemaUpdatedResponse represents the outgoing update schema response message
+// SchemaUpdatedResponse represents the outgoing update schema response
+// message, along with the schema's version and its diff from the previous one
 type SchemaUpdatedResponse struct {
-	ID     string            `json:"id"`
-	Schema []entities.Schema `json:"schema,omitempty"`
-	Error  *string           `json:"error"`
+	ID      string               `json:"id"`
+	Schema  []entities.Schema    `json:"schema,omitempty"`
+	Version int                  `json:"version,omitempty"`
+	Diff    *entities.SchemaDiff `json:"diff,omitempty"`
+	Error   *string              `json:"error"`
 }
 
 // DeviceAuthRequest represents the incoming auth device command
@@ -52,26 +89,139 @@ type DeviceAuthResponse struct {
 	Error *string `json:"error"`
 }
 
+// DevicePresence represents the outgoing device online and offline events
+type DevicePresence struct {
+	ID       string    `json:"id"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
 // DeviceListResponse represents the outgoing list devices command response
 type DeviceListResponse struct {
 	Things []*entities.Thing `json:"devices"`
 	Error  *string           `json:"error"`
 }
 
-// DataRequest represents the incoming request data command
+// SchemaTypesResponse represents the outgoing list sensor types command
+// response
+type SchemaTypesResponse struct {
+	ValueTypes []entities.ValueType  `json:"valueTypes"`
+	Types      []entities.SensorType `json:"types"`
+	Error      *string               `json:"error"`
+}
+
+// DataRequest represents the incoming request data command, which is also
+// sent to the thing. The command ID is generated when it isn't informed.
 type DataRequest struct {
 	ID        string `json:"id"`
+	CommandID string `json:"commandId,omitempty"`
 	SensorIds []int  `json:"sensorIds"`
 }
 
-// DataUpdate represents the incoming update data command
+// DataUpdate represents the incoming update data command, which is also sent
+// to the thing. The command ID is generated when it isn't informed.
 type DataUpdate struct {
-	ID   string          `json:"id"`
-	Data []entities.Data `json:"data"`
+	ID        string          `json:"id"`
+	CommandID string          `json:"commandId,omitempty"`
+	Data      []entities.Data `json:"data"`
+}
+
+// DataUpdateAck represents the incoming acknowledgement of a command sent to
+// the thing, which failed when the error is informed
+type DataUpdateAck struct {
+	ID        string  `json:"id"`
+	CommandID string  `json:"commandId"`
+	Error     *string `json:"error"`
 }
 
-// DataSent represents the data received from the things
+// DataSent represents the data received from the things. The timestamp, when
+// provided, applies to the data without its own timestamp.
 type DataSent struct {
-	ID   string          `json:"id"`
-	Data []entities.Data `json:"data"`
+	ID        string          `json:"id"`
+	Timestamp *time.Time      `json:"timestamp,omitempty"`
+	Data      []entities.Data `json:"data"`
+}
+
+// LastValuesRequest represents the incoming request for the thing's last values
+type LastValuesRequest struct {
+	ID        string `json:"id"`
+	SensorIds []int  `json:"sensorIds"`
+}
+
+// LastValuesResponse represents the outgoing last values command response
+type LastValuesResponse struct {
+	ID     string                     `json:"id"`
+	Values []dataEntities.SensorValue `json:"values"`
+	Error  *string                    `json:"error"`
+}
+
+// HistoryRequest represents the incoming request for the thing's history. The
+// interval is a duration such as "5m" and, when provided, the values are
+// downsampled in buckets of it.
+type HistoryRequest struct {
+	ID        string    `json:"id"`
+	SensorIds []int     `json:"sensorIds"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Interval  string    `json:"interval"`
+}
+
+// HistoryResponse represents the outgoing history command response
+type HistoryResponse struct {
+	ID      string                       `json:"id"`
+	Sensors []dataEntities.SensorHistory `json:"sensors"`
+	Error   *string                      `json:"error"`
+}
+
+// RuleTriggered represents the event published when a rule is triggered,
+// along with the data which satisfied its conditions
+type RuleTriggered struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	ThingID     string          `json:"thingId"`
+	Data        []entities.Data `json:"data"`
+	TriggeredAt time.Time       `json:"triggeredAt"`
+}
+
+// ScheduleRequest represents the incoming request to create a schedule. The
+// schedule is enabled when not informed otherwise.
+type ScheduleRequest struct {
+	Name     string                  `json:"name"`
+	ThingID  string                  `json:"thingId"`
+	Enabled  *bool                   `json:"enabled"`
+	Cron     string                  `json:"cron"`
+	At       *time.Time              `json:"at"`
+	Timezone string                  `json:"timezone"`
+	Action   scheduleEntities.Action `json:"action"`
+}
+
+// ScheduleUpdateRequest represents the incoming request to update a schedule
+type ScheduleUpdateRequest struct {
+	ID string `json:"id"`
+	ScheduleRequest
+}
+
+// ScheduleDeleteRequest represents the incoming request to delete a schedule
+type ScheduleDeleteRequest struct {
+	ID string `json:"id"`
+}
+
+// ScheduleResponse represents the outgoing create and update schedule
+// commands response
+type ScheduleResponse struct {
+	Schedule *scheduleEntities.Schedule `json:"schedule"`
+	Error    *string                    `json:"error"`
+}
+
+// ScheduleDeletedResponse represents the outgoing delete schedule command
+// response
+type ScheduleDeletedResponse struct {
+	ID    string  `json:"id"`
+	Error *string `json:"error"`
+}
+
+// ScheduleListResponse represents the outgoing list schedules command
+// response
+type ScheduleListResponse struct {
+	Schedules []scheduleEntities.Schedule `json:"schedules"`
+	Error     *string                     `json:"error"`
 }
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/interactors"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+)
+
+// PreferenceHTTPController handles the HTTP requests to manage the users'
+// preferences
+type PreferenceHTTPController struct {
+	logger               logging.Logger
+	preferenceInteractor interactors.Interactor
+}
+
+// UnitsRequest represents the request to update the user's preferred units
+type UnitsRequest struct {
+	Units []entities.UnitPreference `json:"units"`
+}
+
+// NewPreferenceHTTPController constructs the controller
+func NewPreferenceHTTPController(logger logging.Logger, preferenceInteractor interactors.Interactor) *PreferenceHTTPController {
+	return &PreferenceHTTPController{logger, preferenceInteractor}
+}
+
+// GetUnits godoc
+// @Summary Gets the units the user prefers the things' data to be normalized to
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Success 200 {array} entities.UnitPreference "User's preferred unit of each sensor type"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /preferences/units [get]
+// GetUnits handles the server request and calls the get units use case
+func (pc *PreferenceHTTPController) GetUnits(w http.ResponseWriter, r *http.Request) {
+	units, err := pc.preferenceInteractor.GetUnits(r.Header.Get("Authorization"))
+	if err != nil {
+		pc.writeError(w, err)
+		return
+	}
+
+	pc.writeResponse(w, http.StatusOK, units)
+}
+
+// UpdateUnits godoc
+// @Summary Updates the units the user prefers the things' data to be normalized to
+// @Description The values of the sensor types without a preferred unit are normalized to their base unit, the SI one when it's registered.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param units body UnitsRequest true "User's preferred unit of each sensor type"
+// @Success 200 {array} entities.UnitPreference "User's preferred unit of each sensor type"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or preferred units"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /preferences/units [put]
+// UpdateUnits handles the server request and calls the update units use case
+func (pc *PreferenceHTTPController) UpdateUnits(w http.ResponseWriter, r *http.Request) {
+	var req UnitsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		pc.logger.Error("failed to parse request body")
+		pc.writeResponse(w, http.StatusUnprocessableEntity, &thingControllers.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	units, err := pc.preferenceInteractor.UpdateUnits(r.Header.Get("Authorization"), req.Units)
+	if err != nil {
+		pc.writeError(w, err)
+		return
+	}
+
+	pc.logger.Info("preferred units updated")
+	pc.writeResponse(w, http.StatusOK, units)
+}
+
+func (pc *PreferenceHTTPController) writeError(w http.ResponseWriter, err error) {
+	pc.logger.Error(err)
+	pc.writeResponse(w, mapPreferenceErrorToStatusCode(err), &thingControllers.ErrorResponse{Message: err.Error()})
+}
+
+func (pc *PreferenceHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	if msg == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	js, err := json.Marshal(msg)
+	if err != nil {
+		pc.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		pc.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func mapPreferenceErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, thingInteractors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, userEntities.ErrUserForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, interactors.ErrPreferenceInvalid):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
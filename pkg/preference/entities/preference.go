@@ -0,0 +1,8 @@
+package entities
+
+// UnitPreference represents the unit a user prefers the values of a sensor
+// type to be normalized to, instead of the type's base unit
+type UnitPreference struct {
+	TypeID int `json:"typeId"`
+	Unit   int `json:"unit"`
+}
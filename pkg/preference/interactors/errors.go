@@ -0,0 +1,7 @@
+package interactors
+
+import "errors"
+
+// ErrPreferenceInvalid is returned when the preferences refer to unknown
+// sensor types or to units which the values can't be normalized to
+var ErrPreferenceInvalid = errors.New("invalid preference")
@@ -0,0 +1,61 @@
+package interactors
+
+import (
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/storage"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	userHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
+)
+
+// identityTTL is how long the user a token belongs to is remembered when
+// resolving the units the user prefers, avoiding to identify the user on
+// every data published by the things
+const identityTTL = time.Minute
+
+// Interactor is an interface that defines the preference's use cases operations
+type Interactor interface {
+	GetUnits(authorization string) ([]entities.UnitPreference, error)
+	UpdateUnits(authorization string, units []entities.UnitPreference) ([]entities.UnitPreference, error)
+}
+
+// PreferenceInteractor represents the preference interactor capabilities,
+// it's composed by the necessary dependencies. It provides the units the
+// users prefer the data published by their things to be normalized to.
+type PreferenceInteractor struct {
+	logger    logging.Logger
+	userProxy userHTTP.UserProxy
+	types     *thingEntities.TypeRegistry
+	store     storage.PreferenceStore
+	now       func() time.Time
+
+	// identities holds the users recently identified by their tokens
+	identitiesMutex sync.Mutex
+	identities      map[string]identity
+}
+
+type identity struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// NewPreferenceInteractor creates a new PreferenceInteractor instance. The
+// preferred units are validated against the sensor types registry.
+func NewPreferenceInteractor(
+	logger logging.Logger,
+	userProxy userHTTP.UserProxy,
+	types *thingEntities.TypeRegistry,
+	store storage.PreferenceStore,
+) *PreferenceInteractor {
+	return &PreferenceInteractor{
+		logger:     logger,
+		userProxy:  userProxy,
+		types:      types,
+		store:      store,
+		now:        time.Now,
+		identities: map[string]identity{},
+	}
+}
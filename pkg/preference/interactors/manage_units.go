@@ -0,0 +1,78 @@
+package interactors
+
+import (
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// GetUnits returns the units preferred by the token's user, sorted by their
+// type ID
+func (i *PreferenceInteractor) GetUnits(authorization string) ([]entities.UnitPreference, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.store.GetUnits(owner)
+}
+
+// UpdateUnits validates and replaces the units preferred by the token's
+// user. The types without a preferred unit are normalized to their base
+// unit.
+func (i *PreferenceInteractor) UpdateUnits(authorization string, units []entities.UnitPreference) ([]entities.UnitPreference, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.validateUnits(units)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.store.SaveUnits(owner, units)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.store.GetUnits(owner)
+}
+
+func (i *PreferenceInteractor) identify(authorization string) (string, error) {
+	if authorization == "" {
+		return "", thingInteractors.ErrAuthNotProvided
+	}
+
+	owner, err := i.userProxy.Identify(authorization)
+	if err != nil {
+		return "", fmt.Errorf("error identifying user: %w", err)
+	}
+
+	return owner, nil
+}
+
+// validateUnits verifies each preference refers to a registered sensor type,
+// only once, and to one of its units the values can be converted to
+func (i *PreferenceInteractor) validateUnits(units []entities.UnitPreference) error {
+	types := map[int]bool{}
+	for _, u := range units {
+		if types[u.TypeID] {
+			return fmt.Errorf("%w: type %d informed more than once", ErrPreferenceInvalid, u.TypeID)
+		}
+		types[u.TypeID] = true
+
+		sensorType, ok := i.types.Type(u.TypeID)
+		if !ok {
+			return fmt.Errorf("%w: unknown type %d", ErrPreferenceInvalid, u.TypeID)
+		}
+
+		unit, ok := sensorType.Unit(u.Unit)
+		if !ok || unit.Factor == 0 {
+			return fmt.Errorf("%w: type %d can't be normalized to unit %d", ErrPreferenceInvalid, u.TypeID, u.Unit)
+		}
+	}
+
+	return nil
+}
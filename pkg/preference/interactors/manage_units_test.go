@@ -0,0 +1,131 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/storage"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	thingStorage "github.com/CESARBR/knot-babeltower/pkg/thing/storage"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestInteractor() (*PreferenceInteractor, *mocks.FakeUserProxy) {
+	types, err := thingStorage.LoadTypeRegistry("../../../internal/config/types.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	userProxy := &mocks.FakeUserProxy{}
+	userProxy.On("Identify", "user-token").Return("user@test.com", nil)
+	userProxy.On("Identify", "other-user-token").Return("other@test.com", nil)
+	userProxy.On("Identify", "invalid-token").Return("", userEntities.ErrUserForbidden)
+
+	return NewPreferenceInteractor(&mocks.FakeLogger{}, userProxy, types, storage.NewMemoryPreferenceStore()), userProxy
+}
+
+func TestUpdateUnits(t *testing.T) {
+	fahrenheit := entities.UnitPreference{TypeID: 0x05, Unit: 2}
+	testCases := []struct {
+		name          string
+		authorization string
+		units         []entities.UnitPreference
+		expected      []entities.UnitPreference
+		expectedErr   error
+	}{
+		{
+			"units updated",
+			"user-token",
+			[]entities.UnitPreference{{TypeID: 0x15, Unit: 4}, fahrenheit},
+			[]entities.UnitPreference{fahrenheit, {TypeID: 0x15, Unit: 4}},
+			nil,
+		},
+		{
+			"units cleared",
+			"user-token",
+			[]entities.UnitPreference{},
+			[]entities.UnitPreference{},
+			nil,
+		},
+		{
+			"authorization token not provided",
+			"",
+			[]entities.UnitPreference{fahrenheit},
+			nil,
+			thingInteractors.ErrAuthNotProvided,
+		},
+		{
+			"invalid authorization token",
+			"invalid-token",
+			[]entities.UnitPreference{fahrenheit},
+			nil,
+			userEntities.ErrUserForbidden,
+		},
+		{
+			"unknown type",
+			"user-token",
+			[]entities.UnitPreference{{TypeID: 0xFFFF, Unit: 1}},
+			nil,
+			ErrPreferenceInvalid,
+		},
+		{
+			"unit not registered for the type",
+			"user-token",
+			[]entities.UnitPreference{{TypeID: 0x05, Unit: 7}},
+			nil,
+			ErrPreferenceInvalid,
+		},
+		{
+			"unit not convertible",
+			"user-token",
+			[]entities.UnitPreference{{TypeID: 0x07, Unit: 1}},
+			nil,
+			ErrPreferenceInvalid,
+		},
+		{
+			"type informed twice",
+			"user-token",
+			[]entities.UnitPreference{fahrenheit, {TypeID: 0x05, Unit: 3}},
+			nil,
+			ErrPreferenceInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interactor, _ := newTestInteractor()
+			assert.NoError(t, interactor.store.SaveUnits("user@test.com", []entities.UnitPreference{{TypeID: 0x0B, Unit: 3}}))
+
+			units, err := interactor.UpdateUnits(tc.authorization, tc.units)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			assert.Equal(t, tc.expected, units)
+
+			if tc.expectedErr != nil {
+				stored, err := interactor.store.GetUnits("user@test.com")
+				assert.NoError(t, err)
+				assert.Equal(t, []entities.UnitPreference{{TypeID: 0x0B, Unit: 3}}, stored)
+			}
+		})
+	}
+}
+
+func TestGetUnits(t *testing.T) {
+	interactor, _ := newTestInteractor()
+	fahrenheit := []entities.UnitPreference{{TypeID: 0x05, Unit: 2}}
+	_, err := interactor.UpdateUnits("user-token", fahrenheit)
+	assert.NoError(t, err)
+
+	units, err := interactor.GetUnits("user-token")
+	assert.NoError(t, err)
+	assert.Equal(t, fahrenheit, units)
+
+	units, err = interactor.GetUnits("other-user-token")
+	assert.NoError(t, err)
+	assert.Empty(t, units)
+
+	_, err = interactor.GetUnits("")
+	assert.Equal(t, thingInteractors.ErrAuthNotProvided, err)
+}
@@ -0,0 +1,52 @@
+package interactors
+
+// PreferredUnits returns the units preferred by the token's user, mapped by
+// their type ID. The user is only identified again after the identityTTL.
+func (i *PreferenceInteractor) PreferredUnits(authorization string) (map[int]int, error) {
+	owner, err := i.cachedIdentify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := i.store.GetUnits(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	preferred := make(map[int]int, len(units))
+	for _, u := range units {
+		preferred[u.TypeID] = u.Unit
+	}
+
+	return preferred, nil
+}
+
+func (i *PreferenceInteractor) cachedIdentify(authorization string) (string, error) {
+	now := i.now()
+
+	i.identitiesMutex.Lock()
+	cached, ok := i.identities[authorization]
+	i.identitiesMutex.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.owner, nil
+	}
+
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return "", err
+	}
+
+	i.identitiesMutex.Lock()
+	defer i.identitiesMutex.Unlock()
+
+	// the expired identities are dropped, so the tokens no longer used don't
+	// pile up
+	for token, id := range i.identities {
+		if !now.Before(id.expiresAt) {
+			delete(i.identities, token)
+		}
+	}
+	i.identities[authorization] = identity{owner, now.Add(identityTTL)}
+
+	return owner, nil
+}
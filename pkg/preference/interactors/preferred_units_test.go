@@ -0,0 +1,45 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredUnits(t *testing.T) {
+	interactor, userProxy := newTestInteractor()
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	interactor.now = func() time.Time { return now }
+	_, err := interactor.UpdateUnits("user-token", []entities.UnitPreference{{TypeID: 0x05, Unit: 2}, {TypeID: 0x15, Unit: 4}})
+	assert.NoError(t, err)
+	userProxy.Calls = nil
+
+	preferred, err := interactor.PreferredUnits("user-token")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{0x05: 2, 0x15: 4}, preferred)
+
+	// the user is remembered until the identity expires
+	_, err = interactor.UpdateUnits("user-token", []entities.UnitPreference{{TypeID: 0x05, Unit: 3}})
+	assert.NoError(t, err)
+	userProxy.Calls = nil
+	preferred, err = interactor.PreferredUnits("user-token")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{0x05: 3}, preferred)
+	userProxy.AssertNotCalled(t, "Identify", "user-token")
+
+	now = now.Add(identityTTL)
+	_, err = interactor.PreferredUnits("user-token")
+	assert.NoError(t, err)
+	userProxy.AssertNumberOfCalls(t, "Identify", 1)
+
+	preferred, err = interactor.PreferredUnits("other-user-token")
+	assert.NoError(t, err)
+	assert.Empty(t, preferred)
+
+	_, err = interactor.PreferredUnits("invalid-token")
+	assert.True(t, errors.Is(err, userEntities.ErrUserForbidden))
+}
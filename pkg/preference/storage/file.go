@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+)
+
+// FilePreferenceStore keeps the preferences in memory and writes them to a
+// JSON file on every change, so they're restored when the service restarts
+type FilePreferenceStore struct {
+	file   *jsonfile.File
+	memory *MemoryPreferenceStore
+}
+
+// NewFilePreferenceStore creates a new FilePreferenceStore instance loading
+// the preferences previously written to the file, which is created when it
+// doesn't exist yet
+func NewFilePreferenceStore(path string) (*FilePreferenceStore, error) {
+	s := &FilePreferenceStore{memory: NewMemoryPreferenceStore()}
+	s.file = jsonfile.New(path, "preferences", 0, func() interface{} { return s.memory.units })
+
+	err := s.file.Load(&s.memory.units)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetUnits returns the user's unit preferences sorted by their type ID,
+// which are empty when the user hasn't set them
+func (s *FilePreferenceStore) GetUnits(owner string) ([]entities.UnitPreference, error) {
+	return s.memory.GetUnits(owner)
+}
+
+// SaveUnits replaces the user's unit preferences and writes all the
+// preferences to the file
+func (s *FilePreferenceStore) SaveUnits(owner string, units []entities.UnitPreference) error {
+	return s.file.Update(func() error { return s.memory.SaveUnits(owner, units) })
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+)
+
+// MemoryPreferenceStore keeps the preferences in memory, so they're lost
+// when the service is restarted
+type MemoryPreferenceStore struct {
+	mutex sync.RWMutex
+	units map[string][]entities.UnitPreference
+}
+
+// NewMemoryPreferenceStore creates a new MemoryPreferenceStore instance
+func NewMemoryPreferenceStore() *MemoryPreferenceStore {
+	return &MemoryPreferenceStore{units: map[string][]entities.UnitPreference{}}
+}
+
+// GetUnits returns the user's unit preferences sorted by their type ID,
+// which are empty when the user hasn't set them
+func (s *MemoryPreferenceStore) GetUnits(owner string) ([]entities.UnitPreference, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	units := append([]entities.UnitPreference{}, s.units[owner]...)
+	return units, nil
+}
+
+// SaveUnits replaces the user's unit preferences, removing them when they're
+// empty
+func (s *MemoryPreferenceStore) SaveUnits(owner string, units []entities.UnitPreference) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(units) == 0 {
+		delete(s.units, owner)
+		return nil
+	}
+
+	saved := append([]entities.UnitPreference{}, units...)
+	sortUnits(saved)
+	s.units[owner] = saved
+	return nil
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+)
+
+// PreferenceStore represents the storage of the users' preferences
+type PreferenceStore interface {
+	GetUnits(owner string) ([]entities.UnitPreference, error)
+	SaveUnits(owner string, units []entities.UnitPreference) error
+}
+
+// sortUnits sorts the unit preferences by their type ID
+func sortUnits(units []entities.UnitPreference) {
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].TypeID < units[j].TypeID
+	})
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/preference/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "preferences")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestPreferenceStores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T) PreferenceStore
+	}{
+		{
+			"memory",
+			func(t *testing.T) PreferenceStore {
+				return NewMemoryPreferenceStore()
+			},
+		},
+		{
+			"file",
+			func(t *testing.T) PreferenceStore {
+				store, err := NewFilePreferenceStore(filepath.Join(tempDir(t), "data", "preferences.json"))
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := tc.newStore(t)
+			units, err := store.GetUnits("user@test.com")
+			assert.NoError(t, err)
+			assert.Empty(t, units)
+
+			fahrenheit := entities.UnitPreference{TypeID: 0x05, Unit: 2}
+			kilowattHour := entities.UnitPreference{TypeID: 0x15, Unit: 4}
+			assert.NoError(t, store.SaveUnits("user@test.com", []entities.UnitPreference{kilowattHour, fahrenheit}))
+			assert.NoError(t, store.SaveUnits("other@test.com", []entities.UnitPreference{kilowattHour}))
+
+			units, err = store.GetUnits("user@test.com")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.UnitPreference{fahrenheit, kilowattHour}, units)
+
+			assert.NoError(t, store.SaveUnits("user@test.com", nil))
+			units, err = store.GetUnits("user@test.com")
+			assert.NoError(t, err)
+			assert.Empty(t, units)
+
+			units, err = store.GetUnits("other@test.com")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.UnitPreference{kilowattHour}, units)
+		})
+	}
+}
+
+func TestFilePreferenceStoreRestoresPreferences(t *testing.T) {
+	path := filepath.Join(tempDir(t), "preferences.json")
+	units := []entities.UnitPreference{{TypeID: 0x05, Unit: 2}}
+	store, err := NewFilePreferenceStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.SaveUnits("user@test.com", units))
+
+	restored, err := NewFilePreferenceStore(path)
+	assert.NoError(t, err)
+
+	stored, err := restored.GetUnits("user@test.com")
+	assert.NoError(t, err)
+	assert.Equal(t, units, stored)
+}
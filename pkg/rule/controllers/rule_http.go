@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/interactors"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/gorilla/mux"
+)
+
+// RuleHTTPController handles the HTTP requests to manage the users' rules
+type RuleHTTPController struct {
+	logger         logging.Logger
+	ruleInteractor interactors.Interactor
+}
+
+// RuleRequest represents the request to create or update a rule. The rule is
+// enabled when not informed otherwise.
+type RuleRequest struct {
+	Name       string               `json:"name"`
+	ThingID    string               `json:"thingId"`
+	Enabled    *bool                `json:"enabled"`
+	Conditions []entities.Condition `json:"conditions"`
+	Actions    []entities.Action    `json:"actions"`
+}
+
+// NewRuleHTTPController constructs the controller
+func NewRuleHTTPController(logger logging.Logger, ruleInteractor interactors.Interactor) *RuleHTTPController {
+	return &RuleHTTPController{logger, ruleInteractor}
+}
+
+// Create godoc
+// @Summary Creates a new rule
+// @Description The rule is evaluated on the data published by its thing, executing its actions when all its conditions become satisfied.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param rule body RuleRequest true "Rule's thing, conditions and actions"
+// @Success 201 {object} entities.Rule "Created rule with its id"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or rule"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /rules [post]
+// Create handles the server request and calls the create rule use case
+func (rc *RuleHTTPController) Create(w http.ResponseWriter, r *http.Request) {
+	rule, ok := rc.parseRule(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := rc.ruleInteractor.Create(r.Header.Get("Authorization"), rule)
+	if err != nil {
+		rc.writeError(w, err)
+		return
+	}
+
+	rc.logger.Infof("rule %s created", created.ID)
+	w.Header().Set("Location", "/rules/"+created.ID)
+	rc.writeResponse(w, http.StatusCreated, created)
+}
+
+// List godoc
+// @Summary Lists the user's rules
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Success 200 {array} entities.Rule "User's rules"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /rules [get]
+// List handles the server request and calls the list rules use case
+func (rc *RuleHTTPController) List(w http.ResponseWriter, r *http.Request) {
+	rules, err := rc.ruleInteractor.List(r.Header.Get("Authorization"))
+	if err != nil {
+		rc.writeError(w, err)
+		return
+	}
+
+	rc.writeResponse(w, http.StatusOK, rules)
+}
+
+// Get godoc
+// @Summary Gets a rule
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Rule's id"
+// @Success 200 {object} entities.Rule "Rule"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Rule not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /rules/{id} [get]
+// Get handles the server request and calls the get rule use case
+func (rc *RuleHTTPController) Get(w http.ResponseWriter, r *http.Request) {
+	rule, err := rc.ruleInteractor.Get(r.Header.Get("Authorization"), mux.Vars(r)["id"])
+	if err != nil {
+		rc.writeError(w, err)
+		return
+	}
+
+	rc.writeResponse(w, http.StatusOK, rule)
+}
+
+// Update godoc
+// @Summary Updates a rule
+// @Description The rule's evaluation starts over, as if it was just created.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Rule's id"
+// @Param rule body RuleRequest true "Rule's thing, conditions and actions"
+// @Success 200 {object} entities.Rule "Updated rule"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Rule not found"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or rule"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /rules/{id} [put]
+// Update handles the server request and calls the update rule use case
+func (rc *RuleHTTPController) Update(w http.ResponseWriter, r *http.Request) {
+	rule, ok := rc.parseRule(w, r)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	updated, err := rc.ruleInteractor.Update(r.Header.Get("Authorization"), id, rule)
+	if err != nil {
+		rc.writeError(w, err)
+		return
+	}
+
+	rc.logger.Infof("rule %s updated", id)
+	rc.writeResponse(w, http.StatusOK, updated)
+}
+
+// Delete godoc
+// @Summary Deletes a rule
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Rule's id"
+// @Success 204 "Rule deleted"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Rule not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /rules/{id} [delete]
+// Delete handles the server request and calls the delete rule use case
+func (rc *RuleHTTPController) Delete(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	err := rc.ruleInteractor.Delete(r.Header.Get("Authorization"), id)
+	if err != nil {
+		rc.writeError(w, err)
+		return
+	}
+
+	rc.logger.Infof("rule %s deleted", id)
+	rc.writeResponse(w, http.StatusNoContent, nil)
+}
+
+// parseRule decodes the rule from the request's body. When it isn't
+// possible, the request is answered with 422 Unprocessable Entity.
+func (rc *RuleHTTPController) parseRule(w http.ResponseWriter, r *http.Request) (entities.Rule, bool) {
+	var req RuleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		rc.logger.Error("failed to parse request body")
+		rc.writeResponse(w, http.StatusUnprocessableEntity, &thingControllers.ErrorResponse{Message: err.Error()})
+		return entities.Rule{}, false
+	}
+
+	rule := entities.Rule{
+		Name:       req.Name,
+		ThingID:    req.ThingID,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		Conditions: req.Conditions,
+		Actions:    req.Actions,
+	}
+	return rule, true
+}
+
+func (rc *RuleHTTPController) writeError(w http.ResponseWriter, err error) {
+	rc.logger.Error(err)
+	rc.writeResponse(w, mapRuleErrorToStatusCode(err), &thingControllers.ErrorResponse{Message: err.Error()})
+}
+
+func (rc *RuleHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	if msg == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	js, err := json.Marshal(msg)
+	if err != nil {
+		rc.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		rc.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func mapRuleErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, thingInteractors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, userEntities.ErrUserForbidden),
+		errors.Is(err, thingEntities.ErrThingForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, interactors.ErrRuleInvalid):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,51 @@
+package amqp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+const (
+	exchangeRuleTriggered     = "rule.triggered"
+	exchangeRuleTriggeredType = "fanout"
+)
+
+// Publisher provides methods to send the rules events to the clients
+type Publisher interface {
+	PublishRuleTriggered(rule entities.Rule, data []thingEntities.Data, triggeredAt time.Time) error
+}
+
+// msgPublisher publishes the rules events to the broker
+type msgPublisher struct {
+	logger logging.Logger
+	amqp   network.AmqpSender
+}
+
+// NewMsgPublisher creates a new msgPublisher instance
+func NewMsgPublisher(logger logging.Logger, amqp network.AmqpSender) Publisher {
+	return &msgPublisher{logger, amqp}
+}
+
+// PublishRuleTriggered sends the rule triggered event
+func (mp *msgPublisher) PublishRuleTriggered(rule entities.Rule, data []thingEntities.Data, triggeredAt time.Time) error {
+	event := &network.RuleTriggered{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		ThingID:     rule.ThingID,
+		Data:        data,
+		TriggeredAt: triggeredAt,
+	}
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("message parsing error: %w", err)
+	}
+
+	return thingAMQP.MapPublishError(mp.amqp.PublishPersistentMessage(exchangeRuleTriggered, exchangeRuleTriggeredType, "", msg, nil))
+}
@@ -0,0 +1,6 @@
+package entities
+
+import "errors"
+
+// ErrRuleNotFound is returned when the rule isn't found among the user's rules
+var ErrRuleNotFound = errors.New("rule not found")
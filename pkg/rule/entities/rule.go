@@ -0,0 +1,66 @@
+package entities
+
+import (
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// Operators comparing the sensor's values, or their rate of change per
+// second, to the condition's value
+const (
+	OperatorGreater      = "gt"
+	OperatorGreaterEqual = "gte"
+	OperatorLess         = "lt"
+	OperatorLessEqual    = "lte"
+	OperatorEqual        = "eq"
+	OperatorNotEqual     = "ne"
+	OperatorRateGreater  = "rateGt"
+	OperatorRateLess     = "rateLt"
+)
+
+// Actions executed when a rule is triggered
+const (
+	ActionUpdateData  = "updateData"
+	ActionRequestData = "requestData"
+	ActionEvent       = "event"
+)
+
+// Rule represents an automation of the user's things. The rule is triggered
+// when all its conditions, evaluated on the data published by the thing,
+// become satisfied, and it's triggered again only after they stop being
+// satisfied.
+type Rule struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	ThingID    string      `json:"thingId"`
+	Enabled    bool        `json:"enabled"`
+	Conditions []Condition `json:"conditions"`
+	Actions    []Action    `json:"actions"`
+
+	// Owner is the e-mail of the user who created the rule
+	Owner string `json:"-"`
+	// ThingToken is the thing's ID on the things service, which is unique
+	// among all the users
+	ThingToken string `json:"-"`
+}
+
+// Condition represents a comparison of a sensor's values. The hysteresis
+// keeps the threshold and rate conditions satisfied until the value goes
+// back beyond the threshold by the hysteresis, avoiding triggering the rule
+// repeatedly when the value oscillates around the threshold.
+type Condition struct {
+	SensorID   int         `json:"sensorId"`
+	Operator   string      `json:"operator"`
+	Value      interface{} `json:"value"`
+	Hysteresis float64     `json:"hysteresis,omitempty"`
+}
+
+// Action represents an operation executed when the rule is triggered. The
+// update and request data actions are sent to the thing, which may be other
+// than the rule's thing, while the event action publishes a rule.triggered
+// event.
+type Action struct {
+	Type      string               `json:"type"`
+	ThingID   string               `json:"thingId,omitempty"`
+	Data      []thingEntities.Data `json:"data,omitempty"`
+	SensorIDs []int                `json:"sensorIds,omitempty"`
+}
@@ -0,0 +1,7 @@
+package interactors
+
+import "errors"
+
+// ErrRuleInvalid is returned when the rule has an invalid format or refers
+// to things and sensors which don't exist
+var ErrRuleInvalid = errors.New("invalid rule")
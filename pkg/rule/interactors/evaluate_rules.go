@@ -0,0 +1,206 @@
+package interactors
+
+import (
+	"errors"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// ruleState represents whether the rule's conditions are satisfied by the
+// data published so far
+type ruleState struct {
+	satisfied  bool
+	conditions []conditionState
+}
+
+// conditionState represents whether the condition is satisfied by the last
+// value of its sensor. The last numeric value is kept to calculate the rate
+// of change.
+type conditionState struct {
+	satisfied  bool
+	last       *thingEntities.Data
+	hasNumeric bool
+	numeric    float64
+	numericAt  time.Time
+}
+
+// trigger represents a rule satisfied by the data, with the last value of
+// each sensor in its conditions
+type trigger struct {
+	rule entities.Rule
+	data []thingEntities.Data
+}
+
+// OnDataPublished evaluates the enabled rules of the thing on the data, in
+// the order it was published, executing the actions of the rules which
+// become satisfied
+func (i *RuleInteractor) OnDataPublished(thing *thingEntities.Thing, data []thingEntities.Data) {
+	rules, err := i.store.ListByThing(thing.Token)
+	if err != nil {
+		i.logger.Errorf("error getting thing %s rules: %s", thing.ID, err)
+		return
+	}
+
+	triggers := []trigger{}
+	for _, rule := range rules {
+		if rule.Enabled {
+			triggers = append(triggers, i.evaluate(rule, data)...)
+		}
+	}
+
+	for _, t := range triggers {
+		i.logger.Infof("rule %s triggered", t.rule.ID)
+		i.execute(t)
+	}
+}
+
+func (i *RuleInteractor) evaluate(rule entities.Rule, data []thingEntities.Data) []trigger {
+	i.statesMutex.Lock()
+	defer i.statesMutex.Unlock()
+
+	// the state is rebuilt when it was kept for another version of the rule,
+	// e.g. the rule was loaded after being updated but before its state was
+	// reset
+	state, ok := i.states[rule.ID]
+	if !ok || len(state.conditions) != len(rule.Conditions) {
+		state = &ruleState{conditions: make([]conditionState, len(rule.Conditions))}
+		i.states[rule.ID] = state
+	}
+
+	triggers := []trigger{}
+	for idx := range data {
+		d := data[idx]
+		evaluated := false
+		for ci, c := range rule.Conditions {
+			if c.SensorID == d.SensorID {
+				state.conditions[ci].update(c, d)
+				evaluated = true
+			}
+		}
+		if !evaluated {
+			continue
+		}
+
+		satisfied := true
+		for _, cs := range state.conditions {
+			satisfied = satisfied && cs.satisfied
+		}
+
+		if satisfied && !state.satisfied {
+			triggers = append(triggers, trigger{rule, state.lastValues(rule)})
+		}
+		state.satisfied = satisfied
+	}
+
+	return triggers
+}
+
+// saveAndReset stores the changed rule and resets its state, so the
+// evaluation of the new rule doesn't start from the previous one's state
+func (i *RuleInteractor) saveAndReset(rule entities.Rule) error {
+	i.statesMutex.Lock()
+	defer i.statesMutex.Unlock()
+
+	delete(i.states, rule.ID)
+	return i.store.Save(rule)
+}
+
+// removeAndReset removes the rule and its state
+func (i *RuleInteractor) removeAndReset(id string) error {
+	i.statesMutex.Lock()
+	defer i.statesMutex.Unlock()
+
+	delete(i.states, id)
+	return i.store.Remove(id)
+}
+
+// execute runs the triggered rule's actions. The actions are independent,
+// so a failure is logged and the remaining actions are still executed. The
+// commands sent by the actions aren't tracked.
+func (i *RuleInteractor) execute(t trigger) {
+	for _, action := range t.rule.Actions {
+		var err error
+		switch action.Type {
+		case entities.ActionUpdateData:
+			err = i.thingPublisher.PublishUpdateData(action.ThingID, "", action.Data)
+		case entities.ActionRequestData:
+			err = i.thingPublisher.PublishRequestData(action.ThingID, "", action.SensorIDs)
+		case entities.ActionEvent:
+			err = i.publisher.PublishRuleTriggered(t.rule, t.data, i.now())
+		}
+
+		if errors.Is(err, thingAMQP.ErrUndeliverable) {
+			i.logger.Warn(err)
+		} else if err != nil {
+			i.logger.Errorf("error executing rule %s %s action: %s", t.rule.ID, action.Type, err)
+		}
+	}
+}
+
+// lastValues returns the last value of each sensor in the rule's conditions
+func (s *ruleState) lastValues(rule entities.Rule) []thingEntities.Data {
+	data := []thingEntities.Data{}
+	seen := map[int]bool{}
+	for ci, c := range rule.Conditions {
+		last := s.conditions[ci].last
+		if last != nil && !seen[c.SensorID] {
+			seen[c.SensorID] = true
+			data = append(data, *last)
+		}
+	}
+
+	return data
+}
+
+// update evaluates the condition on the sensor's new value. While satisfied,
+// the threshold and rate conditions are relaxed by the hysteresis.
+func (cs *conditionState) update(c entities.Condition, d thingEntities.Data) {
+	at := d.ReadAt(time.Time{})
+	value, numeric := thingEntities.NumericValue(d.Value)
+	threshold, _ := c.Value.(float64)
+
+	switch c.Operator {
+	case entities.OperatorEqual:
+		cs.satisfied = thingEntities.EqualValues(d.Value, c.Value)
+	case entities.OperatorNotEqual:
+		cs.satisfied = !thingEntities.EqualValues(d.Value, c.Value)
+	case entities.OperatorGreater, entities.OperatorGreaterEqual, entities.OperatorLess, entities.OperatorLessEqual:
+		cs.satisfied = numeric && cs.compare(c, value, threshold)
+	case entities.OperatorRateGreater, entities.OperatorRateLess:
+		switch {
+		case !numeric || !cs.hasNumeric:
+			cs.satisfied = false
+		case at.After(cs.numericAt):
+			rate := (value - cs.numeric) / at.Sub(cs.numericAt).Seconds()
+			cs.satisfied = cs.compare(c, rate, threshold)
+		}
+	}
+
+	cs.last = &d
+	cs.hasNumeric = numeric
+	cs.numeric = value
+	cs.numericAt = at
+}
+
+func (cs *conditionState) compare(c entities.Condition, value, threshold float64) bool {
+	h := 0.0
+	if cs.satisfied {
+		h = c.Hysteresis
+	}
+
+	switch c.Operator {
+	case entities.OperatorGreater, entities.OperatorRateGreater:
+		return value > threshold-h
+	case entities.OperatorGreaterEqual:
+		return value >= threshold-h
+	case entities.OperatorLess, entities.OperatorRateLess:
+		return value < threshold+h
+	case entities.OperatorLessEqual:
+		return value <= threshold+h
+	default:
+		return false
+	}
+}
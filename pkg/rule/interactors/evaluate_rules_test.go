@@ -0,0 +1,219 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/storage"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var triggeredAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func readingAt(sensorID int, value interface{}, seconds int) thingEntities.Data {
+	timestamp := triggeredAt.Add(time.Duration(seconds) * time.Second)
+	return thingEntities.Data{SensorID: sensorID, Value: value, Timestamp: &timestamp}
+}
+
+func reading(sensorID int, value interface{}) thingEntities.Data {
+	return thingEntities.Data{SensorID: sensorID, Value: value}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	testCases := []struct {
+		name              string
+		conditions        []entities.Condition
+		enabled           bool
+		published         [][]thingEntities.Data
+		expectedTriggered [][]thingEntities.Data
+	}{
+		{
+			"greater than is triggered when the value crosses the threshold",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30)}},
+			true,
+			[][]thingEntities.Data{{reading(1, 29.5), reading(1, 30.0), reading(1, 30.5)}, {reading(1, 31.0)}},
+			[][]thingEntities.Data{{reading(1, 30.5)}},
+		},
+		{
+			"greater than is triggered again after the value goes back",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30)}},
+			true,
+			[][]thingEntities.Data{{reading(1, 30.5)}, {reading(1, 29.5)}, {reading(1, 31.5)}},
+			[][]thingEntities.Data{{reading(1, 30.5)}, {reading(1, 31.5)}},
+		},
+		{
+			"less than or equal is triggered on the threshold",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorLessEqual, Value: float64(10)}},
+			true,
+			[][]thingEntities.Data{{reading(1, 10.5), reading(1, 10.0)}},
+			[][]thingEntities.Data{{reading(1, 10.0)}},
+		},
+		{
+			"hysteresis keeps the condition satisfied around the threshold",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30), Hysteresis: 2}},
+			true,
+			[][]thingEntities.Data{{reading(1, 30.5), reading(1, 29.5), reading(1, 30.5), reading(1, 27.5), reading(1, 30.5)}},
+			[][]thingEntities.Data{{reading(1, 30.5)}, {reading(1, 30.5)}},
+		},
+		{
+			"hysteresis doesn't relax the threshold before triggering",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorLess, Value: float64(10), Hysteresis: 2}},
+			true,
+			[][]thingEntities.Data{{reading(1, 11.5), reading(1, 10.0)}},
+			[][]thingEntities.Data{},
+		},
+		{
+			"equal compares booleans",
+			[]entities.Condition{{SensorID: 2, Operator: entities.OperatorEqual, Value: true}},
+			true,
+			[][]thingEntities.Data{{reading(2, false), reading(2, true), reading(2, true)}},
+			[][]thingEntities.Data{{reading(2, true)}},
+		},
+		{
+			"not equal compares strings",
+			[]entities.Condition{{SensorID: 3, Operator: entities.OperatorNotEqual, Value: "closed"}},
+			true,
+			[][]thingEntities.Data{{reading(3, "closed"), reading(3, "open")}},
+			[][]thingEntities.Data{{reading(3, "open")}},
+		},
+		{
+			"rate of change is calculated per second",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorRateGreater, Value: float64(0.5)}},
+			true,
+			[][]thingEntities.Data{{readingAt(1, 20.0, 0), readingAt(1, 24.0, 10)}, {readingAt(1, 34.0, 20)}},
+			[][]thingEntities.Data{{readingAt(1, 34.0, 20)}},
+		},
+		{
+			"rate of change decreasing",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorRateLess, Value: float64(-1)}},
+			true,
+			[][]thingEntities.Data{{readingAt(1, 20.0, 0)}, {readingAt(1, 5.0, 10)}},
+			[][]thingEntities.Data{{readingAt(1, 5.0, 10)}},
+		},
+		{
+			"all the conditions must be satisfied",
+			[]entities.Condition{
+				{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30)},
+				{SensorID: 2, Operator: entities.OperatorEqual, Value: true},
+			},
+			true,
+			[][]thingEntities.Data{{reading(1, 30.5)}, {reading(2, false)}, {reading(2, true)}},
+			[][]thingEntities.Data{{reading(1, 30.5), reading(2, true)}},
+		},
+		{
+			"disabled rule isn't evaluated",
+			[]entities.Condition{{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30)}},
+			false,
+			[][]thingEntities.Data{{reading(1, 30.5)}},
+			[][]thingEntities.Data{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := storage.NewMemoryRuleStore()
+			rule := entities.Rule{
+				ID:         "rule-id",
+				Name:       "rule",
+				ThingID:    sensorThing.ID,
+				Enabled:    tc.enabled,
+				Conditions: tc.conditions,
+				Actions:    []entities.Action{{Type: entities.ActionEvent}},
+				Owner:      "user@test.com",
+				ThingToken: sensorThing.Token,
+			}
+			assert.NoError(t, store.Save(rule))
+
+			publisher := &mocks.FakeRulePublisher{}
+			triggered := [][]thingEntities.Data{}
+			publisher.On("PublishRuleTriggered", rule, mock.Anything, triggeredAt).
+				Run(func(args mock.Arguments) {
+					triggered = append(triggered, args.Get(1).([]thingEntities.Data))
+				}).
+				Return(nil)
+
+			interactor := NewRuleInteractor(&mocks.FakeLogger{}, &mocks.FakeUserProxy{}, &mocks.FakeThingProxy{}, &mocks.FakePublisher{}, publisher, store)
+			interactor.now = func() time.Time { return triggeredAt }
+			for _, data := range tc.published {
+				interactor.OnDataPublished(sensorThing, data)
+			}
+
+			assert.Equal(t, tc.expectedTriggered, triggered)
+		})
+	}
+}
+
+func TestExecuteRuleActions(t *testing.T) {
+	rule := fanRule()
+	rule.ID = "rule-id"
+	rule.ThingToken = sensorThing.Token
+	store := storage.NewMemoryRuleStore()
+	assert.NoError(t, store.Save(rule))
+
+	thingPublisher := &mocks.FakePublisher{}
+	thingPublisher.On("PublishUpdateData", "fan-thing", "", rule.Actions[0].Data).Return(errors.New("broker unavailable"))
+	thingPublisher.On("PublishRequestData", "sensor-thing", "", []int{2}).Return(nil)
+	publisher := &mocks.FakeRulePublisher{}
+	publisher.On("PublishRuleTriggered", rule, []thingEntities.Data{reading(1, 30.5)}, triggeredAt).Return(nil)
+
+	interactor := NewRuleInteractor(&mocks.FakeLogger{}, &mocks.FakeUserProxy{}, &mocks.FakeThingProxy{}, thingPublisher, publisher, store)
+	interactor.now = func() time.Time { return triggeredAt }
+	interactor.OnDataPublished(sensorThing, []thingEntities.Data{reading(1, 30.5)})
+	interactor.OnDataPublished(&thingEntities.Thing{ID: "sensor-thing", Token: "other-mainflux-id"}, []thingEntities.Data{reading(1, 29.5), reading(1, 30.5)})
+
+	// the remaining actions are executed even though the first one failed
+	thingPublisher.AssertNumberOfCalls(t, "PublishUpdateData", 1)
+	thingPublisher.AssertNumberOfCalls(t, "PublishRequestData", 1)
+	publisher.AssertNumberOfCalls(t, "PublishRuleTriggered", 1)
+}
+
+func TestUpdateRuleResetsState(t *testing.T) {
+	store := storage.NewMemoryRuleStore()
+	interactor := newManagedInteractor(store)
+	publisher := &mocks.FakeRulePublisher{}
+	publisher.On("PublishRuleTriggered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	interactor.publisher = publisher
+
+	rule := fanRule()
+	rule.Actions = []entities.Action{{Type: entities.ActionEvent}}
+	created, err := interactor.Create("user-token", rule)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	interactor.OnDataPublished(sensorThing, []thingEntities.Data{reading(1, 30.5)})
+	_, err = interactor.Update("user-token", created.ID, rule)
+	assert.NoError(t, err)
+	interactor.OnDataPublished(sensorThing, []thingEntities.Data{reading(1, 31.5)})
+
+	publisher.AssertNumberOfCalls(t, "PublishRuleTriggered", 2)
+}
+
+func TestEvaluateRuleWithStateOfPreviousVersion(t *testing.T) {
+	rule := fanRule()
+	rule.ID = "rule-id"
+	rule.ThingToken = sensorThing.Token
+	rule.Actions = []entities.Action{{Type: entities.ActionEvent}}
+	store := storage.NewMemoryRuleStore()
+	assert.NoError(t, store.Save(rule))
+
+	publisher := &mocks.FakeRulePublisher{}
+	publisher.On("PublishRuleTriggered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	interactor := NewRuleInteractor(&mocks.FakeLogger{}, &mocks.FakeUserProxy{}, &mocks.FakeThingProxy{}, &mocks.FakePublisher{}, publisher, store)
+	interactor.OnDataPublished(sensorThing, []thingEntities.Data{reading(1, 29.5)})
+
+	// the rule is changed in the store while the previous version's state is
+	// still kept, as when the data is evaluated during an update
+	rule.Conditions = append(rule.Conditions, entities.Condition{SensorID: 2, Operator: entities.OperatorEqual, Value: true})
+	assert.NoError(t, store.Save(rule))
+	assert.NotPanics(t, func() {
+		interactor.OnDataPublished(sensorThing, []thingEntities.Data{reading(1, 30.5), reading(2, true)})
+	})
+
+	publisher.AssertNumberOfCalls(t, "PublishRuleTriggered", 1)
+}
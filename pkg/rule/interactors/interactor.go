@@ -0,0 +1,62 @@
+package interactors
+
+import (
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	ruleAMQP "github.com/CESARBR/knot-babeltower/pkg/rule/delivery/amqp"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/storage"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+	thingHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
+	userHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
+)
+
+// Interactor is an interface that defines the rule's use cases operations
+type Interactor interface {
+	Create(authorization string, rule entities.Rule) (*entities.Rule, error)
+	List(authorization string) ([]entities.Rule, error)
+	Get(authorization, id string) (*entities.Rule, error)
+	Update(authorization, id string, rule entities.Rule) (*entities.Rule, error)
+	Delete(authorization, id string) error
+}
+
+// RuleInteractor represents the rule interactor capabilities, it's composed
+// by the necessary dependencies. It's notified about the data published by
+// the things to evaluate the rules.
+type RuleInteractor struct {
+	logger         logging.Logger
+	userProxy      userHTTP.UserProxy
+	thingProxy     thingHTTP.ThingProxy
+	thingPublisher thingAMQP.Publisher
+	publisher      ruleAMQP.Publisher
+	store          storage.RuleStore
+	now            func() time.Time
+
+	// states holds the evaluation state of each rule, which is lost when the
+	// rule is changed or the service is restarted
+	statesMutex sync.Mutex
+	states      map[string]*ruleState
+}
+
+// NewRuleInteractor creates a new RuleInteractor instance
+func NewRuleInteractor(
+	logger logging.Logger,
+	userProxy userHTTP.UserProxy,
+	thingProxy thingHTTP.ThingProxy,
+	thingPublisher thingAMQP.Publisher,
+	publisher ruleAMQP.Publisher,
+	store storage.RuleStore,
+) *RuleInteractor {
+	return &RuleInteractor{
+		logger:         logger,
+		userProxy:      userProxy,
+		thingProxy:     thingProxy,
+		thingPublisher: thingPublisher,
+		publisher:      publisher,
+		store:          store,
+		now:            time.Now,
+		states:         map[string]*ruleState{},
+	}
+}
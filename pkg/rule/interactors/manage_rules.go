@@ -0,0 +1,264 @@
+package interactors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/internal/ids"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// Create validates and stores a new rule of the token's user
+func (i *RuleInteractor) Create(authorization string, rule entities.Rule) (*entities.Rule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	thingToken, err := i.validate(authorization, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID, err = ids.New()
+	if err != nil {
+		return nil, fmt.Errorf("error generating rule's id: %w", err)
+	}
+	rule.Owner = owner
+	rule.ThingToken = thingToken
+
+	err = i.store.Save(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error storing rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// List returns the rules of the token's user
+func (i *RuleInteractor) List(authorization string) ([]entities.Rule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.store.List(owner)
+}
+
+// Get returns the rule when it belongs to the token's user
+func (i *RuleInteractor) Get(authorization, id string) (*entities.Rule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.ownedRule(owner, id)
+}
+
+// Update validates and replaces the rule when it belongs to the token's user.
+// The rule's evaluation starts over, as if it was just created.
+func (i *RuleInteractor) Update(authorization, id string, rule entities.Rule) (*entities.Rule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = i.ownedRule(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	thingToken, err := i.validate(authorization, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID = id
+	rule.Owner = owner
+	rule.ThingToken = thingToken
+	err = i.saveAndReset(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error storing rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// Delete removes the rule when it belongs to the token's user
+func (i *RuleInteractor) Delete(authorization, id string) error {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.ownedRule(owner, id)
+	if err != nil {
+		return err
+	}
+
+	return i.removeAndReset(id)
+}
+
+func (i *RuleInteractor) identify(authorization string) (string, error) {
+	if authorization == "" {
+		return "", thingInteractors.ErrAuthNotProvided
+	}
+
+	owner, err := i.userProxy.Identify(authorization)
+	if err != nil {
+		return "", fmt.Errorf("error identifying user: %w", err)
+	}
+
+	return owner, nil
+}
+
+// ownedRule returns the rule when it belongs to the user. The rules of other
+// users aren't found, so their IDs aren't disclosed.
+func (i *RuleInteractor) ownedRule(owner, id string) (*entities.Rule, error) {
+	rule, err := i.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Owner != owner {
+		return nil, entities.ErrRuleNotFound
+	}
+
+	return rule, nil
+}
+
+// validate verifies the rule's conditions refer to the sensors of the rule's
+// thing and its actions to the user's things, returning the thing's ID on
+// the things service
+func (i *RuleInteractor) validate(authorization string, rule entities.Rule) (string, error) {
+	if rule.Name == "" {
+		return "", fmt.Errorf("%w: name not provided", ErrRuleInvalid)
+	}
+	if rule.ThingID == "" {
+		return "", fmt.Errorf("%w: thing's id not provided", ErrRuleInvalid)
+	}
+	if len(rule.Conditions) == 0 {
+		return "", fmt.Errorf("%w: conditions not provided", ErrRuleInvalid)
+	}
+	if len(rule.Actions) == 0 {
+		return "", fmt.Errorf("%w: actions not provided", ErrRuleInvalid)
+	}
+
+	thing, err := i.getThing(authorization, rule.ThingID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range rule.Conditions {
+		err = validateCondition(c, thing.Schema)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, a := range rule.Actions {
+		err = i.validateAction(authorization, a)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return thing.Token, nil
+}
+
+func (i *RuleInteractor) validateAction(authorization string, action entities.Action) error {
+	switch action.Type {
+	case entities.ActionEvent:
+		return nil
+	case entities.ActionUpdateData, entities.ActionRequestData:
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrRuleInvalid, action.Type)
+	}
+
+	if action.ThingID == "" {
+		return fmt.Errorf("%w: %s action's thing id not provided", ErrRuleInvalid, action.Type)
+	}
+
+	thing, err := i.getThing(authorization, action.ThingID)
+	if err != nil {
+		return err
+	}
+
+	if action.Type == entities.ActionUpdateData {
+		if len(action.Data) == 0 {
+			return fmt.Errorf("%w: %s action's data not provided", ErrRuleInvalid, action.Type)
+		}
+		err = thingInteractors.ValidateData(action.Data, thing.Schema)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrRuleInvalid, err)
+		}
+		return nil
+	}
+
+	if len(action.SensorIDs) == 0 {
+		return fmt.Errorf("%w: %s action's sensors not provided", ErrRuleInvalid, action.Type)
+	}
+	for _, id := range action.SensorIDs {
+		if !hasSensor(thing.Schema, id) {
+			return fmt.Errorf("%w: sensor %d not in thing %s schema", ErrRuleInvalid, id, thing.ID)
+		}
+	}
+
+	return nil
+}
+
+// getThing returns the user's thing. A thing which isn't found makes the
+// rule invalid, while the other errors are returned as they are.
+func (i *RuleInteractor) getThing(authorization, id string) (*thingEntities.Thing, error) {
+	thing, err := i.thingProxy.Get(authorization, id)
+	if errors.Is(err, thingEntities.ErrThingNotFound) {
+		return nil, fmt.Errorf("%w: thing %s not found", ErrRuleInvalid, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing metadata: %w", err)
+	}
+
+	return thing, nil
+}
+
+func validateCondition(c entities.Condition, schema []thingEntities.Schema) error {
+	if !hasSensor(schema, c.SensorID) {
+		return fmt.Errorf("%w: sensor %d not in thing's schema", ErrRuleInvalid, c.SensorID)
+	}
+	if c.Hysteresis < 0 {
+		return fmt.Errorf("%w: negative hysteresis", ErrRuleInvalid)
+	}
+
+	switch c.Operator {
+	case entities.OperatorEqual, entities.OperatorNotEqual:
+		switch c.Value.(type) {
+		case float64, bool, string:
+		default:
+			return fmt.Errorf("%w: the %s operator requires a number, boolean or string value", ErrRuleInvalid, c.Operator)
+		}
+		if c.Hysteresis != 0 {
+			return fmt.Errorf("%w: hysteresis not supported by the %s operator", ErrRuleInvalid, c.Operator)
+		}
+	case entities.OperatorGreater, entities.OperatorGreaterEqual,
+		entities.OperatorLess, entities.OperatorLessEqual,
+		entities.OperatorRateGreater, entities.OperatorRateLess:
+		if _, ok := c.Value.(float64); !ok {
+			return fmt.Errorf("%w: the %s operator requires a numeric value", ErrRuleInvalid, c.Operator)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrRuleInvalid, c.Operator)
+	}
+
+	return nil
+}
+
+func hasSensor(schema []thingEntities.Schema, sensorID int) bool {
+	for _, s := range schema {
+		if s.SensorID == sensorID {
+			return true
+		}
+	}
+	return false
+}
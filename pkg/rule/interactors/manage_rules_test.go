@@ -0,0 +1,214 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/storage"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	sensorThing = &thingEntities.Thing{ID: "sensor-thing", Token: "sensor-mainflux-id", Schema: []thingEntities.Schema{
+		{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"},
+		{SensorID: 2, ValueType: 3, Unit: 0, TypeID: 65521, Name: "presence"},
+	}}
+	fanThing = &thingEntities.Thing{ID: "fan-thing", Token: "fan-mainflux-id", Schema: []thingEntities.Schema{
+		{SensorID: 1, ValueType: 3, Unit: 0, TypeID: 65521, Name: "fan"},
+	}}
+)
+
+func fanRule() entities.Rule {
+	return entities.Rule{
+		Name:       "turn on the fan",
+		ThingID:    "sensor-thing",
+		Enabled:    true,
+		Conditions: []entities.Condition{{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30)}},
+		Actions: []entities.Action{
+			{Type: entities.ActionUpdateData, ThingID: "fan-thing", Data: []thingEntities.Data{{SensorID: 1, Value: true}}},
+			{Type: entities.ActionRequestData, ThingID: "sensor-thing", SensorIDs: []int{2}},
+			{Type: entities.ActionEvent},
+		},
+	}
+}
+
+func newManagedInteractor(store storage.RuleStore) *RuleInteractor {
+	userProxy := &mocks.FakeUserProxy{}
+	userProxy.On("Identify", "user-token").Return("user@test.com", nil)
+	userProxy.On("Identify", "other-user-token").Return("other@test.com", nil)
+	userProxy.On("Identify", "invalid-token").Return("", userEntities.ErrUserForbidden)
+	thingProxy := &mocks.FakeThingProxy{}
+	thingProxy.On("Get", "user-token", "sensor-thing").Return(sensorThing, nil)
+	thingProxy.On("Get", "user-token", "fan-thing").Return(fanThing, nil)
+	thingProxy.On("Get", "user-token", "unknown-thing").Return((*thingEntities.Thing)(nil), thingEntities.ErrThingNotFound)
+
+	return NewRuleInteractor(&mocks.FakeLogger{}, userProxy, thingProxy, &mocks.FakePublisher{}, &mocks.FakeRulePublisher{}, store)
+}
+
+func TestCreateRule(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authorization string
+		change        func(rule *entities.Rule)
+		expectedErr   error
+	}{
+		{
+			"authorization token not provided",
+			"",
+			func(rule *entities.Rule) {},
+			thingInteractors.ErrAuthNotProvided,
+		},
+		{
+			"invalid authorization token",
+			"invalid-token",
+			func(rule *entities.Rule) {},
+			userEntities.ErrUserForbidden,
+		},
+		{
+			"name not provided",
+			"user-token",
+			func(rule *entities.Rule) { rule.Name = "" },
+			ErrRuleInvalid,
+		},
+		{
+			"conditions not provided",
+			"user-token",
+			func(rule *entities.Rule) { rule.Conditions = nil },
+			ErrRuleInvalid,
+		},
+		{
+			"actions not provided",
+			"user-token",
+			func(rule *entities.Rule) { rule.Actions = nil },
+			ErrRuleInvalid,
+		},
+		{
+			"thing not found",
+			"user-token",
+			func(rule *entities.Rule) { rule.ThingID = "unknown-thing" },
+			ErrRuleInvalid,
+		},
+		{
+			"condition's sensor not in the thing's schema",
+			"user-token",
+			func(rule *entities.Rule) { rule.Conditions[0].SensorID = 3 },
+			ErrRuleInvalid,
+		},
+		{
+			"unknown operator",
+			"user-token",
+			func(rule *entities.Rule) { rule.Conditions[0].Operator = "between" },
+			ErrRuleInvalid,
+		},
+		{
+			"threshold without numeric value",
+			"user-token",
+			func(rule *entities.Rule) { rule.Conditions[0].Value = "30" },
+			ErrRuleInvalid,
+		},
+		{
+			"negative hysteresis",
+			"user-token",
+			func(rule *entities.Rule) { rule.Conditions[0].Hysteresis = -1 },
+			ErrRuleInvalid,
+		},
+		{
+			"hysteresis on equality",
+			"user-token",
+			func(rule *entities.Rule) {
+				rule.Conditions[0] = entities.Condition{SensorID: 2, Operator: entities.OperatorEqual, Value: true, Hysteresis: 1}
+			},
+			ErrRuleInvalid,
+		},
+		{
+			"unknown action",
+			"user-token",
+			func(rule *entities.Rule) { rule.Actions[0].Type = "email" },
+			ErrRuleInvalid,
+		},
+		{
+			"action's thing not found",
+			"user-token",
+			func(rule *entities.Rule) { rule.Actions[0].ThingID = "unknown-thing" },
+			ErrRuleInvalid,
+		},
+		{
+			"update data incompatible with the thing's schema",
+			"user-token",
+			func(rule *entities.Rule) { rule.Actions[0].Data[0].Value = float64(1.5) },
+			ErrRuleInvalid,
+		},
+		{
+			"requested sensor not in the thing's schema",
+			"user-token",
+			func(rule *entities.Rule) { rule.Actions[1].SensorIDs = []int{3} },
+			ErrRuleInvalid,
+		},
+		{
+			"valid rule",
+			"user-token",
+			func(rule *entities.Rule) {},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := storage.NewMemoryRuleStore()
+			interactor := newManagedInteractor(store)
+			rule := fanRule()
+			tc.change(&rule)
+
+			created, err := interactor.Create(tc.authorization, rule)
+
+			assert.True(t, errors.Is(err, tc.expectedErr), "unexpected error %v", err)
+			rules, _ := store.List("user@test.com")
+			if tc.expectedErr != nil {
+				assert.Nil(t, created)
+				assert.Empty(t, rules)
+				return
+			}
+
+			assert.NotEmpty(t, created.ID)
+			assert.Equal(t, "user@test.com", created.Owner)
+			assert.Equal(t, "sensor-mainflux-id", created.ThingToken)
+			assert.Equal(t, []entities.Rule{*created}, rules)
+		})
+	}
+}
+
+func TestManageRulesOwnership(t *testing.T) {
+	store := storage.NewMemoryRuleStore()
+	interactor := newManagedInteractor(store)
+	created, err := interactor.Create("user-token", fanRule())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = interactor.Get("other-user-token", created.ID)
+	assert.Equal(t, entities.ErrRuleNotFound, err)
+	_, err = interactor.Update("other-user-token", created.ID, fanRule())
+	assert.Equal(t, entities.ErrRuleNotFound, err)
+	assert.Equal(t, entities.ErrRuleNotFound, interactor.Delete("other-user-token", created.ID))
+	rules, err := interactor.List("other-user-token")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	changed := fanRule()
+	changed.Enabled = false
+	updated, err := interactor.Update("user-token", created.ID, changed)
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	rule, err := interactor.Get("user-token", created.ID)
+	assert.NoError(t, err)
+	assert.False(t, rule.Enabled)
+
+	assert.NoError(t, interactor.Delete("user-token", created.ID))
+	_, err = interactor.Get("user-token", created.ID)
+	assert.Equal(t, entities.ErrRuleNotFound, err)
+}
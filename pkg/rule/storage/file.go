@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+)
+
+// FileRuleStore keeps the rules in memory and writes them to a JSON file on
+// every change, so they're restored when the service restarts
+type FileRuleStore struct {
+	file   *jsonfile.File
+	memory *MemoryRuleStore
+}
+
+// record represents a rule written to the file, along with the fields which
+// aren't exposed to the users
+type record struct {
+	Owner      string        `json:"owner"`
+	ThingToken string        `json:"thingToken"`
+	Rule       entities.Rule `json:"rule"`
+}
+
+// NewFileRuleStore creates a new FileRuleStore instance loading the rules
+// previously written to the file, which is created when it doesn't exist yet
+func NewFileRuleStore(path string) (*FileRuleStore, error) {
+	s := &FileRuleStore{memory: NewMemoryRuleStore()}
+	s.file = jsonfile.New(path, "rules", 0, s.records)
+
+	records := []record{}
+	err := s.file.Load(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+		r.Rule.Owner = r.Owner
+		r.Rule.ThingToken = r.ThingToken
+		s.memory.rules[r.Rule.ID] = r.Rule
+	}
+
+	return s, nil
+}
+
+// Save stores the rule, replacing the previous one with the same ID, and
+// writes all the rules to the file
+func (s *FileRuleStore) Save(rule entities.Rule) error {
+	return s.file.Update(func() error { return s.memory.Save(rule) })
+}
+
+// Get returns the rule with the ID
+func (s *FileRuleStore) Get(id string) (*entities.Rule, error) {
+	return s.memory.Get(id)
+}
+
+// List returns the user's rules sorted by their name
+func (s *FileRuleStore) List(owner string) ([]entities.Rule, error) {
+	return s.memory.List(owner)
+}
+
+// ListByThing returns the rules evaluated on the thing's data sorted by
+// their name
+func (s *FileRuleStore) ListByThing(thingToken string) ([]entities.Rule, error) {
+	return s.memory.ListByThing(thingToken)
+}
+
+// Remove removes the rule with the ID and writes the remaining rules to the
+// file
+func (s *FileRuleStore) Remove(id string) error {
+	return s.file.Update(func() error { return s.memory.Remove(id) })
+}
+
+// records returns the file's content. It's called by the file while no
+// change is being made.
+func (s *FileRuleStore) records() interface{} {
+	rules := s.memory.filter(func(entities.Rule) bool { return true })
+	records := make([]record, 0, len(rules))
+	for _, rule := range rules {
+		records = append(records, record{rule.Owner, rule.ThingToken, rule})
+	}
+
+	return records
+}
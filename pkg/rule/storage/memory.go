@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+)
+
+// MemoryRuleStore keeps the rules in memory, so they're lost when the service
+// is restarted
+type MemoryRuleStore struct {
+	mutex sync.RWMutex
+	rules map[string]entities.Rule
+}
+
+// NewMemoryRuleStore creates a new MemoryRuleStore instance
+func NewMemoryRuleStore() *MemoryRuleStore {
+	return &MemoryRuleStore{rules: map[string]entities.Rule{}}
+}
+
+// Save stores the rule, replacing the previous one with the same ID
+func (s *MemoryRuleStore) Save(rule entities.Rule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rules[rule.ID] = rule
+	return nil
+}
+
+// Get returns the rule with the ID
+func (s *MemoryRuleStore) Get(id string) (*entities.Rule, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rule, ok := s.rules[id]
+	if !ok {
+		return nil, entities.ErrRuleNotFound
+	}
+
+	return &rule, nil
+}
+
+// List returns the user's rules sorted by their name
+func (s *MemoryRuleStore) List(owner string) ([]entities.Rule, error) {
+	return s.filter(func(rule entities.Rule) bool { return rule.Owner == owner }), nil
+}
+
+// ListByThing returns the rules evaluated on the thing's data sorted by
+// their name
+func (s *MemoryRuleStore) ListByThing(thingToken string) ([]entities.Rule, error) {
+	return s.filter(func(rule entities.Rule) bool { return rule.ThingToken == thingToken }), nil
+}
+
+// Remove removes the rule with the ID
+func (s *MemoryRuleStore) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.rules[id]; !ok {
+		return entities.ErrRuleNotFound
+	}
+
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *MemoryRuleStore) filter(match func(rule entities.Rule) bool) []entities.Rule {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rules := []entities.Rule{}
+	for _, rule := range s.rules {
+		if match(rule) {
+			rules = append(rules, rule)
+		}
+	}
+
+	sortRules(rules)
+	return rules
+}
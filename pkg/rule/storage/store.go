@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+)
+
+// RuleStore represents the storage of the users' rules
+type RuleStore interface {
+	Save(rule entities.Rule) error
+	Get(id string) (*entities.Rule, error)
+	List(owner string) ([]entities.Rule, error)
+	ListByThing(thingToken string) ([]entities.Rule, error)
+	Remove(id string) error
+}
+
+// sortRules sorts the rules by their name, and ID for the same name
+func sortRules(rules []entities.Rule) {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Name != rules[j].Name {
+			return rules[i].Name < rules[j].Name
+		}
+		return rules[i].ID < rules[j].ID
+	})
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/rule/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rules")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func newRule(id, name, owner, thingToken string) entities.Rule {
+	return entities.Rule{
+		ID:         id,
+		Name:       name,
+		ThingID:    "fbe64efa6c7f717e",
+		Enabled:    true,
+		Conditions: []entities.Condition{{SensorID: 1, Operator: entities.OperatorGreater, Value: float64(30)}},
+		Actions:    []entities.Action{{Type: entities.ActionEvent}},
+		Owner:      owner,
+		ThingToken: thingToken,
+	}
+}
+
+func TestRuleStores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T) RuleStore
+	}{
+		{
+			"memory",
+			func(t *testing.T) RuleStore {
+				return NewMemoryRuleStore()
+			},
+		},
+		{
+			"file",
+			func(t *testing.T) RuleStore {
+				store, err := NewFileRuleStore(filepath.Join(tempDir(t), "data", "rules.json"))
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := tc.newStore(t)
+			fan := newRule("1", "fan", "user@test.com", "mainflux-id")
+			alarm := newRule("2", "alarm", "user@test.com", "other-mainflux-id")
+			other := newRule("3", "other", "other@test.com", "mainflux-id")
+			for _, rule := range []entities.Rule{fan, alarm, other} {
+				assert.NoError(t, store.Save(rule))
+			}
+
+			fan.Enabled = false
+			assert.NoError(t, store.Save(fan))
+			rule, err := store.Get("1")
+			assert.NoError(t, err)
+			assert.Equal(t, &fan, rule)
+
+			rules, err := store.List("user@test.com")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Rule{alarm, fan}, rules)
+
+			rules, err = store.ListByThing("mainflux-id")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Rule{fan, other}, rules)
+
+			assert.NoError(t, store.Remove("1"))
+			_, err = store.Get("1")
+			assert.Equal(t, entities.ErrRuleNotFound, err)
+			assert.Equal(t, entities.ErrRuleNotFound, store.Remove("1"))
+		})
+	}
+}
+
+func TestFileRuleStoreRestoresRules(t *testing.T) {
+	path := filepath.Join(tempDir(t), "rules.json")
+	rule := newRule("1", "fan", "user@test.com", "mainflux-id")
+	store, err := NewFileRuleStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(rule))
+
+	restored, err := NewFileRuleStore(path)
+	assert.NoError(t, err)
+
+	stored, err := restored.Get("1")
+	assert.NoError(t, err)
+	assert.Equal(t, &rule, stored)
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/delivery/amqp"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/interactors"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// ScheduleController handles the schedule commands received from the queue
+type ScheduleController interface {
+	Create(body []byte, authorization, replyTo, corrID string) error
+	Update(body []byte, authorization, replyTo, corrID string) error
+	Delete(body []byte, authorization, replyTo, corrID string) error
+	List(authorization, replyTo, corrID string) error
+}
+
+type scheduleController struct {
+	logger             logging.Logger
+	scheduleInteractor interactors.Interactor
+	sender             amqp.Sender
+}
+
+// NewScheduleController constructs the ScheduleController
+func NewScheduleController(logger logging.Logger, scheduleInteractor interactors.Interactor, sender amqp.Sender) ScheduleController {
+	return &scheduleController{logger, scheduleInteractor, sender}
+}
+
+// Create handles the create schedule request and execute its use case
+func (sc *scheduleController) Create(body []byte, authorization, replyTo, corrID string) error {
+	var req network.ScheduleRequest
+	err := json.Unmarshal(body, &req)
+	if err != nil {
+		return fmt.Errorf("message body parsing error: %w", err)
+	}
+
+	sc.logger.Info("create schedule command received")
+	if replyTo == "" {
+		return thingInteractors.ErrReplyToNotProvided
+	}
+
+	if corrID == "" {
+		return sc.replySchedule(nil, replyTo, corrID, thingInteractors.ErrCorrelationIDNotProvided)
+	}
+
+	created, err := sc.scheduleInteractor.Create(authorization, toSchedule(req))
+	return sc.replySchedule(created, replyTo, corrID, err)
+}
+
+// Update handles the update schedule request and execute its use case
+func (sc *scheduleController) Update(body []byte, authorization, replyTo, corrID string) error {
+	var req network.ScheduleUpdateRequest
+	err := json.Unmarshal(body, &req)
+	if err != nil {
+		return fmt.Errorf("message body parsing error: %w", err)
+	}
+
+	sc.logger.Info("update schedule command received")
+	if replyTo == "" {
+		return thingInteractors.ErrReplyToNotProvided
+	}
+
+	if corrID == "" {
+		return sc.replySchedule(nil, replyTo, corrID, thingInteractors.ErrCorrelationIDNotProvided)
+	}
+
+	updated, err := sc.scheduleInteractor.Update(authorization, req.ID, toSchedule(req.ScheduleRequest))
+	return sc.replySchedule(updated, replyTo, corrID, err)
+}
+
+// Delete handles the delete schedule request and execute its use case
+func (sc *scheduleController) Delete(body []byte, authorization, replyTo, corrID string) error {
+	var req network.ScheduleDeleteRequest
+	err := json.Unmarshal(body, &req)
+	if err != nil {
+		return fmt.Errorf("message body parsing error: %w", err)
+	}
+
+	sc.logger.Info("delete schedule command received")
+	if replyTo == "" {
+		return thingInteractors.ErrReplyToNotProvided
+	}
+
+	if corrID == "" {
+		err = thingInteractors.ErrCorrelationIDNotProvided
+	} else {
+		err = sc.scheduleInteractor.Delete(authorization, req.ID)
+	}
+
+	sendErr := sc.sender.SendScheduleDeletedResponse(req.ID, replyTo, corrID, err)
+	if sendErr != nil {
+		return fmt.Errorf("error sending response: %v: %w", err, sendErr)
+	}
+
+	return err
+}
+
+// List handles the list schedules request and execute its use case
+func (sc *scheduleController) List(authorization, replyTo, corrID string) error {
+	sc.logger.Info("list schedules command received")
+	if replyTo == "" {
+		return thingInteractors.ErrReplyToNotProvided
+	}
+
+	var schedules []entities.Schedule
+	var err error
+	if corrID == "" {
+		err = thingInteractors.ErrCorrelationIDNotProvided
+	} else {
+		schedules, err = sc.scheduleInteractor.List(authorization)
+	}
+
+	sendErr := sc.sender.SendScheduleListResponse(schedules, replyTo, corrID, err)
+	if sendErr != nil {
+		return fmt.Errorf("error sending response: %v: %w", err, sendErr)
+	}
+
+	return err
+}
+
+// replySchedule sends the response, returning the use case error so the
+// message is settled accordingly
+func (sc *scheduleController) replySchedule(schedule *entities.Schedule, replyTo, corrID string, err error) error {
+	sendErr := sc.sender.SendScheduleResponse(schedule, replyTo, corrID, err)
+	if sendErr != nil {
+		return fmt.Errorf("error sending response: %v: %w", err, sendErr)
+	}
+
+	return err
+}
+
+// toSchedule converts the request to a schedule, which is enabled when not
+// informed otherwise
+func toSchedule(req network.ScheduleRequest) entities.Schedule {
+	return entities.Schedule{
+		Name:     req.Name,
+		ThingID:  req.ThingID,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+		Cron:     req.Cron,
+		At:       req.At,
+		Timezone: req.Timezone,
+		Action:   req.Action,
+	}
+}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/interactors"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/gorilla/mux"
+)
+
+// ScheduleHTTPController handles the HTTP requests to manage the users'
+// schedules
+type ScheduleHTTPController struct {
+	logger             logging.Logger
+	scheduleInteractor interactors.Interactor
+}
+
+// NewScheduleHTTPController constructs the controller
+func NewScheduleHTTPController(logger logging.Logger, scheduleInteractor interactors.Interactor) *ScheduleHTTPController {
+	return &ScheduleHTTPController{logger, scheduleInteractor}
+}
+
+// Create godoc
+// @Summary Creates a new schedule
+// @Description The schedule sends its action to the thing at the times of the cron expression or once at the informed time, with the user's token.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param schedule body network.ScheduleRequest true "Schedule's thing, times and action"
+// @Success 201 {object} entities.Schedule "Created schedule with its id"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or schedule"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /schedules [post]
+// Create handles the server request and calls the create schedule use case
+func (sc *ScheduleHTTPController) Create(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := sc.parseSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := sc.scheduleInteractor.Create(r.Header.Get("Authorization"), schedule)
+	if err != nil {
+		sc.writeError(w, err)
+		return
+	}
+
+	sc.logger.Infof("schedule %s created", created.ID)
+	w.Header().Set("Location", "/schedules/"+created.ID)
+	sc.writeResponse(w, http.StatusCreated, created)
+}
+
+// List godoc
+// @Summary Lists the user's schedules
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Success 200 {array} entities.Schedule "User's schedules"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /schedules [get]
+// List handles the server request and calls the list schedules use case
+func (sc *ScheduleHTTPController) List(w http.ResponseWriter, r *http.Request) {
+	schedules, err := sc.scheduleInteractor.List(r.Header.Get("Authorization"))
+	if err != nil {
+		sc.writeError(w, err)
+		return
+	}
+
+	sc.writeResponse(w, http.StatusOK, schedules)
+}
+
+// Get godoc
+// @Summary Gets a schedule
+// @Description The schedule is returned with its next run and the outcome of its last runs.
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Schedule's id"
+// @Success 200 {object} entities.Schedule "Schedule"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Schedule not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /schedules/{id} [get]
+// Get handles the server request and calls the get schedule use case
+func (sc *ScheduleHTTPController) Get(w http.ResponseWriter, r *http.Request) {
+	schedule, err := sc.scheduleInteractor.Get(r.Header.Get("Authorization"), mux.Vars(r)["id"])
+	if err != nil {
+		sc.writeError(w, err)
+		return
+	}
+
+	sc.writeResponse(w, http.StatusOK, schedule)
+}
+
+// Update godoc
+// @Summary Updates a schedule
+// @Description The schedule keeps its runs and runs with the token from then on.
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Schedule's id"
+// @Param schedule body network.ScheduleRequest true "Schedule's thing, times and action"
+// @Success 200 {object} entities.Schedule "Updated schedule"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Schedule not found"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid request format or schedule"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /schedules/{id} [put]
+// Update handles the server request and calls the update schedule use case
+func (sc *ScheduleHTTPController) Update(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := sc.parseSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	updated, err := sc.scheduleInteractor.Update(r.Header.Get("Authorization"), id, schedule)
+	if err != nil {
+		sc.writeError(w, err)
+		return
+	}
+
+	sc.logger.Infof("schedule %s updated", id)
+	sc.writeResponse(w, http.StatusOK, updated)
+}
+
+// Delete godoc
+// @Summary Deletes a schedule
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Schedule's id"
+// @Success 204 "Schedule deleted"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Schedule not found"
+// @Failure 500 {object} controllers.ErrorResponse "Internal server error"
+// @Router /schedules/{id} [delete]
+// Delete handles the server request and calls the delete schedule use case
+func (sc *ScheduleHTTPController) Delete(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	err := sc.scheduleInteractor.Delete(r.Header.Get("Authorization"), id)
+	if err != nil {
+		sc.writeError(w, err)
+		return
+	}
+
+	sc.logger.Infof("schedule %s deleted", id)
+	sc.writeResponse(w, http.StatusNoContent, nil)
+}
+
+// parseSchedule decodes the schedule from the request's body. When it isn't
+// possible, the request is answered with 422 Unprocessable Entity.
+func (sc *ScheduleHTTPController) parseSchedule(w http.ResponseWriter, r *http.Request) (entities.Schedule, bool) {
+	var req network.ScheduleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sc.logger.Error("failed to parse request body")
+		sc.writeResponse(w, http.StatusUnprocessableEntity, &thingControllers.ErrorResponse{Message: err.Error()})
+		return entities.Schedule{}, false
+	}
+
+	return toSchedule(req), true
+}
+
+func (sc *ScheduleHTTPController) writeError(w http.ResponseWriter, err error) {
+	sc.logger.Error(err)
+	sc.writeResponse(w, mapScheduleErrorToStatusCode(err), &thingControllers.ErrorResponse{Message: err.Error()})
+}
+
+func (sc *ScheduleHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	if msg == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	js, err := json.Marshal(msg)
+	if err != nil {
+		sc.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		sc.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func mapScheduleErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, thingInteractors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, userEntities.ErrUserForbidden),
+		errors.Is(err, thingEntities.ErrThingForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrScheduleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, interactors.ErrScheduleInvalid):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
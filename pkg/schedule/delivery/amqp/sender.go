@@ -0,0 +1,75 @@
+package amqp
+
+import (
+	"encoding/json"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	thingAMQP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+)
+
+const (
+	exchangeDevices     = "device"
+	exchangeDevicesType = "direct"
+)
+
+// Sender represents the operations to send the schedule commands response
+type Sender interface {
+	SendScheduleResponse(schedule *entities.Schedule, replyTo, corrID string, err error) error
+	SendScheduleDeletedResponse(id, replyTo, corrID string, err error) error
+	SendScheduleListResponse(schedules []entities.Schedule, replyTo, corrID string, err error) error
+}
+
+// commandSender handle messages received from a service
+type commandSender struct {
+	logger logging.Logger
+	amqp   network.AmqpSender
+}
+
+// NewCommandSender creates a new commandSender instance
+func NewCommandSender(logger logging.Logger, amqp network.AmqpSender) Sender {
+	return &commandSender{logger, amqp}
+}
+
+// SendScheduleResponse sends the create and update schedule commands response
+func (cs *commandSender) SendScheduleResponse(schedule *entities.Schedule, replyTo, corrID string, err error) error {
+	resp := &network.ScheduleResponse{Schedule: schedule, Error: getErrMsg(err)}
+	return cs.reply(resp, replyTo, corrID)
+}
+
+// SendScheduleDeletedResponse sends the delete schedule command response
+func (cs *commandSender) SendScheduleDeletedResponse(id, replyTo, corrID string, err error) error {
+	resp := &network.ScheduleDeletedResponse{ID: id, Error: getErrMsg(err)}
+	return cs.reply(resp, replyTo, corrID)
+}
+
+// SendScheduleListResponse sends the list schedules command response
+func (cs *commandSender) SendScheduleListResponse(schedules []entities.Schedule, replyTo, corrID string, err error) error {
+	if schedules == nil {
+		schedules = []entities.Schedule{}
+	}
+
+	resp := &network.ScheduleListResponse{Schedules: schedules, Error: getErrMsg(err)}
+	return cs.reply(resp, replyTo, corrID)
+}
+
+func (cs *commandSender) reply(resp interface{}, replyTo, corrID string) error {
+	headers := map[string]interface{}{
+		"correlation_id": corrID,
+	}
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return thingAMQP.MapPublishError(cs.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, replyTo, msg, headers))
+}
+
+func getErrMsg(err error) *string {
+	if err != nil {
+		msg := err.Error()
+		return &msg
+	}
+	return nil
+}
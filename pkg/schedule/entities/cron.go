@@ -0,0 +1,201 @@
+package entities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit is how far the next time of a cron expression is searched,
+// enough for the expressions matching only the 29th of February
+const cronSearchLimit = 5
+
+// Cron represents a parsed cron expression, formed by the minute, hour, day
+// of month, month and day of week fields. Each field is a list of values,
+// ranges (1-5) or steps (*/15, 8-18/2), and the months and days of week can
+// be informed by their first three letters (JAN, MON). The expressions
+// @yearly, @monthly, @weekly, @daily and @hourly are also accepted.
+type Cron struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// as in the standard cron, the day matches either the day of month or
+	// the day of week when both are restricted, and both otherwise
+	anyDay     bool
+	anyWeekday bool
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var (
+	minuteField  = cronField{"minute", 0, 59, nil}
+	hourField    = cronField{"hour", 0, 23, nil}
+	dayField     = cronField{"day of month", 1, 31, nil}
+	monthField   = cronField{"month", 1, 12, []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	weekdayField = cronField{"day of week", 0, 7, []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// ParseCron parses the cron expression
+func ParseCron(expr string) (*Cron, error) {
+	if macro, ok := cronMacros[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q must have 5 fields", ErrCronInvalid, expr)
+	}
+
+	c := &Cron{
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, f := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&c.minutes, minuteField},
+		{&c.hours, hourField},
+		{&c.days, dayField},
+		{&c.months, monthField},
+		{&c.weekdays, weekdayField},
+	} {
+		*f.bits, err = f.field.parse(fields[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Sunday can be informed either as 0 or 7
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays = c.weekdays&^(1<<7) | 1
+	}
+
+	return c, nil
+}
+
+// Next returns the first time matching the expression after the informed
+// time, in its location. The zero time is returned when no time matches,
+// such as on the 30th of February.
+func (c *Cron) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchLimit, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *Cron) matchDay(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// parse returns the field's values as a bit set
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: invalid %s step %q", ErrCronInvalid, f.name, part)
+			}
+		}
+
+		lo, hi, err := f.parseRange(rangeExpr, step > 1)
+		if err != nil {
+			return 0, err
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseRange returns the limits of the range, which goes up to the field's
+// maximum when a single value is stepped
+func (f cronField) parseRange(expr string, stepped bool) (int, int, error) {
+	if expr == "*" {
+		return f.min, f.max, nil
+	}
+
+	bounds := strings.SplitN(expr, "-", 2)
+	lo, err := f.parseValue(bounds[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hi := lo
+	if len(bounds) == 2 {
+		hi, err = f.parseValue(bounds[1])
+		if err != nil {
+			return 0, 0, err
+		}
+	} else if stepped {
+		hi = f.max
+	}
+
+	if lo > hi {
+		return 0, 0, fmt.Errorf("%w: invalid %s range %q", ErrCronInvalid, f.name, expr)
+	}
+
+	return lo, hi, nil
+}
+
+func (f cronField) parseValue(expr string) (int, error) {
+	for v, name := range f.names {
+		if name != "" && strings.EqualFold(expr, name) {
+			return v, nil
+		}
+	}
+
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrCronInvalid, f.name, expr)
+	}
+
+	return v, nil
+}
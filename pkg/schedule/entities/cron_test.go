@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronNext(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	testCases := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			"every weekday at 22:00",
+			"0 22 * * MON-FRI",
+			time.Date(2020, 4, 3, 22, 0, 0, 0, time.UTC), // friday
+			time.Date(2020, 4, 6, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			"every 15 minutes",
+			"*/15 * * * *",
+			time.Date(2020, 4, 1, 12, 7, 30, 0, time.UTC),
+			time.Date(2020, 4, 1, 12, 15, 0, 0, time.UTC),
+		},
+		{
+			"stepped range and list",
+			"0,30 8-18/5 * * *",
+			time.Date(2020, 4, 1, 13, 30, 0, 0, time.UTC),
+			time.Date(2020, 4, 1, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			"day of month or day of week when both are restricted",
+			"0 0 15 * 7",
+			time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 4, 5, 0, 0, 0, 0, time.UTC), // sunday
+		},
+		{
+			"29th of february",
+			"0 0 29 FEB *",
+			time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"macro",
+			"@monthly",
+			time.Date(2020, 12, 31, 23, 59, 0, 0, time.UTC),
+			time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"time zone",
+			"0 22 * * *",
+			time.Date(2020, 4, 1, 23, 0, 0, 0, time.UTC).In(saoPaulo),
+			time.Date(2020, 4, 1, 22, 0, 0, 0, saoPaulo),
+		},
+		{
+			"no matching time",
+			"0 0 30 2 *",
+			time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Time{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cron, err := ParseCron(tc.expr)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.True(t, tc.expected.Equal(cron.Next(tc.after)), "next time: %s", cron.Next(tc.after))
+		})
+	}
+}
+
+func TestParseInvalidCron(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "* * * * MON-", "*/0 * * * *", "5-1 * * * *", "@never"} {
+		_, err := ParseCron(expr)
+		assert.True(t, errors.Is(err, ErrCronInvalid), "expression %q", expr)
+	}
+}
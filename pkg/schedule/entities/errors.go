@@ -0,0 +1,12 @@
+package entities
+
+import "errors"
+
+var (
+	// ErrScheduleNotFound is returned when the schedule isn't found among the
+	// user's schedules
+	ErrScheduleNotFound = errors.New("schedule not found")
+
+	// ErrCronInvalid is returned when the cron expression can't be parsed
+	ErrCronInvalid = errors.New("invalid cron expression")
+)
@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// Actions sent to the thing when the schedule runs
+const (
+	ActionUpdateData  = "updateData"
+	ActionRequestData = "requestData"
+)
+
+// Schedule represents a command sent to one of the user's things at the
+// times of a cron expression or, for the one-shot schedules, once at the
+// informed time. The cron expression is evaluated in the schedule's time
+// zone.
+type Schedule struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	ThingID  string     `json:"thingId"`
+	Enabled  bool       `json:"enabled"`
+	Cron     string     `json:"cron,omitempty"`
+	At       *time.Time `json:"at,omitempty"`
+	Timezone string     `json:"timezone,omitempty"`
+	Action   Action     `json:"action"`
+	NextRun  *time.Time `json:"nextRun,omitempty"`
+	Runs     []Run      `json:"runs"`
+
+	// Owner is the e-mail of the user who created the schedule
+	Owner string `json:"-"`
+	// ThingToken is the thing's ID on the things service, which is unique
+	// among all the users
+	ThingToken string `json:"-"`
+	// Authorization is the user's token the commands are sent with
+	Authorization string `json:"-"`
+}
+
+// Action represents the data update or request sent to the schedule's thing
+type Action struct {
+	Type      string               `json:"type"`
+	Data      []thingEntities.Data `json:"data,omitempty"`
+	SensorIDs []int                `json:"sensorIds,omitempty"`
+}
+
+// Run represents the outcome of sending the schedule's command to the thing.
+// The command's status can be followed by its ID, while the error is the
+// reason it couldn't be sent.
+type Run struct {
+	At        time.Time `json:"at"`
+	CommandID string    `json:"commandId"`
+	Error     string    `json:"error,omitempty"`
+}
@@ -0,0 +1,7 @@
+package interactors
+
+import "errors"
+
+// ErrScheduleInvalid is returned when the schedule has an invalid format or
+// refers to things and sensors which don't exist
+var ErrScheduleInvalid = errors.New("invalid schedule")
@@ -0,0 +1,170 @@
+package interactors
+
+import (
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/storage"
+	thingHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
+)
+
+// Interactor is an interface that defines the schedule's use cases operations
+type Interactor interface {
+	Create(authorization string, schedule entities.Schedule) (*entities.Schedule, error)
+	List(authorization string) ([]entities.Schedule, error)
+	Get(authorization, id string) (*entities.Schedule, error)
+	Update(authorization, id string, schedule entities.Schedule) (*entities.Schedule, error)
+	Delete(authorization, id string) error
+}
+
+// ScheduleInteractor represents the schedule interactor capabilities, it's
+// composed by the necessary dependencies. It sends the schedules' commands
+// through the thing interactor, with the token of the user who created or
+// last updated the schedule.
+type ScheduleInteractor struct {
+	logger          logging.Logger
+	userProxy       userHTTP.UserProxy
+	thingProxy      thingHTTP.ThingProxy
+	thingInteractor thingInteractors.Interactor
+	store           storage.ScheduleStore
+	maxRuns         int
+	now             func() time.Time
+
+	// mutex serializes the changes to the schedules and their timers, so a
+	// schedule isn't changed while its run is recorded
+	mutex   sync.Mutex
+	timers  map[string]*timer
+	stopped bool
+}
+
+// timer identifies the next run of a schedule, which is replaced when the
+// schedule is changed
+type timer struct {
+	*time.Timer
+}
+
+// NewScheduleInteractor creates a new ScheduleInteractor instance. The last
+// maxRuns runs of each schedule are kept, unless it's zero.
+func NewScheduleInteractor(
+	logger logging.Logger,
+	userProxy userHTTP.UserProxy,
+	thingProxy thingHTTP.ThingProxy,
+	thingInteractor thingInteractors.Interactor,
+	store storage.ScheduleStore,
+	maxRuns int,
+) *ScheduleInteractor {
+	return &ScheduleInteractor{
+		logger:          logger,
+		userProxy:       userProxy,
+		thingProxy:      thingProxy,
+		thingInteractor: thingInteractor,
+		store:           store,
+		maxRuns:         maxRuns,
+		now:             time.Now,
+		timers:          map[string]*timer{},
+	}
+}
+
+// Start schedules the next runs of the schedules restored by the store. The
+// one-shot schedules missed while the service was stopped run immediately,
+// while the missed runs of the cron schedules are skipped.
+func (i *ScheduleInteractor) Start() error {
+	schedules, err := i.store.ListAll()
+	if err != nil {
+		return err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	now := i.now()
+	for _, schedule := range schedules {
+		if schedule.NextRun == nil {
+			continue
+		}
+
+		if schedule.Cron != "" && schedule.NextRun.Before(now) {
+			i.logger.Infof("schedule %s missed the run at %s", schedule.ID, schedule.NextRun)
+			i.plan(&schedule, now)
+			err = i.store.Save(schedule)
+			if err != nil {
+				return err
+			}
+		}
+
+		i.schedule(schedule)
+	}
+
+	return nil
+}
+
+// Stop stops the timers, so no more runs are started
+func (i *ScheduleInteractor) Stop() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.stopped = true
+	for id, t := range i.timers {
+		t.Stop()
+		delete(i.timers, id)
+	}
+}
+
+// plan sets the schedule's next run after the informed time, which is unset
+// when the schedule is disabled or won't run anymore
+func (i *ScheduleInteractor) plan(schedule *entities.Schedule, after time.Time) {
+	schedule.NextRun = nil
+	if !schedule.Enabled {
+		return
+	}
+
+	if schedule.Cron == "" {
+		if schedule.At != nil && schedule.At.After(after) {
+			at := *schedule.At
+			schedule.NextRun = &at
+		}
+		return
+	}
+
+	cron, err := entities.ParseCron(schedule.Cron)
+	if err != nil {
+		i.logger.Errorf("error parsing schedule %s cron: %s", schedule.ID, err)
+		return
+	}
+
+	// the schedules are validated, so the time zone is known
+	loc, _ := time.LoadLocation(schedule.Timezone)
+	next := cron.Next(after.In(loc))
+	if !next.IsZero() {
+		schedule.NextRun = &next
+	}
+}
+
+// schedule starts the timer of the schedule's next run, replacing the
+// previous one. It must be called holding the mutex.
+func (i *ScheduleInteractor) schedule(schedule entities.Schedule) {
+	i.unschedule(schedule.ID)
+	if schedule.NextRun == nil || i.stopped {
+		return
+	}
+
+	id := schedule.ID
+	t := &timer{}
+	t.Timer = time.AfterFunc(schedule.NextRun.Sub(i.now()), func() {
+		i.run(id, t)
+	})
+	i.timers[id] = t
+}
+
+// unschedule stops the timer of the schedule's next run. It must be called
+// holding the mutex.
+func (i *ScheduleInteractor) unschedule(id string) {
+	if t, ok := i.timers[id]; ok {
+		t.Stop()
+		delete(i.timers, id)
+	}
+}
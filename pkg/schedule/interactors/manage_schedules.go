@@ -0,0 +1,254 @@
+package interactors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/internal/ids"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+)
+
+// Create validates and stores a new schedule of the token's user, which runs
+// with the token
+func (i *ScheduleInteractor) Create(authorization string, schedule entities.Schedule) (*entities.Schedule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	thingToken, err := i.validate(authorization, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.ID, err = ids.New()
+	if err != nil {
+		return nil, fmt.Errorf("error generating schedule's id: %w", err)
+	}
+	schedule.Owner = owner
+	schedule.ThingToken = thingToken
+	schedule.Authorization = authorization
+	schedule.Runs = []entities.Run{}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.plan(&schedule, i.now())
+	err = i.store.Save(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("error storing schedule: %w", err)
+	}
+
+	i.schedule(schedule)
+	return &schedule, nil
+}
+
+// List returns the schedules of the token's user
+func (i *ScheduleInteractor) List(authorization string) ([]entities.Schedule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.store.List(owner)
+}
+
+// Get returns the schedule when it belongs to the token's user
+func (i *ScheduleInteractor) Get(authorization, id string) (*entities.Schedule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.ownedSchedule(owner, id)
+}
+
+// Update validates and replaces the schedule when it belongs to the token's
+// user, keeping its runs. The schedule runs with the token from then on.
+func (i *ScheduleInteractor) Update(authorization, id string, schedule entities.Schedule) (*entities.Schedule, error) {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = i.ownedSchedule(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	thingToken, err := i.validate(authorization, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	// the runs are read again, since one may have finished meanwhile
+	previous, err := i.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.ID = id
+	schedule.Owner = owner
+	schedule.ThingToken = thingToken
+	schedule.Authorization = authorization
+	schedule.Runs = previous.Runs
+	i.plan(&schedule, i.now())
+	err = i.store.Save(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("error storing schedule: %w", err)
+	}
+
+	i.schedule(schedule)
+	return &schedule, nil
+}
+
+// Delete removes the schedule when it belongs to the token's user
+func (i *ScheduleInteractor) Delete(authorization, id string) error {
+	owner, err := i.identify(authorization)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.ownedSchedule(owner, id)
+	if err != nil {
+		return err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	err = i.store.Remove(id)
+	if err != nil {
+		return err
+	}
+
+	i.unschedule(id)
+	return nil
+}
+
+func (i *ScheduleInteractor) identify(authorization string) (string, error) {
+	if authorization == "" {
+		return "", thingInteractors.ErrAuthNotProvided
+	}
+
+	owner, err := i.userProxy.Identify(authorization)
+	if err != nil {
+		return "", fmt.Errorf("error identifying user: %w", err)
+	}
+
+	return owner, nil
+}
+
+// ownedSchedule returns the schedule when it belongs to the user. The
+// schedules of other users aren't found, so their IDs aren't disclosed.
+func (i *ScheduleInteractor) ownedSchedule(owner, id string) (*entities.Schedule, error) {
+	schedule, err := i.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.Owner != owner {
+		return nil, entities.ErrScheduleNotFound
+	}
+
+	return schedule, nil
+}
+
+// validate verifies the schedule has either a valid cron expression or a
+// time to run and its action refers to the sensors of the user's thing,
+// returning the thing's ID on the things service
+func (i *ScheduleInteractor) validate(authorization string, schedule entities.Schedule) (string, error) {
+	if schedule.Name == "" {
+		return "", fmt.Errorf("%w: name not provided", ErrScheduleInvalid)
+	}
+	if schedule.ThingID == "" {
+		return "", fmt.Errorf("%w: thing's id not provided", ErrScheduleInvalid)
+	}
+	if (schedule.Cron == "") == (schedule.At == nil) {
+		return "", fmt.Errorf("%w: either cron or at must be provided", ErrScheduleInvalid)
+	}
+
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("%w: unknown time zone %q", ErrScheduleInvalid, schedule.Timezone)
+	}
+
+	if schedule.Cron != "" {
+		cron, err := entities.ParseCron(schedule.Cron)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrScheduleInvalid, err)
+		}
+		if cron.Next(i.now().In(loc)).IsZero() {
+			return "", fmt.Errorf("%w: cron %q never runs", ErrScheduleInvalid, schedule.Cron)
+		}
+	} else if schedule.Enabled && !schedule.At.After(i.now()) {
+		return "", fmt.Errorf("%w: time to run already passed", ErrScheduleInvalid)
+	}
+
+	thing, err := i.getThing(authorization, schedule.ThingID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateAction(schedule.Action, thing)
+	if err != nil {
+		return "", err
+	}
+
+	return thing.Token, nil
+}
+
+func validateAction(action entities.Action, thing *thingEntities.Thing) error {
+	switch action.Type {
+	case entities.ActionUpdateData:
+		if len(action.Data) == 0 {
+			return fmt.Errorf("%w: %s action's data not provided", ErrScheduleInvalid, action.Type)
+		}
+		err := thingInteractors.ValidateData(action.Data, thing.Schema)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrScheduleInvalid, err)
+		}
+		return nil
+	case entities.ActionRequestData:
+		if len(action.SensorIDs) == 0 {
+			return fmt.Errorf("%w: %s action's sensors not provided", ErrScheduleInvalid, action.Type)
+		}
+		for _, id := range action.SensorIDs {
+			if !hasSensor(thing.Schema, id) {
+				return fmt.Errorf("%w: sensor %d not in thing %s schema", ErrScheduleInvalid, id, thing.ID)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrScheduleInvalid, action.Type)
+	}
+}
+
+// getThing returns the user's thing. A thing which isn't found makes the
+// schedule invalid, while the other errors are returned as they are.
+func (i *ScheduleInteractor) getThing(authorization, id string) (*thingEntities.Thing, error) {
+	thing, err := i.thingProxy.Get(authorization, id)
+	if errors.Is(err, thingEntities.ErrThingNotFound) {
+		return nil, fmt.Errorf("%w: thing %s not found", ErrScheduleInvalid, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing metadata: %w", err)
+	}
+
+	return thing, nil
+}
+
+func hasSensor(schema []thingEntities.Schema, sensorID int) bool {
+	for _, s := range schema {
+		if s.SensorID == sensorID {
+			return true
+		}
+	}
+	return false
+}
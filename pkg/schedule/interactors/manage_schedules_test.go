@@ -0,0 +1,242 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/storage"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	thingInteractors "github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// scheduledAt is a wednesday
+var scheduledAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+var lampThing = &thingEntities.Thing{ID: "lamp-thing", Token: "lamp-mainflux-id", Schema: []thingEntities.Schema{
+	{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"},
+	{SensorID: 2, ValueType: 3, Unit: 0, TypeID: 65521, Name: "lamp"},
+}}
+
+func newTestInteractor(store storage.ScheduleStore) (*ScheduleInteractor, *mocks.FakeThingInteractor) {
+	userProxy := &mocks.FakeUserProxy{}
+	userProxy.On("Identify", "user-token").Return("user@test.com", nil)
+	userProxy.On("Identify", "other-user-token").Return("other@test.com", nil)
+	userProxy.On("Identify", "invalid-token").Return("", userEntities.ErrUserForbidden)
+	thingProxy := &mocks.FakeThingProxy{}
+	thingProxy.On("Get", "user-token", "lamp-thing").Return(lampThing, nil)
+	thingProxy.On("Get", "user-token", "unknown-thing").Return((*thingEntities.Thing)(nil), thingEntities.ErrThingNotFound)
+	thingInteractor := &mocks.FakeThingInteractor{}
+	thingInteractor.On("UpdateData", "user-token", "lamp-thing", mock.Anything, mock.Anything).Return(nil)
+	thingInteractor.On("RequestData", "user-token", "lamp-thing", mock.Anything, mock.Anything).Return(nil)
+
+	interactor := NewScheduleInteractor(&mocks.FakeLogger{}, userProxy, thingProxy, thingInteractor, store, 2)
+	interactor.now = func() time.Time { return scheduledAt }
+	return interactor, thingInteractor
+}
+
+func lampSchedule() entities.Schedule {
+	return entities.Schedule{
+		Name:    "switch off the lamp",
+		ThingID: "lamp-thing",
+		Enabled: true,
+		Cron:    "0 22 * * MON-FRI",
+		Action:  entities.Action{Type: entities.ActionUpdateData, Data: []thingEntities.Data{{SensorID: 2, Value: false}}},
+	}
+}
+
+func TestCreateSchedule(t *testing.T) {
+	in := func(d time.Duration) *time.Time {
+		at := scheduledAt.Add(d)
+		return &at
+	}
+
+	testCases := []struct {
+		name            string
+		authorization   string
+		change          func(schedule *entities.Schedule)
+		expectedNextRun *time.Time
+		expectedErr     error
+	}{
+		{
+			"cron schedule",
+			"user-token",
+			func(schedule *entities.Schedule) {},
+			in(10 * time.Hour),
+			nil,
+		},
+		{
+			"cron schedule in a time zone",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.Timezone = "Etc/GMT+3" },
+			in(13 * time.Hour),
+			nil,
+		},
+		{
+			"one-shot schedule",
+			"user-token",
+			func(schedule *entities.Schedule) {
+				schedule.Cron = ""
+				schedule.At = in(time.Hour)
+			},
+			in(time.Hour),
+			nil,
+		},
+		{
+			"disabled schedule",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.Enabled = false },
+			nil,
+			nil,
+		},
+		{
+			"data request schedule",
+			"user-token",
+			func(schedule *entities.Schedule) {
+				schedule.Action = entities.Action{Type: entities.ActionRequestData, SensorIDs: []int{1}}
+			},
+			in(10 * time.Hour),
+			nil,
+		},
+		{
+			"authorization token not provided",
+			"",
+			func(schedule *entities.Schedule) {},
+			nil,
+			thingInteractors.ErrAuthNotProvided,
+		},
+		{
+			"invalid authorization token",
+			"invalid-token",
+			func(schedule *entities.Schedule) {},
+			nil,
+			userEntities.ErrUserForbidden,
+		},
+		{
+			"both cron and time to run",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.At = in(time.Hour) },
+			nil,
+			ErrScheduleInvalid,
+		},
+		{
+			"invalid cron",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.Cron = "0 25 * * *" },
+			nil,
+			ErrScheduleInvalid,
+		},
+		{
+			"unknown time zone",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.Timezone = "Mars/Olympus" },
+			nil,
+			ErrScheduleInvalid,
+		},
+		{
+			"time to run already passed",
+			"user-token",
+			func(schedule *entities.Schedule) {
+				schedule.Cron = ""
+				schedule.At = in(-time.Hour)
+			},
+			nil,
+			ErrScheduleInvalid,
+		},
+		{
+			"thing not found",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.ThingID = "unknown-thing" },
+			nil,
+			ErrScheduleInvalid,
+		},
+		{
+			"data incompatible with the schema",
+			"user-token",
+			func(schedule *entities.Schedule) { schedule.Action.Data[0].Value = "off" },
+			nil,
+			ErrScheduleInvalid,
+		},
+		{
+			"sensor not in the schema",
+			"user-token",
+			func(schedule *entities.Schedule) {
+				schedule.Action = entities.Action{Type: entities.ActionRequestData, SensorIDs: []int{3}}
+			},
+			nil,
+			ErrScheduleInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interactor, _ := newTestInteractor(storage.NewMemoryScheduleStore())
+			defer interactor.Stop()
+			schedule := lampSchedule()
+			tc.change(&schedule)
+
+			created, err := interactor.Create(tc.authorization, schedule)
+
+			assert.True(t, errors.Is(err, tc.expectedErr), "error: %v", err)
+			if tc.expectedErr != nil {
+				return
+			}
+			assert.NotEmpty(t, created.ID)
+			assert.Equal(t, "user@test.com", created.Owner)
+			assert.Equal(t, "lamp-mainflux-id", created.ThingToken)
+			assert.Equal(t, tc.authorization, created.Authorization)
+			if tc.expectedNextRun == nil {
+				assert.Nil(t, created.NextRun)
+				assert.NotContains(t, interactor.timers, created.ID)
+			} else if assert.NotNil(t, created.NextRun) {
+				assert.True(t, tc.expectedNextRun.Equal(*created.NextRun), "next run: %s", created.NextRun)
+				assert.Contains(t, interactor.timers, created.ID)
+			}
+		})
+	}
+}
+
+func TestManageSchedules(t *testing.T) {
+	interactor, _ := newTestInteractor(storage.NewMemoryScheduleStore())
+	defer interactor.Stop()
+	created, err := interactor.Create("user-token", lampSchedule())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = interactor.Get("other-user-token", created.ID)
+	assert.Equal(t, entities.ErrScheduleNotFound, err)
+	schedules, err := interactor.List("other-user-token")
+	assert.NoError(t, err)
+	assert.Empty(t, schedules)
+
+	runs := []entities.Run{{At: scheduledAt.Add(-time.Hour), CommandID: "4f2d9c1b7e6a8d3f"}}
+	stored := *created
+	stored.Runs = runs
+	assert.NoError(t, interactor.store.Save(stored))
+
+	update := lampSchedule()
+	update.Enabled = false
+	updated, err := interactor.Update("user-token", created.ID, update)
+	assert.NoError(t, err)
+	assert.Equal(t, runs, updated.Runs)
+	assert.Nil(t, updated.NextRun)
+	assert.NotContains(t, interactor.timers, created.ID)
+
+	_, err = interactor.Update("other-user-token", created.ID, update)
+	assert.Equal(t, entities.ErrScheduleNotFound, err)
+	assert.Equal(t, entities.ErrScheduleNotFound, interactor.Delete("other-user-token", created.ID))
+
+	schedules, err = interactor.List("user-token")
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Schedule{*updated}, schedules)
+
+	assert.NoError(t, interactor.Delete("user-token", created.ID))
+	_, err = interactor.Get("user-token", created.ID)
+	assert.Equal(t, entities.ErrScheduleNotFound, err)
+}
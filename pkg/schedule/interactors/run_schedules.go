@@ -0,0 +1,88 @@
+package interactors
+
+import (
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/internal/ids"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+)
+
+// run sends the schedule's command to its thing, records the run's outcome
+// and schedules the next run. The next run isn't changed when the schedule
+// was changed while its command was sent.
+func (i *ScheduleInteractor) run(id string, t *timer) {
+	i.mutex.Lock()
+	if i.stopped || i.timers[id] != t {
+		i.mutex.Unlock()
+		return
+	}
+	schedule, err := i.store.Get(id)
+	i.mutex.Unlock()
+	if err != nil {
+		i.logger.Errorf("error getting schedule %s: %s", id, err)
+		return
+	}
+
+	run := entities.Run{At: i.now()}
+	run.CommandID, err = ids.New()
+	if err == nil {
+		err = i.send(*schedule, run.CommandID)
+	}
+	if err != nil {
+		i.logger.Errorf("error running schedule %s: %s", id, err)
+		run.Error = err.Error()
+	} else {
+		i.logger.Infof("schedule %s sent command %s", id, run.CommandID)
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	current, err := i.store.Get(id)
+	if err != nil {
+		// the schedule was removed while running
+		return
+	}
+
+	current.Runs = i.appendRun(current.Runs, run)
+	changed := i.timers[id] != t
+	if !changed {
+		// the run isn't repeated when the timer fires before the planned
+		// time, e.g. due to clock adjustments
+		after := run.At
+		if schedule.NextRun != nil && schedule.NextRun.After(after) {
+			after = *schedule.NextRun
+		}
+		i.plan(current, after)
+	}
+
+	err = i.store.Save(*current)
+	if err != nil {
+		i.logger.Errorf("error storing schedule %s run: %s", id, err)
+	}
+
+	if !changed {
+		i.schedule(*current)
+	}
+}
+
+func (i *ScheduleInteractor) send(schedule entities.Schedule, commandID string) error {
+	switch schedule.Action.Type {
+	case entities.ActionUpdateData:
+		return i.thingInteractor.UpdateData(schedule.Authorization, schedule.ThingID, commandID, schedule.Action.Data)
+	case entities.ActionRequestData:
+		return i.thingInteractor.RequestData(schedule.Authorization, schedule.ThingID, commandID, schedule.Action.SensorIDs)
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrScheduleInvalid, schedule.Action.Type)
+	}
+}
+
+// appendRun appends the run to a copy of the runs, keeping the last maxRuns
+func (i *ScheduleInteractor) appendRun(runs []entities.Run, run entities.Run) []entities.Run {
+	runs = append(append([]entities.Run{}, runs...), run)
+	if i.maxRuns > 0 && len(runs) > i.maxRuns {
+		runs = runs[len(runs)-i.maxRuns:]
+	}
+
+	return runs
+}
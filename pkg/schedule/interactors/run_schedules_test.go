@@ -0,0 +1,161 @@
+package interactors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/storage"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunCronSchedule(t *testing.T) {
+	interactor, thingInteractor := newTestInteractor(storage.NewMemoryScheduleStore())
+	defer interactor.Stop()
+	created, err := interactor.Create("user-token", lampSchedule())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for n := 0; n < 3; n++ {
+		interactor.run(created.ID, interactor.timers[created.ID])
+	}
+
+	schedule, err := interactor.Get("user-token", created.ID)
+	assert.NoError(t, err)
+	if !assert.Len(t, schedule.Runs, 2) {
+		return
+	}
+	assert.Equal(t, scheduledAt, schedule.Runs[1].At)
+	assert.Empty(t, schedule.Runs[1].Error)
+	thingInteractor.AssertCalled(t, "UpdateData", "user-token", "lamp-thing", schedule.Runs[1].CommandID, []thingEntities.Data{{SensorID: 2, Value: false}})
+	thingInteractor.AssertNumberOfCalls(t, "UpdateData", 3)
+	// wednesday's, thursday's and friday's runs were sent
+	assert.Equal(t, scheduledAt.AddDate(0, 0, 5).Add(10*time.Hour), *schedule.NextRun)
+	assert.Contains(t, interactor.timers, created.ID)
+}
+
+func TestRunScheduleFailure(t *testing.T) {
+	interactor, thingInteractor := newTestInteractor(storage.NewMemoryScheduleStore())
+	defer interactor.Stop()
+	thingInteractor.ExpectedCalls = nil
+	thingInteractor.On("RequestData", "user-token", "lamp-thing", mock.Anything, []int{1}).Return(thingEntities.ErrThingNotFound)
+	schedule := lampSchedule()
+	schedule.Action = entities.Action{Type: entities.ActionRequestData, SensorIDs: []int{1}}
+	created, err := interactor.Create("user-token", schedule)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	interactor.run(created.ID, interactor.timers[created.ID])
+
+	stored, err := interactor.Get("user-token", created.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, stored.Runs, 1) {
+		assert.Equal(t, thingEntities.ErrThingNotFound.Error(), stored.Runs[0].Error)
+	}
+}
+
+func TestRunOneShotSchedule(t *testing.T) {
+	interactor, thingInteractor := newTestInteractor(storage.NewMemoryScheduleStore())
+	defer interactor.Stop()
+	sent := make(chan string, 1)
+	thingInteractor.ExpectedCalls = nil
+	thingInteractor.On("UpdateData", "user-token", "lamp-thing", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		sent <- args.String(2)
+	})
+	schedule := lampSchedule()
+	at := scheduledAt.Add(20 * time.Millisecond)
+	schedule.Cron = ""
+	schedule.At = &at
+	created, err := interactor.Create("user-token", schedule)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var commandID string
+	select {
+	case commandID = <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("schedule not run")
+	}
+
+	stored := waitRuns(t, interactor, created.ID, 1)
+	if assert.Len(t, stored.Runs, 1) {
+		assert.Equal(t, commandID, stored.Runs[0].CommandID)
+	}
+	assert.Nil(t, stored.NextRun)
+}
+
+// waitRuns waits the schedule's runs being recorded, which happens after the
+// command is sent
+func waitRuns(t *testing.T, interactor *ScheduleInteractor, id string, n int) *entities.Schedule {
+	deadline := time.Now().Add(time.Second)
+	for {
+		schedule, err := interactor.Get("user-token", id)
+		assert.NoError(t, err)
+		if err != nil || len(schedule.Runs) >= n || time.Now().After(deadline) {
+			return schedule
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStartRestoredSchedules(t *testing.T) {
+	store := storage.NewMemoryScheduleStore()
+	interactor, thingInteractor := newTestInteractor(store)
+	sent := make(chan string, 1)
+	thingInteractor.ExpectedCalls = nil
+	thingInteractor.On("UpdateData", "user-token", "lamp-thing", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		sent <- args.String(2)
+	})
+
+	missedRun := scheduledAt.Add(-time.Hour)
+	cron := lampSchedule()
+	cron.ID = "cron"
+	cron.NextRun = &missedRun
+	cron.Owner = "user@test.com"
+	cron.Authorization = "user-token"
+	oneShot := lampSchedule()
+	oneShot.ID = "one-shot"
+	oneShot.Cron = ""
+	oneShot.At = &missedRun
+	oneShot.NextRun = &missedRun
+	oneShot.Owner = "user@test.com"
+	oneShot.Authorization = "user-token"
+	for _, schedule := range []entities.Schedule{cron, oneShot} {
+		assert.NoError(t, store.Save(schedule))
+	}
+
+	assert.NoError(t, interactor.Start())
+	defer interactor.Stop()
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("missed one-shot schedule not run")
+	}
+	waitRuns(t, interactor, "one-shot", 1)
+
+	restored, err := store.Get("cron")
+	assert.NoError(t, err)
+	assert.Equal(t, scheduledAt.Add(10*time.Hour), *restored.NextRun)
+	thingInteractor.AssertNumberOfCalls(t, "UpdateData", 1)
+}
+
+func TestStoppedSchedules(t *testing.T) {
+	interactor, _ := newTestInteractor(storage.NewMemoryScheduleStore())
+	created, err := interactor.Create("user-token", lampSchedule())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	interactor.Stop()
+	assert.Empty(t, interactor.timers)
+
+	_, err = interactor.Update("user-token", created.ID, lampSchedule())
+	assert.NoError(t, err)
+	assert.Empty(t, interactor.timers)
+}
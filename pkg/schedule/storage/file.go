@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+)
+
+// FileScheduleStore keeps the schedules in memory and writes them to a JSON
+// file on every change, so they're restored when the service restarts
+type FileScheduleStore struct {
+	file   *jsonfile.File
+	memory *MemoryScheduleStore
+}
+
+// record represents a schedule written to the file, along with the fields
+// which aren't exposed to the users
+type record struct {
+	Owner         string            `json:"owner"`
+	ThingToken    string            `json:"thingToken"`
+	Authorization string            `json:"authorization"`
+	Schedule      entities.Schedule `json:"schedule"`
+}
+
+// NewFileScheduleStore creates a new FileScheduleStore instance loading the
+// schedules previously written to the file, which is created when it
+// doesn't exist yet
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	s := &FileScheduleStore{memory: NewMemoryScheduleStore()}
+	s.file = jsonfile.New(path, "schedules", 0, s.records)
+
+	records := []record{}
+	err := s.file.Load(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+		r.Schedule.Owner = r.Owner
+		r.Schedule.ThingToken = r.ThingToken
+		r.Schedule.Authorization = r.Authorization
+		s.memory.schedules[r.Schedule.ID] = r.Schedule
+	}
+
+	return s, nil
+}
+
+// Save stores the schedule, replacing the previous one with the same ID, and
+// writes all the schedules to the file
+func (s *FileScheduleStore) Save(schedule entities.Schedule) error {
+	return s.file.Update(func() error { return s.memory.Save(schedule) })
+}
+
+// Get returns the schedule with the ID
+func (s *FileScheduleStore) Get(id string) (*entities.Schedule, error) {
+	return s.memory.Get(id)
+}
+
+// List returns the user's schedules sorted by their name
+func (s *FileScheduleStore) List(owner string) ([]entities.Schedule, error) {
+	return s.memory.List(owner)
+}
+
+// ListAll returns the schedules of all the users sorted by their name
+func (s *FileScheduleStore) ListAll() ([]entities.Schedule, error) {
+	return s.memory.ListAll()
+}
+
+// Remove removes the schedule with the ID and writes the remaining schedules
+// to the file
+func (s *FileScheduleStore) Remove(id string) error {
+	return s.file.Update(func() error { return s.memory.Remove(id) })
+}
+
+// records returns the file's content. It's called by the file while no
+// change is being made.
+func (s *FileScheduleStore) records() interface{} {
+	schedules := s.memory.filter(func(entities.Schedule) bool { return true })
+	records := make([]record, 0, len(schedules))
+	for _, schedule := range schedules {
+		records = append(records, record{schedule.Owner, schedule.ThingToken, schedule.Authorization, schedule})
+	}
+
+	return records
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+)
+
+// MemoryScheduleStore keeps the schedules in memory, so they're lost when the
+// service is restarted
+type MemoryScheduleStore struct {
+	mutex     sync.RWMutex
+	schedules map[string]entities.Schedule
+}
+
+// NewMemoryScheduleStore creates a new MemoryScheduleStore instance
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{schedules: map[string]entities.Schedule{}}
+}
+
+// Save stores the schedule, replacing the previous one with the same ID
+func (s *MemoryScheduleStore) Save(schedule entities.Schedule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.schedules[schedule.ID] = schedule
+	return nil
+}
+
+// Get returns the schedule with the ID
+func (s *MemoryScheduleStore) Get(id string) (*entities.Schedule, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, entities.ErrScheduleNotFound
+	}
+
+	return &schedule, nil
+}
+
+// List returns the user's schedules sorted by their name
+func (s *MemoryScheduleStore) List(owner string) ([]entities.Schedule, error) {
+	return s.filter(func(schedule entities.Schedule) bool { return schedule.Owner == owner }), nil
+}
+
+// ListAll returns the schedules of all the users sorted by their name
+func (s *MemoryScheduleStore) ListAll() ([]entities.Schedule, error) {
+	return s.filter(func(entities.Schedule) bool { return true }), nil
+}
+
+// Remove removes the schedule with the ID
+func (s *MemoryScheduleStore) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return entities.ErrScheduleNotFound
+	}
+
+	delete(s.schedules, id)
+	return nil
+}
+
+func (s *MemoryScheduleStore) filter(match func(schedule entities.Schedule) bool) []entities.Schedule {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	schedules := []entities.Schedule{}
+	for _, schedule := range s.schedules {
+		if match(schedule) {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	sortSchedules(schedules)
+	return schedules
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+)
+
+// ScheduleStore represents the storage of the users' schedules
+type ScheduleStore interface {
+	Save(schedule entities.Schedule) error
+	Get(id string) (*entities.Schedule, error)
+	List(owner string) ([]entities.Schedule, error)
+	ListAll() ([]entities.Schedule, error)
+	Remove(id string) error
+}
+
+// sortSchedules sorts the schedules by their name, and ID for the same name
+func sortSchedules(schedules []entities.Schedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Name != schedules[j].Name {
+			return schedules[i].Name < schedules[j].Name
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+}
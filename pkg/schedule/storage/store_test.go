@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/schedule/entities"
+	thingEntities "github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "schedules")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func newSchedule(id, name, owner string) entities.Schedule {
+	nextRun := time.Date(2020, 4, 1, 22, 0, 0, 0, time.UTC)
+	return entities.Schedule{
+		ID:            id,
+		Name:          name,
+		ThingID:       "fbe64efa6c7f717e",
+		Enabled:       true,
+		Cron:          "0 22 * * MON-FRI",
+		Action:        entities.Action{Type: entities.ActionUpdateData, Data: []thingEntities.Data{{SensorID: 2, Value: false}}},
+		NextRun:       &nextRun,
+		Runs:          []entities.Run{{At: nextRun.AddDate(0, 0, -1), CommandID: "4f2d9c1b7e6a8d3f"}},
+		Owner:         owner,
+		ThingToken:    "mainflux-id",
+		Authorization: owner + "-token",
+	}
+}
+
+func TestScheduleStores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T) ScheduleStore
+	}{
+		{
+			"memory",
+			func(t *testing.T) ScheduleStore {
+				return NewMemoryScheduleStore()
+			},
+		},
+		{
+			"file",
+			func(t *testing.T) ScheduleStore {
+				store, err := NewFileScheduleStore(filepath.Join(tempDir(t), "data", "schedules.json"))
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := tc.newStore(t)
+			lights := newSchedule("1", "lights", "user@test.com")
+			fan := newSchedule("2", "fan", "user@test.com")
+			other := newSchedule("3", "other", "other@test.com")
+			for _, schedule := range []entities.Schedule{lights, fan, other} {
+				assert.NoError(t, store.Save(schedule))
+			}
+
+			lights.Enabled = false
+			assert.NoError(t, store.Save(lights))
+			schedule, err := store.Get("1")
+			assert.NoError(t, err)
+			assert.Equal(t, &lights, schedule)
+
+			schedules, err := store.List("user@test.com")
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Schedule{fan, lights}, schedules)
+
+			schedules, err = store.ListAll()
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.Schedule{fan, lights, other}, schedules)
+
+			assert.NoError(t, store.Remove("1"))
+			_, err = store.Get("1")
+			assert.Equal(t, entities.ErrScheduleNotFound, err)
+			assert.Equal(t, entities.ErrScheduleNotFound, store.Remove("1"))
+		})
+	}
+}
+
+func TestFileScheduleStoreRestoresSchedules(t *testing.T) {
+	path := filepath.Join(tempDir(t), "schedules.json")
+	schedule := newSchedule("1", "lights", "user@test.com")
+	store, err := NewFileScheduleStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(schedule))
+
+	restored, err := NewFileScheduleStore(path)
+	assert.NoError(t, err)
+
+	stored, err := restored.Get("1")
+	assert.NoError(t, err)
+	assert.Equal(t, &schedule, stored)
+}
@@ -0,0 +1,428 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/gorilla/mux"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	exchangeDataPublished     = "data.published"
+	exchangeDataPublishedType = "fanout"
+	streamBufferSize          = 32
+	streamKeepAliveInterval   = 15 * time.Second
+)
+
+var (
+	errStreamStopped   = errors.New("data stream stopped")
+	errInvalidSensorID = errors.New("invalid sensor id")
+	errOriginForbidden = errors.New("origin not allowed")
+)
+
+// DataStream delivers the data published by the things to the clients
+// streaming it through the HTTP server. Each client only receives the data
+// published by the thing it subscribed to, when it's owned by the client's
+// user. The WebSocket connections are only accepted from pages served by the
+// server itself or by the allowed origins.
+type DataStream struct {
+	logger          logging.Logger
+	amqp            network.AmqpReceiver
+	thingInteractor interactors.Interactor
+	allowedOrigins  map[string]bool
+	mutex           sync.Mutex
+	subscribers     map[*dataSubscriber]struct{}
+	stopped         bool
+}
+
+type dataSubscriber struct {
+	authorization string
+	thingID       string
+	mainfluxID    string
+	sensorIDs     map[int]bool
+	msgs          chan network.DataSent
+	// owners caches whether the tokens which published the thing's data belong
+	// to the subscriber's user. It's only accessed by the dispatching goroutine.
+	owners map[string]bool
+}
+
+// NewDataStream creates a new DataStream instance with the necessary dependencies
+func NewDataStream(logger logging.Logger, amqp network.AmqpReceiver, thingInteractor interactors.Interactor, allowedOrigins []string) *DataStream {
+	origins := map[string]bool{}
+	for _, origin := range allowedOrigins {
+		origins[strings.TrimSuffix(strings.ToLower(origin), "/")] = true
+	}
+
+	return &DataStream{
+		logger:          logger,
+		amqp:            amqp,
+		thingInteractor: thingInteractor,
+		allowedOrigins:  origins,
+		subscribers:     map[*dataSubscriber]struct{}{},
+	}
+}
+
+// Start subscribes to the data published by the things. The subscription
+// lasts while the connection to the broker is kept.
+func (ds *DataStream) Start() error {
+	msgChan := make(chan network.InMsg)
+	err := ds.amqp.OnTransientMessage(msgChan, exchangeDataPublished, exchangeDataPublishedType, bindingKeyEmpty)
+	if err != nil {
+		return err
+	}
+
+	go ds.dispatch(msgChan)
+	ds.logger.Debug("data stream started")
+	return nil
+}
+
+// Stop ends the streams of every subscribed client
+func (ds *DataStream) Stop() {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.stopped = true
+	for sub := range ds.subscribers {
+		delete(ds.subscribers, sub)
+		close(sub.msgs)
+	}
+}
+
+// subscribe verifies the user owns the thing and starts delivering its data
+func (ds *DataStream) subscribe(authorization, thingID string, sensorIDs []int) (*dataSubscriber, error) {
+	thing, err := ds.thingInteractor.Get(authorization, thingID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &dataSubscriber{
+		authorization: authorization,
+		thingID:       thingID,
+		mainfluxID:    thing.Token,
+		sensorIDs:     map[int]bool{},
+		msgs:          make(chan network.DataSent, streamBufferSize),
+		owners:        map[string]bool{authorization: true},
+	}
+	for _, id := range sensorIDs {
+		sub.sensorIDs[id] = true
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.stopped {
+		return nil, errStreamStopped
+	}
+
+	ds.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (ds *DataStream) unsubscribe(sub *dataSubscriber) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if _, ok := ds.subscribers[sub]; ok {
+		delete(ds.subscribers, sub)
+		close(sub.msgs)
+	}
+}
+
+func (ds *DataStream) dispatch(msgChan chan network.InMsg) {
+	for msg := range msgChan {
+		var data network.DataSent
+		err := json.Unmarshal(msg.Body, &data)
+		if err != nil {
+			ds.logger.Error(fmt.Errorf("invalid published data: %w", err))
+			continue
+		}
+
+		token, _ := msg.Headers["Authorization"].(string)
+		for _, sub := range ds.thingSubscribers(data.ID) {
+			if !ds.owns(sub, token) {
+				continue
+			}
+
+			filtered := sub.filter(data)
+			if len(filtered.Data) == 0 {
+				continue
+			}
+
+			ds.send(sub, filtered)
+		}
+	}
+
+	ds.logger.Debug("data stream stopped")
+}
+
+func (ds *DataStream) thingSubscribers(thingID string) []*dataSubscriber {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	subs := []*dataSubscriber{}
+	for sub := range ds.subscribers {
+		if sub.thingID == thingID {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
+
+// owns verifies whether the token which published the data belongs to the
+// subscriber's user. Since the things' IDs are only unique for each user, the
+// thing seen by the token must be the same the subscriber has verified.
+func (ds *DataStream) owns(sub *dataSubscriber, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	if owner, ok := sub.owners[token]; ok {
+		return owner
+	}
+
+	thing, err := ds.thingInteractor.Get(token, sub.thingID)
+	if err != nil && !errors.Is(err, entities.ErrThingNotFound) && !errors.Is(err, entities.ErrThingForbidden) {
+		ds.logger.Error(fmt.Errorf("unable to verify the thing %s owner: %w", sub.thingID, err))
+		return false
+	}
+
+	owner := err == nil && thing.Token == sub.mainfluxID
+	sub.owners[token] = owner
+	return owner
+}
+
+// send delivers the data without blocking, dropping it when the client
+// isn't consuming the stream fast enough
+func (ds *DataStream) send(sub *dataSubscriber, data network.DataSent) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if _, ok := ds.subscribers[sub]; !ok {
+		return
+	}
+
+	select {
+	case sub.msgs <- data:
+	default:
+		ds.logger.Warn("data stream subscriber is full, dropping thing " + data.ID + " data")
+	}
+}
+
+func (sub *dataSubscriber) filter(data network.DataSent) network.DataSent {
+	if len(sub.sensorIDs) == 0 {
+		return data
+	}
+
+	filtered := network.DataSent{ID: data.ID, Data: []entities.Data{}}
+	for _, d := range data.Data {
+		if sub.sensorIDs[d.SensorID] {
+			filtered.Data = append(filtered.Data, d)
+		}
+	}
+
+	return filtered
+}
+
+// StreamData godoc
+// @Summary Streams the data published by a thing
+// @Description The data is pushed as Server-Sent Events or, when the request is a WebSocket handshake, as WebSocket messages. Each event is a JSON containing the thing's id and data.
+// @Produce json
+// @Param Authorization header string false "User's token"
+// @Param token query string false "User's token, for clients unable to set the Authorization header"
+// @Param id path string true "Thing's id"
+// @Param sensorId query []int false "Sensors to stream, all of them when not provided"
+// @Success 200 {object} network.DataSent "Stream of the data published by the thing"
+// @Failure 401 {object} controllers.ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} controllers.ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} controllers.ErrorResponse "Thing not found"
+// @Failure 422 {object} controllers.ErrorResponse "Invalid sensor id"
+// @Failure 503 {object} controllers.ErrorResponse "Server stopping"
+// @Router /things/{id}/data/stream [get]
+func (s *Server) streamDataHandler(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		authorization = r.URL.Query().Get("token")
+	}
+	if authorization == "" {
+		s.writeStreamError(w, interactors.ErrAuthNotProvided)
+		return
+	}
+
+	sensorIDs, err := parseSensorIDs(r.URL.Query()["sensorId"])
+	if err != nil {
+		s.writeStreamError(w, err)
+		return
+	}
+
+	sub, err := s.dataStream.subscribe(authorization, mux.Vars(r)["id"], sensorIDs)
+	if err != nil {
+		s.writeStreamError(w, err)
+		return
+	}
+	defer s.dataStream.unsubscribe(sub)
+
+	if r.Header.Get("Upgrade") == "websocket" {
+		ws := websocket.Server{Handshake: s.dataStream.checkOrigin, Handler: func(conn *websocket.Conn) {
+			s.streamWebSocket(conn, sub)
+		}}
+		ws.ServeHTTP(w, r)
+		return
+	}
+
+	s.streamEvents(w, r, sub)
+}
+
+// checkOrigin rejects the WebSocket handshakes of the pages served by other
+// sites, unless their origin is allowed. The clients which aren't browsers
+// usually don't send the origin, and are accepted.
+func (ds *DataStream) checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil {
+		return nil
+	}
+
+	config.Origin = origin
+	if strings.EqualFold(origin.Host, r.Host) || ds.allowedOrigins["*"] ||
+		ds.allowedOrigins[strings.ToLower(origin.Scheme+"://"+origin.Host)] {
+		return nil
+	}
+
+	ds.logger.Infof("WebSocket connection from origin %s rejected", origin)
+	return errOriginForbidden
+}
+
+// streamEvents pushes the data as Server-Sent Events, sending comments
+// periodically to keep the connection alive
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, sub *dataSubscriber) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.logger.Error("streaming not supported by the connection")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-sub.msgs:
+			if !ok {
+				return
+			}
+
+			js, err := json.Marshal(data)
+			if err != nil {
+				s.logger.Errorf("unable to marshal json: %s", err)
+				continue
+			}
+
+			_, err = fmt.Fprintf(w, "data: %s\n\n", js)
+			if err != nil {
+				s.logger.Errorf("unable to write to connection HTTP: %s", err)
+				return
+			}
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				s.logger.Errorf("unable to write to connection HTTP: %s", err)
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+// streamWebSocket pushes the data as WebSocket messages until the client
+// closes the connection
+func (s *Server) streamWebSocket(conn *websocket.Conn, sub *dataSubscriber) {
+	closed := make(chan struct{})
+	go func() {
+		var discarded []byte
+		for websocket.Message.Receive(conn, &discarded) == nil {
+		}
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case data, ok := <-sub.msgs:
+			if !ok {
+				return
+			}
+
+			err := websocket.JSON.Send(conn, data)
+			if err != nil {
+				s.logger.Errorf("unable to write to connection WebSocket: %s", err)
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) writeStreamError(w http.ResponseWriter, err error) {
+	s.logger.Error(err)
+
+	var statusCode int
+	switch {
+	case errors.Is(err, interactors.ErrAuthNotProvided):
+		statusCode = http.StatusUnauthorized
+	case errors.Is(err, entities.ErrThingForbidden):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, entities.ErrThingNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, errInvalidSensorID):
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, errStreamStopped):
+		statusCode = http.StatusServiceUnavailable
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	response, _ := json.Marshal(&controllers.ErrorResponse{Message: err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(response)
+	if err != nil {
+		s.logger.Errorf("error sending response, %s\n", err)
+	}
+}
+
+func parseSensorIDs(values []string) ([]int, error) {
+	sensorIDs := []int{}
+	for _, value := range values {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidSensorID, value)
+		}
+		sensorIDs = append(sensorIDs, id)
+	}
+
+	return sensorIDs, nil
+}
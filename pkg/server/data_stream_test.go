@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/network"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+const streamThingID = "fbe64efa6c7f717e"
+
+// setupDataStream serves the data stream of the things registered by the
+// "token" user. The "other-user" token sees a different thing with the same
+// id and the "another-device" token sees the same thing, as a thing of the
+// same user.
+func setupDataStream(t *testing.T) (*network.Memory, *httptest.Server) {
+	interactor := &mocks.FakeThingInteractor{}
+	interactor.On("Get", "token", streamThingID).Return(&entities.Thing{ID: streamThingID, Token: "mainflux-id"}, nil)
+	interactor.On("Get", "another-device", streamThingID).Return(&entities.Thing{ID: streamThingID, Token: "mainflux-id"}, nil)
+	interactor.On("Get", "other-user", streamThingID).Return(&entities.Thing{ID: streamThingID, Token: "other-mainflux-id"}, nil)
+	interactor.On("Get", "forbidden", streamThingID).Return((*entities.Thing)(nil), entities.ErrThingForbidden)
+	interactor.On("Get", "token", "0000000000000000").Return((*entities.Thing)(nil), entities.ErrThingNotFound)
+
+	memory := network.NewMemory(&mocks.FakeLogger{})
+	dataStream := NewDataStream(&mocks.FakeLogger{}, memory, interactor, []string{"https://dashboard.example.com"})
+	assert.NoError(t, dataStream.Start())
+
+	s := NewServer(0, &mocks.FakeLogger{}, Dependencies{DataStream: dataStream})
+	ts := httptest.NewServer(s.createRouters())
+	t.Cleanup(func() {
+		dataStream.Stop()
+		ts.Close()
+	})
+
+	return memory, ts
+}
+
+func publishData(t *testing.T, memory *network.Memory, token string, data ...entities.Data) {
+	body, err := json.Marshal(network.DataSent{ID: streamThingID, Data: data})
+	assert.NoError(t, err)
+	err = memory.Publish(exchangeDataPublished, bindingKeyEmpty, body, map[string]interface{}{"Authorization": token})
+	assert.NoError(t, err)
+}
+
+// publishStreamedData publishes data that must be dropped by the stream
+// followed by the data expected to be received
+func publishStreamedData(t *testing.T, memory *network.Memory) network.DataSent {
+	publishData(t, memory, "other-user", entities.Data{SensorID: 1, Value: float64(10)})
+	publishData(t, memory, "", entities.Data{SensorID: 1, Value: float64(11)})
+	publishData(t, memory, "token", entities.Data{SensorID: 2, Value: true})
+	publishData(t, memory, "another-device", entities.Data{SensorID: 1, Value: float64(12)}, entities.Data{SensorID: 2, Value: false})
+
+	return network.DataSent{ID: streamThingID, Data: []entities.Data{{SensorID: 1, Value: float64(12)}}}
+}
+
+func TestStreamDataEvents(t *testing.T) {
+	memory, ts := setupDataStream(t)
+
+	req, err := http.NewRequest("GET", ts.URL+"/things/"+streamThingID+"/data/stream?sensorId=1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "token")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	expected := publishStreamedData(t, memory)
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && !strings.HasPrefix(scanner.Text(), "data: ") {
+	}
+
+	var data network.DataSent
+	err = json.Unmarshal([]byte(strings.TrimPrefix(scanner.Text(), "data: ")), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, data)
+}
+
+func TestStreamDataWebSocket(t *testing.T) {
+	memory, ts := setupDataStream(t)
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/things/" + streamThingID + "/data/stream?token=token&sensorId=1"
+	conn, err := websocket.Dial(url, "", ts.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	expected := publishStreamedData(t, memory)
+
+	var data network.DataSent
+	assert.NoError(t, websocket.JSON.Receive(conn, &data))
+	assert.Equal(t, expected, data)
+}
+
+func TestStreamDataWebSocketOrigin(t *testing.T) {
+	testCases := []struct {
+		name            string
+		origin          string
+		expectedAllowed bool
+	}{
+		{"page served by the server", "", true},
+		{"allowed origin", "https://dashboard.example.com", true},
+		{"other site", "https://attacker.example.com", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ts := setupDataStream(t)
+
+			origin := tc.origin
+			if origin == "" {
+				origin = ts.URL
+			}
+			url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/things/" + streamThingID + "/data/stream?token=token"
+			conn, err := websocket.Dial(url, "", origin)
+			if tc.expectedAllowed {
+				if assert.NoError(t, err) {
+					conn.Close()
+				}
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestStreamDataErrors(t *testing.T) {
+	testCases := []struct {
+		name               string
+		authorization      string
+		path               string
+		expectedStatusCode int
+	}{
+		{
+			"token not provided",
+			"",
+			"/things/" + streamThingID + "/data/stream",
+			http.StatusUnauthorized,
+		},
+		{
+			"invalid token",
+			"forbidden",
+			"/things/" + streamThingID + "/data/stream",
+			http.StatusForbidden,
+		},
+		{
+			"thing not found",
+			"token",
+			"/things/0000000000000000/data/stream",
+			http.StatusNotFound,
+		},
+		{
+			"invalid sensor id",
+			"token",
+			"/things/" + streamThingID + "/data/stream?sensorId=temperature",
+			http.StatusUnprocessableEntity,
+		},
+	}
+
+	_, ts := setupDataStream(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+tc.path, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", tc.authorization)
+			resp, err := http.DefaultClient.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
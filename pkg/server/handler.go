@@ -1,11 +1,23 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+	"syscall"
+	"time"
 
+	commandControllers "github.com/CESARBR/knot-babeltower/pkg/command/controllers"
+	dataControllers "github.com/CESARBR/knot-babeltower/pkg/data/controllers"
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
 	"github.com/CESARBR/knot-babeltower/pkg/network"
+	scheduleControllers "github.com/CESARBR/knot-babeltower/pkg/schedule/controllers"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	"github.com/streadway/amqp"
 )
 
 // API definition to enable receiving request-reply commands from the clients
@@ -23,10 +35,21 @@ const (
 	bindingKeyListDevices      = "device.list"
 	bindingKeyRegisterDevice   = "device.register"
 	bindingKeyUnregisterDevice = "device.unregister"
+	bindingKeyRegisterBatch    = "device.register.batch"
+	bindingKeyUnregisterBatch  = "device.unregister.batch"
 	bindingKeyRequestData      = "data.request"
 	bindingKeyUpdateData       = "data.update"
+	bindingKeyUpdateDataAck    = "data.update.ack"
 	bindingKeySchemaSent       = "device.schema.sent"
+	bindingKeyLastValues       = "data.last"
+	bindingKeyHistory          = "data.history"
+	bindingKeyCreateSchedule   = "schedule.create"
+	bindingKeyUpdateSchedule   = "schedule.update"
+	bindingKeyDeleteSchedule   = "schedule.delete"
+	bindingKeyListSchedules    = "schedule.list"
+	bindingKeySchemaTypes      = "schema.types"
 	bindingKeyEmpty            = ""
+	workerQueueSize            = 64
 )
 
 var (
@@ -34,18 +57,52 @@ var (
 	errMissingMsgChannel    = errors.New("missing message channel")
 	errUnsupportedMsg       = errors.New("unsupported message")
 	errUnexpectedRoutingKey = errors.New("unexpected routing key")
+	errHandlerPanic         = errors.New("panic handling message")
 )
 
 // MsgHandler handle messages received from a service
 type MsgHandler struct {
-	logger          logging.Logger
-	amqp            network.AmqpReceiver
-	thingController controllers.ThingController
+	logger             logging.Logger
+	amqp               network.AmqpReceiver
+	thingController    controllers.ThingController
+	dataController     dataControllers.DataController
+	commandController  commandControllers.CommandController
+	scheduleController scheduleControllers.ScheduleController
+	workers            int
+	maxRetries         int
+	retryDelay         time.Duration
+	mutex              sync.Mutex
+	stopped            bool
+	inFlight           sync.WaitGroup
 }
 
-// NewMsgHandler creates a new MsgHandler instance with the necessary dependencies
-func NewMsgHandler(logger logging.Logger, amqp network.AmqpReceiver, thingController controllers.ThingController) *MsgHandler {
-	return &MsgHandler{logger, amqp, thingController}
+// NewMsgHandler creates a new MsgHandler instance with the necessary dependencies.
+// The messages are handled in parallel by the number of workers received,
+// although the messages related to the same thing are handled in order. The
+// messages failed due to transient errors are retried after the delay, up to
+// the maximum number of retries.
+func NewMsgHandler(
+	logger logging.Logger,
+	amqp network.AmqpReceiver,
+	thingController controllers.ThingController,
+	dataController dataControllers.DataController,
+	commandController commandControllers.CommandController,
+	scheduleController scheduleControllers.ScheduleController,
+	workers int,
+	maxRetries int,
+	retryDelay time.Duration,
+) *MsgHandler {
+	return &MsgHandler{
+		logger:             logger,
+		amqp:               amqp,
+		thingController:    thingController,
+		dataController:     dataController,
+		commandController:  commandController,
+		scheduleController: scheduleController,
+		workers:            workers,
+		maxRetries:         maxRetries,
+		retryDelay:         retryDelay,
+	}
 }
 
 // Start starts to listen messages
@@ -73,22 +130,90 @@ func (mc *MsgHandler) start(started chan bool, msgChan chan network.InMsg) error
 		return err
 	}
 
-	go func() {
-		for {
-			err := mc.onMsgReceived(msgChan)
-			if err != nil {
-				mc.logger.Error(err)
-			}
-		}
-	}()
+	workers := mc.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	workerChans := make([]chan network.InMsg, workers)
+	for i := range workerChans {
+		workerChans[i] = make(chan network.InMsg, workerQueueSize)
+		go mc.work(workerChans[i])
+	}
+
+	go mc.dispatch(msgChan, workerChans)
 
 	started <- true
 	return nil
 }
 
-// Stop stops to listen for messages
-func (mc *MsgHandler) Stop() {
-	mc.logger.Debug("message handler stopped")
+// dispatch distributes the received messages to the workers. The messages
+// related to the same thing are always sent to the same worker, so they are
+// handled in the order they were received.
+func (mc *MsgHandler) dispatch(msgChan chan network.InMsg, workerChans []chan network.InMsg) {
+	next := 0
+	for msg := range msgChan {
+		var idx int
+		if id := getThingID(msg); id != "" {
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(id))
+			idx = int(hash.Sum32() % uint32(len(workerChans)))
+		} else {
+			idx = next
+			next = (next + 1) % len(workerChans)
+		}
+
+		mc.mutex.Lock()
+		if mc.stopped {
+			mc.mutex.Unlock()
+			// the message was delivered before the consumer was canceled
+			if err := msg.Requeue(); err != nil {
+				mc.logger.Errorf("error requeueing message: %s", err)
+			}
+			continue
+		}
+		mc.inFlight.Add(1)
+		mc.mutex.Unlock()
+
+		workerChans[idx] <- msg
+	}
+}
+
+func (mc *MsgHandler) work(msgChan chan network.InMsg) {
+	for {
+		err := mc.onMsgReceived(msgChan)
+		if err != nil {
+			mc.logger.Error(err)
+		}
+		mc.inFlight.Done()
+	}
+}
+
+// Stop stops to listen for messages and waits the messages being handled to
+// finish, up to the context deadline
+func (mc *MsgHandler) Stop(ctx context.Context) error {
+	err := mc.amqp.StopConsuming()
+	if err != nil {
+		mc.logger.Error(err)
+	}
+
+	mc.mutex.Lock()
+	mc.stopped = true
+	mc.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		mc.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		mc.logger.Debug("message handler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting messages being handled: %w", ctx.Err())
+	}
 }
 
 func (mc *MsgHandler) subscribeToMessages(msgChan chan network.InMsg) error {
@@ -103,13 +228,23 @@ func (mc *MsgHandler) subscribeToMessages(msgChan chan network.InMsg) error {
 	// Subscribe to general direct commands
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRegisterDevice)
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUnregisterDevice)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRegisterBatch)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUnregisterBatch)
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRequestData)
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateData)
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeySchemaSent)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateDataAck)
 
 	// Subscribe to request-reply messages received from any client
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyAuthDevice)
 	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyListDevices)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyLastValues)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyHistory)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyCreateSchedule)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateSchedule)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyDeleteSchedule)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyListSchedules)
+	subscribe(msgChan, queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeySchemaTypes)
 
 	// Subscribe to broadcasted data events
 	subscribe(msgChan, queueNameEvents, exchangeDataSent, exchangeDataSentType, bindingKeyEmpty)
@@ -119,6 +254,13 @@ func (mc *MsgHandler) subscribeToMessages(msgChan chan network.InMsg) error {
 
 func (mc *MsgHandler) onMsgReceived(msgChan chan network.InMsg) (err error) {
 	msg := <-msgChan
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errHandlerPanic, r)
+		}
+		mc.settle(msg, err)
+	}()
+
 	mc.logger.Infof("exchange: %s, routing key: %s", msg.Exchange, msg.RoutingKey)
 	mc.logger.Infof("message received: %s", string(msg.Body))
 
@@ -128,7 +270,7 @@ func (mc *MsgHandler) onMsgReceived(msgChan chan network.InMsg) (err error) {
 		return errUnsupportedMsg
 	}
 
-	if msg.RoutingKey == bindingKeyAuthDevice || msg.RoutingKey == bindingKeyListDevices {
+	if isRequestReplyCommand(msg.RoutingKey) {
 		// handling request-reply command messages, which requires specific validations such as if correlation_id was correctly received
 		err = mc.handleRequestReplyCommands(msg, token)
 	} else if msg.Exchange == exchangeDataSent {
@@ -149,12 +291,18 @@ func (mc *MsgHandler) handleClientMessages(msg network.InMsg, token string) erro
 		return mc.thingController.Register(msg.Body, token)
 	case bindingKeyUnregisterDevice:
 		return mc.thingController.Unregister(msg.Body, token)
+	case bindingKeyRegisterBatch:
+		return mc.thingController.RegisterBatch(msg.Body, token)
+	case bindingKeyUnregisterBatch:
+		return mc.thingController.UnregisterBatch(msg.Body, token)
 	case bindingKeySchemaSent:
 		return mc.thingController.UpdateSchema(msg.Body, token)
 	case bindingKeyRequestData:
 		return mc.thingController.RequestData(msg.Body, token)
 	case bindingKeyUpdateData:
 		return mc.thingController.UpdateData(msg.Body, token)
+	case bindingKeyUpdateDataAck:
+		return mc.commandController.Acknowledge(msg.Body, token)
 	default:
 		return errUnexpectedRoutingKey
 	}
@@ -169,11 +317,83 @@ func (mc *MsgHandler) handleRequestReplyCommands(msg network.InMsg, token string
 		return mc.thingController.AuthDevice(msg.Body, token, replyTo, corrID)
 	case bindingKeyListDevices:
 		return mc.thingController.ListDevices(token, replyTo, corrID)
+	case bindingKeyLastValues:
+		return mc.dataController.GetLastValues(msg.Body, token, replyTo, corrID)
+	case bindingKeyHistory:
+		return mc.dataController.GetHistory(msg.Body, token, replyTo, corrID)
+	case bindingKeyCreateSchedule:
+		return mc.scheduleController.Create(msg.Body, token, replyTo, corrID)
+	case bindingKeyUpdateSchedule:
+		return mc.scheduleController.Update(msg.Body, token, replyTo, corrID)
+	case bindingKeyDeleteSchedule:
+		return mc.scheduleController.Delete(msg.Body, token, replyTo, corrID)
+	case bindingKeyListSchedules:
+		return mc.scheduleController.List(token, replyTo, corrID)
+	case bindingKeySchemaTypes:
+		return mc.thingController.ListTypes(token, replyTo, corrID)
 	}
 
 	return nil
 }
 
+func isRequestReplyCommand(routingKey string) bool {
+	switch routingKey {
+	case bindingKeyAuthDevice, bindingKeyListDevices, bindingKeyLastValues, bindingKeyHistory,
+		bindingKeyCreateSchedule, bindingKeyUpdateSchedule, bindingKeyDeleteSchedule, bindingKeyListSchedules,
+		bindingKeySchemaTypes:
+		return true
+	default:
+		return false
+	}
+}
+
 func (mc *MsgHandler) handleBroadcastedData(msg network.InMsg, token string) error {
 	return mc.thingController.PublishData(msg.Body, token)
 }
+
+// getThingID returns the thing's id from the message body, which is empty for
+// the messages that aren't related to a specific thing
+func getThingID(msg network.InMsg) string {
+	var body struct {
+		ID string `json:"id"`
+	}
+
+	_ = json.Unmarshal(msg.Body, &body)
+	return body.ID
+}
+
+// settle acknowledges the message when it was successfully handled, retries
+// it later when the failure is transient and routes it to the dead-letter
+// exchange otherwise or when it was retried too many times
+func (mc *MsgHandler) settle(msg network.InMsg, handleErr error) {
+	var err error
+	switch {
+	case handleErr == nil:
+		err = msg.Ack()
+	case isTransientErr(handleErr) && msg.Retries() < mc.maxRetries:
+		mc.logger.Infof("retrying message in %s: %s", mc.retryDelay, handleErr)
+		err = msg.Retry(mc.retryDelay)
+	default:
+		mc.logger.Infof("dead-lettering message: %s", handleErr)
+		err = msg.DeadLetter(handleErr.Error())
+	}
+
+	if err != nil {
+		mc.logger.Errorf("error settling message: %s", err)
+	}
+}
+
+// isTransientErr reports whether the error is caused by a dependency which is
+// temporarily unavailable, so handling the message again may succeed
+func isTransientErr(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, amqp.ErrClosed) ||
+		errors.Is(err, network.ErrConfirmTimeout) ||
+		errors.Is(err, network.ErrPublishNacked)
+}
@@ -1,13 +1,19 @@
 package server
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
 	"github.com/CESARBR/knot-babeltower/pkg/mocks"
 	"github.com/CESARBR/knot-babeltower/pkg/network"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestStart(t *testing.T) {
@@ -108,6 +114,8 @@ func TestOnMsgReceived(t *testing.T) {
 			map[string]string{
 				bindingKeyRegisterDevice:   "Register",
 				bindingKeyUnregisterDevice: "Unregister",
+				bindingKeyRegisterBatch:    "RegisterBatch",
+				bindingKeyUnregisterBatch:  "UnregisterBatch",
 				bindingKeyRequestData:      "RequestData",
 				bindingKeyUpdateData:       "UpdateData",
 				bindingKeySchemaSent:       "UpdateSchema",
@@ -137,6 +145,7 @@ func TestOnMsgReceived(t *testing.T) {
 			map[string]string{
 				bindingKeyAuthDevice:  "AuthDevice",
 				bindingKeyListDevices: "ListDevices",
+				bindingKeySchemaTypes: "ListTypes",
 			},
 		},
 		{
@@ -185,6 +194,7 @@ func TestOnMsgReceived(t *testing.T) {
 			map[string]string{
 				bindingKeyAuthDevice:  "AuthDevice",
 				bindingKeyListDevices: "ListDevices",
+				bindingKeySchemaTypes: "ListTypes",
 			},
 		},
 		{
@@ -210,6 +220,7 @@ func TestOnMsgReceived(t *testing.T) {
 			map[string]string{
 				bindingKeyAuthDevice:  "AuthDevice",
 				bindingKeyListDevices: "ListDevices",
+				bindingKeySchemaTypes: "ListTypes",
 			},
 		},
 		{
@@ -228,6 +239,8 @@ func TestOnMsgReceived(t *testing.T) {
 			map[string]string{
 				bindingKeyRegisterDevice:   "Register",
 				bindingKeyUnregisterDevice: "Unregister",
+				bindingKeyRegisterBatch:    "RegisterBatch",
+				bindingKeyUnregisterBatch:  "UnregisterBatch",
 				bindingKeyRequestData:      "RequestData",
 				bindingKeyUpdateData:       "UpdateData",
 				bindingKeySchemaSent:       "UpdateSchema",
@@ -286,6 +299,8 @@ func TestOnMsgReceived(t *testing.T) {
 			map[string]string{
 				bindingKeyRegisterDevice:   "Register",
 				bindingKeyUnregisterDevice: "Unregister",
+				bindingKeyRegisterBatch:    "RegisterBatch",
+				bindingKeyUnregisterBatch:  "UnregisterBatch",
 				bindingKeyRequestData:      "RequestData",
 				bindingKeyUpdateData:       "UpdateData",
 				bindingKeySchemaSent:       "UpdateSchema",
@@ -347,6 +362,132 @@ func TestOnMsgReceived(t *testing.T) {
 	}
 }
 
+func TestOnDataCommandReceived(t *testing.T) {
+	body := []byte(`{"id":"fbe64efa6c7f717e"}`)
+	tests := []struct {
+		name          string
+		routingKey    string
+		method        string
+		controllerErr error
+		expectedErr   bool
+	}{
+		{"data.last request should be handled by the data controller", bindingKeyLastValues, "GetLastValues", nil, false},
+		{"data.last request failure should return an error", bindingKeyLastValues, "GetLastValues", errors.New("thing not found"), true},
+		{"data.history request should be handled by the data controller", bindingKeyHistory, "GetHistory", nil, false},
+		{"data.history request failure should return an error", bindingKeyHistory, "GetHistory", errors.New("thing not found"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDataController := &mocks.FakeDataController{}
+			fakeDataController.On(tt.method, body, "test-token", "test-reply_to", "test-corrId").Return(tt.controllerErr).Once()
+			mc := &MsgHandler{
+				logger:          &mocks.FakeLogger{},
+				amqp:            &mocks.FakeAmqpReceiver{},
+				thingController: &mocks.FakeController{},
+				dataController:  fakeDataController,
+			}
+			msgChan := make(chan network.InMsg, 1)
+			msgChan <- network.InMsg{
+				Exchange:   exchangeDevices,
+				RoutingKey: tt.routingKey,
+				Body:       body,
+				Headers: map[string]interface{}{
+					"Authorization":  "test-token",
+					"correlation_id": "test-corrId",
+					"reply_to":       "test-reply_to",
+				},
+			}
+
+			err := mc.onMsgReceived(msgChan)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			fakeDataController.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOnCommandAckReceived(t *testing.T) {
+	body := []byte(`{"id":"fbe64efa6c7f717e","commandId":"6f1cbbd5e8a2c1a9"}`)
+	tests := []struct {
+		name          string
+		controllerErr error
+		expectedErr   bool
+	}{
+		{"data.update.ack should be handled by the command controller", nil, false},
+		{"data.update.ack failure should return an error", errors.New("command not found"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommandController := &mocks.FakeCommandController{}
+			fakeCommandController.On("Acknowledge", body, "test-token").Return(tt.controllerErr).Once()
+			mc := &MsgHandler{
+				logger:            &mocks.FakeLogger{},
+				amqp:              &mocks.FakeAmqpReceiver{},
+				thingController:   &mocks.FakeController{},
+				commandController: fakeCommandController,
+			}
+			msgChan := make(chan network.InMsg, 1)
+			msgChan <- network.InMsg{
+				Exchange:   exchangeDevices,
+				RoutingKey: bindingKeyUpdateDataAck,
+				Body:       body,
+				Headers:    map[string]interface{}{"Authorization": "test-token"},
+			}
+
+			err := mc.onMsgReceived(msgChan)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			fakeCommandController.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOnScheduleCommandReceived(t *testing.T) {
+	body := []byte(`{"id":"6f1cbbd5e8a2c1a9"}`)
+	tests := []struct {
+		name          string
+		routingKey    string
+		method        string
+		args          []interface{}
+		controllerErr error
+		expectedErr   bool
+	}{
+		{"schedule.create request should be handled by the schedule controller", bindingKeyCreateSchedule, "Create", []interface{}{body, "test-token", "test-reply_to", "test-corrId"}, nil, false},
+		{"schedule.update request should be handled by the schedule controller", bindingKeyUpdateSchedule, "Update", []interface{}{body, "test-token", "test-reply_to", "test-corrId"}, nil, false},
+		{"schedule.delete request should be handled by the schedule controller", bindingKeyDeleteSchedule, "Delete", []interface{}{body, "test-token", "test-reply_to", "test-corrId"}, nil, false},
+		{"schedule.list request should be handled by the schedule controller", bindingKeyListSchedules, "List", []interface{}{"test-token", "test-reply_to", "test-corrId"}, nil, false},
+		{"schedule.create request failure should return an error", bindingKeyCreateSchedule, "Create", []interface{}{body, "test-token", "test-reply_to", "test-corrId"}, errors.New("invalid schedule"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeScheduleController := &mocks.FakeScheduleController{}
+			fakeScheduleController.On(tt.method, tt.args...).Return(tt.controllerErr).Once()
+			mc := &MsgHandler{
+				logger:             &mocks.FakeLogger{},
+				amqp:               &mocks.FakeAmqpReceiver{},
+				thingController:    &mocks.FakeController{},
+				scheduleController: fakeScheduleController,
+			}
+			msgChan := make(chan network.InMsg, 1)
+			msgChan <- network.InMsg{
+				Exchange:   exchangeDevices,
+				RoutingKey: tt.routingKey,
+				Body:       body,
+				Headers: map[string]interface{}{
+					"Authorization":  "test-token",
+					"correlation_id": "test-corrId",
+					"reply_to":       "test-reply_to",
+				},
+			}
+
+			err := mc.onMsgReceived(msgChan)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			fakeScheduleController.AssertExpectations(t)
+		})
+	}
+}
+
 func TestSubscribeToMessagesCalls(t *testing.T) {
 	type fields struct {
 		logger          logging.Logger
@@ -383,11 +524,21 @@ func TestSubscribeToMessagesCalls(t *testing.T) {
 				[]mockArgs{
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRegisterDevice, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUnregisterDevice, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRegisterBatch, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUnregisterBatch, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRequestData, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateData, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeySchemaSent, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateDataAck, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyAuthDevice, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyListDevices, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyLastValues, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyHistory, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyCreateSchedule, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateSchedule, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyDeleteSchedule, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyListSchedules, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeySchemaTypes, nil},
 					{queueNameEvents, exchangeDataSent, exchangeDataSentType, bindingKeyEmpty, nil},
 				},
 			},
@@ -422,6 +573,8 @@ func TestSubscribeToMessagesCalls(t *testing.T) {
 				[]mockArgs{
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRegisterDevice, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUnregisterDevice, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRegisterBatch, nil},
+					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUnregisterBatch, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyRequestData, nil},
 					{queueNameCommands, exchangeDevices, exchangeDevicesType, bindingKeyUpdateData, errors.New("missing routing key argument on subscribe")},
 				},
@@ -452,3 +605,188 @@ func TestSubscribeToMessagesCalls(t *testing.T) {
 		})
 	}
 }
+
+func TestOnMsgReceivedSettlement(t *testing.T) {
+	errTimeout := &net.DNSError{Err: "i/o timeout", IsTimeout: true}
+	tests := []struct {
+		name          string
+		exchange      string
+		routingKey    string
+		retries       int
+		controllerErr error
+		settleMethod  string
+		settleArgs    []interface{}
+	}{
+		{
+			"message successfully handled should be acknowledged",
+			exchangeDevices,
+			bindingKeyRegisterDevice,
+			0,
+			nil,
+			"Ack",
+			nil,
+		},
+		{
+			"message failed due to a timeout should be retried after the delay",
+			exchangeDevices,
+			bindingKeyRegisterDevice,
+			2,
+			fmt.Errorf("error registering thing: %w", errTimeout),
+			"Retry",
+			[]interface{}{time.Second},
+		},
+		{
+			"message failed due to a timeout too many times should be dead-lettered",
+			exchangeDevices,
+			bindingKeyRegisterDevice,
+			3,
+			fmt.Errorf("error registering thing: %w", errTimeout),
+			"DeadLetter",
+			[]interface{}{"error registering thing: lookup : i/o timeout"},
+		},
+		{
+			"message failed due to a validation error should be dead-lettered with the reason",
+			exchangeDevices,
+			bindingKeyRegisterDevice,
+			0,
+			errors.New("id is not in hexadecimal format"),
+			"DeadLetter",
+			[]interface{}{"id is not in hexadecimal format"},
+		},
+		{
+			"message from unexpected exchange should be dead-lettered",
+			"test",
+			bindingKeyRegisterDevice,
+			0,
+			nil,
+			"DeadLetter",
+			[]interface{}{errUnsupportedMsg.Error()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeController := &mocks.FakeController{}
+			fakeAcknowledger := &mocks.FakeAcknowledger{}
+			fakeController.On("Register").Return(tt.controllerErr).Maybe()
+			fakeAcknowledger.On(tt.settleMethod, tt.settleArgs...).Return(nil).Once()
+
+			mc := &MsgHandler{
+				logger:          &mocks.FakeLogger{},
+				amqp:            &mocks.FakeAmqpReceiver{},
+				thingController: fakeController,
+				maxRetries:      3,
+				retryDelay:      time.Second,
+			}
+			msgChan := make(chan network.InMsg, 1)
+			msgChan <- network.InMsg{
+				Exchange:     tt.exchange,
+				RoutingKey:   tt.routingKey,
+				Body:         []byte{1, 2, 3},
+				Headers:      map[string]interface{}{"Authorization": "test-token", "x-retries": int32(tt.retries)},
+				Acknowledger: fakeAcknowledger,
+			}
+
+			_ = mc.onMsgReceived(msgChan)
+			fakeAcknowledger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOnMsgReceivedRecoversFromPanic(t *testing.T) {
+	fakeAcknowledger := &mocks.FakeAcknowledger{}
+	fakeAcknowledger.On("DeadLetter", mock.AnythingOfType("string")).Return(nil).Once()
+
+	mc := &MsgHandler{
+		logger:          &mocks.FakeLogger{},
+		amqp:            &mocks.FakeAmqpReceiver{},
+		thingController: &mocks.FakeController{}, // no expectation set, so the call panics
+	}
+	msgChan := make(chan network.InMsg, 1)
+	msgChan <- network.InMsg{
+		Exchange:     exchangeDevices,
+		RoutingKey:   bindingKeyRegisterDevice,
+		Body:         []byte{1, 2, 3},
+		Acknowledger: fakeAcknowledger,
+	}
+
+	err := mc.onMsgReceived(msgChan)
+	if !errors.Is(err, errHandlerPanic) {
+		t.Errorf("msgHandler.onMsgReceived() error = %v, expected %v", err, errHandlerPanic)
+	}
+	fakeAcknowledger.AssertExpectations(t)
+}
+
+func TestDispatchKeepsThingOrder(t *testing.T) {
+	mc := &MsgHandler{logger: &mocks.FakeLogger{}}
+	msgChan := make(chan network.InMsg)
+	workerChans := []chan network.InMsg{
+		make(chan network.InMsg, 10),
+		make(chan network.InMsg, 10),
+		make(chan network.InMsg, 10),
+	}
+	go mc.dispatch(msgChan, workerChans)
+
+	bodies := []string{
+		`{"id":"fc3fcf912d0c290a","schema":[]}`,
+		`{"id":"3aa21010cda96fe9","data":[]}`,
+		`{"id":"fc3fcf912d0c290a","data":[]}`,
+		`{"id":"fc3fcf912d0c290a","data":[]}`,
+	}
+	for _, body := range bodies {
+		msgChan <- network.InMsg{Exchange: exchangeDevices, Body: []byte(body)}
+	}
+	close(msgChan)
+
+	received := map[string][]int{}
+	for i := 0; i < len(bodies); {
+		for w, workerChan := range workerChans {
+			select {
+			case msg := <-workerChan:
+				id := getThingID(msg)
+				received[id] = append(received[id], w)
+				i++
+			default:
+			}
+		}
+	}
+
+	assert.Len(t, received["fc3fcf912d0c290a"], 3)
+	for _, w := range received["fc3fcf912d0c290a"] {
+		assert.Equal(t, received["fc3fcf912d0c290a"][0], w, "messages of the same thing must be sent to the same worker")
+	}
+}
+
+func TestStopWaitsMessagesBeingHandled(t *testing.T) {
+	fakeAmqp := &mocks.FakeAmqpReceiver{}
+	fakeAmqp.On("StopConsuming").Return(nil)
+	mc := &MsgHandler{logger: &mocks.FakeLogger{}, amqp: fakeAmqp}
+	mc.inFlight.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := mc.Stop(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	mc.inFlight.Done()
+	err = mc.Stop(context.Background())
+	assert.NoError(t, err)
+	fakeAmqp.AssertExpectations(t)
+}
+
+func TestDispatchRequeuesMessagesAfterStop(t *testing.T) {
+	fakeAmqp := &mocks.FakeAmqpReceiver{}
+	fakeAmqp.On("StopConsuming").Return(nil)
+	fakeAcknowledger := &mocks.FakeAcknowledger{}
+	fakeAcknowledger.On("Requeue").Return(nil).Once()
+	mc := &MsgHandler{logger: &mocks.FakeLogger{}, amqp: fakeAmqp}
+	assert.NoError(t, mc.Stop(context.Background()))
+
+	msgChan := make(chan network.InMsg, 1)
+	workerChans := []chan network.InMsg{make(chan network.InMsg, 1)}
+	msgChan <- network.InMsg{Exchange: exchangeDevices, Acknowledger: fakeAcknowledger}
+	close(msgChan)
+	mc.dispatch(msgChan, workerChans)
+
+	assert.Len(t, workerChans[0], 0)
+	fakeAcknowledger.AssertExpectations(t)
+}
@@ -9,7 +9,15 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	_ "github.com/CESARBR/knot-babeltower/docs" // This blank import is needed in order to documentation be provided by the server
+	alarmControllers "github.com/CESARBR/knot-babeltower/pkg/alarm/controllers"
+	commandControllers "github.com/CESARBR/knot-babeltower/pkg/command/controllers"
+	dataControllers "github.com/CESARBR/knot-babeltower/pkg/data/controllers"
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	preferenceControllers "github.com/CESARBR/knot-babeltower/pkg/preference/controllers"
+	ruleControllers "github.com/CESARBR/knot-babeltower/pkg/rule/controllers"
+	scheduleControllers "github.com/CESARBR/knot-babeltower/pkg/schedule/controllers"
+	thingControllers "github.com/CESARBR/knot-babeltower/pkg/thing/controllers"
+	thingDeliveryHTTP "github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
 	"github.com/CESARBR/knot-babeltower/pkg/user/controllers"
 
 	"github.com/gorilla/mux"
@@ -17,10 +25,19 @@ import (
 
 // Server represents the HTTP server
 type Server struct {
-	port           int
-	logger         logging.Logger
-	userController *controllers.UserController
-	srv            *http.Server
+	port                 int
+	logger               logging.Logger
+	userController       *controllers.UserController
+	thingController      *thingControllers.ThingHTTPController
+	dataController       *dataControllers.DataHTTPController
+	ruleController       *ruleControllers.RuleHTTPController
+	alarmController      *alarmControllers.AlarmHTTPController
+	commandController    *commandControllers.CommandHTTPController
+	scheduleController   *scheduleControllers.ScheduleHTTPController
+	preferenceController *preferenceControllers.PreferenceHTTPController
+	thingCache           *thingDeliveryHTTP.CachedThingProxy
+	dataStream           *DataStream
+	srv                  *http.Server
 }
 
 // Health represents the service's health status
@@ -28,27 +45,59 @@ type Health struct {
 	Status string `json:"status"`
 }
 
+// Dependencies groups the controllers and components the server handles the
+// requests with
+type Dependencies struct {
+	UserController       *controllers.UserController
+	ThingController      *thingControllers.ThingHTTPController
+	DataController       *dataControllers.DataHTTPController
+	RuleController       *ruleControllers.RuleHTTPController
+	AlarmController      *alarmControllers.AlarmHTTPController
+	CommandController    *commandControllers.CommandHTTPController
+	ScheduleController   *scheduleControllers.ScheduleHTTPController
+	PreferenceController *preferenceControllers.PreferenceHTTPController
+	ThingCache           *thingDeliveryHTTP.CachedThingProxy
+	DataStream           *DataStream
+}
+
 // NewServer creates a new server instance
-func NewServer(port int, logger logging.Logger, userController *controllers.UserController) Server {
-	return Server{port, logger, userController, nil}
+func NewServer(port int, logger logging.Logger, deps Dependencies) *Server {
+	s := &Server{
+		port:                 port,
+		logger:               logger,
+		userController:       deps.UserController,
+		thingController:      deps.ThingController,
+		dataController:       deps.DataController,
+		ruleController:       deps.RuleController,
+		alarmController:      deps.AlarmController,
+		commandController:    deps.CommandController,
+		scheduleController:   deps.ScheduleController,
+		preferenceController: deps.PreferenceController,
+		thingCache:           deps.ThingCache,
+		dataStream:           deps.DataStream,
+	}
+	s.srv = &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: s.logRequest(s.createRouters())}
+	// The streams don't finish by themselves, so they're ended when the server
+	// starts shutting down
+	s.srv.RegisterOnShutdown(deps.DataStream.Stop)
+	return s
 }
 
 // Start starts the http server
 func (s *Server) Start(started chan bool) {
-	routers := s.createRouters()
 	s.logger.Infof("listening on %d", s.port)
 	started <- true
-	s.srv = &http.Server{Addr: fmt.Sprintf(":%d", s.port), Handler: s.logRequest(routers)}
 	err := s.srv.ListenAndServe()
-	if err != nil {
+	if err != nil && err != http.ErrServerClosed {
 		s.logger.Error(err)
 		started <- false
 	}
 }
 
-// Stop stops the server
-func (s *Server) Stop() {
-	err := s.srv.Shutdown(context.TODO())
+// Stop stops the server waiting the active connections to finish, up to the
+// context deadline. When the server hasn't started yet, it won't start.
+func (s *Server) Stop(ctx context.Context) {
+	err := s.srv.Shutdown(ctx)
 	if err != nil {
 		s.logger.Error(err)
 	}
@@ -66,8 +115,40 @@ func (s *Server) Stop() {
 func (s *Server) createRouters() *mux.Router {
 	r := mux.NewRouter()
 	r.HandleFunc("/healthcheck", s.healthcheckHandler)
+	r.HandleFunc("/stats/things-cache", s.thingCacheStatsHandler).Methods("GET")
 	r.HandleFunc("/users", s.userController.Create).Methods("POST")
 	r.HandleFunc("/tokens", s.userController.CreateToken).Methods("POST")
+	r.HandleFunc("/things", s.thingController.Register).Methods("POST")
+	r.HandleFunc("/things", s.thingController.List).Methods("GET")
+	r.HandleFunc("/things/{id}", s.thingController.Get).Methods("GET")
+	r.HandleFunc("/things/{id}", s.thingController.Unregister).Methods("DELETE")
+	r.HandleFunc("/things/{id}/schema", s.thingController.UpdateSchema).Methods("PUT")
+	r.HandleFunc("/things/{id}/schema/versions", s.thingController.ListSchemaVersions).Methods("GET")
+	r.HandleFunc("/schema/types", s.thingController.ListTypes).Methods("GET")
+	r.HandleFunc("/things/{id}/data/last", s.dataController.GetLastValues).Methods("GET")
+	r.HandleFunc("/things/{id}/data/history", s.dataController.GetHistory).Methods("GET")
+	r.HandleFunc("/things/{id}/data/stream", s.streamDataHandler).Methods("GET")
+	r.HandleFunc("/rules", s.ruleController.Create).Methods("POST")
+	r.HandleFunc("/rules", s.ruleController.List).Methods("GET")
+	r.HandleFunc("/rules/{id}", s.ruleController.Get).Methods("GET")
+	r.HandleFunc("/rules/{id}", s.ruleController.Update).Methods("PUT")
+	r.HandleFunc("/rules/{id}", s.ruleController.Delete).Methods("DELETE")
+	r.HandleFunc("/alarm-definitions", s.alarmController.CreateDefinition).Methods("POST")
+	r.HandleFunc("/alarm-definitions", s.alarmController.ListDefinitions).Methods("GET")
+	r.HandleFunc("/alarm-definitions/{id}", s.alarmController.GetDefinition).Methods("GET")
+	r.HandleFunc("/alarm-definitions/{id}", s.alarmController.UpdateDefinition).Methods("PUT")
+	r.HandleFunc("/alarm-definitions/{id}", s.alarmController.DeleteDefinition).Methods("DELETE")
+	r.HandleFunc("/alarms", s.alarmController.List).Methods("GET")
+	r.HandleFunc("/alarms/{id}", s.alarmController.Get).Methods("GET")
+	r.HandleFunc("/alarms/{id}/ack", s.alarmController.Acknowledge).Methods("POST")
+	r.HandleFunc("/commands/{id}", s.commandController.Get).Methods("GET")
+	r.HandleFunc("/schedules", s.scheduleController.Create).Methods("POST")
+	r.HandleFunc("/schedules", s.scheduleController.List).Methods("GET")
+	r.HandleFunc("/schedules/{id}", s.scheduleController.Get).Methods("GET")
+	r.HandleFunc("/schedules/{id}", s.scheduleController.Update).Methods("PUT")
+	r.HandleFunc("/schedules/{id}", s.scheduleController.Delete).Methods("DELETE")
+	r.HandleFunc("/preferences/units", s.preferenceController.GetUnits).Methods("GET")
+	r.HandleFunc("/preferences/units", s.preferenceController.UpdateUnits).Methods("PUT")
 	r.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	)).Methods("GET")
@@ -89,9 +170,25 @@ func (s *Server) healthcheckHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ThingCacheStats godoc
+// @Summary Get the things cache usage statistics
+// @Produce json
+// @Success 200 {object} http.CacheStats
+// @Router /stats/things-cache [get]
+func (s *Server) thingCacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	response, _ := json.Marshal(s.thingCache.Stats())
+	_, err := w.Write(response)
+	if err != nil {
+		s.logger.Errorf("error sending response, %s\n", err)
+	}
+}
+
 func (s *Server) logRequest(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		s.logger.Infof("%s %s %s\n", r.RemoteAddr, r.Method, r.URL)
+		// only the path is logged, as the query may carry the user's token
+		s.logger.Infof("%s %s %s\n", r.RemoteAddr, r.Method, r.URL.Path)
 		handler.ServeHTTP(w, r)
 	})
 }
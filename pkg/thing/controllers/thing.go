@@ -15,9 +15,12 @@ import (
 type ThingController interface {
 	Register(body []byte, authorizationHeader string) error
 	Unregister(body []byte, authorizationHeader string) error
+	RegisterBatch(body []byte, authorizationHeader string) error
+	UnregisterBatch(body []byte, authorizationHeader string) error
 	UpdateSchema(body []byte, authorizationHeader string) error
 	AuthDevice(body []byte, authorization, replyTo, corrID string) error
 	ListDevices(authorization, replyTo, corrID string) error
+	ListTypes(authorization, replyTo, corrID string) error
 	PublishData(body []byte, authorization string) error
 	RequestData(body []byte, authorization string) error
 	UpdateData(body []byte, authorization string) error
@@ -56,6 +59,40 @@ func (mc *thingController) Unregister(body []byte, authorizationHeader string) e
 	return mc.thingInteractor.Unregister(authorizationHeader, msg.ID)
 }
 
+// RegisterBatch handles the register devices batch request and execute its
+// use case
+func (mc *thingController) RegisterBatch(body []byte, authorizationHeader string) error {
+	msg := network.DeviceRegisterBatchRequest{}
+	err := json.Unmarshal(body, &msg)
+	if err != nil {
+		return err
+	}
+
+	things := make([]entities.Thing, len(msg.Things))
+	for idx, t := range msg.Things {
+		things[idx] = entities.Thing{ID: t.ID, Name: t.Name}
+	}
+
+	return mc.thingInteractor.RegisterBatch(authorizationHeader, things)
+}
+
+// UnregisterBatch handles the unregister devices batch request and execute
+// its use case
+func (mc *thingController) UnregisterBatch(body []byte, authorizationHeader string) error {
+	msg := network.DeviceUnregisterBatchRequest{}
+	err := json.Unmarshal(body, &msg)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(msg.Things))
+	for idx, t := range msg.Things {
+		ids[idx] = t.ID
+	}
+
+	return mc.thingInteractor.UnregisterBatch(authorizationHeader, ids)
+}
+
 // UpdateSchema handles the update schema request and execute its use case
 func (mc *thingController) UpdateSchema(body []byte, authorizationHeader string) error {
 	var updateSchemaReq network.SchemaUpdateRequest
@@ -113,6 +150,34 @@ func (mc *thingController) ListDevices(authorization, replyTo, corrID string) er
 	return nil
 }
 
+// ListTypes handles the list sensor types request and execute its use case
+func (mc *thingController) ListTypes(authorization, replyTo, corrID string) error {
+	mc.logger.Info("list sensor types command received")
+	if replyTo == "" {
+		sendErr := mc.sender.SendTypesResponse(nil, replyTo, corrID, interactors.ErrReplyToNotProvided)
+		if sendErr != nil {
+			return fmt.Errorf("error sending response: %v: %w", interactors.ErrReplyToNotProvided, sendErr)
+		}
+		return interactors.ErrReplyToNotProvided
+	}
+
+	if corrID == "" {
+		sendErr := mc.sender.SendTypesResponse(nil, replyTo, corrID, interactors.ErrCorrelationIDNotProvided)
+		if sendErr != nil {
+			return fmt.Errorf("error sending response: %v: %w", interactors.ErrCorrelationIDNotProvided, sendErr)
+		}
+		return interactors.ErrCorrelationIDNotProvided
+	}
+
+	registry, err := mc.thingInteractor.ListTypes(authorization)
+	sendErr := mc.sender.SendTypesResponse(registry, replyTo, corrID, err)
+	if sendErr != nil {
+		return fmt.Errorf("error sending response: %v: %w", err, sendErr)
+	}
+
+	return err
+}
+
 // AuthDevice handles the auth device request and execute its use case
 func (mc *thingController) AuthDevice(body []byte, authorization, replyTo, corrID string) error {
 	var authThingReq network.DeviceAuthRequest
@@ -166,7 +231,7 @@ func (mc *thingController) RequestData(body []byte, authorization string) error
 
 	mc.logger.Info("request data command received")
 	mc.logger.Debug(authorization, requestDataReq)
-	err = mc.thingInteractor.RequestData(authorization, requestDataReq.ID, requestDataReq.SensorIds)
+	err = mc.thingInteractor.RequestData(authorization, requestDataReq.ID, requestDataReq.CommandID, requestDataReq.SensorIds)
 	if err != nil {
 		return err
 	}
@@ -182,7 +247,7 @@ func (mc *thingController) UpdateData(body []byte, authorization string) error {
 		return fmt.Errorf("message body parsing error: %w", err)
 	}
 
-	return mc.thingInteractor.UpdateData(authorization, msg.ID, msg.Data)
+	return mc.thingInteractor.UpdateData(authorization, msg.ID, msg.CommandID, msg.Data)
 }
 
 // PublishData handles the publish data request and execute its use case
@@ -193,5 +258,13 @@ func (mc *thingController) PublishData(body []byte, authorization string) error
 		return fmt.Errorf("message body parsing error: %w", err)
 	}
 
+	if msg.Timestamp != nil {
+		for i := range msg.Data {
+			if msg.Data[i].Timestamp == nil {
+				msg.Data[i].Timestamp = msg.Timestamp
+			}
+		}
+	}
+
 	return mc.thingInteractor.PublishData(authorization, msg.ID, msg.Data)
 }
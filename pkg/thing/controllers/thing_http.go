@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/interactors"
+	"github.com/gorilla/mux"
+)
+
+// ThingHTTPController handles the HTTP requests to manage the things, calling
+// the same use cases as the AMQP commands
+type ThingHTTPController struct {
+	logger          logging.Logger
+	thingInteractor interactors.Interactor
+}
+
+// RegisterThingRequest represents the request to register a thing
+type RegisterThingRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UpdateSchemaRequest represents the request to update the thing's schema
+type UpdateSchemaRequest struct {
+	Schema []entities.Schema `json:"schema"`
+}
+
+// ErrorResponse represents the error response to be sent to the request
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// NewThingHTTPController constructs the controller
+func NewThingHTTPController(logger logging.Logger, thingInteractor interactors.Interactor) *ThingHTTPController {
+	return &ThingHTTPController{logger, thingInteractor}
+}
+
+// Register godoc
+// @Summary Registers a new thing
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param thing body RegisterThingRequest true "Thing's id and name"
+// @Success 201 {object} entities.Thing "Registered thing with its token"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} ErrorResponse "Invalid authorization token"
+// @Failure 409 {object} ErrorResponse "Thing already registered"
+// @Failure 422 {object} ErrorResponse "Invalid request format"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /things [post]
+// Register handles the server request and calls the register use case
+func (tc *ThingHTTPController) Register(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	var req RegisterThingRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		tc.logger.Error("failed to parse request body")
+		tc.writeResponse(w, http.StatusUnprocessableEntity, &ErrorResponse{err.Error()})
+		return
+	}
+
+	err = tc.thingInteractor.Register(authorization, req.ID, req.Name)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	thing, err := tc.thingInteractor.Get(authorization, req.ID)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.logger.Infof("thing %s registered", req.ID)
+	w.Header().Set("Location", "/things/"+req.ID)
+	tc.writeResponse(w, http.StatusCreated, thing)
+}
+
+// List godoc
+// @Summary Lists the registered things
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Success 200 {array} entities.Thing "Registered things"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} ErrorResponse "Invalid authorization token"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /things [get]
+// List handles the server request and calls the list use case
+func (tc *ThingHTTPController) List(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	things, err := tc.thingInteractor.List(authorization)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.writeResponse(w, http.StatusOK, things)
+}
+
+// Get godoc
+// @Summary Gets a registered thing
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Thing's id"
+// @Success 200 {object} entities.Thing "Registered thing"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} ErrorResponse "Thing not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /things/{id} [get]
+// Get handles the server request and calls the get use case
+func (tc *ThingHTTPController) Get(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	thing, err := tc.thingInteractor.Get(authorization, mux.Vars(r)["id"])
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.writeResponse(w, http.StatusOK, thing)
+}
+
+// Unregister godoc
+// @Summary Unregisters a thing
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Thing's id"
+// @Success 204 "Thing unregistered"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} ErrorResponse "Thing not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /things/{id} [delete]
+// Unregister handles the server request and calls the unregister use case
+func (tc *ThingHTTPController) Unregister(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	err := tc.thingInteractor.Unregister(authorization, id)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.logger.Infof("thing %s unregistered", id)
+	tc.writeResponse(w, http.StatusNoContent, nil)
+}
+
+// UpdateSchema godoc
+// @Summary Updates the thing's schema
+// @Produce json
+// @Accept  json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Thing's id"
+// @Param schema body UpdateSchemaRequest true "Thing's schema"
+// @Success 200 {object} entities.Thing "Thing with the updated schema"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} ErrorResponse "Thing not found"
+// @Failure 409 {object} ErrorResponse "Schema update with breaking changes rejected"
+// @Failure 422 {object} ErrorResponse "Invalid request format or schema"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /things/{id}/schema [put]
+// UpdateSchema handles the server request and calls the update schema use case
+func (tc *ThingHTTPController) UpdateSchema(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateSchemaRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		tc.logger.Error("failed to parse request body")
+		tc.writeResponse(w, http.StatusUnprocessableEntity, &ErrorResponse{err.Error()})
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	err = tc.thingInteractor.UpdateSchema(authorization, id, req.Schema)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	thing, err := tc.thingInteractor.Get(authorization, id)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.logger.Infof("thing %s schema updated", id)
+	tc.writeResponse(w, http.StatusOK, thing)
+}
+
+// ListSchemaVersions godoc
+// @Summary Lists the versions of the thing's schema
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Param id path string true "Thing's id"
+// @Success 200 {array} entities.SchemaVersion "Schema versions from the oldest, with their diffs"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 403 {object} ErrorResponse "Invalid authorization token"
+// @Failure 404 {object} ErrorResponse "Thing not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /things/{id}/schema/versions [get]
+// ListSchemaVersions handles the server request and calls the list schema versions use case
+func (tc *ThingHTTPController) ListSchemaVersions(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := tc.thingInteractor.ListSchemaVersions(authorization, mux.Vars(r)["id"])
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.writeResponse(w, http.StatusOK, versions)
+}
+
+// ListTypes godoc
+// @Summary Lists the sensor types accepted on the things' schemas
+// @Produce json
+// @Param Authorization header string true "User's token"
+// @Success 200 {object} entities.TypeRegistry "Value types and sensor types with their units"
+// @Failure 401 {object} ErrorResponse "Authorization token not provided"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /schema/types [get]
+// ListTypes handles the server request and calls the list types use case
+func (tc *ThingHTTPController) ListTypes(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := tc.authorization(w, r)
+	if !ok {
+		return
+	}
+
+	registry, err := tc.thingInteractor.ListTypes(authorization)
+	if err != nil {
+		tc.writeError(w, err)
+		return
+	}
+
+	tc.writeResponse(w, http.StatusOK, registry)
+}
+
+// authorization returns the request's authorization token. When it isn't
+// provided, the request is answered with 401 Unauthorized.
+func (tc *ThingHTTPController) authorization(w http.ResponseWriter, r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		tc.writeError(w, interactors.ErrAuthNotProvided)
+		return "", false
+	}
+
+	return authorization, true
+}
+
+func (tc *ThingHTTPController) writeError(w http.ResponseWriter, err error) {
+	tc.logger.Error(err)
+	tc.writeResponse(w, mapThingErrorToStatusCode(err), &ErrorResponse{err.Error()})
+}
+
+func (tc *ThingHTTPController) writeResponse(w http.ResponseWriter, statusCode int, msg interface{}) {
+	if msg == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	js, err := json.Marshal(msg)
+	if err != nil {
+		tc.logger.Errorf("unable to marshal json: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(js)
+	if err != nil {
+		tc.logger.Errorf("unable to write to connection HTTP: %s", err)
+	}
+}
+
+func mapThingErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, interactors.ErrAuthNotProvided):
+		return http.StatusUnauthorized
+	case errors.Is(err, entities.ErrThingForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrThingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entities.ErrThingExists),
+		errors.Is(err, interactors.ErrSchemaBreakingChange):
+		return http.StatusConflict
+	case errors.Is(err, interactors.ErrIDNotProvided),
+		errors.Is(err, interactors.ErrNameNotProvided),
+		errors.Is(err, interactors.ErrIDLength),
+		errors.Is(err, interactors.ErrIDNotHex),
+		errors.Is(err, interactors.ErrSchemaNotProvided),
+		errors.Is(err, interactors.ErrSchemaInvalid):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,7 +2,9 @@ package amqp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
 	"github.com/CESARBR/knot-babeltower/pkg/network"
@@ -16,25 +18,38 @@ const (
 	exchangeDataPublishedType = "fanout"
 	registerOutKey            = "device.registered"
 	unregisterOutKey          = "device.unregistered"
+	registerBatchOutKey       = "device.registered.batch"
+	unregisterBatchOutKey     = "device.unregistered.batch"
 	schemaOutKey              = "device.schema.updated"
 	updateDataKey             = "data.update"
 	requestDataKey            = "data.request"
+	onlineOutKey              = "device.online"
+	offlineOutKey             = "device.offline"
 )
 
+// ErrUndeliverable is returned when the broker has no queue to deliver the
+// message to, i.e. there is no client subscribed to receive it
+var ErrUndeliverable = errors.New("no client subscribed to receive the message")
+
 // Publisher provides methods to send events to the clients
 type Publisher interface {
 	PublishRegisteredDevice(thingID, name, token string, err error) error
 	PublishUnregisteredDevice(thingID string, err error) error
-	PublishUpdatedSchema(thingID string, schema []entities.Schema, err error) error
-	PublishUpdateData(thingID string, data []entities.Data) error
-	PublishRequestData(thingID string, sensorIds []int) error
+	PublishRegisteredBatch(results []entities.BatchResult, err error) error
+	PublishUnregisteredBatch(results []entities.BatchResult, err error) error
+	PublishUpdatedSchema(thingID string, schema []entities.Schema, version *entities.SchemaVersion, err error) error
+	PublishUpdateData(thingID, commandID string, data []entities.Data) error
+	PublishRequestData(thingID, commandID string, sensorIds []int) error
 	PublishPublishedData(thingID, token string, data []entities.Data) error
+	PublishDeviceOnline(thingID string, lastSeen time.Time) error
+	PublishDeviceOffline(thingID string, lastSeen time.Time) error
 }
 
 // Sender represents the operations to send commands response
 type Sender interface {
 	SendAuthResponse(thingID, replyTo, corrID string, err error) error
 	SendListResponse(things []*entities.Thing, replyTo, corrID string, err error) error
+	SendTypesResponse(registry *entities.TypeRegistry, replyTo, corrID string, err error) error
 }
 
 // msgClientPublisher handle messages received from a service
@@ -70,7 +85,7 @@ func (mp *msgClientPublisher) PublishRegisteredDevice(thingID, name, token strin
 		return err
 	}
 
-	return mp.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, registerOutKey, msg, nil)
+	return mp.publish(exchangeDevices, exchangeDevicesType, registerOutKey, msg, nil)
 }
 
 // PublishUnregisteredDevice publishes the unregistered device's id and error message to the device unregistered queue
@@ -84,43 +99,86 @@ func (mp *msgClientPublisher) PublishUnregisteredDevice(thingID string, err erro
 		return err
 	}
 
-	return mp.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, unregisterOutKey, msg, nil)
+	return mp.publish(exchangeDevices, exchangeDevicesType, unregisterOutKey, msg, nil)
+}
+
+// PublishRegisteredBatch publishes the result of registering each thing of
+// a batch, along with the batch's error
+func (mp *msgClientPublisher) PublishRegisteredBatch(results []entities.BatchResult, err error) error {
+	mp.logger.Debug("sending registered batch message")
+	resp := &network.DeviceRegisteredBatchResponse{Things: []network.DeviceRegisteredResponse{}, Error: getErrMsg(err)}
+	for _, r := range results {
+		resp.Things = append(resp.Things, network.DeviceRegisteredResponse{ID: r.ID, Name: r.Name, Token: r.Token, Error: getErrMsg(r.Err)})
+	}
+
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		mp.logger.Error(err)
+		return err
+	}
+
+	return mp.publish(exchangeDevices, exchangeDevicesType, registerBatchOutKey, msg, nil)
 }
 
-// PublishUpdatedSchema sends the updated schema response
-func (mp *msgClientPublisher) PublishUpdatedSchema(thingID string, schema []entities.Schema, err error) error {
+// PublishUnregisteredBatch publishes the result of unregistering each thing
+// of a batch, along with the batch's error
+func (mp *msgClientPublisher) PublishUnregisteredBatch(results []entities.BatchResult, err error) error {
+	mp.logger.Debug("sending unregistered batch message")
+	resp := &network.DeviceUnregisteredBatchResponse{Things: []network.DeviceUnregisteredResponse{}, Error: getErrMsg(err)}
+	for _, r := range results {
+		resp.Things = append(resp.Things, network.DeviceUnregisteredResponse{ID: r.ID, Error: getErrMsg(r.Err)})
+	}
+
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		mp.logger.Error(err)
+		return err
+	}
+
+	return mp.publish(exchangeDevices, exchangeDevicesType, unregisterBatchOutKey, msg, nil)
+}
+
+// PublishUpdatedSchema sends the updated schema response. The version number
+// and the diff from the previous version are omitted when version is nil.
+func (mp *msgClientPublisher) PublishUpdatedSchema(thingID string, schema []entities.Schema, version *entities.SchemaVersion, err error) error {
 	errMsg := getErrMsg(err)
 	resp := &network.SchemaUpdatedResponse{ID: thingID, Schema: schema, Error: errMsg}
+	if version != nil {
+		resp.Version = version.Version
+		resp.Diff = &version.Diff
+	}
 	msg, err := json.Marshal(resp)
 	if err != nil {
 		return err
 	}
 
-	return mp.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, schemaOutKey, msg, nil)
+	return mp.publish(exchangeDevices, exchangeDevicesType, schemaOutKey, msg, nil)
 }
 
-// PublishRequestData sends request data command
-func (mp *msgClientPublisher) PublishRequestData(thingID string, sensorIds []int) error {
-	resp := &network.DataRequest{ID: thingID, SensorIds: sensorIds}
+// PublishRequestData sends request data command. The command ID is omitted
+// when it's empty.
+func (mp *msgClientPublisher) PublishRequestData(thingID, commandID string, sensorIds []int) error {
+	resp := &network.DataRequest{ID: thingID, CommandID: commandID, SensorIds: sensorIds}
 	msg, err := json.Marshal(resp)
 	if err != nil {
 		return err
 	}
 
 	routingKey := "device." + thingID + "." + requestDataKey
-	return mp.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, routingKey, msg, nil)
+	return mp.publish(exchangeDevices, exchangeDevicesType, routingKey, msg, nil)
 }
 
-// PublishUpdateData send update data command
-func (mp *msgClientPublisher) PublishUpdateData(thingID string, data []entities.Data) error {
-	resp := &network.DataUpdate{ID: thingID, Data: data}
+// PublishUpdateData send update data command. The command ID is omitted when
+// it's empty.
+func (mp *msgClientPublisher) PublishUpdateData(thingID, commandID string, data []entities.Data) error {
+	resp := &network.DataUpdate{ID: thingID, CommandID: commandID, Data: data}
 	msg, err := json.Marshal(resp)
 	if err != nil {
 		return fmt.Errorf("message parsing error: %w", err)
 	}
 
 	routingKey := "device." + thingID + "." + updateDataKey
-	return mp.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, routingKey, msg, nil)
+	return mp.publish(exchangeDevices, exchangeDevicesType, routingKey, msg, nil)
 }
 
 // SendAuthResponse sends the auth thing status response
@@ -135,7 +193,7 @@ func (cs *commandSender) SendAuthResponse(thingID string, replyTo, corrID string
 		return err
 	}
 
-	return cs.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, replyTo, msg, headers)
+	return cs.publish(exchangeDevices, exchangeDevicesType, replyTo, msg, headers)
 }
 
 // SendListResponse sends the list devices command response
@@ -150,7 +208,26 @@ func (cs *commandSender) SendListResponse(things []*entities.Thing, replyTo, cor
 		return err
 	}
 
-	return cs.amqp.PublishPersistentMessage(exchangeDevices, exchangeDevicesType, replyTo, msg, headers)
+	return cs.publish(exchangeDevices, exchangeDevicesType, replyTo, msg, headers)
+}
+
+// SendTypesResponse sends the list sensor types command response
+func (cs *commandSender) SendTypesResponse(registry *entities.TypeRegistry, replyTo, corrID string, err error) error {
+	errMsg := getErrMsg(err)
+	resp := &network.SchemaTypesResponse{ValueTypes: []entities.ValueType{}, Types: []entities.SensorType{}, Error: errMsg}
+	if registry != nil {
+		resp.ValueTypes = registry.ValueTypes
+		resp.Types = registry.Types
+	}
+	headers := map[string]interface{}{
+		"correlation_id": corrID,
+	}
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return cs.publish(exchangeDevices, exchangeDevicesType, replyTo, msg, headers)
 }
 
 // PublishPublishedData send update data command
@@ -164,7 +241,44 @@ func (mp *msgClientPublisher) PublishPublishedData(thingID, token string, data [
 		return fmt.Errorf("message parsing error: %w", err)
 	}
 
-	return mp.amqp.PublishPersistentMessage(exchangeDataPublished, exchangeDataPublishedType, "", msg, headers)
+	return mp.publish(exchangeDataPublished, exchangeDataPublishedType, "", msg, headers)
+}
+
+// PublishDeviceOnline sends the device online event
+func (mp *msgClientPublisher) PublishDeviceOnline(thingID string, lastSeen time.Time) error {
+	msg, err := json.Marshal(&network.DevicePresence{ID: thingID, LastSeen: lastSeen})
+	if err != nil {
+		return fmt.Errorf("message parsing error: %w", err)
+	}
+
+	return mp.publish(exchangeDevices, exchangeDevicesType, onlineOutKey, msg, nil)
+}
+
+// PublishDeviceOffline sends the device offline event
+func (mp *msgClientPublisher) PublishDeviceOffline(thingID string, lastSeen time.Time) error {
+	msg, err := json.Marshal(&network.DevicePresence{ID: thingID, LastSeen: lastSeen})
+	if err != nil {
+		return fmt.Errorf("message parsing error: %w", err)
+	}
+
+	return mp.publish(exchangeDevices, exchangeDevicesType, offlineOutKey, msg, nil)
+}
+
+func (mp *msgClientPublisher) publish(exchange, exchangeType, key string, body []byte, headers map[string]interface{}) error {
+	return MapPublishError(mp.amqp.PublishPersistentMessage(exchange, exchangeType, key, body, headers))
+}
+
+func (cs *commandSender) publish(exchange, exchangeType, key string, body []byte, headers map[string]interface{}) error {
+	return MapPublishError(cs.amqp.PublishPersistentMessage(exchange, exchangeType, key, body, headers))
+}
+
+// MapPublishError wraps the error of a message the broker couldn't route in
+// ErrUndeliverable, so the publishers of every domain report it the same way
+func MapPublishError(err error) error {
+	if errors.Is(err, network.ErrUnroutable) {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+	return err
 }
 
 func getErrMsg(err error) *string {
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// CacheStats represents the cache usage statistics
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+	MaxSize   int    `json:"maxSize"`
+}
+
+// CachedThingProxy decorates a ThingProxy caching the things fetched by each
+// token. The things are kept up to the TTL and the least recently used are
+// evicted when the cache is full. A thing is invalidated for every token when
+// it's created, has its schema updated or is removed through the proxy.
+type CachedThingProxy struct {
+	logger  logging.Logger
+	proxy   ThingProxy
+	ttl     time.Duration
+	maxSize int
+	now     func() time.Time
+
+	mutex   sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+	stats   CacheStats
+	// generation changes on every invalidation, so a thing fetched before it
+	// isn't cached
+	generation uint64
+}
+
+type cacheKey struct {
+	authorization string
+	id            string
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	thing   entities.Thing
+	expires time.Time
+}
+
+// NewCachedThingProxy creates a cache for the things fetched through the
+// proxy. Caching is disabled when the TTL or the maximum size is zero.
+func NewCachedThingProxy(logger logging.Logger, proxy ThingProxy, ttl time.Duration, maxSize int) *CachedThingProxy {
+	return &CachedThingProxy{
+		logger:  logger,
+		proxy:   proxy,
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     time.Now,
+		entries: map[cacheKey]*list.Element{},
+		lru:     list.New(),
+		stats:   CacheStats{MaxSize: maxSize},
+	}
+}
+
+// Create creates the thing and invalidates it on the cache
+func (c *CachedThingProxy) Create(id, name, authorization string) (string, error) {
+	defer c.Invalidate(id)
+	return c.proxy.Create(id, name, authorization)
+}
+
+// UpdateSchema updates the thing's schema and invalidates it on the cache
+func (c *CachedThingProxy) UpdateSchema(authorization, ID string, schemaList []entities.Schema) error {
+	defer c.Invalidate(ID)
+	return c.proxy.UpdateSchema(authorization, ID, schemaList)
+}
+
+// List lists the things, always requesting them to the proxy
+func (c *CachedThingProxy) List(authorization string) ([]*entities.Thing, error) {
+	return c.proxy.List(authorization)
+}
+
+// Get returns the thing from the cache or, when it isn't cached or has
+// expired, fetches it from the proxy and caches it
+func (c *CachedThingProxy) Get(authorization, ID string) (*entities.Thing, error) {
+	key := cacheKey{authorization, ID}
+	thing, generation, ok := c.get(key)
+	if ok {
+		return thing, nil
+	}
+
+	thing, err := c.proxy.Get(authorization, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(key, thing, generation)
+	return thing, nil
+}
+
+// Remove removes the thing and invalidates it on the cache
+func (c *CachedThingProxy) Remove(authorization, ID string) error {
+	defer c.Invalidate(ID)
+	return c.proxy.Remove(authorization, ID)
+}
+
+// Invalidate drops the thing from the cache for every token
+func (c *CachedThingProxy) Invalidate(ID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	for key, elem := range c.entries {
+		if key.id == ID {
+			c.remove(elem)
+		}
+	}
+
+	c.logger.Debug("thing " + ID + " invalidated on cache")
+}
+
+// Stats returns the cache usage statistics
+func (c *CachedThingProxy) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+func (c *CachedThingProxy) enabled() bool {
+	return c.ttl > 0 && c.maxSize > 0
+}
+
+// get returns the cached thing or, on a miss, the current generation
+func (c *CachedThingProxy) get(key cacheKey) (*entities.Thing, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+	thing := entry.thing
+	return &thing, c.generation, true
+}
+
+// set caches the thing unless an invalidation happened since the generation
+func (c *CachedThingProxy) set(key cacheKey, thing *entities.Thing, generation uint64) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	for c.lru.Len() >= c.maxSize {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+
+	entry := &cacheEntry{key, *thing, c.now().Add(c.ttl)}
+	c.entries[key] = c.lru.PushFront(entry)
+}
+
+func (c *CachedThingProxy) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	return fc.now
+}
+
+func newCachedProxy(proxy ThingProxy, ttl time.Duration, maxSize int) (*CachedThingProxy, *fakeClock) {
+	clock := &fakeClock{time.Now()}
+	cache := NewCachedThingProxy(&mocks.FakeLogger{}, proxy, ttl, maxSize)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func TestCachedGet(t *testing.T) {
+	thing := &entities.Thing{ID: "1", Token: "mainflux-id", Name: "thing"}
+	testCases := []struct {
+		name              string
+		ttl               time.Duration
+		maxSize           int
+		act               func(cache *CachedThingProxy, clock *fakeClock)
+		expectedProxyGets int
+		expectedStats     CacheStats
+	}{
+		{
+			"second get is a hit",
+			time.Minute,
+			10,
+			func(cache *CachedThingProxy, clock *fakeClock) {},
+			1,
+			CacheStats{Hits: 1, Misses: 1, Size: 1, MaxSize: 10},
+		},
+		{
+			"expired thing is fetched again",
+			time.Minute,
+			10,
+			func(cache *CachedThingProxy, clock *fakeClock) {
+				clock.now = clock.now.Add(time.Minute)
+			},
+			2,
+			CacheStats{Misses: 2, Size: 1, MaxSize: 10},
+		},
+		{
+			"invalidated thing is fetched again",
+			time.Minute,
+			10,
+			func(cache *CachedThingProxy, clock *fakeClock) {
+				cache.Invalidate("1")
+			},
+			2,
+			CacheStats{Misses: 2, Size: 1, MaxSize: 10},
+		},
+		{
+			"disabled cache always fetches the thing",
+			0,
+			10,
+			func(cache *CachedThingProxy, clock *fakeClock) {},
+			2,
+			CacheStats{Misses: 2, MaxSize: 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy := &mocks.FakeThingProxy{}
+			proxy.On("Get", "token", "1").Return(thing, nil)
+			cache, clock := newCachedProxy(proxy, tc.ttl, tc.maxSize)
+
+			first, err := cache.Get("token", "1")
+			assert.NoError(t, err)
+			tc.act(cache, clock)
+			second, err := cache.Get("token", "1")
+			assert.NoError(t, err)
+
+			assert.Equal(t, thing, first)
+			assert.Equal(t, thing, second)
+			proxy.AssertNumberOfCalls(t, "Get", tc.expectedProxyGets)
+			assert.Equal(t, tc.expectedStats, cache.Stats())
+		})
+	}
+}
+
+func TestCachedGetIsScopedByToken(t *testing.T) {
+	proxy := &mocks.FakeThingProxy{}
+	proxy.On("Get", "token", "1").Return(&entities.Thing{ID: "1"}, nil)
+	proxy.On("Get", "other-token", "1").Return((*entities.Thing)(nil), entities.ErrThingNotFound)
+	cache, _ := newCachedProxy(proxy, time.Minute, 10)
+
+	_, err := cache.Get("token", "1")
+	assert.NoError(t, err)
+	_, err = cache.Get("other-token", "1")
+	assert.Equal(t, entities.ErrThingNotFound, err)
+	_, err = cache.Get("other-token", "1")
+	assert.Equal(t, entities.ErrThingNotFound, err)
+
+	proxy.AssertNumberOfCalls(t, "Get", 3)
+}
+
+func TestCachedGetEvictsLeastRecentlyUsed(t *testing.T) {
+	proxy := &mocks.FakeThingProxy{}
+	for _, id := range []string{"1", "2", "3"} {
+		proxy.On("Get", "token", id).Return(&entities.Thing{ID: id}, nil)
+	}
+	cache, _ := newCachedProxy(proxy, time.Minute, 2)
+
+	for _, id := range []string{"1", "2", "1", "3", "1", "2"} {
+		_, err := cache.Get("token", id)
+		assert.NoError(t, err)
+	}
+
+	proxy.AssertNumberOfCalls(t, "Get", 4)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2, MaxSize: 2}, cache.Stats())
+}
+
+func TestCachedProxyInvalidatesChangedThings(t *testing.T) {
+	schema := []entities.Schema{{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 13, Name: "temperature"}}
+	testCases := []struct {
+		name   string
+		change func(cache *CachedThingProxy) error
+	}{
+		{
+			"create",
+			func(cache *CachedThingProxy) error {
+				_, err := cache.Create("1", "thing", "token")
+				return err
+			},
+		},
+		{
+			"update schema",
+			func(cache *CachedThingProxy) error {
+				return cache.UpdateSchema("token", "1", schema)
+			},
+		},
+		{
+			"remove",
+			func(cache *CachedThingProxy) error {
+				return cache.Remove("token", "1")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy := &mocks.FakeThingProxy{}
+			proxy.On("Get", "token", "1").Return(&entities.Thing{ID: "1"}, nil)
+			proxy.On("Get", "other-token", "1").Return(&entities.Thing{ID: "1"}, nil)
+			proxy.On("Create", "1", "thing", "token").Return("mainflux-id", nil)
+			proxy.On("UpdateSchema", "1", schema).Return(nil)
+			proxy.On("Remove", "token", "1").Return(nil)
+			cache, _ := newCachedProxy(proxy, time.Minute, 10)
+			_, _ = cache.Get("token", "1")
+			_, _ = cache.Get("other-token", "1")
+
+			assert.NoError(t, tc.change(cache))
+
+			assert.Equal(t, 0, cache.Stats().Size)
+		})
+	}
+}
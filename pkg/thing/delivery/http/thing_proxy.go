@@ -3,8 +3,10 @@ package http
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
@@ -12,6 +14,10 @@ import (
 	"github.com/google/go-querystring/query"
 )
 
+// errMetadataQueryUnsupported is returned when the thing's service ignores the
+// metadata query parameter, as the older Mainflux versions do
+var errMetadataQueryUnsupported = errors.New("thing's service doesn't support metadata queries")
+
 type errorConflict struct{ error }
 
 func (err errorConflict) Error() string {
@@ -53,6 +59,22 @@ type pageFetchInput struct {
 type proxy struct {
 	url    string
 	logger logging.Logger
+
+	// mutex protects the lookup state below
+	mutex sync.Mutex
+	// mainfluxIDs maps the things' KNoT ID to their ID on the thing's service.
+	// It's scoped by the user's token, since the KNoT IDs are only unique
+	// among the user's things.
+	mainfluxIDs map[mainfluxIDKey]string
+	// noMetadataQuery is set once the thing's service ignores a metadata query
+	noMetadataQuery bool
+}
+
+// mainfluxIDKey identifies a thing by its KNoT ID among the token's user
+// things
+type mainfluxIDKey struct {
+	authorization string
+	id            string
 }
 
 // RequestInfo aims to group all request releated information
@@ -67,8 +89,9 @@ type RequestInfo struct {
 
 // RequestOptions represents the request query parameters
 type RequestOptions struct {
-	Limit  int `url:"limit"`
-	Offset int `url:"offset"`
+	Limit    int    `url:"limit"`
+	Offset   int    `url:"offset"`
+	Metadata string `url:"metadata,omitempty"`
 }
 
 // NewThingProxy creates a proxy to the thing service
@@ -76,11 +99,11 @@ func NewThingProxy(logger logging.Logger, hostname string, port uint16) ThingPro
 	url := fmt.Sprintf("http://%s:%d", hostname, port)
 
 	logger.Debug("proxy setup to " + url)
-	return proxy{url, logger}
+	return &proxy{url: url, logger: logger, mainfluxIDs: map[mainfluxIDKey]string{}}
 }
 
 // Create register a thing on service and return the id generated
-func (p proxy) Create(id, name, authorization string) (idGenerated string, err error) {
+func (p *proxy) Create(id, name, authorization string) (idGenerated string, err error) {
 	p.logger.Debug("proxying request to create thing")
 	t := p.getRemoteThingRepr(id, name, nil)
 	body, err := json.Marshal(t)
@@ -113,12 +136,13 @@ func (p proxy) Create(id, name, authorization string) (idGenerated string, err e
 
 	locationHeader := resp.Header.Get("Location")
 	thingID := locationHeader[len("/things/"):] // get substring after "/things/"
+	p.setMainfluxID(authorization, id, thingID)
 	return thingID, nil
 }
 
 // UpdateSchema receives the thing's ID and schema and send a HTTP request to
 // the thing's service in order to update it with the schema.
-func (p proxy) UpdateSchema(authorization, ID string, schemaList []entities.Schema) error {
+func (p *proxy) UpdateSchema(authorization, ID string, schemaList []entities.Schema) error {
 	t, err := p.Get(authorization, ID)
 	if err != nil {
 		return err
@@ -151,7 +175,7 @@ func (p proxy) UpdateSchema(authorization, ID string, schemaList []entities.Sche
 	return p.mapErrorFromStatusCode(resp.StatusCode)
 }
 
-func (p proxy) List(authorization string) ([]*entities.Thing, error) {
+func (p *proxy) List(authorization string) ([]*entities.Thing, error) {
 	things := []*entities.Thing{}
 	pagThings, err := p.getPaginatedThings(authorization)
 	if err != nil {
@@ -159,32 +183,45 @@ func (p proxy) List(authorization string) ([]*entities.Thing, error) {
 	}
 
 	for _, t := range pagThings {
-		things = append(things, &entities.Thing{ID: t.Metadata.Knot.ID, Name: t.Name, Schema: t.Metadata.Knot.Schema})
+		things = append(things, &entities.Thing{ID: t.Metadata.Knot.ID, Token: t.ID, Name: t.Name, Schema: t.Metadata.Knot.Schema})
 	}
 
 	return things, err
 }
 
-// Get list the things registered on thing's service
-func (p proxy) Get(authorization, ID string) (*entities.Thing, error) {
-	things, err := p.getPaginatedThings(authorization)
-	if err != nil {
-		return nil, err
+// Get returns the thing identified by the KNoT ID. The thing is fetched by its
+// cached ID on the thing's service or queried by its metadata, so a single
+// request is needed. When the thing's service doesn't support metadata
+// queries, all the user's things are scanned, caching their IDs.
+func (p *proxy) Get(authorization, ID string) (*entities.Thing, error) {
+	if mainfluxID, ok := p.getMainfluxID(authorization, ID); ok {
+		t, err := p.getByMainfluxID(authorization, mainfluxID)
+		if err == nil && t.ID == ID {
+			return t, nil
+		}
+		if err != nil && err != entities.ErrThingNotFound && err != entities.ErrThingForbidden {
+			return nil, err
+		}
+
+		// the thing was removed or doesn't belong to this user anymore
+		p.removeMainfluxID(authorization, ID)
 	}
 
-	for i := range things {
-		t := things[i]
-		if t.Metadata.Knot.ID == ID {
-			nt := &entities.Thing{ID: ID, Token: t.ID, Name: t.Name, Schema: t.Metadata.Knot.Schema}
-			return nt, nil
+	if p.supportsMetadataQuery() {
+		t, err := p.getByMetadata(authorization, ID)
+		if err != errMetadataQueryUnsupported {
+			return t, err
 		}
+
+		p.logger.Warn(err)
+		p.disableMetadataQuery()
 	}
 
-	return nil, entities.ErrThingNotFound
+	return p.getByScanning(authorization, ID)
 }
 
 // Remove removes the indicated thing from the thing's service
-func (p proxy) Remove(authorization, ID string) error {
+func (p *proxy) Remove(authorization, ID string) error {
 	t, err := p.Get(authorization, ID)
 	if err != nil {
 		return err
@@ -205,10 +242,159 @@ func (p proxy) Remove(authorization, ID string) error {
 	}
 	defer resp.Body.Close()
 
-	return p.mapErrorFromStatusCode(resp.StatusCode)
+	err = p.mapErrorFromStatusCode(resp.StatusCode)
+	if err != nil {
+		return err
+	}
+
+	p.removeMainfluxID(authorization, ID)
+	return nil
+}
+
+func (p *proxy) getByMainfluxID(authorization, mainfluxID string) (*entities.Thing, error) {
+	requestInfo := &RequestInfo{
+		"GET",
+		p.url + "/things/" + mainfluxID,
+		authorization,
+		"application/json",
+		nil,
+		nil,
+	}
+
+	resp, err := p.sendRequest(requestInfo)
+	if err != nil {
+		p.logger.Error(err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = p.mapErrorFromStatusCode(resp.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ThingProxyRepr{}
+	err = json.NewDecoder(resp.Body).Decode(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.toThing(t), nil
+}
+
+// getByMetadata queries the thing's service for the things with the KNoT ID.
+// Since the services not supporting the query return any of the user's things,
+// errMetadataQueryUnsupported is returned when a thing doesn't match.
+func (p *proxy) getByMetadata(authorization, ID string) (*entities.Thing, error) {
+	metadata, err := json.Marshal(objMetadata{Knot: objKnot{ID: ID}})
+	if err != nil {
+		return nil, err
+	}
+
+	requestInfo := &RequestInfo{
+		"GET",
+		p.url + "/things",
+		authorization,
+		"application/json",
+		nil,
+		&RequestOptions{Limit: 100, Offset: 0, Metadata: string(metadata)},
+	}
+
+	resp, err := p.sendRequest(requestInfo)
+	if err != nil {
+		p.logger.Error(err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = p.mapErrorFromStatusCode(resp.StatusCode)
+	if err != nil {
+		p.logger.Error(err)
+		return nil, err
+	}
+
+	page := &pageFetchInput{}
+	err = json.NewDecoder(resp.Body).Decode(page)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range page.Things {
+		if t.Metadata.Knot.ID != ID {
+			return nil, errMetadataQueryUnsupported
+		}
+	}
+
+	if len(page.Things) == 0 {
+		return nil, entities.ErrThingNotFound
+	}
+
+	p.setMainfluxID(authorization, ID, page.Things[0].ID)
+	return p.toThing(page.Things[0]), nil
+}
+
+func (p *proxy) getByScanning(authorization, ID string) (*entities.Thing, error) {
+	things, err := p.getPaginatedThings(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *entities.Thing
+	for _, t := range things {
+		p.setMainfluxID(authorization, t.Metadata.Knot.ID, t.ID)
+		if t.Metadata.Knot.ID == ID {
+			found = p.toThing(t)
+		}
+	}
+
+	if found == nil {
+		return nil, entities.ErrThingNotFound
+	}
+
+	return found, nil
+}
+
+func (p *proxy) toThing(t *ThingProxyRepr) *entities.Thing {
+	return &entities.Thing{ID: t.Metadata.Knot.ID, Token: t.ID, Name: t.Name, Schema: t.Metadata.Knot.Schema}
+}
+
+func (p *proxy) getMainfluxID(authorization, ID string) (string, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	mainfluxID, ok := p.mainfluxIDs[mainfluxIDKey{authorization, ID}]
+	return mainfluxID, ok
+}
+
+func (p *proxy) setMainfluxID(authorization, ID, mainfluxID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.mainfluxIDs[mainfluxIDKey{authorization, ID}] = mainfluxID
+}
+
+func (p *proxy) removeMainfluxID(authorization, ID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.mainfluxIDs, mainfluxIDKey{authorization, ID})
+}
+
+func (p *proxy) supportsMetadataQuery() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return !p.noMetadataQuery
+}
+
+func (p *proxy) disableMetadataQuery() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.noMetadataQuery = true
 }
 
-func (p proxy) getRemoteThingRepr(id, name string, schemaList []entities.Schema) ThingProxyRepr {
+func (p *proxy) getRemoteThingRepr(id, name string, schemaList []entities.Schema) ThingProxyRepr {
 	return ThingProxyRepr{
 		Name: name,
 		Metadata: objMetadata{
@@ -220,7 +406,7 @@ func (p proxy) getRemoteThingRepr(id, name string, schemaList []entities.Schema)
 	}
 }
 
-func (p proxy) sendRequest(info *RequestInfo) (*http.Response, error) {
+func (p *proxy) sendRequest(info *RequestInfo) (*http.Response, error) {
 	values, err := query.Values(info.options)
 	if err != nil {
 		return nil, err
@@ -243,7 +429,7 @@ func (p proxy) sendRequest(info *RequestInfo) (*http.Response, error) {
 	return client.Do(req)
 }
 
-func (p proxy) mapErrorFromStatusCode(code int) error {
+func (p *proxy) mapErrorFromStatusCode(code int) error {
 	var err error
 
 	if code != http.StatusCreated {
@@ -252,12 +438,14 @@ func (p proxy) mapErrorFromStatusCode(code int) error {
 			err = errorConflict{}
 		case http.StatusForbidden:
 			err = entities.ErrThingForbidden
+		case http.StatusNotFound:
+			err = entities.ErrThingNotFound
 		}
 	}
 	return err
 }
 
-func (p proxy) getPaginatedThings(authorization string) ([]*ThingProxyRepr, error) {
+func (p *proxy) getPaginatedThings(authorization string) ([]*ThingProxyRepr, error) {
 	requestInfo := &RequestInfo{
 		"GET",
 		p.url + "/things",
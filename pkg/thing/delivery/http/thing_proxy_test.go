@@ -0,0 +1,142 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/internal/mainflux"
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	userDeliveryHTTP "github.com/CESARBR/knot-babeltower/pkg/user/delivery/http"
+	userEntities "github.com/CESARBR/knot-babeltower/pkg/user/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingHandler counts the requests and, when metadata queries aren't
+// supported, drops the metadata query parameter as older Mainflux versions do
+type countingHandler struct {
+	handler       http.Handler
+	metadataQuery bool
+	requests      int32
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&h.requests, 1)
+	if !h.metadataQuery {
+		query := r.URL.Query()
+		query.Del("metadata")
+		r.URL.RawQuery = query.Encode()
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *countingHandler) count() int {
+	return int(atomic.SwapInt32(&h.requests, 0))
+}
+
+func setupProxies(t *testing.T, handler http.Handler) (owner, reader ThingProxy, token string) {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	assert.NoError(t, err)
+
+	users := userDeliveryHTTP.NewUserProxy(&mocks.FakeLogger{}, u.Hostname(), uint16(port))
+	user := userEntities.User{Email: "test@test.com", Password: "12345678"}
+	assert.NoError(t, users.Create(user))
+	token, err = users.CreateToken(user)
+	assert.NoError(t, err)
+
+	owner = NewThingProxy(&mocks.FakeLogger{}, u.Hostname(), uint16(port))
+	reader = NewThingProxy(&mocks.FakeLogger{}, u.Hostname(), uint16(port))
+	return owner, reader, token
+}
+
+func TestGetThing(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		metadataQuery         bool
+		expectedFirstRequests int
+	}{
+		{
+			"metadata query supported looks up the thing with a single request",
+			true,
+			1,
+		},
+		{
+			"metadata query not supported falls back to scanning the things pages",
+			false,
+			3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := &countingHandler{handler: mainflux.NewServer(&mocks.FakeLogger{}).Handler(), metadataQuery: tc.metadataQuery}
+			owner, reader, token := setupProxies(t, handler)
+			for i := 0; i < 150; i++ {
+				_, err := owner.Create(fmt.Sprintf("%016x", i), "thing", token)
+				assert.NoError(t, err)
+			}
+			handler.count()
+
+			thing, err := reader.Get(token, fmt.Sprintf("%016x", 120))
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("%016x", 120), thing.ID)
+			assert.Equal(t, tc.expectedFirstRequests, handler.count())
+
+			for _, i := range []int{120, 42} {
+				thing, err = reader.Get(token, fmt.Sprintf("%016x", i))
+				assert.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("%016x", i), thing.ID)
+				assert.Equal(t, 1, handler.count())
+			}
+
+			assert.NoError(t, owner.Remove(token, fmt.Sprintf("%016x", 42)))
+			_, err = reader.Get(token, fmt.Sprintf("%016x", 42))
+			assert.Equal(t, entities.ErrThingNotFound, err)
+		})
+	}
+}
+
+func TestGetThingsOfDifferentUsersWithTheSameID(t *testing.T) {
+	handler := &countingHandler{handler: mainflux.NewServer(&mocks.FakeLogger{}).Handler(), metadataQuery: true}
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	u, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	assert.NoError(t, err)
+
+	users := userDeliveryHTTP.NewUserProxy(&mocks.FakeLogger{}, u.Hostname(), uint16(port))
+	proxy := NewThingProxy(&mocks.FakeLogger{}, u.Hostname(), uint16(port))
+	tokens := []string{}
+	mainfluxIDs := []string{}
+	for _, email := range []string{"a@test.com", "b@test.com"} {
+		user := userEntities.User{Email: email, Password: "12345678"}
+		assert.NoError(t, users.Create(user))
+		token, err := users.CreateToken(user)
+		assert.NoError(t, err)
+		mainfluxID, err := proxy.Create("fc3fcf912d0c290a", "thing", token)
+		assert.NoError(t, err)
+		tokens = append(tokens, token)
+		mainfluxIDs = append(mainfluxIDs, mainfluxID)
+	}
+	handler.count()
+
+	// each user's thing is fetched by its own cached ID
+	for i, token := range tokens {
+		thing, err := proxy.Get(token, "fc3fcf912d0c290a")
+		assert.NoError(t, err)
+		assert.Equal(t, mainfluxIDs[i], thing.Token)
+		assert.Equal(t, 1, handler.count())
+	}
+}
@@ -0,0 +1,11 @@
+package entities
+
+// BatchResult represents the result of registering or unregistering one of
+// the things of a batch. The token is only set when the thing was
+// registered, and the error when the operation failed.
+type BatchResult struct {
+	ID    string
+	Name  string
+	Token string
+	Err   error
+}
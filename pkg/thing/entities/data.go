@@ -1,7 +1,73 @@
 package entities
 
-// Data represents the thing's data
+import (
+	"reflect"
+	"time"
+)
+
+// Data represents the thing's data. The timestamp is optionally informed by
+// the thing, with the time the value was read, while the time it was
+// received and the sequence number are assigned by babeltower when the data
+// is published, along with the value normalized to the user's unit.
 type Data struct {
-	SensorID int         `json:"sensorId"`
-	Value    interface{} `json:"value"`
+	SensorID   int              `json:"sensorId"`
+	Value      interface{}      `json:"value"`
+	Timestamp  *time.Time       `json:"timestamp,omitempty"`
+	ReceivedAt *time.Time       `json:"receivedAt,omitempty"`
+	Sequence   uint64           `json:"sequence,omitempty"`
+	Normalized *NormalizedValue `json:"normalized,omitempty"`
+}
+
+// NormalizedValue represents the sensor's value converted from the unit
+// declared on its schema to the unit preferred by the user, or to its type's
+// base unit
+type NormalizedValue struct {
+	Value  float64 `json:"value"`
+	Unit   int     `json:"unit"`
+	Symbol string  `json:"symbol"`
+}
+
+// ReadAt returns the time the data was read, as informed by the thing, or
+// the time it was received otherwise. The fallback is returned when none of
+// them is known.
+func (d Data) ReadAt(fallback time.Time) time.Time {
+	switch {
+	case d.Timestamp != nil:
+		return *d.Timestamp
+	case d.ReceivedAt != nil:
+		return *d.ReceivedAt
+	default:
+		return fallback
+	}
+}
+
+// NumericValue converts the value received in JSON to a number, the booleans
+// being 1 or 0, informing whether it's a number or boolean
+func NumericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// EqualValues compares the values received in JSON, which are numbers,
+// booleans or strings. The arrays and objects, which can't be compared, are
+// never equal.
+func EqualValues(value, expected interface{}) bool {
+	if v, ok := value.(float64); ok {
+		e, ok := expected.(float64)
+		return ok && v == e
+	}
+	if value != nil && !reflect.TypeOf(value).Comparable() {
+		return false
+	}
+
+	return value == expected
 }
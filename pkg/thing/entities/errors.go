@@ -11,4 +11,8 @@ var (
 
 	// ErrThingExists is returned when trying to register an existing thing
 	ErrThingExists = errors.New("thing is already registered")
+
+	// ErrTypeRegistryInvalid is returned when the sensor types registry is
+	// malformed or inconsistent
+	ErrTypeRegistryInvalid = errors.New("invalid sensor types registry")
 )
@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+// Presence represents whether the thing is online, i.e. it has authenticated
+// or published data recently, and the last time it did so
+type Presence struct {
+	Online   bool       `json:"online"`
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+}
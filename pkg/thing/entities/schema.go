@@ -1,10 +1,18 @@
 package entities
 
-// Schema represents the thing's schema
+// Schema represents the thing's schema. The optional constraints restrict the
+// values accepted for the sensor: the minimum, maximum and step of the
+// numeric values, the maximum length of the raw values and the values
+// allowed for any value type.
 type Schema struct {
-	SensorID  int    `json:"sensorId"`
-	ValueType int    `json:"valueType" validate:"required"`
-	Unit      int    `json:"unit"`
-	TypeID    int    `json:"typeId" validate:"required"`
-	Name      string `json:"name" validate:"required,max=30"`
+	SensorID      int           `json:"sensorId"`
+	ValueType     int           `json:"valueType" validate:"required"`
+	Unit          int           `json:"unit"`
+	TypeID        int           `json:"typeId" validate:"required"`
+	Name          string        `json:"name" validate:"required,max=30"`
+	Min           *float64      `json:"min,omitempty"`
+	Max           *float64      `json:"max,omitempty"`
+	Step          *float64      `json:"step,omitempty"`
+	MaxLength     *int          `json:"maxLength,omitempty"`
+	AllowedValues []interface{} `json:"allowedValues,omitempty"`
 }
@@ -0,0 +1,87 @@
+package entities
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// SchemaVersion represents a schema the thing had, numbered from 1 in the
+// order the schemas were updated, along with its difference from the
+// previous version
+type SchemaVersion struct {
+	Version   int        `json:"version"`
+	Schema    []Schema   `json:"schema"`
+	Diff      SchemaDiff `json:"diff"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// SchemaDiff represents the sensors added, removed and changed by a schema
+// update. It's breaking when any of the changes is breaking.
+type SchemaDiff struct {
+	Added    []Schema       `json:"added"`
+	Removed  []Schema       `json:"removed"`
+	Changed  []SensorChange `json:"changed"`
+	Breaking bool           `json:"breaking"`
+}
+
+// SensorChange represents a sensor declared on both schemas with different
+// properties. Changing the sensor's value type is breaking, since the values
+// previously published can't be interpreted as the new ones.
+type SensorChange struct {
+	SensorID int    `json:"sensorId"`
+	Previous Schema `json:"previous"`
+	Current  Schema `json:"current"`
+	Breaking bool   `json:"breaking"`
+}
+
+// DiffSchemas compares the sensors of the current schema with the previous
+// one's, matching them by their IDs. The differences are sorted by the
+// sensor ID.
+func DiffSchemas(previous, current []Schema) SchemaDiff {
+	diff := SchemaDiff{Added: []Schema{}, Removed: []Schema{}, Changed: []SensorChange{}}
+
+	sensors := map[int]Schema{}
+	for _, s := range previous {
+		sensors[s.SensorID] = s
+	}
+
+	for _, s := range current {
+		old, ok := sensors[s.SensorID]
+		if !ok {
+			diff.Added = append(diff.Added, s)
+			continue
+		}
+		delete(sensors, s.SensorID)
+
+		if reflect.DeepEqual(old, s) {
+			continue
+		}
+
+		change := SensorChange{
+			SensorID: s.SensorID,
+			Previous: old,
+			Current:  s,
+			Breaking: old.ValueType != s.ValueType,
+		}
+		diff.Changed = append(diff.Changed, change)
+		diff.Breaking = diff.Breaking || change.Breaking
+	}
+
+	for _, s := range previous {
+		if _, ok := sensors[s.SensorID]; ok {
+			diff.Removed = append(diff.Removed, s)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].SensorID < diff.Added[j].SensorID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].SensorID < diff.Removed[j].SensorID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].SensorID < diff.Changed[j].SensorID })
+
+	return diff
+}
+
+// Empty returns whether the schemas compared are equivalent
+func (d SchemaDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
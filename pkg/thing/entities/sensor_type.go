@@ -0,0 +1,110 @@
+package entities
+
+// ValueType represents a type of the sensors' values, such as int or bool
+type ValueType struct {
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// Unit represents a unit of measurement of a sensor type. Its ID is only
+// unique among the units of the same type. A value in the unit is converted
+// to the type's base unit by multiplying it by the factor and adding the
+// offset, and the units without a factor aren't convertible.
+type Unit struct {
+	ID     int     `json:"id" yaml:"id"`
+	Name   string  `json:"name" yaml:"name"`
+	Symbol string  `json:"symbol" yaml:"symbol"`
+	Factor float64 `json:"factor,omitempty" yaml:"factor"`
+	Offset float64 `json:"offset,omitempty" yaml:"offset"`
+}
+
+// SensorType represents what a sensor measures or controls, along with the
+// value types and units its schema may declare. The sensors of a type
+// without units declare the unit 0, meaning none. The base unit, the SI one
+// when it's registered, is the unit the values are normalized to.
+type SensorType struct {
+	ID         int    `json:"id" yaml:"id"`
+	Name       string `json:"name" yaml:"name"`
+	ValueTypes []int  `json:"valueTypes" yaml:"valueTypes"`
+	Units      []Unit `json:"units" yaml:"units"`
+	BaseUnit   int    `json:"baseUnit,omitempty" yaml:"baseUnit"`
+}
+
+// TypeRegistry represents the value types and the sensor types accepted on
+// the things' schemas
+type TypeRegistry struct {
+	ValueTypes []ValueType  `json:"valueTypes" yaml:"valueTypes"`
+	Types      []SensorType `json:"types" yaml:"types"`
+}
+
+// Type returns the sensor type with the ID, if it's registered
+func (r *TypeRegistry) Type(id int) (SensorType, bool) {
+	for _, t := range r.Types {
+		if t.ID == id {
+			return t, true
+		}
+	}
+
+	return SensorType{}, false
+}
+
+// AllowsValueType returns whether the sensors of the type may have values of
+// the value type
+func (t SensorType) AllowsValueType(valueType int) bool {
+	for _, v := range t.ValueTypes {
+		if v == valueType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsUnit returns whether the sensors of the type may be measured in the
+// unit
+func (t SensorType) AllowsUnit(unit int) bool {
+	if len(t.Units) == 0 {
+		return unit == 0
+	}
+
+	for _, u := range t.Units {
+		if u.ID == unit {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unit returns the type's unit with the ID, if it's registered
+func (t SensorType) Unit(id int) (Unit, bool) {
+	for _, u := range t.Units {
+		if u.ID == id {
+			return u, true
+		}
+	}
+
+	return Unit{}, false
+}
+
+// Convert converts the value measured in the unit from to the unit to,
+// through the type's base unit. It fails when any of the units isn't
+// registered or convertible.
+func (t SensorType) Convert(value float64, from, to int) (float64, bool) {
+	source, ok := t.Unit(from)
+	if !ok || source.Factor == 0 {
+		return 0, false
+	}
+
+	target, ok := t.Unit(to)
+	if !ok || target.Factor == 0 {
+		return 0, false
+	}
+
+	if from == to {
+		return value, true
+	}
+
+	base := value*source.Factor + source.Offset
+	return (base - target.Offset) / target.Factor, true
+}
@@ -1,9 +1,11 @@
 package entities
 
-// Thing represents the thing domain entity
+// Thing represents the thing domain entity. The presence is only informed
+// when listing the things.
 type Thing struct {
 	ID     string   `json:"id"`
 	Token  string   `json:"token,omitempty"`
 	Name   string   `json:"name,omitempty"`
 	Schema []Schema `json:"schema,omitempty"`
+	*Presence
 }
@@ -2,7 +2,8 @@ package interactors
 
 import "fmt"
 
-// Auth is responsible to implement the thing's authentication use case
+// Auth is responsible to implement the thing's authentication use case. The
+// commands queued while the thing was offline are sent when it reconnects.
 func (i *ThingInteractor) Auth(authorization, id string) error {
 	if authorization == "" {
 		return ErrAuthNotProvided
@@ -11,10 +12,14 @@ func (i *ThingInteractor) Auth(authorization, id string) error {
 		return ErrIDNotProvided
 	}
 
-	_, err := i.thingProxy.Get(authorization, id)
+	thing, err := i.thingProxy.Get(authorization, id)
 	if err != nil {
 		return fmt.Errorf("can't receive thing metadata: %w", err)
 	}
 
+	if i.presence.Seen(id, thing.Token) {
+		i.sendQueuedCommands(id, thing.Token)
+	}
+
 	return nil
 }
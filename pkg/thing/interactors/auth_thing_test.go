@@ -71,8 +71,10 @@ func TestAuthThing(t *testing.T) {
 				On("Get", tc.authParam, tc.idParam).
 				Return(tc.fakeThingProxy.Thing, tc.fakeThingProxy.ReturnErr).
 				Maybe()
+			fakePresence := &mocks.FakePresenceTracker{}
+			fakePresence.On("Seen", tc.idParam, "token").Return(false).Maybe()
 
-			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy)
+			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
 			err := thingInteractor.Auth(tc.authParam, tc.idParam)
 
 			if tc.authParam == "" {
@@ -84,6 +86,12 @@ func TestAuthThing(t *testing.T) {
 				assert.EqualError(t, err, msg)
 			}
 
+			if tc.expectedErr == nil {
+				fakePresence.AssertCalled(t, "Seen", tc.idParam, "token")
+			} else {
+				fakePresence.AssertNotCalled(t, "Seen", tc.idParam, "token")
+			}
+
 			tc.fakeThingProxy.AssertExpectations(t)
 		})
 	}
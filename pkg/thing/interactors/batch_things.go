@@ -0,0 +1,142 @@
+package interactors
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// RegisterBatch runs the use case to create several things at once and
+// publishes a single response with the result of each one, in the order
+// they were informed. The failures of the things are only reported on the
+// response, so a partially registered batch isn't handled again.
+func (i *ThingInteractor) RegisterBatch(authorization string, things []entities.Thing) error {
+	i.logger.Debug("executing register things batch use case")
+
+	err := validateBatch(authorization, len(things))
+	if err != nil {
+		return i.sendBatchResponse(i.publisher.PublishRegisteredBatch, nil, err)
+	}
+
+	results := make([]entities.BatchResult, len(things))
+	pending := []int{}
+	seen := map[string]bool{}
+	for idx, thing := range things {
+		results[idx] = entities.BatchResult{ID: thing.ID, Name: thing.Name}
+		switch {
+		case thing.ID == "":
+			results[idx].Err = ErrIDNotProvided
+		case thing.Name == "":
+			results[idx].Err = ErrNameNotProvided
+		case seen[thing.ID]:
+			results[idx].Err = ErrIDRepeated
+		default:
+			pending = append(pending, idx)
+		}
+		seen[thing.ID] = true
+	}
+
+	i.runBatch(pending, func(idx int) {
+		results[idx].Token, results[idx].Err = i.createThing(authorization, results[idx].ID, results[idx].Name)
+	})
+	i.logger.Infof("registerBatch: %d of %d things registered", succeeded(results), len(results))
+
+	return i.sendBatchResponse(i.publisher.PublishRegisteredBatch, results, nil)
+}
+
+// UnregisterBatch runs the use case to remove several things at once and
+// publishes a single response with the result of each one, in the order
+// they were informed. The failures of the things are only reported on the
+// response, so a partially unregistered batch isn't handled again.
+func (i *ThingInteractor) UnregisterBatch(authorization string, ids []string) error {
+	i.logger.Debug("executing unregister things batch use case")
+
+	err := validateBatch(authorization, len(ids))
+	if err != nil {
+		return i.sendBatchResponse(i.publisher.PublishUnregisteredBatch, nil, err)
+	}
+
+	results := make([]entities.BatchResult, len(ids))
+	pending := []int{}
+	seen := map[string]bool{}
+	for idx, id := range ids {
+		results[idx] = entities.BatchResult{ID: id}
+		switch {
+		case id == "":
+			results[idx].Err = ErrIDNotProvided
+		case seen[id]:
+			results[idx].Err = ErrIDRepeated
+		default:
+			pending = append(pending, idx)
+		}
+		seen[id] = true
+	}
+
+	i.runBatch(pending, func(idx int) {
+		results[idx].Err = i.removeThing(authorization, results[idx].ID)
+	})
+	i.logger.Infof("unregisterBatch: %d of %d things unregistered", succeeded(results), len(results))
+
+	return i.sendBatchResponse(i.publisher.PublishUnregisteredBatch, results, nil)
+}
+
+func validateBatch(authorization string, size int) error {
+	if authorization == "" {
+		return ErrAuthNotProvided
+	}
+	if size == 0 {
+		return ErrThingsNotProvided
+	}
+
+	return nil
+}
+
+// runBatch calls the operation for each index, running up to the batch
+// concurrency at a time, and waits all of them to finish
+func (i *ThingInteractor) runBatch(indexes []int, operation func(idx int)) {
+	concurrency := i.batchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, idx := range indexes {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(idx int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			operation(idx)
+		}(idx)
+	}
+
+	wg.Wait()
+}
+
+func (i *ThingInteractor) sendBatchResponse(publish func([]entities.BatchResult, error) error, results []entities.BatchResult, err error) error {
+	sendErr := i.ignoreUndeliverable(publish(results, err))
+	if sendErr != nil {
+		if err != nil {
+			return fmt.Errorf("error sending response to client: %v: %w", sendErr, err)
+		}
+		return fmt.Errorf("error sending response to client: %w", sendErr)
+	}
+	return err
+}
+
+// succeeded returns the number of things successfully registered or
+// unregistered
+func succeeded(results []entities.BatchResult) int {
+	count := 0
+	for _, r := range results {
+		if r.Err == nil {
+			count++
+		}
+	}
+
+	return count
+}
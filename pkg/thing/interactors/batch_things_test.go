@@ -0,0 +1,153 @@
+package interactors
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errCreateFailed = errors.New("failed to create the thing")
+
+func TestRegisterBatch(t *testing.T) {
+	testCases := []struct {
+		name            string
+		authorization   string
+		things          []entities.Thing
+		expectedErr     error
+		expectedResults []entities.BatchResult
+	}{
+		{
+			"authorization token not provided",
+			"",
+			[]entities.Thing{{ID: "a1", Name: "lamp"}},
+			ErrAuthNotProvided,
+			nil,
+		},
+		{
+			"things not provided",
+			"authorization-token",
+			[]entities.Thing{},
+			ErrThingsNotProvided,
+			nil,
+		},
+		{
+			"result of each thing sent in order",
+			"authorization-token",
+			[]entities.Thing{
+				{ID: "a1", Name: "lamp"},
+				{ID: "a2", Name: "fan"},
+				{ID: "", Name: "door"},
+				{ID: "a1", Name: "window"},
+				{ID: "zz", Name: "heater"},
+				{ID: "a3", Name: ""},
+				{ID: "a4", Name: "cooler"},
+			},
+			nil,
+			[]entities.BatchResult{
+				{ID: "a1", Name: "lamp", Token: "a1-token"},
+				{ID: "a2", Name: "fan", Err: entities.ErrThingExists},
+				{ID: "", Name: "door", Err: ErrIDNotProvided},
+				{ID: "a1", Name: "window", Err: ErrIDRepeated},
+				{ID: "zz", Name: "heater", Err: ErrIDNotHex},
+				{ID: "a3", Name: "", Err: ErrNameNotProvided},
+				{ID: "a4", Name: "cooler", Err: errCreateFailed},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", tc.authorization, "a2").Return(&entities.Thing{ID: "a2"}, nil).Maybe()
+			fakeThingProxy.On("Get", tc.authorization, mock.Anything).Return((*entities.Thing)(nil), entities.ErrThingNotFound).Maybe()
+			fakeThingProxy.On("Create", "a1", "lamp", tc.authorization).Return("a1-token", nil).Maybe()
+			fakeThingProxy.On("Create", "a4", "cooler", tc.authorization).Return("", errCreateFailed).Maybe()
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishRegisteredBatch", mock.Anything, tc.expectedErr).Return(nil)
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, BatchConcurrency: 4})
+			err := thingInteractor.RegisterBatch(tc.authorization, tc.things)
+
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			fakePublisher.AssertCalled(t, "PublishRegisteredBatch", tc.expectedResults, tc.expectedErr)
+			fakeThingProxy.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRegisterBatchConcurrency(t *testing.T) {
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	fakeThingProxy := &mocks.FakeThingProxy{}
+	fakeThingProxy.On("Get", "authorization-token", mock.Anything).
+		Return((*entities.Thing)(nil), entities.ErrThingNotFound).
+		Run(func(args mock.Arguments) {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		})
+	fakeThingProxy.On("Create", mock.Anything, "sensor", "authorization-token").Return("token", nil)
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.On("PublishRegisteredBatch", mock.Anything, nil).Return(nil)
+
+	things := []entities.Thing{}
+	for _, id := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "a"} {
+		things = append(things, entities.Thing{ID: id, Name: "sensor"})
+	}
+
+	thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, BatchConcurrency: 3})
+	err := thingInteractor.RegisterBatch("authorization-token", things)
+
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, maxRunning, 3)
+	fakeThingProxy.AssertNumberOfCalls(t, "Create", len(things))
+	results := fakePublisher.Calls[0].Arguments.Get(0).([]entities.BatchResult)
+	for idx, r := range results {
+		assert.Equal(t, things[idx].ID, r.ID)
+		assert.NoError(t, r.Err)
+	}
+}
+
+func TestUnregisterBatch(t *testing.T) {
+	errRemoveFailed := errors.New("failed to remove the thing")
+	fakeThingProxy := &mocks.FakeThingProxy{}
+	fakeThingProxy.On("Get", "authorization-token", "a1").Return(&entities.Thing{ID: "a1", Token: "a1-token"}, nil).Once()
+	fakeThingProxy.On("Get", "authorization-token", "a2").Return(&entities.Thing{ID: "a2", Token: "a2-token"}, nil).Once()
+	fakeThingProxy.On("Remove", "authorization-token", "a1").Return(nil).Once()
+	fakeThingProxy.On("Remove", "authorization-token", "a2").Return(errRemoveFailed).Once()
+	fakePresence := &mocks.FakePresenceTracker{}
+	fakePresence.On("Remove", "a1-token").Once()
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.On("PublishUnregisteredBatch", mock.Anything, mock.Anything).Return(nil)
+
+	thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, BatchConcurrency: 4})
+	err := thingInteractor.UnregisterBatch("authorization-token", []string{"a1", "a2", "a1", ""})
+
+	assert.NoError(t, err)
+	fakePublisher.AssertCalled(t, "PublishUnregisteredBatch", []entities.BatchResult{
+		{ID: "a1"},
+		{ID: "a2", Err: errRemoveFailed},
+		{ID: "a1", Err: ErrIDRepeated},
+		{ID: "", Err: ErrIDNotProvided},
+	}, nil)
+	fakeThingProxy.AssertExpectations(t)
+	fakePresence.AssertExpectations(t)
+
+	err = thingInteractor.UnregisterBatch("", []string{"a1"})
+	assert.True(t, errors.Is(err, ErrAuthNotProvided))
+}
@@ -1,6 +1,9 @@
 package interactors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrAuthNotProvided is returned when authorization token is not provided
@@ -21,6 +24,12 @@ var (
 	// ErrSensorsNotProvided is returned when thing's sensors are not provided
 	ErrSensorsNotProvided = errors.New("thing's sensors not provided")
 
+	// ErrThingsNotProvided is returned when the batch has no things
+	ErrThingsNotProvided = errors.New("things not provided")
+
+	// ErrIDRepeated is returned when the thing's id was already informed in the batch
+	ErrIDRepeated = errors.New("thing's id repeated in the batch")
+
 	// ErrIDLength is returned when the thing's id have more than 16 ascii characters
 	ErrIDLength = errors.New("id length exceeds 16 characters")
 
@@ -30,6 +39,9 @@ var (
 	// ErrSchemaInvalid is returned when schema has an invalid format
 	ErrSchemaInvalid = errors.New("invalid schema")
 
+	// ErrSchemaBreakingChange is returned when a schema update with breaking changes is rejected
+	ErrSchemaBreakingChange = errors.New("schema update has breaking changes")
+
 	// ErrSensorInvalid is returned when some sensorId mismatch with thing's schema
 	ErrSensorInvalid = errors.New("sensor list is incompatible with thing's schema")
 
@@ -38,8 +50,28 @@ var (
 
 	// ErrDataInvalid is returned when the provided data mismatch the thing's schema
 	ErrDataInvalid = errors.New("data is incompatible with thing's schema")
+
+	// ErrTimestampInvalid is returned when the data timestamp is too far in the future
+	ErrTimestampInvalid = errors.New("data timestamp is too far in the future")
+
 	// ErrCorrelationIDNotProvided is returned when the correlation id is not provided in RPC calls
 	ErrCorrelationIDNotProvided = errors.New("correlation ID not provided")
 	// ErrReplyToNotProvided is returned when the reply_to is not provided in RPC calls
 	ErrReplyToNotProvided = errors.New("reply_to property not provided")
 )
+
+// DataValidationError represents a data item which doesn't satisfy the
+// sensor's schema, along with the reason
+type DataValidationError struct {
+	SensorID int
+	Reason   string
+}
+
+func (e *DataValidationError) Error() string {
+	return fmt.Sprintf("%s: sensor %d: %s", ErrDataInvalid, e.SensorID, e.Reason)
+}
+
+// Unwrap allows the error to be compared with ErrDataInvalid
+func (e *DataValidationError) Unwrap() error {
+	return ErrDataInvalid
+}
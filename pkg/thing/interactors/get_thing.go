@@ -0,0 +1,24 @@
+package interactors
+
+import (
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// Get fetches the thing registered with the id
+func (i *ThingInteractor) Get(authorization, id string) (*entities.Thing, error) {
+	if authorization == "" {
+		return nil, ErrAuthNotProvided
+	}
+	if id == "" {
+		return nil, ErrIDNotProvided
+	}
+
+	thing, err := i.thingProxy.Get(authorization, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing: %w", err)
+	}
+
+	return thing, nil
+}
@@ -0,0 +1,84 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+type getThingTestCase struct {
+	name                       string
+	authorization              string
+	thingID                    string
+	expectedProxyResponseThing *entities.Thing
+	expectedProxyResponseError error
+	expectedErrorResult        error
+	fakeLogger                 *mocks.FakeLogger
+	fakeThingProxy             *mocks.FakeThingProxy
+}
+
+var gtCases = []getThingTestCase{
+	{
+		"authorization token not provided",
+		"",
+		"8a6f2fe9da74485f",
+		nil,
+		nil,
+		ErrAuthNotProvided,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+	},
+	{
+		"thing's id not provided",
+		"authorization-token",
+		"",
+		nil,
+		nil,
+		ErrIDNotProvided,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+	},
+	{
+		"thing not found on thing's service",
+		"authorization-token",
+		"8a6f2fe9da74485f",
+		nil,
+		entities.ErrThingNotFound,
+		entities.ErrThingNotFound,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+	},
+	{
+		"thing successfully received from the thing's service",
+		"authorization-token",
+		"8a6f2fe9da74485f",
+		things[0],
+		nil,
+		nil,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+	},
+}
+
+func TestGetThing(t *testing.T) {
+	for _, tc := range gtCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fakeThingProxy.
+				On("Get", tc.authorization, tc.thingID).
+				Return(tc.expectedProxyResponseThing, tc.expectedProxyResponseError).
+				Maybe()
+
+			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
+			thing, err := thingInteractor.Get(tc.authorization, tc.thingID)
+
+			assert.True(t, errors.Is(err, tc.expectedErrorResult))
+			if tc.expectedErrorResult == nil {
+				assert.Equal(t, tc.expectedProxyResponseThing, thing)
+			}
+			tc.fakeThingProxy.AssertExpectations(t)
+		})
+	}
+}
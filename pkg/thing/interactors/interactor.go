@@ -1,30 +1,106 @@
 package interactors
 
 import (
+	"errors"
+	"sync"
+	"time"
+
+	commandEntities "github.com/CESARBR/knot-babeltower/pkg/command/entities"
 	"github.com/CESARBR/knot-babeltower/pkg/logging"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/delivery/http"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/storage"
 )
 
 // Interactor is an interface that defines the thing's use cases operations
 type Interactor interface {
 	Register(authorization, id, name string) error
 	Unregister(authorization, id string) error
+	RegisterBatch(authorization string, things []entities.Thing) error
+	UnregisterBatch(authorization string, ids []string) error
 	UpdateSchema(authorization, id string, schemaList []entities.Schema) error
 	List(authorization string) ([]*entities.Thing, error)
-	RequestData(authorization, thingID string, sensorIds []int) error
-	UpdateData(authorization, thingID string, data []entities.Data) error
+	Get(authorization, id string) (*entities.Thing, error)
+	RequestData(authorization, thingID, commandID string, sensorIds []int) error
+	UpdateData(authorization, thingID, commandID string, data []entities.Data) error
 	PublishData(authorization, thingID string, data []entities.Data) error
 	Auth(authorization, id string) error
+	ListTypes(authorization string) (*entities.TypeRegistry, error)
+	ListSchemaVersions(authorization, id string) ([]entities.SchemaVersion, error)
+}
+
+// DataListener is notified about the valid data published by the things,
+// allowing other use cases to act on it
+type DataListener interface {
+	OnDataPublished(thing *entities.Thing, data []entities.Data)
+}
+
+// CommandTracker tracks the commands sent to the things until they're
+// completed, failed or expired, and holds the commands to the offline things
+// until they reconnect
+type CommandTracker interface {
+	Track(command *commandEntities.Command) error
+	Queue(command *commandEntities.Command) error
+	Dequeue(thingToken string) ([]commandEntities.Command, error)
+	Fail(commandID string, reason error)
+}
+
+// UnitPreferences provides the units the users prefer the data published by
+// their things to be normalized to, mapped by the sensor type ID
+type UnitPreferences interface {
+	PreferredUnits(authorization string) (map[int]int, error)
 }
 
 // ThingInteractor represents the thing interactor capabilities, it's composed
 // by the necessary dependencies
 type ThingInteractor struct {
-	logger     logging.Logger
-	publisher  amqp.Publisher
-	thingProxy http.ThingProxy
+	logger           logging.Logger
+	publisher        amqp.Publisher
+	thingProxy       http.ThingProxy
+	presence         PresenceTracker
+	commands         CommandTracker
+	types            *entities.TypeRegistry
+	preferences      UnitPreferences
+	schemas          storage.SchemaStore
+	breaking         BreakingChangePolicy
+	batchConcurrency int
+	maxClockSkew     time.Duration
+	dataListeners    []DataListener
+	now              func() time.Time
+
+	// sequences holds the last sequence number assigned to the data of each
+	// thing, identified by its ID on the things service
+	sequencesMutex sync.Mutex
+	sequences      map[string]uint64
+}
+
+// ThingOptions groups the ThingInteractor's collaborators and settings
+// besides its logger, publisher and proxy
+type ThingOptions struct {
+	// Presence is notified when the things authenticate or publish data
+	Presence PresenceTracker
+	// Commands tracks the commands sent to the things and queues the ones to
+	// the offline things
+	Commands CommandTracker
+	// Types is the registry the schemas are validated against
+	Types *entities.TypeRegistry
+	// Preferences provides the units the published data is normalized to.
+	// When nil, the data isn't normalized.
+	Preferences UnitPreferences
+	// Schemas keeps the schemas' versions, in memory when nil
+	Schemas storage.SchemaStore
+	// Breaking is how the schema updates with breaking changes are handled,
+	// flagging them by default
+	Breaking BreakingChangePolicy
+	// BatchConcurrency is how many things of a batch are registered or
+	// unregistered at a time, one when not set
+	BatchConcurrency int
+	// MaxClockSkew is how far in the future the data's timestamp can be,
+	// unless it's zero
+	MaxClockSkew time.Duration
+	// DataListeners are notified, in order, about the data published
+	DataListeners []DataListener
 }
 
 // NewThingInteractor creates a new ThingInteractor instance
@@ -32,6 +108,38 @@ func NewThingInteractor(
 	logger logging.Logger,
 	publisher amqp.Publisher,
 	thingProxy http.ThingProxy,
+	options ThingOptions,
 ) *ThingInteractor {
-	return &ThingInteractor{logger, publisher, thingProxy}
+	schemas := options.Schemas
+	if schemas == nil {
+		schemas = storage.NewMemorySchemaStore(0)
+	}
+
+	return &ThingInteractor{
+		logger:           logger,
+		publisher:        publisher,
+		thingProxy:       thingProxy,
+		presence:         options.Presence,
+		commands:         options.Commands,
+		types:            options.Types,
+		preferences:      options.Preferences,
+		schemas:          schemas,
+		breaking:         options.Breaking,
+		batchConcurrency: options.BatchConcurrency,
+		maxClockSkew:     options.MaxClockSkew,
+		dataListeners:    options.DataListeners,
+		now:              time.Now,
+		sequences:        map[string]uint64{},
+	}
+}
+
+// ignoreUndeliverable drops the error reported when a response couldn't be
+// delivered because no client was subscribed to receive it, since the use
+// case itself was already executed
+func (i *ThingInteractor) ignoreUndeliverable(err error) error {
+	if errors.Is(err, amqp.ErrUndeliverable) {
+		i.logger.Warn(err)
+		return nil
+	}
+	return err
 }
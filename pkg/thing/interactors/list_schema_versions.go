@@ -0,0 +1,33 @@
+package interactors
+
+import (
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// ListSchemaVersions returns the versions of the thing's schema from the
+// oldest, which are empty when its schema wasn't updated since the versions
+// started being kept
+func (i *ThingInteractor) ListSchemaVersions(authorization, id string) ([]entities.SchemaVersion, error) {
+	if authorization == "" {
+		return nil, ErrAuthNotProvided
+	}
+	if id == "" {
+		return nil, ErrIDNotProvided
+	}
+
+	// the thing is fetched to verify it's owned by the token's user and to
+	// get its Mainflux ID, by which the versions are kept
+	thing, err := i.thingProxy.Get(authorization, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting thing: %w", err)
+	}
+
+	versions, err := i.schemas.List(thing.Token)
+	if err != nil {
+		return nil, fmt.Errorf("error listing schema versions: %w", err)
+	}
+
+	return versions, nil
+}
@@ -6,7 +6,8 @@ import (
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 )
 
-// List fetchs the registered things and return them as an array
+// List fetchs the registered things and return them as an array, informing
+// whether they're online
 func (i *ThingInteractor) List(authorization string) ([]*entities.Thing, error) {
 	if authorization == "" {
 		return nil, ErrAuthNotProvided
@@ -17,5 +18,10 @@ func (i *ThingInteractor) List(authorization string) ([]*entities.Thing, error)
 		return nil, fmt.Errorf("error getting list of things: %w", err)
 	}
 
+	for _, thing := range things {
+		presence := i.presence.Presence(thing.Token)
+		thing.Presence = &presence
+	}
+
 	return things, nil
 }
@@ -33,6 +33,10 @@ var things = []*entities.Thing{
 	},
 }
 
+var listedPresence = entities.Presence{Online: true, LastSeen: &dataReceivedAt}
+
+var errThingServiceUnavailable = errors.New("thing's service unavailable")
+
 var ltCases = []listThingsTestCase{
 	{
 		"authorization token not provided",
@@ -47,9 +51,9 @@ var ltCases = []listThingsTestCase{
 	{
 		"failed to list things from thing's service",
 		"authorization-token",
-		errors.New("thing's service unavailable"),
+		errThingServiceUnavailable,
 		nil,
-		errors.New("thing's service unavailable"),
+		errThingServiceUnavailable,
 		nil,
 		&mocks.FakeLogger{},
 		&mocks.FakeThingProxy{},
@@ -83,21 +87,26 @@ func TestListThings(t *testing.T) {
 				On("List", tc.authorization).
 				Return(tc.expectedProxyResponseThings, tc.expectedProxyResponseError).
 				Maybe()
+			fakePresence := &mocks.FakePresenceTracker{}
+			fakePresence.On("Presence", "token").Return(listedPresence).Maybe()
 
-			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy)
+			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
 			things, err := thingInteractor.List(tc.authorization)
 			if tc.authorization == "" {
 				assert.EqualError(t, err, ErrAuthNotProvided.Error())
 				return
 			}
 
-			if err != nil && !errors.As(err, &tc.expectedErrorResult) {
+			if err != nil && !errors.Is(err, tc.expectedErrorResult) {
 				t.Errorf("failed to list the devices. Error: %s", err)
 				return
 			}
 
 			if tc.expectedProxyResponseError == nil {
 				assert.Equal(t, things, tc.expectedThingsResult)
+				for _, thing := range things {
+					assert.Equal(t, &listedPresence, thing.Presence)
+				}
 			}
 
 			tc.fakeThingProxy.AssertExpectations(t)
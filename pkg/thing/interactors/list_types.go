@@ -0,0 +1,15 @@
+package interactors
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// ListTypes returns the sensor types and value types accepted on the things'
+// schemas, allowing the clients to build their schemas from them
+func (i *ThingInteractor) ListTypes(authorization string) (*entities.TypeRegistry, error) {
+	if authorization == "" {
+		return nil, ErrAuthNotProvided
+	}
+
+	return i.types, nil
+}
@@ -0,0 +1,31 @@
+package interactors
+
+import (
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTypes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authorization string
+		expected      *entities.TypeRegistry
+		expectedError error
+	}{
+		{"sensor types listed", "authorization-token", knotTypes, nil},
+		{"authorization token not provided", "", nil, ErrAuthNotProvided},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, &mocks.FakePublisher{}, &mocks.FakeThingProxy{}, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
+			registry, err := thingInteractor.ListTypes(tc.authorization)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expected, registry)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package interactors
+
+import (
+	"fmt"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// normalizeData returns a copy of the data with the values measured in a
+// convertible unit also converted to the unit preferred by the user, or to
+// their type's base unit when the user has no preference. The data isn't
+// normalized when there are no preferences.
+func (i *ThingInteractor) normalizeData(authorization string, thing *entities.Thing, data []entities.Data) []entities.Data {
+	if i.preferences == nil {
+		return data
+	}
+
+	preferred, err := i.preferences.PreferredUnits(authorization)
+	if err != nil {
+		i.logger.Warn(fmt.Sprintf("error getting the user's preferred units, normalizing to the base units: %s", err))
+	}
+
+	normalized := make([]entities.Data, len(data))
+	for idx, d := range data {
+		d.Normalized = i.normalizeValue(d, thing.Schema, preferred)
+		normalized[idx] = d
+	}
+
+	return normalized
+}
+
+func (i *ThingInteractor) normalizeValue(data entities.Data, schemaList []entities.Schema, preferred map[int]int) *entities.NormalizedValue {
+	value, ok := data.Value.(float64)
+	if !ok {
+		return nil
+	}
+
+	for _, schema := range schemaList {
+		if schema.SensorID != data.SensorID {
+			continue
+		}
+
+		sensorType, ok := i.types.Type(schema.TypeID)
+		if !ok {
+			return nil
+		}
+
+		unit, ok := preferred[schema.TypeID]
+		if !ok {
+			unit = sensorType.BaseUnit
+		}
+
+		converted, ok := sensorType.Convert(value, schema.Unit, unit)
+		if !ok {
+			return nil
+		}
+
+		target, _ := sensorType.Unit(unit)
+		return &entities.NormalizedValue{Value: converted, Unit: unit, Symbol: target.Symbol}
+	}
+
+	return nil
+}
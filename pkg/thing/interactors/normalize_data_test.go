@@ -0,0 +1,90 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// meteringThing has a temperature in Celsius, an energy meter in kilowatt
+// hours, a luminosity in lumens, which isn't convertible, and a switch
+var meteringThing = &entities.Thing{ID: "thing-id", Token: "thing-token", Name: "meter", Schema: []entities.Schema{
+	{SensorID: 1, ValueType: 1, Unit: 1, TypeID: 0x05, Name: "temperature"},
+	{SensorID: 2, ValueType: 1, Unit: 4, TypeID: 0x15, Name: "energy"},
+	{SensorID: 3, ValueType: 1, Unit: 1, TypeID: 0x07, Name: "luminosity"},
+	{SensorID: 4, ValueType: 3, Unit: 0, TypeID: 0xFFF1, Name: "switch"},
+}}
+
+func TestNormalizePublishedData(t *testing.T) {
+	data := []entities.Data{
+		{SensorID: 1, Value: float64(20)},
+		{SensorID: 2, Value: float64(2)},
+		{SensorID: 3, Value: float64(800)},
+		{SensorID: 4, Value: true},
+	}
+	testCases := []struct {
+		name           string
+		preferred      map[int]int
+		preferredErr   error
+		expectedValues []*entities.NormalizedValue
+	}{
+		{
+			"values normalized to the base units",
+			map[int]int{},
+			nil,
+			[]*entities.NormalizedValue{{Value: 293.15, Unit: 3, Symbol: "K"}, {Value: 7200000, Unit: 1, Symbol: "J"}, nil, nil},
+		},
+		{
+			"values normalized to the preferred units",
+			map[int]int{0x05: 2, 0x15: 3},
+			nil,
+			[]*entities.NormalizedValue{{Value: 68, Unit: 2, Symbol: "°F"}, {Value: 2000, Unit: 3, Symbol: "Wh"}, nil, nil},
+		},
+		{
+			"values normalized to the base units when the preferences are unavailable",
+			map[int]int(nil),
+			errors.New("users service unavailable"),
+			[]*entities.NormalizedValue{{Value: 293.15, Unit: 3, Symbol: "K"}, {Value: 7200000, Unit: 1, Symbol: "J"}, nil, nil},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(meteringThing, nil)
+			fakePreferences := &mocks.FakeUnitPreferences{}
+			fakePreferences.On("PreferredUnits", "authorization-token").Return(tc.preferred, tc.preferredErr)
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishPublishedData", "thing-id", mock.Anything).Return(nil)
+			listener := &mocks.FakeDataListener{}
+			listener.On("OnDataPublished", meteringThing, stampedData(data, 1))
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: seenPresence(), Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, Preferences: fakePreferences, DataListeners: []DataListener{listener}})
+			thingInteractor.now = func() time.Time { return dataReceivedAt }
+			err := thingInteractor.PublishData("authorization-token", "thing-id", data)
+			assert.NoError(t, err)
+
+			// the listeners receive the values as sent by the thing
+			listener.AssertExpectations(t)
+
+			published := fakePublisher.Calls[0].Arguments.Get(1).([]entities.Data)
+			for idx, expected := range tc.expectedValues {
+				assert.Equal(t, data[idx].Value, published[idx].Value)
+				if expected == nil {
+					assert.Nil(t, published[idx].Normalized)
+					continue
+				}
+				if assert.NotNil(t, published[idx].Normalized) {
+					assert.InDelta(t, expected.Value, published[idx].Normalized.Value, 1e-9)
+					assert.Equal(t, expected.Unit, published[idx].Normalized.Unit)
+					assert.Equal(t, expected.Symbol, published[idx].Normalized.Symbol)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package interactors
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/logging"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// PresenceTracker is notified about the things' activity to inform whether
+// they're online. The things are identified by their Mainflux IDs, since the
+// things of different users may have the same ID.
+type PresenceTracker interface {
+	Seen(thingID, mainfluxID string) bool
+	Remove(mainfluxID string)
+	Presence(mainfluxID string) entities.Presence
+}
+
+// TimeoutPresenceTracker considers a thing online from the moment it's seen
+// until it stays silent for longer than its timeout, sending the device
+// online and offline events with the thing's ID. The things are unknown
+// until seen after the service starts.
+type TimeoutPresenceTracker struct {
+	logger    logging.Logger
+	publisher amqp.Publisher
+	timeout   time.Duration
+	timeouts  map[string]time.Duration
+	now       func() time.Time
+
+	mutex   sync.Mutex
+	things  map[string]*thingPresence
+	stopped bool
+}
+
+type thingPresence struct {
+	id       string
+	online   bool
+	lastSeen time.Time
+	timer    *time.Timer
+	// generation identifies the timer started by the last time the thing was
+	// seen, so a timer which fired while being replaced is ignored
+	generation uint64
+
+	// the events are queued while holding the mutex and sent after releasing
+	// it by a single sender at a time, so a thing's online and offline
+	// events are sent in order without blocking the other things
+	changes []presenceChange
+	sending bool
+}
+
+type presenceChange struct {
+	online   bool
+	lastSeen time.Time
+}
+
+// NewTimeoutPresenceTracker creates a new TimeoutPresenceTracker instance.
+// The things are marked offline after the timeout, unless a specific timeout
+// is informed for their ID. The IDs are compared ignoring the case, as the
+// configuration keys are case insensitive.
+func NewTimeoutPresenceTracker(
+	logger logging.Logger,
+	publisher amqp.Publisher,
+	timeout time.Duration,
+	timeouts map[string]time.Duration,
+) *TimeoutPresenceTracker {
+	normalized := map[string]time.Duration{}
+	for id, t := range timeouts {
+		normalized[strings.ToLower(id)] = t
+	}
+
+	return &TimeoutPresenceTracker{
+		logger:    logger,
+		publisher: publisher,
+		timeout:   timeout,
+		timeouts:  normalized,
+		now:       time.Now,
+		things:    map[string]*thingPresence{},
+	}
+}
+
+// Seen records the activity of the thing with the Mainflux ID, sending the
+// device online event when it was offline or unknown, and restarts its
+// timeout. It returns whether the thing came online.
+func (t *TimeoutPresenceTracker) Seen(thingID, mainfluxID string) bool {
+	t.mutex.Lock()
+
+	if t.stopped {
+		t.mutex.Unlock()
+		return false
+	}
+
+	p, ok := t.things[mainfluxID]
+	if !ok {
+		p = &thingPresence{id: thingID}
+		t.things[mainfluxID] = p
+	}
+
+	p.lastSeen = t.now()
+	cameOnline := !p.online
+	send := false
+	if cameOnline {
+		p.online = true
+		t.logger.Infof("thing %s is online", thingID)
+		send = t.queueEvent(p)
+	}
+
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.generation++
+	generation := p.generation
+	p.timer = time.AfterFunc(t.timeoutOf(thingID), func() {
+		t.expire(mainfluxID, generation)
+	})
+	t.mutex.Unlock()
+
+	if send {
+		t.sendEvents(p)
+	}
+
+	return cameOnline
+}
+
+// Remove forgets the thing, which won't be reported offline, e.g. because
+// it was unregistered
+func (t *TimeoutPresenceTracker) Remove(mainfluxID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if p, ok := t.things[mainfluxID]; ok {
+		p.timer.Stop()
+		delete(t.things, mainfluxID)
+	}
+}
+
+// Presence returns whether the thing is online and the last time it was seen
+func (t *TimeoutPresenceTracker) Presence(mainfluxID string) entities.Presence {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	p, ok := t.things[mainfluxID]
+	if !ok {
+		return entities.Presence{}
+	}
+
+	lastSeen := p.lastSeen
+	return entities.Presence{Online: p.online, LastSeen: &lastSeen}
+}
+
+// Stop stops the timeouts, so no event is sent anymore
+func (t *TimeoutPresenceTracker) Stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stopped = true
+	for _, p := range t.things {
+		p.timer.Stop()
+	}
+}
+
+func (t *TimeoutPresenceTracker) expire(mainfluxID string, generation uint64) {
+	t.mutex.Lock()
+
+	p, ok := t.things[mainfluxID]
+	if t.stopped || !ok || p.generation != generation || !p.online {
+		t.mutex.Unlock()
+		return
+	}
+
+	p.online = false
+	t.logger.Infof("thing %s is offline", p.id)
+	send := t.queueEvent(p)
+	t.mutex.Unlock()
+
+	if send {
+		t.sendEvents(p)
+	}
+}
+
+// queueEvent queues the thing's current presence to be sent, returning
+// whether the caller must send the queued events. It must be called while
+// holding the mutex.
+func (t *TimeoutPresenceTracker) queueEvent(p *thingPresence) bool {
+	p.changes = append(p.changes, presenceChange{online: p.online, lastSeen: p.lastSeen})
+	if p.sending {
+		return false
+	}
+
+	p.sending = true
+	return true
+}
+
+// sendEvents sends the thing's queued events until none is left, without
+// holding the mutex while publishing them
+func (t *TimeoutPresenceTracker) sendEvents(p *thingPresence) {
+	for {
+		t.mutex.Lock()
+		if len(p.changes) == 0 {
+			p.sending = false
+			t.mutex.Unlock()
+			return
+		}
+		change := p.changes[0]
+		p.changes = p.changes[1:]
+		t.mutex.Unlock()
+
+		if change.online {
+			t.sendEvent(t.publisher.PublishDeviceOnline(p.id, change.lastSeen))
+		} else {
+			t.sendEvent(t.publisher.PublishDeviceOffline(p.id, change.lastSeen))
+		}
+	}
+}
+
+func (t *TimeoutPresenceTracker) timeoutOf(thingID string) time.Duration {
+	if timeout, ok := t.timeouts[strings.ToLower(thingID)]; ok {
+		return timeout
+	}
+
+	return t.timeout
+}
+
+func (t *TimeoutPresenceTracker) sendEvent(err error) {
+	if errors.Is(err, amqp.ErrUndeliverable) {
+		t.logger.Warn(err)
+	} else if err != nil {
+		t.logger.Errorf("error sending presence event: %s", err)
+	}
+}
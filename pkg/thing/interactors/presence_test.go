@@ -0,0 +1,150 @@
+package interactors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var presenceSeenAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+type presenceEvent struct {
+	key     string
+	thingID string
+}
+
+// newPresenceTracker creates a tracker whose events are sent to the returned
+// channel
+func newPresenceTracker(timeout time.Duration, timeouts map[string]time.Duration) (*TimeoutPresenceTracker, chan presenceEvent) {
+	events := make(chan presenceEvent, 10)
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.On("PublishDeviceOnline", mock.Anything, presenceSeenAt).Return(nil).Run(func(args mock.Arguments) {
+		events <- presenceEvent{"device.online", args.String(0)}
+	})
+	fakePublisher.On("PublishDeviceOffline", mock.Anything, presenceSeenAt).Return(nil).Run(func(args mock.Arguments) {
+		events <- presenceEvent{"device.offline", args.String(0)}
+	})
+
+	tracker := NewTimeoutPresenceTracker(&mocks.FakeLogger{}, fakePublisher, timeout, timeouts)
+	tracker.now = func() time.Time { return presenceSeenAt }
+	return tracker, events
+}
+
+func receiveEvent(t *testing.T, events chan presenceEvent, expected presenceEvent) {
+	select {
+	case event := <-events:
+		assert.Equal(t, expected, event)
+	case <-time.After(time.Second):
+		t.Errorf("%s event not sent", expected.key)
+	}
+}
+
+func assertNoEvent(t *testing.T, events chan presenceEvent, wait time.Duration) {
+	select {
+	case event := <-events:
+		t.Errorf("unexpected %s event sent", event.key)
+	case <-time.After(wait):
+	}
+}
+
+func TestPresenceOnlineAndOffline(t *testing.T) {
+	tracker, events := newPresenceTracker(20*time.Millisecond, nil)
+	defer tracker.Stop()
+
+	assert.Equal(t, entities.Presence{}, tracker.Presence("fc3f-token"))
+
+	assert.True(t, tracker.Seen("fc3fcf912d0c290a", "fc3f-token"))
+	assert.False(t, tracker.Seen("fc3fcf912d0c290a", "fc3f-token"))
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+	assert.Equal(t, entities.Presence{Online: true, LastSeen: &presenceSeenAt}, tracker.Presence("fc3f-token"))
+
+	receiveEvent(t, events, presenceEvent{"device.offline", "fc3fcf912d0c290a"})
+	assert.Equal(t, entities.Presence{Online: false, LastSeen: &presenceSeenAt}, tracker.Presence("fc3f-token"))
+
+	assert.True(t, tracker.Seen("fc3fcf912d0c290a", "fc3f-token"))
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+}
+
+func TestPresenceThingTimeout(t *testing.T) {
+	tracker, events := newPresenceTracker(time.Hour, map[string]time.Duration{"FC3FCF912D0C290A": 20 * time.Millisecond})
+	defer tracker.Stop()
+
+	tracker.Seen("8380ba096a091fb9", "8380-token")
+	receiveEvent(t, events, presenceEvent{"device.online", "8380ba096a091fb9"})
+	tracker.Seen("fc3fcf912d0c290a", "fc3f-token")
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+
+	receiveEvent(t, events, presenceEvent{"device.offline", "fc3fcf912d0c290a"})
+	assertNoEvent(t, events, 50*time.Millisecond)
+	assert.True(t, tracker.Presence("8380-token").Online)
+}
+
+func TestPresenceRemovedThing(t *testing.T) {
+	tracker, events := newPresenceTracker(20*time.Millisecond, nil)
+	defer tracker.Stop()
+
+	tracker.Seen("fc3fcf912d0c290a", "fc3f-token")
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+	tracker.Remove("fc3f-token")
+
+	assertNoEvent(t, events, 50*time.Millisecond)
+	assert.Equal(t, entities.Presence{}, tracker.Presence("fc3f-token"))
+}
+
+func TestPresenceStopped(t *testing.T) {
+	tracker, events := newPresenceTracker(20*time.Millisecond, nil)
+
+	tracker.Seen("fc3fcf912d0c290a", "fc3f-token")
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+	tracker.Stop()
+	tracker.Seen("8380ba096a091fb9", "8380-token")
+
+	assertNoEvent(t, events, 50*time.Millisecond)
+}
+
+func TestPresenceThingsOfDifferentUsers(t *testing.T) {
+	tracker, events := newPresenceTracker(20*time.Millisecond, nil)
+	defer tracker.Stop()
+
+	// the things have the same ID, but are tracked by their Mainflux IDs
+	assert.True(t, tracker.Seen("fc3fcf912d0c290a", "first-token"))
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+	assert.True(t, tracker.Seen("fc3fcf912d0c290a", "second-token"))
+	receiveEvent(t, events, presenceEvent{"device.online", "fc3fcf912d0c290a"})
+
+	tracker.Remove("first-token")
+	assert.Equal(t, entities.Presence{}, tracker.Presence("first-token"))
+	assert.True(t, tracker.Presence("second-token").Online)
+}
+
+func TestPresenceEventNotBlockingSeen(t *testing.T) {
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.On("PublishDeviceOnline", "fc3fcf912d0c290a", presenceSeenAt).Return(nil).Run(func(args mock.Arguments) {
+		close(sending)
+		<-release
+	})
+	fakePublisher.On("PublishDeviceOnline", "8380ba096a091fb9", presenceSeenAt).Return(nil)
+	tracker := NewTimeoutPresenceTracker(&mocks.FakeLogger{}, fakePublisher, time.Hour, nil)
+	tracker.now = func() time.Time { return presenceSeenAt }
+	defer tracker.Stop()
+
+	go tracker.Seen("fc3fcf912d0c290a", "fc3f-token")
+	<-sending
+
+	// the other things are seen while the event is waiting to be confirmed
+	seen := make(chan bool)
+	go func() { seen <- tracker.Seen("8380ba096a091fb9", "8380-token") }()
+	select {
+	case cameOnline := <-seen:
+		assert.True(t, cameOnline)
+	case <-time.After(time.Second):
+		t.Error("thing seen blocked by the event being sent")
+	}
+	close(release)
+}
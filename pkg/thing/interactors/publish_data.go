@@ -2,11 +2,14 @@ package interactors
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 )
 
-// PublishData executes the use case operations to publish data from the things to cloud
+// PublishData executes the use case operations to publish data from the things to cloud.
+// The commands queued while the thing was offline are sent when it reconnects.
+// The published values are sent along with their normalized values.
 func (i *ThingInteractor) PublishData(authorization, thingID string, data []entities.Data) error {
 	if authorization == "" {
 		return ErrAuthNotProvided
@@ -18,12 +21,31 @@ func (i *ThingInteractor) PublishData(authorization, thingID string, data []enti
 		return ErrDataNotProvided
 	}
 
-	err := i.verifyThingData(authorization, thingID, data)
+	receivedAt := i.now()
+	err := i.verifyTimestamps(data, receivedAt)
 	if err != nil {
 		return fmt.Errorf("error validating thing's data: %w", err)
 	}
 
-	err = i.publisher.PublishPublishedData(thingID, authorization, data)
+	thing, err := i.verifyThingData(authorization, thingID, data)
+	if err != nil {
+		return fmt.Errorf("error validating thing's data: %w", err)
+	}
+
+	if i.presence.Seen(thingID, thing.Token) {
+		i.sendQueuedCommands(thingID, thing.Token)
+	}
+
+	data = i.stampData(thing.Token, data, receivedAt)
+
+	// the listeners are notified before the data is forwarded, so the clients
+	// receiving it can rely on the listeners' state being up to date
+	for _, listener := range i.dataListeners {
+		listener.OnDataPublished(thing, data)
+	}
+
+	data = i.normalizeData(authorization, thing, data)
+	err = i.ignoreUndeliverable(i.publisher.PublishPublishedData(thingID, authorization, data))
 	if err != nil {
 		return fmt.Errorf("error sending message to client: %w", err)
 	}
@@ -31,3 +53,38 @@ func (i *ThingInteractor) PublishData(authorization, thingID string, data []enti
 	i.logger.Info("publish data message successfully sent")
 	return nil
 }
+
+// verifyTimestamps rejects the data read later than the time it was received,
+// allowing the things' clocks to be ahead by the maximum clock skew
+func (i *ThingInteractor) verifyTimestamps(data []entities.Data, receivedAt time.Time) error {
+	if i.maxClockSkew <= 0 {
+		return nil
+	}
+
+	limit := receivedAt.Add(i.maxClockSkew)
+	for _, d := range data {
+		if d.Timestamp != nil && d.Timestamp.After(limit) {
+			return ErrTimestampInvalid
+		}
+	}
+
+	return nil
+}
+
+// stampData returns a copy of the data with the time it was received and
+// the thing's next sequence numbers. The sequence numbers are kept in memory,
+// so they restart when the service is restarted.
+func (i *ThingInteractor) stampData(mainfluxID string, data []entities.Data, receivedAt time.Time) []entities.Data {
+	i.sequencesMutex.Lock()
+	defer i.sequencesMutex.Unlock()
+
+	stamped := make([]entities.Data, len(data))
+	for idx, d := range data {
+		i.sequences[mainfluxID]++
+		d.ReceivedAt = &receivedAt
+		d.Sequence = i.sequences[mainfluxID]
+		stamped[idx] = d
+	}
+
+	return stamped
+}
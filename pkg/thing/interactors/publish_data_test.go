@@ -2,13 +2,38 @@ package interactors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/delivery/amqp"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+var dataReceivedAt = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+// seenPresence returns a presence tracker accepting the things being seen
+func seenPresence() *mocks.FakePresenceTracker {
+	fakePresence := &mocks.FakePresenceTracker{}
+	fakePresence.On("Seen", mock.Anything, mock.Anything).Return(false).Maybe()
+	return fakePresence
+}
+
+// stampedData returns the data as published, received at dataReceivedAt and
+// numbered from the first sequence number
+func stampedData(data []entities.Data, first uint64) []entities.Data {
+	stamped := []entities.Data{}
+	for i, d := range data {
+		d.ReceivedAt = &dataReceivedAt
+		d.Sequence = first + uint64(i)
+		stamped = append(stamped, d)
+	}
+	return stamped
+}
+
 type PublishDataTestCase struct {
 	name           string
 	authParam      string
@@ -97,6 +122,20 @@ var publishDataUseCases = []PublishDataTestCase{
 		}},
 		ErrDataInvalid,
 	},
+	{
+		"data out of the sensor's range",
+		"authorization-token",
+		"thing-id",
+		[]entities.Data{{SensorID: 1, Value: 9000.5}},
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{Thing: &entities.Thing{
+			ID:     "thing-id",
+			Token:  "thing-token",
+			Name:   "thing",
+			Schema: constrainedSchema,
+		}},
+		ErrDataInvalid,
+	},
 }
 
 func TestPublishData(t *testing.T) {
@@ -106,13 +145,157 @@ func TestPublishData(t *testing.T) {
 				On("Get", tc.authParam, tc.idParam).
 				Return(tc.fakeThingProxy.Thing, tc.fakeThingProxy.ReturnErr).
 				Maybe()
+			fakePresence := seenPresence()
 
-			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy)
+			thingInteractor := NewThingInteractor(tc.fakeLogger, nil, tc.fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
 			err := thingInteractor.PublishData(tc.authParam, tc.idParam, tc.dataParam)
 
 			assert.EqualValues(t, errors.Is(err, tc.expectedError), true)
+			if tc.expectedError != nil {
+				fakePresence.AssertNotCalled(t, "Seen", tc.idParam, mock.Anything)
+			}
 
 			tc.fakeThingProxy.AssertExpectations(t)
 		})
 	}
 }
+
+func TestPublishDataWithoutSubscribers(t *testing.T) {
+	data := []entities.Data{{SensorID: 0, Value: float64(5)}}
+	fakeThingProxy := &mocks.FakeThingProxy{}
+	fakeThingProxy.
+		On("Get", "authorization-token", "thing-id").
+		Return(&entities.Thing{ID: "thing-id", Token: "thing-token", Name: "thing", Schema: voltageSchema}, nil)
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.
+		On("PublishPublishedData", "thing-id", stampedData(data, 1)).
+		Return(fmt.Errorf("%w: message returned", amqp.ErrUndeliverable))
+
+	thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: seenPresence(), Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
+	thingInteractor.now = func() time.Time { return dataReceivedAt }
+	err := thingInteractor.PublishData("authorization-token", "thing-id", data)
+
+	assert.NoError(t, err)
+	fakePublisher.AssertExpectations(t)
+}
+
+func TestPublishDataTimestamps(t *testing.T) {
+	readAt := dataReceivedAt.Add(-time.Hour)
+	aheadAt := dataReceivedAt.Add(time.Minute)
+	futureAt := dataReceivedAt.Add(time.Hour)
+	testCases := []struct {
+		name          string
+		data          []entities.Data
+		maxClockSkew  time.Duration
+		expectedError error
+	}{
+		{
+			"data without timestamp",
+			[]entities.Data{{SensorID: 0, Value: float64(5)}},
+			5 * time.Minute,
+			nil,
+		},
+		{
+			"data read in the past",
+			[]entities.Data{{SensorID: 0, Value: float64(5), Timestamp: &readAt}},
+			5 * time.Minute,
+			nil,
+		},
+		{
+			"thing's clock ahead within the maximum skew",
+			[]entities.Data{{SensorID: 0, Value: float64(5), Timestamp: &aheadAt}},
+			5 * time.Minute,
+			nil,
+		},
+		{
+			"data read too far in the future",
+			[]entities.Data{{SensorID: 0, Value: float64(5)}, {SensorID: 0, Value: float64(6), Timestamp: &futureAt}},
+			5 * time.Minute,
+			ErrTimestampInvalid,
+		},
+		{
+			"verification disabled",
+			[]entities.Data{{SensorID: 0, Value: float64(5), Timestamp: &futureAt}},
+			0,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.
+				On("Get", "authorization-token", "thing-id").
+				Return(&entities.Thing{ID: "thing-id", Token: "thing-token", Name: "thing", Schema: voltageSchema}, nil).
+				Maybe()
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishPublishedData", "thing-id", stampedData(tc.data, 1)).Return(nil).Maybe()
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: seenPresence(), Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, MaxClockSkew: tc.maxClockSkew})
+			thingInteractor.now = func() time.Time { return dataReceivedAt }
+			err := thingInteractor.PublishData("authorization-token", "thing-id", tc.data)
+
+			assert.True(t, errors.Is(err, tc.expectedError))
+			if tc.expectedError != nil {
+				fakePublisher.AssertNotCalled(t, "PublishPublishedData", "thing-id", stampedData(tc.data, 1))
+			}
+		})
+	}
+}
+
+func TestPublishDataSequence(t *testing.T) {
+	thing := &entities.Thing{ID: "thing-id", Token: "thing-token", Name: "thing", Schema: voltageSchema}
+	otherThing := &entities.Thing{ID: "thing-id", Token: "other-thing-token", Name: "thing", Schema: voltageSchema}
+	first := []entities.Data{{SensorID: 0, Value: float64(5)}, {SensorID: 0, Value: float64(6)}}
+	second := []entities.Data{{SensorID: 0, Value: float64(7)}}
+	fakeThingProxy := &mocks.FakeThingProxy{}
+	fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(thing, nil)
+	fakeThingProxy.On("Get", "other-user-token", "thing-id").Return(otherThing, nil)
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.On("PublishPublishedData", "thing-id", stampedData(first, 1)).Return(nil).Twice()
+	fakePublisher.On("PublishPublishedData", "thing-id", stampedData(second, 3)).Return(nil).Once()
+
+	thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: seenPresence(), Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
+	thingInteractor.now = func() time.Time { return dataReceivedAt }
+
+	// each thing has its own sequence, even when other user's thing has the same id
+	assert.NoError(t, thingInteractor.PublishData("authorization-token", "thing-id", first))
+	assert.NoError(t, thingInteractor.PublishData("other-user-token", "thing-id", first))
+	assert.NoError(t, thingInteractor.PublishData("authorization-token", "thing-id", second))
+
+	fakePublisher.AssertExpectations(t)
+	assert.Nil(t, first[0].ReceivedAt)
+}
+
+func TestPublishDataNotifiesListeners(t *testing.T) {
+	thing := &entities.Thing{ID: "thing-id", Token: "thing-token", Name: "thing", Schema: voltageSchema}
+	data := []entities.Data{{SensorID: 0, Value: float64(5)}}
+	testCases := []struct {
+		name              string
+		data              []entities.Data
+		expectedListeners int
+	}{
+		{"valid data is notified to every listener", data, 1},
+		{"invalid data isn't notified", []entities.Data{{SensorID: 0, Value: false}}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(thing, nil)
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishPublishedData", "thing-id", stampedData(tc.data, 1)).Return(nil).Maybe()
+			first := &mocks.FakeDataListener{}
+			first.On("OnDataPublished", thing, stampedData(tc.data, 1)).Maybe()
+			second := &mocks.FakeDataListener{}
+			second.On("OnDataPublished", thing, stampedData(tc.data, 1)).Maybe()
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: seenPresence(), Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, DataListeners: []DataListener{first, second}})
+			thingInteractor.now = func() time.Time { return dataReceivedAt }
+			_ = thingInteractor.PublishData("authorization-token", "thing-id", tc.data)
+
+			first.AssertNumberOfCalls(t, "OnDataPublished", tc.expectedListeners)
+			second.AssertNumberOfCalls(t, "OnDataPublished", tc.expectedListeners)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package interactors
+
+import (
+	"fmt"
+
+	commandEntities "github.com/CESARBR/knot-babeltower/pkg/command/entities"
+)
+
+// isOffline returns whether the thing was seen but has stopped being seen.
+// The things not seen since the service started aren't considered offline,
+// since their presence is unknown.
+func (i *ThingInteractor) isOffline(mainfluxID string) bool {
+	presence := i.presence.Presence(mainfluxID)
+	return presence.LastSeen != nil && !presence.Online
+}
+
+// queueCommand holds the command until the thing reconnects
+func (i *ThingInteractor) queueCommand(command *commandEntities.Command) error {
+	err := i.commands.Queue(command)
+	if err != nil {
+		return fmt.Errorf("error queueing command: %w", err)
+	}
+
+	i.logger.Infof("command %s queued until thing %s reconnects", command.ID, command.ThingID)
+
+	// the thing may have reconnected before the command was queued, in which
+	// case its queued commands were already sent
+	if !i.isOffline(command.ThingToken) {
+		i.sendQueuedCommands(command.ThingID, command.ThingToken)
+	}
+
+	return nil
+}
+
+// sendQueuedCommands sends the commands held while the thing was offline, in
+// the order they were queued
+func (i *ThingInteractor) sendQueuedCommands(thingID, thingToken string) {
+	commands, err := i.commands.Dequeue(thingToken)
+	if err != nil {
+		i.logger.Errorf("error getting the queued commands of thing %s: %s", thingID, err)
+	}
+
+	for _, command := range commands {
+		switch command.Type {
+		case commandEntities.TypeUpdateData:
+			err = i.publisher.PublishUpdateData(command.ThingID, command.ID, command.Data)
+		case commandEntities.TypeRequestData:
+			err = i.publisher.PublishRequestData(command.ThingID, command.ID, command.SensorIDs)
+		}
+
+		if err != nil {
+			i.commands.Fail(command.ID, err)
+			i.logger.Errorf("error sending queued command %s: %s", command.ID, err)
+			continue
+		}
+
+		i.logger.Infof("queued command %s successfully sent", command.ID)
+	}
+}
@@ -0,0 +1,132 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	commandEntities "github.com/CESARBR/knot-babeltower/pkg/command/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var lastSeen = time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+var queuedThing = &entities.Thing{ID: "thing-id", Token: "thing-token", Name: "thing", Schema: voltageSchema}
+
+// offlinePresence returns a presence tracker which has seen the thing before
+// it went offline
+func offlinePresence() *mocks.FakePresenceTracker {
+	fakePresence := &mocks.FakePresenceTracker{}
+	fakePresence.On("Presence", "thing-token").Return(entities.Presence{Online: false, LastSeen: &lastSeen})
+	return fakePresence
+}
+
+func TestCommandsQueuedWhileOffline(t *testing.T) {
+	data := []entities.Data{{SensorID: 0, Value: float64(5)}}
+	testCases := []struct {
+		name          string
+		send          func(i *ThingInteractor) error
+		expected      *commandEntities.Command
+		queueErr      error
+		expectedError error
+	}{
+		{
+			"data update queued",
+			func(i *ThingInteractor) error {
+				return i.UpdateData("authorization-token", "thing-id", "command-id", data)
+			},
+			&commandEntities.Command{ID: "command-id", Type: commandEntities.TypeUpdateData, ThingID: "thing-id", Data: data, ThingToken: "thing-token"},
+			nil,
+			nil,
+		},
+		{
+			"data request queued",
+			func(i *ThingInteractor) error {
+				return i.RequestData("authorization-token", "thing-id", "command-id", []int{0})
+			},
+			&commandEntities.Command{ID: "command-id", Type: commandEntities.TypeRequestData, ThingID: "thing-id", SensorIDs: []int{0}, ThingToken: "thing-token"},
+			nil,
+			nil,
+		},
+		{
+			"command not queued when the id is already used",
+			func(i *ThingInteractor) error {
+				return i.UpdateData("authorization-token", "thing-id", "command-id", data)
+			},
+			&commandEntities.Command{ID: "command-id", Type: commandEntities.TypeUpdateData, ThingID: "thing-id", Data: data, ThingToken: "thing-token"},
+			commandEntities.ErrCommandExists,
+			commandEntities.ErrCommandExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(queuedThing, nil)
+			fakePublisher := &mocks.FakePublisher{}
+			fakeCommands := &mocks.FakeCommandTracker{}
+			fakeCommands.On("Queue", tc.expected).Return(tc.queueErr)
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: offlinePresence(), Commands: fakeCommands, Types: knotTypes})
+			err := tc.send(thingInteractor)
+
+			assert.True(t, errors.Is(err, tc.expectedError))
+			fakeCommands.AssertExpectations(t)
+			fakeCommands.AssertNotCalled(t, "Track", mock.Anything)
+			assert.Empty(t, fakePublisher.Calls)
+		})
+	}
+}
+
+func TestQueuedCommandsSentOnReconnect(t *testing.T) {
+	update := commandEntities.Command{ID: "update-id", Type: commandEntities.TypeUpdateData, ThingID: "thing-id", Data: []entities.Data{{SensorID: 0, Value: float64(5)}}}
+	request := commandEntities.Command{ID: "request-id", Type: commandEntities.TypeRequestData, ThingID: "thing-id", SensorIDs: []int{0}}
+	testCases := []struct {
+		name       string
+		cameOnline bool
+		publishErr error
+	}{
+		{"queued commands sent when the thing comes online", true, nil},
+		{"queued commands failed when they can't be sent", true, errClientSend},
+		{"queued commands not dequeued when the thing was online", false, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(queuedThing, nil)
+			fakePresence := &mocks.FakePresenceTracker{}
+			fakePresence.On("Seen", "thing-id", "thing-token").Return(tc.cameOnline)
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishUpdateData", "thing-id", "update-id", update.Data).Return(tc.publishErr)
+			fakePublisher.On("PublishRequestData", "thing-id", "request-id", request.SensorIDs).Return(tc.publishErr)
+			fakeCommands := &mocks.FakeCommandTracker{}
+			fakeCommands.On("Dequeue", "thing-token").Return([]commandEntities.Command{update, request}, nil)
+			fakeCommands.On("Fail", mock.Anything, tc.publishErr)
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: fakeCommands, Types: knotTypes})
+			err := thingInteractor.Auth("authorization-token", "thing-id")
+			assert.NoError(t, err)
+
+			if !tc.cameOnline {
+				assert.Empty(t, fakeCommands.Calls)
+				assert.Empty(t, fakePublisher.Calls)
+				return
+			}
+
+			if assert.Len(t, fakePublisher.Calls, 2) {
+				assert.Equal(t, "PublishUpdateData", fakePublisher.Calls[0].Method)
+				assert.Equal(t, "PublishRequestData", fakePublisher.Calls[1].Method)
+			}
+			if tc.publishErr != nil {
+				fakeCommands.AssertCalled(t, "Fail", "update-id", tc.publishErr)
+				fakeCommands.AssertCalled(t, "Fail", "request-id", tc.publishErr)
+			} else {
+				fakeCommands.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -22,27 +22,31 @@ func (i *ThingInteractor) Register(authorization, id, name string) error {
 		return sendErr
 	}
 
-	err := i.verifyThingID(id)
+	token, err := i.createThing(authorization, id, name)
+	sendErr := i.sendResponse(id, name, token, err)
 	if err != nil {
-		sendErr := i.sendResponse(id, name, "", err)
 		return fmt.Errorf("error registering thing: %w", sendErr)
 	}
 
+	return sendErr
+}
+
+// createThing creates the thing on the thing's service, returning its token,
+// unless its ID is invalid or already registered
+func (i *ThingInteractor) createThing(authorization, id, name string) (string, error) {
+	err := i.verifyThingID(id)
+	if err != nil {
+		return "", err
+	}
+
 	// verify if thing is already registered
 	_, err = i.thingProxy.Get(authorization, id)
 	if err == nil {
-		sendErr := i.sendResponse(id, name, "", entities.ErrThingExists)
-		return fmt.Errorf("error registering thing: %w", sendErr)
+		return "", entities.ErrThingExists
 	}
 
 	// Get the id generated as a token and send in the response
-	token, err := i.thingProxy.Create(id, name, authorization)
-	sendErr := i.sendResponse(id, name, token, err)
-	if err != nil {
-		return fmt.Errorf("error registering thing: %w", sendErr)
-	}
-
-	return sendErr
+	return i.thingProxy.Create(id, name, authorization)
 }
 
 func (i *ThingInteractor) verifyThingID(id string) error {
@@ -59,7 +63,7 @@ func (i *ThingInteractor) verifyThingID(id string) error {
 }
 
 func (i *ThingInteractor) sendResponse(id, name, token string, err error) error {
-	sendErr := i.publisher.PublishRegisteredDevice(id, name, token, err)
+	sendErr := i.ignoreUndeliverable(i.publisher.PublishRegisteredDevice(id, name, token, err))
 	if sendErr != nil {
 		if err != nil {
 			return fmt.Errorf("error sending response to client: %v: %w", sendErr, err)
@@ -1,13 +1,15 @@
 package interactors
 
 import (
-	"fmt"
-
+	commandEntities "github.com/CESARBR/knot-babeltower/pkg/command/entities"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 )
 
-// RequestData executes the use case operations to request data from the thing
-func (i *ThingInteractor) RequestData(authorization, thingID string, sensorIds []int) error {
+// RequestData executes the use case operations to request data from the
+// thing. The command is tracked until the thing acknowledges it or publishes
+// the requested sensors' data, and its ID is generated when it isn't
+// informed. The command is queued while the thing is offline.
+func (i *ThingInteractor) RequestData(authorization, thingID, commandID string, sensorIds []int) error {
 	if authorization == "" {
 		return ErrAuthNotProvided
 	}
@@ -25,8 +27,8 @@ func (i *ThingInteractor) RequestData(authorization, thingID string, sensorIds [
 	}
 
 	if thing.Schema == nil {
-		i.logger.Error(fmt.Errorf("thing %s has no schema yet", thing.ID))
-		return err
+		i.logger.Error(ErrSchemaUndefined)
+		return ErrSchemaUndefined
 	}
 
 	err = validateSensors(sensorIds, thing.Schema)
@@ -35,13 +37,35 @@ func (i *ThingInteractor) RequestData(authorization, thingID string, sensorIds [
 		return err
 	}
 
-	err = i.publisher.PublishRequestData(thingID, sensorIds)
+	command := &commandEntities.Command{
+		ID:         commandID,
+		Type:       commandEntities.TypeRequestData,
+		ThingID:    thingID,
+		SensorIDs:  sensorIds,
+		ThingToken: thing.Token,
+	}
+	if i.isOffline(thing.Token) {
+		err = i.queueCommand(command)
+		if err != nil {
+			i.logger.Error(err)
+		}
+		return err
+	}
+
+	err = i.commands.Track(command)
+	if err != nil {
+		i.logger.Error(err)
+		return err
+	}
+
+	err = i.publisher.PublishRequestData(thingID, command.ID, sensorIds)
 	if err != nil {
+		i.commands.Fail(command.ID, err)
 		i.logger.Error(err)
 		return err
 	}
 
-	i.logger.Info("data request command successfully sent")
+	i.logger.Infof("data request command %s successfully sent", command.ID)
 	return nil
 }
 
@@ -169,13 +169,13 @@ func TestGetData(t *testing.T) {
 				Return(tc.expectedThing, tc.expectedThingError).
 				Maybe()
 			tc.fakePublisher.
-				On("PublishRequestData", tc.thingID, tc.sensorIds).
+				On("PublishRequestData", tc.thingID, "command-id", tc.sensorIds).
 				Return(tc.expectedRequestDataResponse).
 				Maybe()
 		})
 
-		thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy)
-		err := thingInteractor.RequestData(tc.authorization, tc.thingID, tc.sensorIds)
+		thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy, ThingOptions{Presence: unknownPresence(), Commands: trackedCommands("command-id"), Types: knotTypes})
+		err := thingInteractor.RequestData(tc.authorization, tc.thingID, "", tc.sensorIds)
 		if tc.authorization == "" {
 			assert.EqualError(t, err, ErrAuthNotProvided.Error())
 		}
@@ -184,3 +184,17 @@ func TestGetData(t *testing.T) {
 		tc.fakePublisher.AssertExpectations(t)
 	}
 }
+
+func TestRequestDataSchemaUndefined(t *testing.T) {
+	thingProxy := &mocks.FakeThingProxy{}
+	publisher := &mocks.FakePublisher{}
+	thingProxy.
+		On("Get", "authorization-token", "fc3fcf912d0c290a").
+		Return(&entities.Thing{ID: "fc3fcf912d0c290a", Token: "token"}, nil)
+
+	thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, publisher, thingProxy, ThingOptions{Presence: unknownPresence(), Commands: trackedCommands("command-id"), Types: knotTypes})
+	err := thingInteractor.RequestData("authorization-token", "fc3fcf912d0c290a", "", []int{1})
+
+	assert.Equal(t, ErrSchemaUndefined, err)
+	publisher.AssertNotCalled(t, "PublishRequestData", "fc3fcf912d0c290a", "command-id", []int{1})
+}
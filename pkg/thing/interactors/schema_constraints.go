@@ -0,0 +1,115 @@
+package interactors
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// stepTolerance absorbs the floating point error when verifying a value is
+// on the sensor's step
+const stepTolerance = 1e-9
+
+// validateConstraints returns the reason the schema's constraints are
+// inconsistent with its value type or among themselves, or an empty string
+// when they're consistent
+func validateConstraints(schema entities.Schema) string {
+	numeric := schema.ValueType == 1 || schema.ValueType == 2 // int or float
+	if !numeric && (schema.Min != nil || schema.Max != nil || schema.Step != nil) {
+		return "min, max and step only apply to the int and float value types"
+	}
+
+	if schema.ValueType == 1 {
+		for _, c := range []*float64{schema.Min, schema.Max, schema.Step} {
+			if c != nil && *c != math.Trunc(*c) {
+				return "min, max and step of the int value type must be integers"
+			}
+		}
+	}
+
+	if schema.Min != nil && schema.Max != nil && *schema.Min > *schema.Max {
+		return fmt.Sprintf("minimum %v is greater than the maximum %v", *schema.Min, *schema.Max)
+	}
+
+	if schema.Step != nil && *schema.Step <= 0 {
+		return "step must be positive"
+	}
+
+	if schema.MaxLength != nil {
+		if schema.ValueType != 4 { // raw
+			return "maxLength only applies to the raw value type"
+		}
+		if *schema.MaxLength <= 0 {
+			return "maxLength must be positive"
+		}
+	}
+
+	// the allowed values must also satisfy the other constraints, otherwise
+	// they would never be accepted
+	unrestricted := schema
+	unrestricted.AllowedValues = nil
+	for _, v := range schema.AllowedValues {
+		if !matchesValueType(v, schema.ValueType) {
+			return fmt.Sprintf("allowed value %v doesn't match the value type %d", v, schema.ValueType)
+		}
+
+		reason := checkConstraints(v, unrestricted)
+		if reason != "" {
+			return "allowed " + reason
+		}
+	}
+
+	return ""
+}
+
+// checkConstraints returns the reason the value, which matches the sensor's
+// value type, doesn't satisfy the schema's constraints, or an empty string
+// when it satisfies them
+func checkConstraints(value interface{}, schema entities.Schema) string {
+	switch v := value.(type) {
+	case float64:
+		if schema.Min != nil && v < *schema.Min {
+			return fmt.Sprintf("value %v is less than the minimum %v", v, *schema.Min)
+		}
+		if schema.Max != nil && v > *schema.Max {
+			return fmt.Sprintf("value %v is greater than the maximum %v", v, *schema.Max)
+		}
+		if schema.Step != nil && !onStep(v, schema) {
+			return fmt.Sprintf("value %v isn't on the step %v", v, *schema.Step)
+		}
+	case string:
+		if schema.MaxLength != nil && utf8.RuneCountInString(v) > *schema.MaxLength {
+			return fmt.Sprintf("value is longer than the maximum length %d", *schema.MaxLength)
+		}
+	}
+
+	if len(schema.AllowedValues) > 0 && !isAllowed(value, schema.AllowedValues) {
+		return fmt.Sprintf("value %v isn't one of the allowed values", value)
+	}
+
+	return ""
+}
+
+// onStep returns whether the value is a whole number of steps away from the
+// minimum, or from zero when there's no minimum
+func onStep(value float64, schema entities.Schema) bool {
+	base := 0.0
+	if schema.Min != nil {
+		base = *schema.Min
+	}
+
+	steps := (value - base) / *schema.Step
+	return math.Abs(steps-math.Round(steps)) < stepTolerance
+}
+
+func isAllowed(value interface{}, allowed []interface{}) bool {
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+package interactors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func length(v int) *int {
+	return &v
+}
+
+// constrainedSchema has a temperature from -40 to 125 in half degrees, a
+// fan speed among the allowed levels and a display message up to 8
+// characters
+var constrainedSchema = []entities.Schema{
+	{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 0x0C, Name: "temperature", Min: float(-40), Max: float(125), Step: float(0.5)},
+	{SensorID: 2, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "fan", AllowedValues: []interface{}{float64(0), float64(1), float64(3)}},
+	{SensorID: 3, ValueType: 4, Unit: 0, TypeID: 0xFFF2, Name: "display", MaxLength: length(8)},
+}
+
+func TestValidateDataConstraints(t *testing.T) {
+	testCases := []struct {
+		name           string
+		data           entities.Data
+		expectedReason string
+	}{
+		{"value within the constraints", entities.Data{SensorID: 1, Value: 20.5}, ""},
+		{"value on the minimum", entities.Data{SensorID: 1, Value: -39.5}, ""},
+		{"value less than the minimum", entities.Data{SensorID: 1, Value: -40.5}, "value -40.5 is less than the minimum -40"},
+		{"value greater than the maximum", entities.Data{SensorID: 1, Value: 9000.5}, "value 9000.5 is greater than the maximum 125"},
+		{"value out of the step", entities.Data{SensorID: 1, Value: 20.25}, "value 20.25 isn't on the step 0.5"},
+		{"allowed value", entities.Data{SensorID: 2, Value: float64(3)}, ""},
+		{"value not allowed", entities.Data{SensorID: 2, Value: float64(2)}, "value 2 isn't one of the allowed values"},
+		{"value type mismatch", entities.Data{SensorID: 2, Value: "high"}, "value high doesn't match the value type 1"},
+		{"raw value within the maximum length", entities.Data{SensorID: 3, Value: "ligado"}, ""},
+		{"raw value longer than the maximum length", entities.Data{SensorID: 3, Value: "temperatura"}, "value is longer than the maximum length 8"},
+		{"sensor not in the schema", entities.Data{SensorID: 4, Value: true}, "sensor not in the thing's schema"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateData([]entities.Data{tc.data}, constrainedSchema)
+			if tc.expectedReason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrDataInvalid))
+			var validationErr *DataValidationError
+			if assert.True(t, errors.As(err, &validationErr)) {
+				assert.Equal(t, tc.data.SensorID, validationErr.SensorID)
+				assert.Equal(t, tc.expectedReason, validationErr.Reason)
+			}
+		})
+	}
+}
+
+func TestUpdateSchemaConstraints(t *testing.T) {
+	testCases := []struct {
+		name           string
+		schema         entities.Schema
+		expectedReason string
+	}{
+		{
+			"consistent constraints",
+			constrainedSchema[0],
+			"",
+		},
+		{
+			"range on a bool sensor",
+			entities.Schema{SensorID: 1, ValueType: 3, Unit: 0, TypeID: 0xFFF1, Name: "switch", Max: float(1)},
+			"min, max and step only apply to the int and float value types",
+		},
+		{
+			"fractional step on an int sensor",
+			entities.Schema{SensorID: 1, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "analog", Step: float(0.5)},
+			"min, max and step of the int value type must be integers",
+		},
+		{
+			"minimum greater than the maximum",
+			entities.Schema{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 0x0C, Name: "angle", Min: float(10), Max: float(5)},
+			"minimum 10 is greater than the maximum 5",
+		},
+		{
+			"step not positive",
+			entities.Schema{SensorID: 1, ValueType: 2, Unit: 1, TypeID: 0x0C, Name: "angle", Step: float(0)},
+			"step must be positive",
+		},
+		{
+			"maximum length on a numeric sensor",
+			entities.Schema{SensorID: 1, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "analog", MaxLength: length(4)},
+			"maxLength only applies to the raw value type",
+		},
+		{
+			"maximum length not positive",
+			entities.Schema{SensorID: 1, ValueType: 4, Unit: 0, TypeID: 0xFFF2, Name: "display", MaxLength: length(0)},
+			"maxLength must be positive",
+		},
+		{
+			"allowed value of another type",
+			entities.Schema{SensorID: 1, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "analog", AllowedValues: []interface{}{float64(1), "two"}},
+			"allowed value two doesn't match the value type 1",
+		},
+		{
+			"allowed value out of the range",
+			entities.Schema{SensorID: 1, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "analog", Max: float(2), AllowedValues: []interface{}{float64(1), float64(3)}},
+			"allowed value 3 is greater than the maximum 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schemaList := []entities.Schema{tc.schema}
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("UpdateSchema", "thing-id", schemaList).Return(nil).Maybe()
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(&entities.Thing{ID: "thing-id"}, nil).Maybe()
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishUpdatedSchema", "thing-id", schemaList, mock.Anything, mock.Anything).Return(nil)
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
+			err := thingInteractor.UpdateSchema("authorization-token", "thing-id", schemaList)
+
+			if tc.expectedReason == "" {
+				assert.NoError(t, err)
+				fakeThingProxy.AssertCalled(t, "UpdateSchema", "thing-id", schemaList)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrSchemaInvalid))
+			assert.EqualError(t, err, "invalid schema: sensor 1: "+tc.expectedReason)
+			fakeThingProxy.AssertNotCalled(t, "UpdateSchema", mock.Anything, mock.Anything)
+			fakePublisher.AssertCalled(t, "PublishUpdatedSchema", "thing-id", schemaList, (*entities.SchemaVersion)(nil), err)
+		})
+	}
+}
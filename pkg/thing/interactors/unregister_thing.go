@@ -1,11 +1,13 @@
 package interactors
 
+import "fmt"
+
 // Unregister runs the use case to remove a registered thing
 func (i *ThingInteractor) Unregister(authorization, id string) error {
 	i.logger.Debug("executing unregister thing use case")
 
 	if authorization == "" {
-		sendErr := i.publisher.PublishUnregisteredDevice(id, ErrAuthNotProvided)
+		sendErr := i.ignoreUndeliverable(i.publisher.PublishUnregisteredDevice(id, ErrAuthNotProvided))
 		return sendErr
 	}
 
@@ -13,9 +15,9 @@ func (i *ThingInteractor) Unregister(authorization, id string) error {
 		return ErrIDNotProvided
 	}
 
-	err := i.thingProxy.Remove(authorization, id)
+	err := i.removeThing(authorization, id)
 	if err != nil {
-		sendErr := i.publisher.PublishUnregisteredDevice(id, err)
+		sendErr := i.ignoreUndeliverable(i.publisher.PublishUnregisteredDevice(id, err))
 		if sendErr != nil {
 			i.logger.Debug(err)
 			return sendErr
@@ -23,10 +25,33 @@ func (i *ThingInteractor) Unregister(authorization, id string) error {
 		return err
 	}
 
-	sendErr := i.publisher.PublishUnregisteredDevice(id, nil)
+	sendErr := i.ignoreUndeliverable(i.publisher.PublishUnregisteredDevice(id, nil))
 	if sendErr != nil {
 		return sendErr
 	}
 
 	return nil
 }
+
+// removeThing removes the thing from the thing's service, along with its
+// presence and schema versions. The thing is fetched first to get its
+// Mainflux ID, by which the schema versions are kept.
+func (i *ThingInteractor) removeThing(authorization, id string) error {
+	thing, err := i.thingProxy.Get(authorization, id)
+	if err != nil {
+		return fmt.Errorf("error getting thing: %w", err)
+	}
+
+	err = i.thingProxy.Remove(authorization, id)
+	if err != nil {
+		return err
+	}
+
+	i.presence.Remove(thing.Token)
+	err = i.schemas.Remove(thing.Token)
+	if err != nil {
+		i.logger.Errorf("failed to remove thing %s schema versions: %v", id, err)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/CESARBR/knot-babeltower/pkg/mocks"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -99,6 +100,10 @@ var unregisterAtCases = []UnregisterThingTestCase{
 func TestUnregisterThing(t *testing.T) {
 	for _, tc := range unregisterAtCases {
 		t.Run(tc.name, func(t *testing.T) {
+			tc.fakeThingProxy.
+				On("Get", tc.authParam, tc.idParam).
+				Return(&entities.Thing{ID: tc.idParam, Token: "thing-token"}, nil).
+				Maybe()
 			tc.fakeThingProxy.
 				On("Remove", tc.authParam, tc.idParam).
 				Return(tc.fakeThingProxy.ReturnErr).
@@ -107,8 +112,10 @@ func TestUnregisterThing(t *testing.T) {
 				On("PublishUnregisteredDevice", tc.idParam, tc.fakePublisher.Err).
 				Return(tc.fakePublisher.SendError).
 				Maybe()
+			fakePresence := &mocks.FakePresenceTracker{}
+			fakePresence.On("Remove", "thing-token").Maybe()
 
-			thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy)
+			thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
 			err := thingInteractor.Unregister(tc.authParam, tc.idParam)
 
 			if err != nil {
@@ -120,6 +127,11 @@ func TestUnregisterThing(t *testing.T) {
 			tc.fakeLogger.AssertExpectations(t)
 			tc.fakeThingProxy.AssertExpectations(t)
 			tc.fakePublisher.AssertExpectations(t)
+			if tc.fakeThingProxy.ReturnErr == nil && tc.idParam != "" && tc.authParam != "" {
+				fakePresence.AssertCalled(t, "Remove", "thing-token")
+			} else {
+				fakePresence.AssertNotCalled(t, "Remove", "thing-token")
+			}
 		})
 	}
 }
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"math"
 
+	commandEntities "github.com/CESARBR/knot-babeltower/pkg/command/entities"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 )
 
-// UpdateData executes the use case operations to update data in thing
-func (i *ThingInteractor) UpdateData(authorization, thingID string, data []entities.Data) error {
+// UpdateData executes the use case operations to update data in thing. The
+// command is tracked until the thing acknowledges it or publishes the updated
+// data, and its ID is generated when it isn't informed. The command is queued
+// while the thing is offline.
+func (i *ThingInteractor) UpdateData(authorization, thingID, commandID string, data []entities.Data) error {
 	if authorization == "" {
 		return ErrAuthNotProvided
 	}
@@ -19,57 +23,99 @@ func (i *ThingInteractor) UpdateData(authorization, thingID string, data []entit
 		return ErrDataNotProvided
 	}
 
-	err := i.verifyThingData(authorization, thingID, data)
+	thing, err := i.verifyThingData(authorization, thingID, data)
 	if err != nil {
 		return fmt.Errorf("error validating thing's data: %w", err)
 	}
 
-	err = i.publisher.PublishUpdateData(thingID, data)
+	command := &commandEntities.Command{
+		ID:         commandID,
+		Type:       commandEntities.TypeUpdateData,
+		ThingID:    thingID,
+		Data:       data,
+		ThingToken: thing.Token,
+	}
+	if i.isOffline(thing.Token) {
+		return i.queueCommand(command)
+	}
+
+	err = i.commands.Track(command)
+	if err != nil {
+		return fmt.Errorf("error tracking command: %w", err)
+	}
+
+	err = i.publisher.PublishUpdateData(thingID, command.ID, data)
 	if err != nil {
+		i.commands.Fail(command.ID, err)
 		return fmt.Errorf("error sending message to client: %w", err)
 	}
 
-	i.logger.Info("data update command successfully sent")
+	i.logger.Infof("data update command %s successfully sent", command.ID)
 	return nil
 }
 
-func (i *ThingInteractor) verifyThingData(authorization, thingID string, data []entities.Data) error {
+// verifyThingData validates the data against the thing's schema, returning
+// the thing when it's valid
+func (i *ThingInteractor) verifyThingData(authorization, thingID string, data []entities.Data) (*entities.Thing, error) {
 	thing, err := i.thingProxy.Get(authorization, thingID)
 	if err != nil {
-		return fmt.Errorf("error getting thing metadata: %w", err)
+		return nil, fmt.Errorf("error getting thing metadata: %w", err)
 	}
 
 	if thing.Schema == nil {
-		return ErrSchemaUndefined
+		return nil, ErrSchemaUndefined
 	}
 
+	err = ValidateData(data, thing.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return thing, nil
+}
+
+// ValidateData verifies the data matches the thing's schema, returning a
+// DataValidationError, which wraps ErrDataInvalid, for the first data item
+// of a sensor not in the schema or whose value doesn't satisfy the sensor's
+// value type and constraints
+func ValidateData(data []entities.Data, schema []entities.Schema) error {
 	for _, d := range data {
-		if !validateSchema(d, thing.Schema) {
-			return ErrDataInvalid
+		reason := validateSchema(d, schema)
+		if reason != "" {
+			return &DataValidationError{SensorID: d.SensorID, Reason: reason}
 		}
 	}
 
 	return nil
 }
 
-func validateSchema(data entities.Data, schema []entities.Schema) bool {
+// validateSchema returns the reason the data doesn't match the schema, or an
+// empty string when it matches
+func validateSchema(data entities.Data, schema []entities.Schema) string {
 	for _, s := range schema {
 		if s.SensorID == data.SensorID {
-			switch data.Value.(type) {
-			case float64:
-				if data.Value == math.Trunc(data.Value.(float64)) { // check if number is integer
-					return s.ValueType == 1 // int
-				}
-				return s.ValueType == 2 // float
-			case bool:
-				return s.ValueType == 3 // bool
-			case string:
-				return s.ValueType == 4 // raw
-			default:
-				return false
+			if !matchesValueType(data.Value, s.ValueType) {
+				return fmt.Sprintf("value %v doesn't match the value type %d", data.Value, s.ValueType)
 			}
+			return checkConstraints(data.Value, s)
 		}
 	}
 
-	return false
+	return "sensor not in the thing's schema"
+}
+
+func matchesValueType(value interface{}, valueType int) bool {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) { // check if number is integer
+			return valueType == 1 // int
+		}
+		return valueType == 2 // float
+	case bool:
+		return valueType == 3 // bool
+	case string:
+		return valueType == 4 // raw
+	default:
+		return false
+	}
 }
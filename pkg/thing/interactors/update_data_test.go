@@ -4,9 +4,11 @@ import (
 	"errors"
 	"testing"
 
+	commandEntities "github.com/CESARBR/knot-babeltower/pkg/command/entities"
 	"github.com/CESARBR/knot-babeltower/pkg/mocks"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type UpdateDataTestCase struct {
@@ -25,6 +27,25 @@ var (
 	errClientSend    = errors.New("error sending message to client")
 )
 
+// trackedCommands returns a command tracker assigning the ID to the tracked
+// commands
+func trackedCommands(commandID string) *mocks.FakeCommandTracker {
+	fakeCommands := &mocks.FakeCommandTracker{}
+	fakeCommands.On("Track", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*commandEntities.Command).ID = commandID
+	}).Maybe()
+	fakeCommands.On("Fail", commandID, mock.Anything).Maybe()
+	return fakeCommands
+}
+
+// unknownPresence returns a presence tracker which has never seen the things,
+// so the commands are sent without being queued
+func unknownPresence() *mocks.FakePresenceTracker {
+	fakePresence := &mocks.FakePresenceTracker{}
+	fakePresence.On("Presence", mock.Anything).Return(entities.Presence{}).Maybe()
+	return fakePresence
+}
+
 var voltageSchema = []entities.Schema{{
 	SensorID:  0,
 	ValueType: 1,
@@ -158,17 +179,64 @@ func TestUpdateData(t *testing.T) {
 				Return(tc.fakeThingProxy.Thing, tc.fakeThingProxy.ReturnErr).
 				Maybe()
 			tc.fakePublisher.
-				On("PublishUpdateData", tc.idParam, tc.dataParam).
+				On("PublishUpdateData", tc.idParam, "command-id", tc.dataParam).
 				Return(tc.fakePublisher.ReturnErr).
 				Maybe()
+			fakeCommands := trackedCommands("command-id")
 
-			thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy)
-			err := thingInteractor.UpdateData(tc.authParam, tc.idParam, tc.dataParam)
+			thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy, ThingOptions{Presence: unknownPresence(), Commands: fakeCommands, Types: knotTypes})
+			err := thingInteractor.UpdateData(tc.authParam, tc.idParam, "", tc.dataParam)
 
 			assert.EqualValues(t, errors.Is(err, tc.expectedError), true)
+			if tc.fakePublisher.ReturnErr != nil {
+				fakeCommands.AssertCalled(t, "Fail", "command-id", tc.fakePublisher.ReturnErr)
+			} else {
+				fakeCommands.AssertNotCalled(t, "Fail", "command-id", mock.Anything)
+			}
 
 			tc.fakeThingProxy.AssertExpectations(t)
 			tc.fakePublisher.AssertExpectations(t)
 		})
 	}
 }
+
+func TestUpdateDataTracksCommand(t *testing.T) {
+	thing := &entities.Thing{ID: "thing-id", Token: "thing-token", Name: "thing", Schema: voltageSchema}
+	data := []entities.Data{{SensorID: 0, Value: float64(5)}}
+	testCases := []struct {
+		name          string
+		trackErr      error
+		expectedError error
+	}{
+		{"command tracked and sent with the client's id", nil, nil},
+		{"command not sent when the id is already used", commandEntities.ErrCommandExists, commandEntities.ErrCommandExists},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(thing, nil)
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishUpdateData", "thing-id", "client-command-id", data).Return(nil).Maybe()
+			fakeCommands := &mocks.FakeCommandTracker{}
+			fakeCommands.On("Track", &commandEntities.Command{
+				ID:         "client-command-id",
+				Type:       commandEntities.TypeUpdateData,
+				ThingID:    "thing-id",
+				Data:       data,
+				ThingToken: "thing-token",
+			}).Return(tc.trackErr)
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: unknownPresence(), Commands: fakeCommands, Types: knotTypes})
+			err := thingInteractor.UpdateData("authorization-token", "thing-id", "client-command-id", data)
+
+			assert.True(t, errors.Is(err, tc.expectedError))
+			fakeCommands.AssertExpectations(t)
+			if tc.expectedError == nil {
+				fakePublisher.AssertExpectations(t)
+			} else {
+				fakePublisher.AssertNotCalled(t, "PublishUpdateData", "thing-id", "client-command-id", data)
+			}
+		})
+	}
+}
@@ -2,56 +2,33 @@ package interactors
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
 	"github.com/go-playground/validator"
 )
 
-type schemaType struct {
-	valueType interface{}
-	unit      interface{}
-}
+// BreakingChangePolicy defines how the schema updates with breaking changes,
+// which make the values previously published by the thing meaningless, are
+// handled
+type BreakingChangePolicy string
 
-type interval struct {
-	min int
-	max int
-}
+const (
+	// BreakingChangesFlag accepts the breaking changes, flagging them on the
+	// schema's diff
+	BreakingChangesFlag BreakingChangePolicy = "flag"
 
-// rules reference table: https://knot-devel.cesar.org.br/doc/thing/unit-type-value.html
-var rules = map[int]schemaType{
-	0x0000: {valueType: 4, unit: 0},              // RAW   => NONE
-	0x0001: {valueType: 1, unit: interval{1, 3}}, // INT   => VOLTAGE
-	0x0002: {valueType: 1, unit: interval{1, 2}}, // INT   => CURRENT
-	0x0003: {valueType: 1, unit: 1},              // INT   => RESISTENCE
-	0x0004: {valueType: 1, unit: interval{1, 3}}, // INT   => POWER
-	0x0005: {valueType: 1, unit: interval{1, 3}}, // INT   => TEMPERATURE
-	0x0006: {valueType: 1, unit: 1},              // INT   => RELATIVE_HUMIDITY
-	0x0007: {valueType: 1, unit: interval{1, 3}}, // INT   => LUMINOSITY
-	0x0008: {valueType: 1, unit: interval{1, 3}}, // INT   => TIME
-	0x0009: {valueType: 1, unit: interval{1, 4}}, // INT   => MASS
-	0x000A: {valueType: 1, unit: interval{1, 3}}, // INT   => PRESSURE
-	0x000B: {valueType: 1, unit: interval{1, 4}}, // INT   => DISTANCE
-	0x000C: {valueType: 2, unit: interval{1, 2}}, // FLOAT => ANGLE
-	0x000D: {valueType: 2, unit: interval{1, 4}}, // FLOAT => VOLUME
-	0x000E: {valueType: 2, unit: interval{1, 3}}, // FLOAT => AREA
-	0x000F: {valueType: 2, unit: 1},              // FLOAT => RAIN
-	0x0010: {valueType: 2, unit: 1},              // FLOAT => DENSITY
-	0x0011: {valueType: 2, unit: 1},              // FLOAT => LATITUDE
-	0x0012: {valueType: 2, unit: 1},              // FLOAT => LONGITUDE
-	0x0013: {valueType: 1, unit: interval{1, 4}}, // INT   => SPEED
-	0x0014: {valueType: 2, unit: interval{1, 6}}, // FLOAT => VOLUMEFLOW
-	0x0015: {valueType: 1, unit: interval{1, 6}}, // INT   => ENERGY
-	0xFFF0: {valueType: 3, unit: 0},              // BOOL  => PRESENCE
-	0xFFF1: {valueType: 3, unit: 0},              // BOOL  => SWITCH
-	0xFFF2: {valueType: 4, unit: 0},              // RAW   => COMMAND
-	0xFF10: {valueType: 1, unit: 0},              // INT   => ANALOG
-	0xFFFF: {valueType: 4, unit: 0},              // RAW   => INVALID
-}
+	// BreakingChangesReject rejects the schema updates with breaking changes
+	BreakingChangesReject BreakingChangePolicy = "reject"
+)
 
-// UpdateSchema receive the new sensor schema and update it on the thing's service
+// UpdateSchema receive the new sensor schema and update it on the thing's service.
+// The sensors' constraints must be consistent with their value types. The
+// schema is recorded as the thing's next version when it differs from the
+// previous one, and the client is notified about its diff.
 func (i *ThingInteractor) UpdateSchema(authorization, thingID string, schemaList []entities.Schema) error {
 	if authorization == "" {
-		sendErr := i.notifyClient(thingID, schemaList, ErrAuthNotProvided)
+		sendErr := i.notifyClient(thingID, schemaList, nil, ErrAuthNotProvided)
 		return sendErr
 	}
 	if thingID == "" {
@@ -62,19 +39,50 @@ func (i *ThingInteractor) UpdateSchema(authorization, thingID string, schemaList
 	}
 
 	if !i.isValidSchema(schemaList) {
-		err := i.notifyClient(thingID, schemaList, ErrSchemaInvalid)
+		err := i.notifyClient(thingID, schemaList, nil, ErrSchemaInvalid)
 		return err
 	}
+
+	for _, schema := range schemaList {
+		reason := validateConstraints(schema)
+		if reason != "" {
+			err := fmt.Errorf("%w: sensor %d: %s", ErrSchemaInvalid, schema.SensorID, reason)
+			return i.notifyClient(thingID, schemaList, nil, err)
+		}
+	}
 	i.logger.Info("updateSchema: schema validated")
 
-	err := i.thingProxy.UpdateSchema(authorization, thingID, schemaList)
+	// the versions are kept by the thing's Mainflux ID, since the things of
+	// different users may have the same ID
+	thing, err := i.thingProxy.Get(authorization, thingID)
+	if err != nil {
+		return i.notifyClient(thingID, schemaList, nil, fmt.Errorf("error getting thing: %w", err))
+	}
+
+	versions, err := i.schemas.List(thing.Token)
+	if err != nil {
+		return i.notifyClient(thingID, schemaList, nil, err)
+	}
+
+	version := &entities.SchemaVersion{
+		Schema:    schemaList,
+		Diff:      entities.DiffSchemas(previousSchema(thing, versions), schemaList),
+		CreatedAt: i.now(),
+	}
+	if version.Diff.Breaking && i.breaking == BreakingChangesReject {
+		err = fmt.Errorf("%w: %s", ErrSchemaBreakingChange, breakingChanges(version.Diff))
+		return i.notifyClient(thingID, schemaList, version, err)
+	}
+
+	err = i.thingProxy.UpdateSchema(authorization, thingID, schemaList)
 	if err != nil {
-		sendErr := i.notifyClient(thingID, schemaList, err)
+		sendErr := i.notifyClient(thingID, schemaList, nil, err)
 		return sendErr
 	}
 	i.logger.Info("updateSchema: schema updated")
 
-	err = i.notifyClient(thingID, schemaList, err)
+	version = i.recordVersion(thing, versions, version)
+	err = i.notifyClient(thingID, schemaList, version, err)
 	if err != nil {
 		// TODO: handle error when publishing message to queue.
 		return err
@@ -84,9 +92,51 @@ func (i *ThingInteractor) UpdateSchema(authorization, thingID string, schemaList
 	return nil
 }
 
+// previousSchema returns the schema of the thing's latest version. When no
+// version was recorded yet, the schema on the thing's service is used, since
+// it may have been updated before the versions were kept.
+func previousSchema(thing *entities.Thing, versions []entities.SchemaVersion) []entities.Schema {
+	if len(versions) > 0 {
+		return versions[len(versions)-1].Schema
+	}
+
+	return thing.Schema
+}
+
+// recordVersion appends the schema as the thing's next version, unless it's
+// the same as the latest one, whose number is kept. The schema was already
+// updated, so failing to record it is only logged and the version is
+// published without its number.
+func (i *ThingInteractor) recordVersion(thing *entities.Thing, versions []entities.SchemaVersion, version *entities.SchemaVersion) *entities.SchemaVersion {
+	if version.Diff.Empty() && len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version
+		return version
+	}
+
+	recorded, err := i.schemas.Append(thing.Token, *version)
+	if err != nil {
+		i.logger.Errorf("updateSchema: failed to record thing %s schema version: %v", thing.ID, err)
+		return version
+	}
+
+	return &recorded
+}
+
+// breakingChanges describes the diff's breaking changes
+func breakingChanges(diff entities.SchemaDiff) string {
+	reasons := []string{}
+	for _, c := range diff.Changed {
+		if c.Breaking {
+			reasons = append(reasons, fmt.Sprintf("sensor %d value type changed from %d to %d", c.SensorID, c.Previous.ValueType, c.Current.ValueType))
+		}
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
 func (i *ThingInteractor) isValidSchema(schemaList []entities.Schema) bool {
 	validate := validator.New()
-	validate.RegisterStructValidation(schemaValidation, entities.Schema{})
+	validate.RegisterStructValidation(i.schemaValidation, entities.Schema{})
 	for _, schema := range schemaList {
 		err := validate.Struct(schema)
 		if err != nil {
@@ -97,8 +147,8 @@ func (i *ThingInteractor) isValidSchema(schemaList []entities.Schema) bool {
 	return true
 }
 
-func (i *ThingInteractor) notifyClient(thingID string, schemaList []entities.Schema, err error) error {
-	sendErr := i.publisher.PublishUpdatedSchema(thingID, schemaList, err)
+func (i *ThingInteractor) notifyClient(thingID string, schemaList []entities.Schema, version *entities.SchemaVersion, err error) error {
+	sendErr := i.ignoreUndeliverable(i.publisher.PublishUpdatedSchema(thingID, schemaList, version, err))
 	if sendErr != nil {
 		if err != nil {
 			return fmt.Errorf("error sending response to client: %v: %w", sendErr, err)
@@ -108,65 +158,23 @@ func (i *ThingInteractor) notifyClient(thingID string, schemaList []entities.Sch
 	return err
 }
 
-func schemaValidation(sl validator.StructLevel) {
+// schemaValidation verifies the sensor's type is registered and allows the
+// sensor's value type and unit
+func (i *ThingInteractor) schemaValidation(sl validator.StructLevel) {
 	schema := sl.Current().Interface().(entities.Schema)
-	typeID := schema.TypeID
 
-	if (typeID < 0 || 15 < typeID) && (typeID < 0xfff0 || 0xfff2 < typeID) && typeID != 0xff10 {
+	sensorType, ok := i.types.Type(schema.TypeID)
+	if !ok {
 		sl.ReportError(schema, "schema", "Type ID", "typeID", "false")
 		return
 	}
 
-	if !isValidValueType(schema.TypeID, schema.ValueType) {
+	if !sensorType.AllowsValueType(schema.ValueType) {
 		sl.ReportError(schema, "schema", "Value Type", "valueType", "false")
 		return
 	}
 
-	if !isValidUnit(schema.TypeID, schema.Unit) {
+	if !sensorType.AllowsUnit(schema.Unit) {
 		sl.ReportError(schema, "schema", "Unit", "unit", "false")
 	}
 }
-
-func isValidValueType(typeID, valueType int) bool {
-	t := rules[typeID].valueType
-	if t == nil {
-		return false
-	}
-
-	switch v := t.(type) {
-	case int:
-		value := v
-		if valueType != value {
-			return false
-		}
-	case interval:
-		interval := t.(interval)
-		if valueType < interval.min || interval.max < valueType {
-			return false
-		}
-	}
-
-	return true
-}
-
-func isValidUnit(typeID, unit int) bool {
-	u := rules[typeID].unit
-	if u == nil {
-		return false
-	}
-
-	switch v := u.(type) {
-	case int:
-		value := v
-		if unit != value {
-			return false
-		}
-	case interval:
-		interval := u.(interval)
-		if unit < interval.min || interval.max < unit {
-			return false
-		}
-	}
-
-	return true
-}
@@ -6,9 +6,22 @@ import (
 
 	"github.com/CESARBR/knot-babeltower/pkg/mocks"
 	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// knotTypes is the sensor types registry shipped with the service
+var knotTypes = loadKnotTypes()
+
+func loadKnotTypes() *entities.TypeRegistry {
+	registry, err := storage.LoadTypeRegistry("../../../internal/config/types.yaml")
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
 var (
 	errThingProxyFailed      = errors.New("failed to update the schema on the thing's proxy")
 	errPublisherClientFailed = errors.New("failed to send updated schema response")
@@ -189,6 +202,66 @@ var tCases = []UpdateSchemaTestCase{
 		&mocks.FakeThingProxy{},
 		&mocks.FakePublisher{},
 	},
+	{
+		"energy schema successfully updated",
+		"authorization-token",
+		"99cf40c23012ce1c",
+		nil,
+		[]entities.Schema{
+			{
+				SensorID:  0,
+				ValueType: 1,
+				Unit:      4,
+				TypeID:    0x0015,
+				Name:      "energy",
+			},
+		},
+		true,
+		nil,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+		&mocks.FakePublisher{},
+	},
+	{
+		"invalid schema value type for the sensor type",
+		"authorization-token",
+		"a9cf40c23012ce1c",
+		errSchemaInvalid,
+		[]entities.Schema{
+			{
+				SensorID:  0,
+				ValueType: 2,
+				Unit:      1,
+				TypeID:    0x0015,
+				Name:      "energy",
+			},
+		},
+		false,
+		nil,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+		&mocks.FakePublisher{},
+	},
+	{
+		"unit not registered for the sensor type",
+		"authorization-token",
+		"b9cf40c23012ce1c",
+		errSchemaInvalid,
+		[]entities.Schema{
+			{
+				SensorID:  0,
+				ValueType: 1,
+				Unit:      7,
+				TypeID:    0x0015,
+				Name:      "energy",
+			},
+		},
+		false,
+		nil,
+		&mocks.FakeLogger{},
+		&mocks.FakeThingProxy{},
+		&mocks.FakePublisher{},
+	},
 }
 
 func TestUpdateSchema(t *testing.T) {
@@ -199,15 +272,22 @@ func TestUpdateSchema(t *testing.T) {
 				Return(tc.expectedErr).
 				Maybe()
 
+			tc.fakeThingProxy.
+				On("Get", tc.authorization, tc.thingID).
+				Return(&entities.Thing{ID: tc.thingID}, nil).
+				Maybe()
+
 			tc.fakePublisher.
-				On("PublishUpdatedSchema", tc.thingID, tc.schemaList, tc.err).
+				On("PublishUpdatedSchema", tc.thingID, tc.schemaList, mock.Anything, tc.err).
 				Return(tc.expectedErr).
 				Maybe()
 
-			thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy)
+			thingInteractor := NewThingInteractor(tc.fakeLogger, tc.fakePublisher, tc.fakeThingProxy, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes})
 			err := thingInteractor.UpdateSchema(tc.authorization, tc.thingID, tc.schemaList)
 			if !tc.isSchemaValid {
 				assert.EqualError(t, err, errSchemaInvalid.Error())
+			} else if tc.expectedErr == nil {
+				assert.NoError(t, err)
 			}
 
 			tc.fakeThingProxy.AssertExpectations(t)
@@ -215,3 +295,157 @@ func TestUpdateSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateSchemaVersions(t *testing.T) {
+	led := entities.Schema{SensorID: 1, ValueType: 3, Unit: 0, TypeID: 0xFFF1, Name: "LED"}
+	dimmer := entities.Schema{SensorID: 1, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "dimmer"}
+	temperature := entities.Schema{SensorID: 2, ValueType: 1, Unit: 1, TypeID: 0x05, Name: "temperature"}
+	renamed := entities.Schema{SensorID: 2, ValueType: 1, Unit: 1, TypeID: 0x05, Name: "room"}
+
+	testCases := []struct {
+		name            string
+		policy          BreakingChangePolicy
+		current         []entities.Schema
+		updates         [][]entities.Schema
+		expectedErr     error
+		expectedVersion int
+		expectedDiff    entities.SchemaDiff
+		expectedStored  int
+	}{
+		{
+			"first version compared to the thing's schema",
+			BreakingChangesFlag,
+			[]entities.Schema{led},
+			[][]entities.Schema{{led, temperature}},
+			nil,
+			1,
+			entities.SchemaDiff{Added: []entities.Schema{temperature}, Removed: []entities.Schema{}, Changed: []entities.SensorChange{}},
+			1,
+		},
+		{
+			"sensor renamed and removed",
+			BreakingChangesReject,
+			nil,
+			[][]entities.Schema{{led, temperature}, {renamed}},
+			nil,
+			2,
+			entities.SchemaDiff{
+				Added:   []entities.Schema{},
+				Removed: []entities.Schema{led},
+				Changed: []entities.SensorChange{{SensorID: 2, Previous: temperature, Current: renamed}},
+			},
+			2,
+		},
+		{
+			"same schema keeps the version",
+			BreakingChangesFlag,
+			nil,
+			[][]entities.Schema{{led}, {led}},
+			nil,
+			1,
+			entities.SchemaDiff{Added: []entities.Schema{}, Removed: []entities.Schema{}, Changed: []entities.SensorChange{}},
+			1,
+		},
+		{
+			"value type change flagged as breaking",
+			BreakingChangesFlag,
+			nil,
+			[][]entities.Schema{{led}, {dimmer}},
+			nil,
+			2,
+			entities.SchemaDiff{
+				Added:    []entities.Schema{},
+				Removed:  []entities.Schema{},
+				Changed:  []entities.SensorChange{{SensorID: 1, Previous: led, Current: dimmer, Breaking: true}},
+				Breaking: true,
+			},
+			2,
+		},
+		{
+			"value type change rejected",
+			BreakingChangesReject,
+			nil,
+			[][]entities.Schema{{led}, {dimmer}},
+			ErrSchemaBreakingChange,
+			0,
+			entities.SchemaDiff{
+				Added:    []entities.Schema{},
+				Removed:  []entities.Schema{},
+				Changed:  []entities.SensorChange{{SensorID: 1, Previous: led, Current: dimmer, Breaking: true}},
+				Breaking: true,
+			},
+			1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeThingProxy := &mocks.FakeThingProxy{}
+			fakeThingProxy.On("Get", "authorization-token", "thing-id").Return(&entities.Thing{ID: "thing-id", Token: "thing-token", Schema: tc.current}, nil)
+			fakeThingProxy.On("UpdateSchema", "thing-id", mock.Anything).Return(nil)
+			fakePublisher := &mocks.FakePublisher{}
+			fakePublisher.On("PublishUpdatedSchema", "thing-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			schemas := storage.NewMemorySchemaStore(0)
+
+			thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: &mocks.FakePresenceTracker{}, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, Schemas: schemas, Breaking: tc.policy})
+			var err error
+			for _, update := range tc.updates {
+				err = thingInteractor.UpdateSchema("authorization-token", "thing-id", update)
+			}
+
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			last := fakePublisher.Calls[len(fakePublisher.Calls)-1]
+			if version := last.Arguments.Get(2).(*entities.SchemaVersion); assert.NotNil(t, version) {
+				assert.Equal(t, tc.expectedVersion, version.Version)
+				assert.Equal(t, tc.expectedDiff, version.Diff)
+			}
+
+			stored, err := schemas.List("thing-token")
+			assert.NoError(t, err)
+			assert.Len(t, stored, tc.expectedStored)
+
+			// the rejected update isn't sent to the thing's service
+			updated := len(tc.updates)
+			if tc.expectedErr != nil {
+				updated--
+			}
+			fakeThingProxy.AssertNumberOfCalls(t, "UpdateSchema", updated)
+		})
+	}
+}
+
+func TestUpdateSchemaVersionsOfDifferentUsers(t *testing.T) {
+	led := entities.Schema{SensorID: 1, ValueType: 3, Unit: 0, TypeID: 0xFFF1, Name: "LED"}
+	dimmer := entities.Schema{SensorID: 1, ValueType: 1, Unit: 0, TypeID: 0xFF10, Name: "dimmer"}
+
+	// both users have a thing with the same ID, but different Mainflux IDs
+	fakeThingProxy := &mocks.FakeThingProxy{}
+	fakeThingProxy.On("Get", "first-user-token", "thing-id").Return(&entities.Thing{ID: "thing-id", Token: "first-thing-token"}, nil)
+	fakeThingProxy.On("Get", "second-user-token", "thing-id").Return(&entities.Thing{ID: "thing-id", Token: "second-thing-token"}, nil)
+	fakeThingProxy.On("UpdateSchema", "thing-id", mock.Anything).Return(nil)
+	fakeThingProxy.On("Remove", "first-user-token", "thing-id").Return(nil)
+	fakePresence := &mocks.FakePresenceTracker{}
+	fakePresence.On("Remove", mock.Anything)
+	fakePublisher := &mocks.FakePublisher{}
+	fakePublisher.On("PublishUpdatedSchema", "thing-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fakePublisher.On("PublishUnregisteredDevice", "thing-id", nil).Return(nil)
+	schemas := storage.NewMemorySchemaStore(0)
+
+	thingInteractor := NewThingInteractor(&mocks.FakeLogger{}, fakePublisher, fakeThingProxy, ThingOptions{Presence: fakePresence, Commands: &mocks.FakeCommandTracker{}, Types: knotTypes, Schemas: schemas, Breaking: BreakingChangesReject})
+	assert.NoError(t, thingInteractor.UpdateSchema("first-user-token", "thing-id", []entities.Schema{led}))
+
+	// the first user's schema isn't the baseline of the second user's one
+	assert.NoError(t, thingInteractor.UpdateSchema("second-user-token", "thing-id", []entities.Schema{dimmer}))
+
+	versions, err := thingInteractor.ListSchemaVersions("second-user-token", "thing-id")
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 1) {
+		assert.Equal(t, []entities.Schema{dimmer}, versions[0].Schema)
+	}
+
+	// unregistering the first user's thing keeps the second user's versions
+	assert.NoError(t, thingInteractor.Unregister("first-user-token", "thing-id"))
+	versions, err = thingInteractor.ListSchemaVersions("second-user-token", "thing-id")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+}
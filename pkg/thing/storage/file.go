@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"github.com/CESARBR/knot-babeltower/internal/jsonfile"
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// FileSchemaStore keeps the schema versions in memory and writes them to a
+// JSON file on every change, so they're restored when the service restarts
+type FileSchemaStore struct {
+	file   *jsonfile.File
+	memory *MemorySchemaStore
+}
+
+// NewFileSchemaStore creates a new FileSchemaStore instance keeping up to
+// maxVersions versions of each thing's schema and loading the ones
+// previously written to the file, which is created when it doesn't exist yet
+func NewFileSchemaStore(path string, maxVersions int) (*FileSchemaStore, error) {
+	s := &FileSchemaStore{memory: NewMemorySchemaStore(maxVersions)}
+	s.file = jsonfile.New(path, "schemas", 0, func() interface{} { return s.memory.versions })
+
+	err := s.file.Load(&s.memory.versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Append stores the schema as the thing's next version and writes the file
+func (s *FileSchemaStore) Append(thingID string, version entities.SchemaVersion) (entities.SchemaVersion, error) {
+	err := s.file.Update(func() error {
+		var err error
+		version, err = s.memory.Append(thingID, version)
+		return err
+	})
+
+	return version, err
+}
+
+// List returns the thing's schema versions from the oldest, which are empty
+// when its schema was never updated
+func (s *FileSchemaStore) List(thingID string) ([]entities.SchemaVersion, error) {
+	return s.memory.List(thingID)
+}
+
+// Remove removes all the thing's schema versions and writes the file
+func (s *FileSchemaStore) Remove(thingID string) error {
+	return s.file.Update(func() error { return s.memory.Remove(thingID) })
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// MemorySchemaStore keeps the schema versions in memory, so they're lost
+// when the service is restarted. Only the most recent versions of each thing
+// are kept, up to the maximum.
+type MemorySchemaStore struct {
+	maxVersions int
+	mutex       sync.RWMutex
+	versions    map[string][]entities.SchemaVersion
+}
+
+// NewMemorySchemaStore creates a new MemorySchemaStore instance keeping up to
+// maxVersions versions of each thing's schema. Zero disables the limit.
+func NewMemorySchemaStore(maxVersions int) *MemorySchemaStore {
+	return &MemorySchemaStore{
+		maxVersions: maxVersions,
+		versions:    map[string][]entities.SchemaVersion{},
+	}
+}
+
+// Append stores the schema as the thing's next version, numbered after the
+// latest one, and removes the oldest versions exceeding the maximum
+func (s *MemorySchemaStore) Append(thingID string, version entities.SchemaVersion) (entities.SchemaVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	versions := s.versions[thingID]
+	version.Version = 1
+	if len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+
+	versions = append(versions, version)
+	if s.maxVersions > 0 && len(versions) > s.maxVersions {
+		versions = append([]entities.SchemaVersion{}, versions[len(versions)-s.maxVersions:]...)
+	}
+	s.versions[thingID] = versions
+
+	return version, nil
+}
+
+// List returns the thing's schema versions from the oldest, which are empty
+// when its schema was never updated
+func (s *MemorySchemaStore) List(thingID string) ([]entities.SchemaVersion, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versions := append([]entities.SchemaVersion{}, s.versions[thingID]...)
+	return versions, nil
+}
+
+// Remove removes all the thing's schema versions
+func (s *MemorySchemaStore) Remove(thingID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.versions, thingID)
+	return nil
+}
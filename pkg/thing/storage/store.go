@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+)
+
+// SchemaStore represents the storage of the things' schema versions, which
+// are kept by the things' Mainflux IDs, since the things of different users
+// may have the same ID
+type SchemaStore interface {
+	Append(thingID string, version entities.SchemaVersion) (entities.SchemaVersion, error)
+	List(thingID string) ([]entities.SchemaVersion, error)
+	Remove(thingID string) error
+}
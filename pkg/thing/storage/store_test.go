@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaStores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T) SchemaStore
+	}{
+		{
+			"memory",
+			func(t *testing.T) SchemaStore {
+				return NewMemorySchemaStore(2)
+			},
+		},
+		{
+			"file",
+			func(t *testing.T) SchemaStore {
+				store, err := NewFileSchemaStore(filepath.Join(tempDir(t), "data", "schemas.json"), 2)
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := tc.newStore(t)
+			versions, err := store.List("thing-id")
+			assert.NoError(t, err)
+			assert.Empty(t, versions)
+
+			for _, name := range []string{"led", "switch", "lamp"} {
+				schema := []entities.Schema{{SensorID: 1, ValueType: 3, TypeID: 0xFFF1, Name: name}}
+				_, err := store.Append("thing-id", entities.SchemaVersion{Schema: schema})
+				assert.NoError(t, err)
+			}
+			version, err := store.Append("other-id", entities.SchemaVersion{})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, version.Version)
+
+			versions, err = store.List("thing-id")
+			assert.NoError(t, err)
+			if assert.Len(t, versions, 2) {
+				assert.Equal(t, 2, versions[0].Version)
+				assert.Equal(t, "switch", versions[0].Schema[0].Name)
+				assert.Equal(t, 3, versions[1].Version)
+				assert.Equal(t, "lamp", versions[1].Schema[0].Name)
+			}
+
+			assert.NoError(t, store.Remove("thing-id"))
+			versions, err = store.List("thing-id")
+			assert.NoError(t, err)
+			assert.Empty(t, versions)
+
+			version, err = store.Append("thing-id", entities.SchemaVersion{})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, version.Version)
+		})
+	}
+}
+
+func TestFileSchemaStoreRestoresVersions(t *testing.T) {
+	path := filepath.Join(tempDir(t), "schemas.json")
+	schema := []entities.Schema{{SensorID: 1, ValueType: 3, TypeID: 0xFFF1, Name: "led"}}
+	store, err := NewFileSchemaStore(path, 0)
+	assert.NoError(t, err)
+	version, err := store.Append("thing-id", entities.SchemaVersion{
+		Schema:    schema,
+		Diff:      entities.DiffSchemas(nil, schema),
+		CreatedAt: time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	restored, err := NewFileSchemaStore(path, 0)
+	assert.NoError(t, err)
+
+	versions, err := restored.List("thing-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.SchemaVersion{version}, versions)
+}
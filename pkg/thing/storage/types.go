@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadTypeRegistry reads the sensor types registry from the file, which is
+// parsed as JSON when its extension is .json and as YAML otherwise
+func LoadTypeRegistry(path string) (*entities.TypeRegistry, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sensor types file: %w", err)
+	}
+
+	registry := &entities.TypeRegistry{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(content, registry)
+	} else {
+		err = yaml.UnmarshalStrict(content, registry)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sensor types file: %w", err)
+	}
+
+	err = validateRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	// the types without units are listed with an empty array of units
+	for i := range registry.Types {
+		if registry.Types[i].Units == nil {
+			registry.Types[i].Units = []entities.Unit{}
+		}
+	}
+
+	return registry, nil
+}
+
+// validateRegistry verifies the IDs are unique, the sensor types only
+// reference the registered value types and their units are convertible to
+// their base units
+func validateRegistry(registry *entities.TypeRegistry) error {
+	valueTypes := map[int]bool{}
+	for _, v := range registry.ValueTypes {
+		if v.ID <= 0 || v.Name == "" {
+			return fmt.Errorf("%w: value type %d must have a positive ID and a name", entities.ErrTypeRegistryInvalid, v.ID)
+		}
+		if valueTypes[v.ID] {
+			return fmt.Errorf("%w: value type %d registered twice", entities.ErrTypeRegistryInvalid, v.ID)
+		}
+		valueTypes[v.ID] = true
+	}
+
+	types := map[int]bool{}
+	for _, t := range registry.Types {
+		if t.ID <= 0 || t.Name == "" {
+			return fmt.Errorf("%w: type %#x must have a positive ID and a name", entities.ErrTypeRegistryInvalid, t.ID)
+		}
+		if types[t.ID] {
+			return fmt.Errorf("%w: type %#x registered twice", entities.ErrTypeRegistryInvalid, t.ID)
+		}
+		types[t.ID] = true
+
+		if len(t.ValueTypes) == 0 {
+			return fmt.Errorf("%w: type %#x has no value types", entities.ErrTypeRegistryInvalid, t.ID)
+		}
+		for _, v := range t.ValueTypes {
+			if !valueTypes[v] {
+				return fmt.Errorf("%w: type %#x references the unknown value type %d", entities.ErrTypeRegistryInvalid, t.ID, v)
+			}
+		}
+
+		units := map[int]bool{}
+		for _, u := range t.Units {
+			if u.ID <= 0 || u.Name == "" {
+				return fmt.Errorf("%w: unit %d of type %#x must have a positive ID and a name", entities.ErrTypeRegistryInvalid, u.ID, t.ID)
+			}
+			if units[u.ID] {
+				return fmt.Errorf("%w: unit %d of type %#x registered twice", entities.ErrTypeRegistryInvalid, u.ID, t.ID)
+			}
+			units[u.ID] = true
+
+			if u.Factor < 0 {
+				return fmt.Errorf("%w: unit %d of type %#x has a negative factor", entities.ErrTypeRegistryInvalid, u.ID, t.ID)
+			}
+			if u.Factor > 0 && t.BaseUnit == 0 {
+				return fmt.Errorf("%w: unit %d of type %#x has a factor, but the type has no base unit", entities.ErrTypeRegistryInvalid, u.ID, t.ID)
+			}
+		}
+
+		if t.BaseUnit != 0 {
+			base, ok := t.Unit(t.BaseUnit)
+			if !ok || base.Factor != 1 || base.Offset != 0 {
+				return fmt.Errorf("%w: base unit %d of type %#x must be registered with factor 1 and no offset", entities.ErrTypeRegistryInvalid, t.BaseUnit, t.ID)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CESARBR/knot-babeltower/pkg/thing/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "types")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestLoadDefaultTypeRegistry(t *testing.T) {
+	registry, err := LoadTypeRegistry("../../../internal/config/types.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	energy, ok := registry.Type(0x15)
+	if assert.True(t, ok) {
+		assert.Equal(t, "ENERGY", energy.Name)
+		assert.True(t, energy.AllowsValueType(1))
+		assert.True(t, energy.AllowsUnit(4))
+		assert.False(t, energy.AllowsUnit(0))
+	}
+
+	command, ok := registry.Type(0xFFF2)
+	if assert.True(t, ok) {
+		assert.True(t, command.AllowsValueType(4))
+		assert.True(t, command.AllowsUnit(0))
+		assert.False(t, command.AllowsUnit(1))
+	}
+
+	_, ok = registry.Type(0xFFFF)
+	assert.False(t, ok)
+}
+
+func TestConvertDefaultUnits(t *testing.T) {
+	registry, err := LoadTypeRegistry("../../../internal/config/types.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		name     string
+		typeID   int
+		value    float64
+		from     int
+		to       int
+		expected float64
+		ok       bool
+	}{
+		{"celsius to kelvin", 0x05, 20, 1, 3, 293.15, true},
+		{"fahrenheit to kelvin", 0x05, 68, 2, 3, 293.15, true},
+		{"celsius to fahrenheit", 0x05, 100, 1, 2, 212, true},
+		{"kilowatt-hour to joule", 0x15, 2, 4, 1, 7200000, true},
+		{"psi to pascal", 0x0A, 1, 2, 1, 6894.757293168361, true},
+		{"miles to kilometers", 0x0B, 10, 3, 4, 16.09344, true},
+		{"same unit", 0x01, 220, 1, 1, 220, true},
+		{"unit without factor", 0x07, 800, 1, 3, 0, false},
+		{"unit not registered", 0x01, 220, 1, 7, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sensorType, ok := registry.Type(tc.typeID)
+			if !assert.True(t, ok) {
+				return
+			}
+
+			converted, ok := sensorType.Convert(tc.value, tc.from, tc.to)
+			assert.Equal(t, tc.ok, ok)
+			assert.InDelta(t, tc.expected, converted, 1e-9)
+		})
+	}
+}
+
+func TestLoadTypeRegistry(t *testing.T) {
+	testCases := []struct {
+		name          string
+		file          string
+		content       string
+		expected      *entities.TypeRegistry
+		expectedError error
+	}{
+		{
+			"registry loaded from json",
+			"types.json",
+			`{"valueTypes": [{"id": 1, "name": "INT"}], "types": [{"id": 5, "name": "TEMPERATURE", "valueTypes": [1], "units": [{"id": 1, "name": "CELSIUS", "symbol": "°C"}]}]}`,
+			&entities.TypeRegistry{
+				ValueTypes: []entities.ValueType{{ID: 1, Name: "INT"}},
+				Types:      []entities.SensorType{{ID: 5, Name: "TEMPERATURE", ValueTypes: []int{1}, Units: []entities.Unit{{ID: 1, Name: "CELSIUS", Symbol: "°C"}}}},
+			},
+			nil,
+		},
+		{
+			"registry loaded from yaml with hexadecimal IDs",
+			"types.yaml",
+			"valueTypes: [{id: 3, name: BOOL}]\ntypes: [{id: 0xFFF1, name: SWITCH, valueTypes: [3]}]\n",
+			&entities.TypeRegistry{
+				ValueTypes: []entities.ValueType{{ID: 3, Name: "BOOL"}},
+				Types:      []entities.SensorType{{ID: 0xFFF1, Name: "SWITCH", ValueTypes: []int{3}, Units: []entities.Unit{}}},
+			},
+			nil,
+		},
+		{
+			"type registered twice",
+			"types.yaml",
+			"valueTypes: [{id: 3, name: BOOL}]\ntypes: [{id: 1, name: SWITCH, valueTypes: [3]}, {id: 1, name: PRESENCE, valueTypes: [3]}]\n",
+			nil,
+			entities.ErrTypeRegistryInvalid,
+		},
+		{
+			"type referencing an unknown value type",
+			"types.yaml",
+			"valueTypes: [{id: 3, name: BOOL}]\ntypes: [{id: 1, name: SWITCH, valueTypes: [4]}]\n",
+			nil,
+			entities.ErrTypeRegistryInvalid,
+		},
+		{
+			"unit registered twice",
+			"types.yaml",
+			"valueTypes: [{id: 1, name: INT}]\ntypes: [{id: 1, name: VOLTAGE, valueTypes: [1], units: [{id: 1, name: VOLT}, {id: 1, name: MILLIVOLT}]}]\n",
+			nil,
+			entities.ErrTypeRegistryInvalid,
+		},
+		{
+			"base unit with a factor other than one",
+			"types.yaml",
+			"valueTypes: [{id: 1, name: INT}]\ntypes: [{id: 1, name: VOLTAGE, valueTypes: [1], baseUnit: 2, units: [{id: 1, name: VOLT, factor: 1}, {id: 2, name: MILLIVOLT, factor: 0.001}]}]\n",
+			nil,
+			entities.ErrTypeRegistryInvalid,
+		},
+		{
+			"unit with a factor on a type without base unit",
+			"types.yaml",
+			"valueTypes: [{id: 1, name: INT}]\ntypes: [{id: 1, name: VOLTAGE, valueTypes: [1], units: [{id: 1, name: VOLT, factor: 1}]}]\n",
+			nil,
+			entities.ErrTypeRegistryInvalid,
+		},
+		{
+			"unit without name",
+			"types.yaml",
+			"valueTypes: [{id: 1, name: INT}]\ntypes: [{id: 1, name: VOLTAGE, valueTypes: [1], units: [{id: 1}]}]\n",
+			nil,
+			entities.ErrTypeRegistryInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(tempDir(t), tc.file)
+			assert.NoError(t, ioutil.WriteFile(path, []byte(tc.content), 0600))
+
+			registry, err := LoadTypeRegistry(path)
+
+			assert.True(t, errors.Is(err, tc.expectedError))
+			assert.Equal(t, tc.expected, registry)
+		})
+	}
+}
+
+func TestLoadMalformedTypeRegistry(t *testing.T) {
+	path := filepath.Join(tempDir(t), "types.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("types: [{id: 1, name: VOLTAGE, unknown: true}]\n"), 0600))
+
+	_, err := LoadTypeRegistry(path)
+	assert.Error(t, err)
+
+	_, err = LoadTypeRegistry(filepath.Join(tempDir(t), "missing.yaml"))
+	assert.Error(t, err)
+}
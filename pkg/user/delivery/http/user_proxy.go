@@ -15,6 +15,7 @@ import (
 type UserProxy interface {
 	Create(user entities.User) (err error)
 	CreateToken(user entities.User) (string, error)
+	Identify(token string) (string, error)
 }
 
 // Proxy is responsible for implementing the user's proxy operations
@@ -28,6 +29,11 @@ type TokenResponse struct {
 	Token string `json:"token"`
 }
 
+// UserInfoResponse represents the user's information from the user's service
+type UserInfoResponse struct {
+	Email string `json:"email"`
+}
+
 // NewUserProxy creates a new Proxy instance
 func NewUserProxy(logger logging.Logger, hostname string, port uint16) *Proxy {
 	url := fmt.Sprintf("http://%s:%d", hostname, port)
@@ -88,6 +94,40 @@ func (p *Proxy) CreateToken(user entities.User) (string, error) {
 	return tr.Token, nil
 }
 
+// Identify returns the e-mail of the user the token belongs to
+func (p *Proxy) Identify(token string) (string, error) {
+	p.logger.Debug("proxying request to identify user")
+
+	req, err := http.NewRequest(http.MethodGet, p.url+"/users", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", token)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = p.mapErrorFromStatusCode(resp.StatusCode)
+		if err == nil {
+			err = fmt.Errorf("unexpected status code %d identifying user", resp.StatusCode)
+		}
+		return "", err
+	}
+
+	info := &UserInfoResponse{}
+	err = json.NewDecoder(resp.Body).Decode(info)
+	if err != nil {
+		return "", err
+	}
+
+	return info.Email, nil
+}
+
 func (p *Proxy) mapErrorFromStatusCode(code int) error {
 	var err error
 